@@ -1,6 +1,9 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
 	"strings"
@@ -11,7 +14,8 @@ import (
 	// Config
 	"github.com/RodrigoGonzalez78/config"
 
-	// Domain services
+	// Domain
+	"github.com/RodrigoGonzalez78/internal/domain/repository"
 	"github.com/RodrigoGonzalez78/internal/domain/service"
 
 	// Application use cases
@@ -20,6 +24,8 @@ import (
 
 	// Infrastructure
 	gormDB "github.com/RodrigoGonzalez78/internal/infrastructure/persistence/gorm"
+	fsStorage "github.com/RodrigoGonzalez78/internal/infrastructure/storage/filesystem"
+	memoryStorage "github.com/RodrigoGonzalez78/internal/infrastructure/storage/memory"
 	minioStorage "github.com/RodrigoGonzalez78/internal/infrastructure/storage/minio"
 
 	// HTTP
@@ -43,6 +49,8 @@ import (
 // @name Authorization
 // @description Escribe "Bearer " seguido del token JWT obtenido en /login
 func main() {
+	backfillHashes := flag.Bool("backfill-hashes", false, "calcula el hash perceptual de las imágenes guardadas que no lo tienen y termina")
+	flag.Parse()
 
 	config.LoadConfig()
 
@@ -59,35 +67,97 @@ func main() {
 		log.Fatal(" Error al migrar la base de datos:", err)
 	}
 
-	fileStorage, err := minioStorage.NewFileStorage(
-		config.Cnf.MinioEndpoint,
-		config.Cnf.MinioAccessKey,
-		config.Cnf.MinioSecretKey,
-		config.Cnf.MinioBucket,
-		config.Cnf.MinioUseSSL,
-	)
+	fileStorage, err := newFileStorage(config.Cnf)
 	if err != nil {
-		log.Fatal(" Error al conectar a MinIO:", err)
+		log.Fatal(" Error al inicializar el storage:", err)
 	}
+	// Toda lectura de un blob comprueba su checksum
+	fileStorage = imageUC.NewVerifiedStorage(fileStorage)
 
 	userRepo := gormDB.NewUserRepository(database.DB)
 	imageRepo := gormDB.NewImageRepository(database.DB)
+	refreshTokenRepo := gormDB.NewRefreshTokenRepository(database.DB)
+	revokedTokenRepo := gormDB.NewRevokedTokenRepository(database.DB)
+	transformJobRepo := gormDB.NewTransformJobRepository(database.DB)
+	fontRepo := gormDB.NewFontRepository(database.DB)
+	imageMetadataRepo := gormDB.NewImageMetadataRepository(database.DB)
+	presetRepo := gormDB.NewPresetRepository(database.DB)
+	renditionRepo := gormDB.NewRenditionRepository(database.DB)
+	blobRepo := gormDB.NewBlobRepository(database.DB)
+	resumableUploadRepo := gormDB.NewResumableUploadRepository(database.DB)
 
 	passwordService := service.NewPasswordService()
-	tokenService := service.NewTokenService(config.Cnf.JWTSecret, 24*time.Hour)
+	tokenService := service.NewTokenService(config.Cnf.JWTSecret, time.Duration(config.Cnf.AccessTokenMinutes)*time.Minute)
+	refreshExpiry := time.Duration(config.Cnf.RefreshTokenDays) * 24 * time.Hour
+	urlSigner := service.NewURLSigner(config.Cnf.URLSignSecret)
 
 	registerUC := authUC.NewRegisterUseCase(userRepo, passwordService)
-	loginUC := authUC.NewLoginUseCase(userRepo, passwordService, tokenService)
+	loginUC := authUC.NewLoginUseCase(userRepo, passwordService, tokenService, refreshTokenRepo, refreshExpiry)
+	refreshUC := authUC.NewRefreshUseCase(refreshTokenRepo, tokenService, refreshExpiry)
+	logoutUC := authUC.NewLogoutUseCase(refreshTokenRepo, revokedTokenRepo, tokenService)
 
-	uploadUC := imageUC.NewUploadUseCase(imageRepo, fileStorage, config.Cnf.BaseURL, config.Cnf.Port)
-	getImageUC := imageUC.NewGetUseCase(imageRepo, config.Cnf.BaseURL, config.Cnf.Port)
+	var derivativeCache *imageUC.DerivativeCache
+	if config.Cnf.CacheEnabled {
+		derivativeCache = imageUC.NewDerivativeCache(fileStorage, int64(config.Cnf.CacheMemoryMB)<<20)
+	}
+
+	imageLimits := imageUC.ImageLimits{
+		MaxBytes:  int64(config.Cnf.MaxUploadMB) << 20,
+		MaxWidth:  config.Cnf.MaxImageWidth,
+		MaxHeight: config.Cnf.MaxImageHeight,
+		MaxPixels: int64(config.Cnf.MaxImageMegapixels) * 1_000_000,
+	}
+
+	blobStore := imageUC.NewBlobStore(blobRepo, fileStorage)
+
+	var renditionUC *imageUC.RenditionUseCase
+	if config.Cnf.RenditionsEnabled {
+		renditionUC, err = imageUC.NewRenditionUseCase(imageRepo, renditionRepo, fileStorage, imageLimits, config.Cnf.RenditionWidths, config.Cnf.RenditionFormats, config.Cnf.RenditionQueueSize)
+		if err != nil {
+			log.Fatal(" Error en la configuración de versiones responsive:", err)
+		}
+	}
+
+	uploadUC := imageUC.NewUploadUseCase(imageRepo, imageMetadataRepo, blobStore, renditionUC, imageLimits, config.Cnf.BaseURL, config.Cnf.Port)
+	getImageUC := imageUC.NewGetUseCase(imageRepo, renditionRepo, config.Cnf.BaseURL, config.Cnf.Port)
 	listImagesUC := imageUC.NewListUseCase(imageRepo, config.Cnf.BaseURL, config.Cnf.Port)
-	transformUC := imageUC.NewTransformUseCase(imageRepo, fileStorage)
 
-	authHandler := handler.NewAuthHandler(registerUC, loginUC)
-	imageHandler := handler.NewImageHandler(uploadUC, getImageUC, listImagesUC, transformUC)
+	transformUC := imageUC.NewTransformUseCase(imageRepo, fontRepo, fileStorage, derivativeCache, imageLimits)
+	deleteImageUC := imageUC.NewDeleteUseCase(imageRepo, transformJobRepo, fileStorage, blobStore, derivativeCache)
+	transformJobUC := imageUC.NewTransformJobUseCase(transformJobRepo, imageRepo, fileStorage, transformUC, config.Cnf.BaseURL, config.Cnf.Port, config.Cnf.JobQueueSize, time.Duration(config.Cnf.JobRetentionHours)*time.Hour)
+	signedURLUC := imageUC.NewSignedURLUseCase(imageRepo, transformUC, urlSigner, config.Cnf.BaseURL, config.Cnf.Port)
+	watermarkUC := imageUC.NewWatermarkUseCase(userRepo, imageRepo)
+	fontUC := imageUC.NewFontUseCase(fontRepo, fileStorage)
+	metadataUC := imageUC.NewMetadataUseCase(imageRepo, imageMetadataRepo)
+	presetUC := imageUC.NewPresetUseCase(presetRepo, transformUC)
+	batchUploadUC := imageUC.NewBatchUploadUseCase(uploadUC, imageLimits, config.Cnf.BatchUploadWorkers, config.Cnf.BatchUploadMaxFiles)
+	resumableUploadUC := imageUC.NewResumableUploadUseCase(resumableUploadRepo, imageRepo, fileStorage, uploadUC, imageLimits, time.Duration(config.Cnf.TusExpirationMinutes)*time.Minute, int64(config.Cnf.TusPartMB)<<20)
+	derivativeUC := imageUC.NewDerivativeUseCase(imageRepo, transformUC, uploadUC, config.Cnf.BaseURL, config.Cnf.Port)
+	similarityUC := imageUC.NewSimilarityUseCase(imageRepo, fileStorage, imageLimits, config.Cnf.BaseURL, config.Cnf.Port)
+	privacyUC := imageUC.NewPrivacyUseCase(userRepo, imageRepo, imageMetadataRepo, fileStorage, blobStore, derivativeCache, config.Cnf.BaseURL, config.Cnf.Port)
+
+	if *backfillHashes {
+		output, err := similarityUC.Backfill(context.Background(), 100)
+		if err != nil {
+			log.Fatal(" Error al calcular los hashes perceptuales:", err)
+		}
+		log.Printf(" Hashes perceptuales calculados: %d (fallidos: %d)", output.Hashed, output.Failed)
+		return
+	}
+
+	authHandler := handler.NewAuthHandler(registerUC, loginUC, refreshUC, logoutUC)
+	imageHandler := handler.NewImageHandler(uploadUC, batchUploadUC, getImageUC, listImagesUC, transformUC, deleteImageUC, watermarkUC, metadataUC, privacyUC, presetUC, imageLimits)
+	signedURLHandler := handler.NewSignedURLHandler(signedURLUC, transformUC, watermarkUC)
+	jobHandler := handler.NewJobHandler(transformJobUC)
+	watermarkHandler := handler.NewWatermarkHandler(watermarkUC)
+	fontHandler := handler.NewFontHandler(fontUC)
+	privacyHandler := handler.NewPrivacyHandler(privacyUC)
+	presetHandler := handler.NewPresetHandler(presetUC)
+	derivativeHandler := handler.NewDerivativeHandler(derivativeUC, presetUC)
+	similarityHandler := handler.NewSimilarityHandler(similarityUC)
+	tusHandler := handler.NewTusHandler(resumableUploadUC, privacyUC, imageLimits)
 
-	jwtMiddleware := middleware.NewJWTMiddleware(tokenService)
+	jwtMiddleware := middleware.NewJWTMiddleware(tokenService, revokedTokenRepo)
 
 	r := mux.NewRouter()
 
@@ -95,15 +165,89 @@ func main() {
 
 	r.HandleFunc("/register", authHandler.Register).Methods("POST")
 	r.HandleFunc("/login", authHandler.Login).Methods("POST")
+	r.HandleFunc("/token/refresh", authHandler.Refresh).Methods("POST")
+	r.HandleFunc("/logout", jwtMiddleware.Authenticate(authHandler.Logout)).Methods("POST")
+
+	// Se registra antes de la ruta pública de archivos, que acepta cualquier
+	// ruta bajo /images/
+	r.HandleFunc("/images/{id:[0-9]+}", jwtMiddleware.Authenticate(imageHandler.GetImage)).Methods("GET")
+	r.HandleFunc("/images/{id:[0-9]+}/metadata", jwtMiddleware.Authenticate(imageHandler.GetImageMetadata)).Methods("GET")
+	r.HandleFunc("/images/{id:[0-9]+}/derivatives", jwtMiddleware.Authenticate(derivativeHandler.ListDerivatives)).Methods("GET")
+	r.HandleFunc("/images/{id:[0-9]+}/similar", jwtMiddleware.Authenticate(similarityHandler.FindSimilar)).Methods("GET")
+	r.HandleFunc("/images/{id:[0-9]+}/lineage", jwtMiddleware.Authenticate(derivativeHandler.GetLineage)).Methods("GET")
 
 	r.HandleFunc("/images/{rest:.*}", imageHandler.ServeImage).Methods("GET")
+	r.HandleFunc("/t/{signature}/{rest:.*}", signedURLHandler.ServeTransform).Methods("GET")
 
 	r.HandleFunc("/upload", jwtMiddleware.Authenticate(imageHandler.Upload)).Methods("POST")
+	r.HandleFunc("/upload/batch", jwtMiddleware.Authenticate(imageHandler.UploadBatch)).Methods("POST")
+	r.HandleFunc("/uploads/tus", tusHandler.Options).Methods("OPTIONS")
+	r.HandleFunc("/uploads/tus", jwtMiddleware.Authenticate(tusHandler.Create)).Methods("POST")
+	r.HandleFunc("/uploads/tus/{id}", tusHandler.Options).Methods("OPTIONS")
+	r.HandleFunc("/uploads/tus/{id}", jwtMiddleware.Authenticate(tusHandler.Head)).Methods("HEAD")
+	r.HandleFunc("/uploads/tus/{id}", jwtMiddleware.Authenticate(tusHandler.Patch)).Methods("PATCH")
+	r.HandleFunc("/uploads/tus/{id}", jwtMiddleware.Authenticate(tusHandler.Terminate)).Methods("DELETE")
 	r.HandleFunc("/user-images", jwtMiddleware.Authenticate(imageHandler.ListUserImages)).Methods("GET")
-	r.HandleFunc("/images/{id}", jwtMiddleware.Authenticate(imageHandler.GetImage)).Methods("GET")
+	r.HandleFunc("/user-images/gps", jwtMiddleware.Authenticate(privacyHandler.AuditGPS)).Methods("GET")
+	r.HandleFunc("/images/{id}", jwtMiddleware.Authenticate(imageHandler.DeleteImage)).Methods("DELETE")
 	r.HandleFunc("/images/{id}/transform", jwtMiddleware.Authenticate(imageHandler.TransformImage)).Methods("POST")
+	r.HandleFunc("/images/{id}/derivatives", jwtMiddleware.Authenticate(derivativeHandler.CreateDerivative)).Methods("POST")
+	r.HandleFunc("/images/{id}/signed-url", jwtMiddleware.Authenticate(signedURLHandler.CreateSignedURL)).Methods("POST")
+	r.HandleFunc("/images/{id}/strip-metadata", jwtMiddleware.Authenticate(privacyHandler.StripMetadata)).Methods("POST")
+	r.HandleFunc("/images/{id}/transform-jobs", jwtMiddleware.Authenticate(jobHandler.CreateTransformJob)).Methods("POST")
+	r.HandleFunc("/jobs/{id}", jwtMiddleware.Authenticate(jobHandler.GetJob)).Methods("GET")
+	r.HandleFunc("/jobs/{id}/cancel", jwtMiddleware.Authenticate(jobHandler.CancelJob)).Methods("POST")
+	r.HandleFunc("/account/watermark", jwtMiddleware.Authenticate(watermarkHandler.GetDefault)).Methods("GET")
+	r.HandleFunc("/account/watermark", jwtMiddleware.Authenticate(watermarkHandler.SetDefault)).Methods("PUT")
+	r.HandleFunc("/account/watermark", jwtMiddleware.Authenticate(watermarkHandler.ClearDefault)).Methods("DELETE")
+	r.HandleFunc("/account/metadata-policy", jwtMiddleware.Authenticate(privacyHandler.GetPolicy)).Methods("GET")
+	r.HandleFunc("/account/metadata-policy", jwtMiddleware.Authenticate(privacyHandler.SetPolicy)).Methods("PUT")
+	r.HandleFunc("/presets", jwtMiddleware.Authenticate(presetHandler.ListPresets)).Methods("GET")
+	r.HandleFunc("/presets", jwtMiddleware.Authenticate(presetHandler.CreatePreset)).Methods("POST")
+	r.HandleFunc("/presets/{id}", jwtMiddleware.Authenticate(presetHandler.GetPreset)).Methods("GET")
+	r.HandleFunc("/presets/{id}", jwtMiddleware.Authenticate(presetHandler.UpdatePreset)).Methods("PUT")
+	r.HandleFunc("/presets/{id}", jwtMiddleware.Authenticate(presetHandler.DeletePreset)).Methods("DELETE")
+	r.HandleFunc("/fonts", jwtMiddleware.Authenticate(fontHandler.ListFonts)).Methods("GET")
+	r.HandleFunc("/fonts", jwtMiddleware.Authenticate(fontHandler.UploadFont)).Methods("POST")
+	r.HandleFunc("/fonts/{id}", jwtMiddleware.Authenticate(fontHandler.DeleteFont)).Methods("DELETE")
+
+	// Completar en segundo plano las eliminaciones que quedaron a medias
+	go deleteImageUC.StartReconciler(context.Background(), time.Duration(config.Cnf.DeleteReconcileMinutes)*time.Minute)
+
+	// Borrar en segundo plano las subidas reanudables abandonadas
+	go resumableUploadUC.StartJanitor(context.Background(), time.Duration(config.Cnf.TusCleanupMinutes)*time.Minute)
+
+	// Workers de transformaciones asíncronas
+	go transformJobUC.Start(context.Background(), config.Cnf.JobWorkers, 5*time.Second)
+	go transformJobUC.StartJanitor(context.Background(), time.Duration(config.Cnf.JobCleanupMinutes)*time.Minute)
+
+	// Workers de versiones responsive
+	if renditionUC != nil {
+		go renditionUC.Start(context.Background(), config.Cnf.RenditionWorkers)
+	}
 
 	log.Println(" Servidor iniciado en el puerto:", config.Cnf.Port)
 	log.Println(" Swagger UI disponible en: http://localhost:" + config.Cnf.Port + "/swagger/index.html")
 	http.ListenAndServe(":"+config.Cnf.Port, r)
 }
+
+// newFileStorage crea el backend de storage indicado por STORAGE_DRIVER
+func newFileStorage(cnf *config.Config) (repository.FileStorage, error) {
+	switch cnf.StorageDriver {
+	case "minio":
+		return minioStorage.NewFileStorage(
+			cnf.MinioEndpoint,
+			cnf.MinioAccessKey,
+			cnf.MinioSecretKey,
+			cnf.MinioBucket,
+			cnf.MinioUseSSL,
+		)
+	case "fs":
+		return fsStorage.NewFileStorage(cnf.StorageFSRoot)
+	case "memory":
+		log.Println("  Usando storage en memoria: las imágenes se pierden al reiniciar")
+		return memoryStorage.NewFileStorage(), nil
+	default:
+		return nil, fmt.Errorf("STORAGE_DRIVER desconocido: %q (valores válidos: minio, fs, memory)", cnf.StorageDriver)
+	}
+}
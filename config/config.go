@@ -4,19 +4,46 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Port           string
-	JWTSecret      string
-	BaseURL        string
-	MinioEndpoint  string
-	MinioAccessKey string
-	MinioSecretKey string
-	MinioBucket    string
-	MinioUseSSL    bool
+	Port                   string
+	JWTSecret              string
+	BaseURL                string
+	StorageDriver          string
+	StorageFSRoot          string
+	MinioEndpoint          string
+	MinioAccessKey         string
+	MinioSecretKey         string
+	MinioBucket            string
+	MinioUseSSL            bool
+	URLSignSecret          string
+	CacheEnabled           bool
+	CacheMemoryMB          int
+	DeleteReconcileMinutes int
+	AccessTokenMinutes     int
+	RefreshTokenDays       int
+	JobWorkers             int
+	JobQueueSize           int
+	JobRetentionHours      int
+	JobCleanupMinutes      int
+	MaxUploadMB            int
+	MaxImageWidth          int
+	MaxImageHeight         int
+	MaxImageMegapixels     int
+	RenditionsEnabled      bool
+	RenditionWidths        []int
+	RenditionFormats       []string
+	RenditionWorkers       int
+	RenditionQueueSize     int
+	TusExpirationMinutes   int
+	TusCleanupMinutes      int
+	TusPartMB              int
+	BatchUploadWorkers     int
+	BatchUploadMaxFiles    int
 }
 
 var Cnf *Config
@@ -50,6 +77,57 @@ func getEnvBool(key string, fallback bool) bool {
 	return boolValue
 }
 
+func getEnvInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	intValue, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("  No se pudo parsear %s como entero, usando valor por defecto: %d", key, fallback)
+		return fallback
+	}
+
+	return intValue
+}
+
+// getEnvList lee una lista separada por comas e ignora los elementos vacíos
+func getEnvList(key, fallback string) []string {
+	return splitList(getEnv(key, fallback, false))
+}
+
+func getEnvIntList(key, fallback string) []int {
+	values, err := parseIntList(getEnvList(key, fallback))
+	if err != nil {
+		log.Printf("  No se pudo parsear %s como lista de enteros, usando valor por defecto: %s", key, fallback)
+		values, _ = parseIntList(splitList(fallback))
+	}
+	return values
+}
+
+func splitList(value string) []string {
+	var values []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
+func parseIntList(values []string) ([]int, error) {
+	ints := make([]int, len(values))
+	for i, value := range values {
+		intValue, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, err
+		}
+		ints[i] = intValue
+	}
+	return ints, nil
+}
+
 func LoadConfig() {
 	// Intentar cargar .env solo en desarrollo
 	if err := godotenv.Load(); err != nil {
@@ -57,14 +135,40 @@ func LoadConfig() {
 	}
 
 	Cnf = &Config{
-		Port:           getEnv("PORT", "8080", false),
-		JWTSecret:      getEnv("JWT_SECRET", "", true), // JWT_SECRET debería ser obligatorio
-		BaseURL:        getEnv("BASE_URL", "http://localhost", false),
-		MinioEndpoint:  getEnv("MINIO_ENDPOINT", "localhost:9000", false),
-		MinioAccessKey: getEnv("MINIO_ACCESS_KEY", "minioadmin", false),
-		MinioSecretKey: getEnv("MINIO_SECRET_KEY", "minioadmin", false),
-		MinioBucket:    getEnv("MINIO_BUCKET", "images", false),
-		MinioUseSSL:    getEnvBool("MINIO_USE_SSL", false),
+		Port:                   getEnv("PORT", "8080", false),
+		JWTSecret:              getEnv("JWT_SECRET", "", true), // JWT_SECRET debería ser obligatorio
+		BaseURL:                getEnv("BASE_URL", "http://localhost", false),
+		StorageDriver:          getEnv("STORAGE_DRIVER", "minio", false),
+		StorageFSRoot:          getEnv("STORAGE_FS_ROOT", "./data", false),
+		MinioEndpoint:          getEnv("MINIO_ENDPOINT", "localhost:9000", false),
+		MinioAccessKey:         getEnv("MINIO_ACCESS_KEY", "minioadmin", false),
+		MinioSecretKey:         getEnv("MINIO_SECRET_KEY", "minioadmin", false),
+		MinioBucket:            getEnv("MINIO_BUCKET", "images", false),
+		MinioUseSSL:            getEnvBool("MINIO_USE_SSL", false),
+		URLSignSecret:          getEnv("URL_SIGN_SECRET", "", false),
+		CacheEnabled:           getEnvBool("CACHE_ENABLED", true),
+		CacheMemoryMB:          getEnvInt("CACHE_MEMORY_MB", 64),
+		DeleteReconcileMinutes: getEnvInt("DELETE_RECONCILE_MINUTES", 5),
+		AccessTokenMinutes:     getEnvInt("ACCESS_TOKEN_MINUTES", 15),
+		RefreshTokenDays:       getEnvInt("REFRESH_TOKEN_DAYS", 30),
+		JobWorkers:             getEnvInt("JOB_WORKERS", 2),
+		JobQueueSize:           getEnvInt("JOB_QUEUE_SIZE", 100),
+		JobRetentionHours:      getEnvInt("JOB_RETENTION_HOURS", 168),
+		JobCleanupMinutes:      getEnvInt("JOB_CLEANUP_MINUTES", 60),
+		MaxUploadMB:            getEnvInt("MAX_UPLOAD_MB", 20),
+		MaxImageWidth:          getEnvInt("MAX_IMAGE_WIDTH", 10000),
+		MaxImageHeight:         getEnvInt("MAX_IMAGE_HEIGHT", 10000),
+		MaxImageMegapixels:     getEnvInt("MAX_IMAGE_MEGAPIXELS", 50),
+		RenditionsEnabled:      getEnvBool("RENDITIONS_ENABLED", true),
+		RenditionWidths:        getEnvIntList("RENDITION_WIDTHS", "320,640,1280,1920"),
+		RenditionFormats:       getEnvList("RENDITION_FORMATS", "original,webp"),
+		RenditionWorkers:       getEnvInt("RENDITION_WORKERS", 1),
+		RenditionQueueSize:     getEnvInt("RENDITION_QUEUE_SIZE", 100),
+		TusExpirationMinutes:   getEnvInt("TUS_EXPIRATION_MINUTES", 1440),
+		TusCleanupMinutes:      getEnvInt("TUS_CLEANUP_MINUTES", 15),
+		TusPartMB:              getEnvInt("TUS_PART_MB", 8),
+		BatchUploadWorkers:     getEnvInt("BATCH_UPLOAD_WORKERS", 4),
+		BatchUploadMaxFiles:    getEnvInt("BATCH_UPLOAD_MAX_FILES", 20),
 	}
 
 	// Validación adicional
@@ -72,9 +176,18 @@ func LoadConfig() {
 		log.Println("  ADVERTENCIA: Estás usando el JWT_SECRET por defecto. Cambia esto en producción.")
 	}
 
+	if Cnf.URLSignSecret == "" {
+		log.Println("  URL_SIGN_SECRET no está definida. Las URLs de transformación firmadas (/t/...) quedan deshabilitadas.")
+	}
+
 	log.Printf(" Configuración cargada exitosamente")
 	log.Printf(" Puerto: %s", Cnf.Port)
+	log.Printf(" Storage: %s", Cnf.StorageDriver)
 	log.Printf(" Minio Endpoint: %s", Cnf.MinioEndpoint)
 	log.Printf(" Minio Bucket: %s", Cnf.MinioBucket)
 	log.Printf(" Minio SSL: %t", Cnf.MinioUseSSL)
+	log.Printf(" Límites de imagen: %d MB, %dx%d, %d MP", Cnf.MaxUploadMB, Cnf.MaxImageWidth, Cnf.MaxImageHeight, Cnf.MaxImageMegapixels)
+	log.Printf(" Caché de derivados: %t (%d MB en memoria)", Cnf.CacheEnabled, Cnf.CacheMemoryMB)
+	log.Printf(" Versiones responsive: %t (anchos %v, formatos %v)", Cnf.RenditionsEnabled, Cnf.RenditionWidths, Cnf.RenditionFormats)
+	log.Printf(" Subidas reanudables: vencen a los %d minutos sin datos", Cnf.TusExpirationMinutes)
 }
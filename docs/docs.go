@@ -16,41 +16,26 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/images/{id}": {
+        "/account/metadata-policy": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Devuelve la metadata y URL de una imagen del usuario autenticado",
+                "description": "Devuelve qué metadatos se quitan de las imágenes que sube el usuario autenticado: keep (ninguno), strip_gps (solo la posición) o strip_all (todo salvo la orientación).",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "images"
-                ],
-                "summary": "Obtener información de una imagen",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "ID de la imagen",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "account"
                 ],
+                "summary": "Política de metadatos",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.ImageDetailResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/dto.ErrorResponse"
+                            "$ref": "#/definitions/dto.MetadataPolicyResponse"
                         }
                     },
                     "401": {
@@ -58,56 +43,42 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
-                    },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/dto.ErrorResponse"
-                        }
                     }
                 }
-            }
-        },
-        "/images/{id}/transform": {
-            "post": {
+            },
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Aplica transformaciones (resize, crop, rotación, filtros) a una imagen previamente cargada por el usuario",
+                "description": "Define qué metadatos se quitan de las próximas imágenes que suba el usuario. Cada subida puede indicar otra política con el campo \"metadata\". No modifica las imágenes ya guardadas (ver /images/{id}/strip-metadata).",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
-                    "image/png",
-                    "image/jpeg",
-                    "image/gif"
+                    "application/json"
                 ],
                 "tags": [
-                    "images"
+                    "account"
                 ],
-                "summary": "Aplica transformaciones a una imagen",
+                "summary": "Configura la política de metadatos",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID de la imagen",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Parámetros de transformación",
+                        "description": "Política",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.TransformationRequest"
+                            "$ref": "#/definitions/dto.MetadataPolicyRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Imagen transformada",
+                        "description": "OK",
                         "schema": {
-                            "type": "file"
+                            "$ref": "#/definitions/dto.MetadataPolicyResponse"
                         }
                     },
                     "400": {
@@ -116,14 +87,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/dto.ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
@@ -131,52 +96,49 @@ const docTemplate = `{
                 }
             }
         },
-        "/images/{rest}": {
+        "/account/watermark": {
             "get": {
-                "description": "Devuelve el archivo de imagen almacenado en el servidor desde la carpeta local \"uploads\", sin exponer su ruta real.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve la marca de agua que se aplica a las imágenes públicas del usuario autenticado.",
                 "produces": [
-                    "image/jpeg",
-                    "image/png",
-                    "image/gif"
+                    "application/json"
                 ],
                 "tags": [
-                    "images"
-                ],
-                "summary": "Servir imagen",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Ruta relativa de la imagen (por ejemplo: user123/imagen.jpg)",
-                        "name": "rest",
-                        "in": "path",
-                        "required": true
-                    }
+                    "account"
                 ],
+                "summary": "Marca de agua por defecto",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "file"
+                            "$ref": "#/definitions/dto.WatermarkResponse"
                         }
                     },
-                    "403": {
-                        "description": "Acceso prohibido",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Archivo no encontrado",
+                        "description": "Not Found",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/login": {
-            "post": {
-                "description": "Autentica al usuario y devuelve un token JWT si las credenciales son correctas.",
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Define una imagen PNG propia como marca de agua. Se aplica automáticamente a las imágenes del usuario servidas públicamente (/images/{usuario}/{archivo} y URLs firmadas), salvo a la propia imagen de la marca de agua.",
                 "consumes": [
                     "application/json"
                 ],
@@ -184,25 +146,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "account"
                 ],
-                "summary": "Inicia sesión",
+                "summary": "Configura la marca de agua por defecto",
                 "parameters": [
                     {
-                        "description": "Credenciales de usuario",
-                        "name": "credentials",
+                        "description": "Imagen y posición de la marca de agua",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.LoginRequest"
+                            "$ref": "#/definitions/dto.WatermarkRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.LoginResponse"
+                            "$ref": "#/definitions/dto.WatermarkResponse"
                         }
                     },
                     "400": {
@@ -210,55 +172,82 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deja de aplicar la marca de agua a las imágenes públicas del usuario autenticado.",
+                "tags": [
+                    "account"
+                ],
+                "summary": "Elimina la marca de agua por defecto",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/register": {
-            "post": {
-                "description": "Crea un nuevo usuario en la base de datos con nombre de usuario y contraseña.",
-                "consumes": [
-                    "application/json"
+        "/fonts": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Devuelve las fuentes incluidas en el servidor (se eligen por nombre con \"font\") y las subidas por el usuario autenticado (se eligen con \"font_id\").",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "fonts"
                 ],
-                "summary": "Registra un nuevo usuario",
-                "parameters": [
-                    {
-                        "description": "Datos del usuario a registrar",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
+                "summary": "Lista las fuentes disponibles",
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.RegisterRequest"
+                            "$ref": "#/definitions/dto.FontListResponse"
                         }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Created"
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/upload": {
+            },
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Permite a un usuario autenticado subir una imagen. Se guarda en MinIO y se almacena la metadata.",
+                "description": "Sube una fuente TrueType u OpenType propia para usarla en la operación \"text\" con \"font_id\". Las fuentes no se sirven públicamente.",
                 "consumes": [
                     "multipart/form-data"
                 ],
@@ -266,23 +255,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "images"
+                    "fonts"
                 ],
-                "summary": "Sube una imagen",
+                "summary": "Sube una fuente",
                 "parameters": [
                     {
                         "type": "file",
-                        "description": "Imagen a subir (jpg, jpeg, png, gif)",
-                        "name": "image",
+                        "description": "Fuente (.ttf u .otf)",
+                        "name": "font",
                         "in": "formData",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Nombre para identificarla (por defecto, la familia declarada en la fuente)",
+                        "name": "name",
+                        "in": "formData"
                     }
                 ],
                 "responses": {
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/dto.UploadResponse"
+                            "$ref": "#/definitions/dto.FontResponse"
                         }
                     },
                     "400": {
@@ -297,6 +292,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UploadErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -306,42 +307,87 @@ const docTemplate = `{
                 }
             }
         },
-        "/user-images": {
+        "/fonts/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Elimina una fuente subida por el usuario autenticado.",
+                "tags": [
+                    "fonts"
+                ],
+                "summary": "Elimina una fuente",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la fuente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/images/{id}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Devuelve las imágenes subidas por el usuario autenticado con soporte de paginación.",
+                "description": "Devuelve la metadata y URL de una imagen del usuario autenticado, junto con sus versiones redimensionadas para srcset. Las versiones se generan en segundo plano después de la subida, así que la lista puede estar vacía al principio.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "images"
                 ],
-                "summary": "Lista imágenes del usuario autenticado",
+                "summary": "Obtener información de una imagen",
                 "parameters": [
                     {
                         "type": "integer",
-                        "default": 1,
-                        "description": "Número de página",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "Cantidad por página",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "ID de la imagen",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.PaginatedImagesResponse"
+                            "$ref": "#/definitions/dto.ImageDetailResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
                     "401": {
@@ -350,58 +396,2617 @@ const docTemplate = `{
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     }
                 }
-            }
-        }
-    },
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Elimina una imagen del usuario autenticado junto con su archivo y sus derivados cacheados. Responde 202 si la limpieza del storage quedó pendiente de reintento.",
+                "tags": [
+                    "images"
+                ],
+                "summary": "Elimina una imagen",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la imagen",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted"
+                    },
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/images/{id}/derivatives": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista las imágenes guardadas directamente a partir de la imagen indicada, con las operaciones que se le aplicaron",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Imágenes derivadas",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la imagen",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.DerivativeListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Acepta el mismo cuerpo y el mismo ?preset= que /images/{id}/transform, pero en lugar de devolver el resultado lo guarda como una imagen nueva del usuario que recuerda su imagen de origen y las operaciones aplicadas. Sin formato se conserva el de la imagen de origen.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Guarda una transformación como imagen nueva",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la imagen de origen",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Nombre de un preset del usuario",
+                        "name": "preset",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Parámetros de transformación",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TransformationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UploadResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UploadErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/images/{id}/lineage": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve la cadena de imágenes desde la imagen subida hasta la indicada, con las operaciones de cada paso. Si se eliminó algún antecesor la cadena empieza en el primero que existe y complete es false.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Historial de ediciones",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la imagen",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.LineageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/images/{id}/metadata": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve los datos EXIF leídos al subir la imagen: orientación, cámara, lente, fecha de captura, GPS y exposición. Responde 404 si la imagen no tenía EXIF.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Metadatos EXIF de una imagen",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la imagen",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ImageMetadataResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/images/{id}/signed-url": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve una URL GET pública (firmada con HMAC) que aplica las operaciones indicadas a la imagen, para usar en etiquetas \u003cimg\u003e o detrás de un CDN sin enviar el token.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Genera una URL de transformación firmada",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la imagen",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Operaciones en formato de URL (por ejemplo rs:800:600/gs)",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SignedURLRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SignedURLResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/images/{id}/similar": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista las demás imágenes del usuario cuyo hash perceptual (dHash) está a una distancia de Hamming menor o igual que threshold, de la más parecida a la menos parecida. 0 encuentra copias exactas o recomprimidas; alrededor de 10, versiones retocadas o redimensionadas. Responde 422 si la imagen todavía no tiene hash.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Imágenes parecidas",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la imagen",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Distancia máxima, de 0 a 64 (por defecto 10)",
+                        "name": "threshold",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SimilarImagesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/images/{id}/strip-metadata": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Reescribe el archivo original sin volver a codificar los píxeles: strip_gps quita la posición de captura y strip_all (por defecto) quita EXIF, XMP, IPTC y comentarios conservando la orientación. Los derivados en caché se descartan.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Quita los metadatos de una imagen guardada",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la imagen",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Política a aplicar",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dto.StripMetadataRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.StripMetadataResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/images/{id}/transform": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Aplica un pipeline ordenado de operaciones (\"operations\") a una imagen previamente cargada por el usuario. El objeto \"transformations\" se acepta como formato anterior (resize → crop → rotate → filtros). Con \"preset\" se aplica primero el pipeline guardado y después las operaciones del cuerpo, que en ese caso es opcional.",
+                "produces": [
+                    "image/png",
+                    "image/jpeg",
+                    "image/gif",
+                    "image/tiff",
+                    "image/bmp",
+                    "image/webp"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Aplica transformaciones a una imagen",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la imagen",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Nombre de un preset del usuario",
+                        "name": "preset",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Parámetros de transformación",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TransformationRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Formato de salida si el cuerpo y el preset no definen format (por ejemplo: image/webp,image/jpeg;q=0.8)",
+                        "name": "Accept",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Imagen transformada",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UploadErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/images/{id}/transform-jobs": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Acepta el mismo cuerpo que /images/{id}/transform pero procesa la imagen en segundo plano. Devuelve el ID del trabajo para consultar su estado en /jobs/{id}.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Encola una transformación asíncrona",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la imagen",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Parámetros de transformación",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TransformationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/dto.JobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/images/{rest}": {
+            "get": {
+                "description": "Devuelve el archivo de imagen almacenado sin exponer su ruta real. Si el header Accept no admite el formato guardado, la imagen se convierte al vuelo al formato aceptado de mayor prioridad; si ninguno puede generarse responde 406. Si el dueño configuró una marca de agua por defecto, se aplica a todo lo que se sirve, incluidas las versiones responsive y los resultados de trabajos, aunque no cambie el formato.",
+                "produces": [
+                    "image/jpeg",
+                    "image/png",
+                    "image/gif",
+                    "image/tiff",
+                    "image/bmp",
+                    "image/webp"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Servir imagen",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ruta relativa de la imagen (por ejemplo: user123/imagen.jpg)",
+                        "name": "rest",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Formatos aceptados, con q-values (por ejemplo: image/webp,image/*;q=0.8)",
+                        "name": "Accept",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "403": {
+                        "description": "Acceso prohibido",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Archivo no encontrado",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "406": {
+                        "description": "Ningún formato aceptable",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve el estado (queued, running, succeeded, failed, canceled) y, si terminó bien, la URL del resultado.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Estado de un trabajo de transformación",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del trabajo",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.JobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/cancel": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cancela un trabajo en cola o en ejecución. Un trabajo ya finalizado no puede cancelarse.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Cancela un trabajo de transformación",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del trabajo",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.JobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/login": {
+            "post": {
+                "description": "Autentica al usuario y devuelve un access token JWT de vida corta junto con un refresh token.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Inicia sesión",
+                "parameters": [
+                    {
+                        "description": "Credenciales de usuario",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/logout": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revoca el access token usado en la petición y, si se envía, toda la familia del refresh token.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Cierra la sesión",
+                "parameters": [
+                    {
+                        "description": "Refresh token a revocar",
+                        "name": "token",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dto.LogoutRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/presets": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve los presets del usuario autenticado ordenados por nombre.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "presets"
+                ],
+                "summary": "Lista los presets",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.PresetResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Guarda un pipeline de transformación con nombre para aplicarlo con /images/{id}/transform?preset={nombre}. Se valida igual que una transformación.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "presets"
+                ],
+                "summary": "Crea un preset",
+                "parameters": [
+                    {
+                        "description": "Preset",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.PresetRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PresetResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/presets/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "presets"
+                ],
+                "summary": "Obtiene un preset",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del preset",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PresetResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Reemplaza el nombre, el pipeline y el formato de un preset. Se valida igual que al crearlo.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "presets"
+                ],
+                "summary": "Reemplaza un preset",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del preset",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Preset",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.PresetRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PresetResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "presets"
+                ],
+                "summary": "Elimina un preset",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del preset",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/register": {
+            "post": {
+                "description": "Crea un nuevo usuario en la base de datos con nombre de usuario y contraseña.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Registra un nuevo usuario",
+                "parameters": [
+                    {
+                        "description": "Datos del usuario a registrar",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/t/{signature}/{rest}": {
+            "get": {
+                "description": "Verifica la firma HMAC de la ruta, aplica las operaciones codificadas en ella (por ejemplo rs:800:600/crop:400:300:0:0/f:jpeg) y devuelve la imagen resultante. No requiere token. Si el dueño configuró una marca de agua por defecto, se aplica al final.",
+                "produces": [
+                    "image/png",
+                    "image/jpeg",
+                    "image/gif",
+                    "image/tiff",
+                    "image/bmp",
+                    "image/webp"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Sirve una imagen transformada mediante URL firmada",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Firma HMAC-SHA256 en base64 URL-safe",
+                        "name": "signature",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Operaciones seguidas de usuario/archivo",
+                        "name": "rest",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Imagen transformada",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Ruta u operaciones inválidas",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Firma inválida",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Imagen no encontrada",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "406": {
+                        "description": "Ningún formato aceptable",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/token/refresh": {
+            "post": {
+                "description": "Canjea un refresh token por un nuevo access token y un nuevo refresh token. El refresh token recibido queda invalidado; si se vuelve a usar se revoca toda la sesión.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Renueva el access token",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "token",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RefreshRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/upload": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Permite a un usuario autenticado subir una imagen. El formato se detecta por el contenido del archivo (no por la extensión) y se rechazan archivos o dimensiones que superen los límites configurados. Antes de guardar el original se quitan los metadatos según el campo \"metadata\" o, si se omite, la política de la cuenta.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Sube una imagen",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Imagen a subir (jpg, jpeg, png, gif)",
+                        "name": "image",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "keep",
+                            "strip_gps",
+                            "strip_all"
+                        ],
+                        "type": "string",
+                        "description": "Metadatos a quitar: keep, strip_gps o strip_all",
+                        "name": "metadata",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "allow",
+                            "reject",
+                            "return_existing"
+                        ],
+                        "type": "string",
+                        "description": "Qué hacer si ya existe una imagen igual o casi igual: allow (por defecto), reject (409) o return_existing (200 con la imagen existente)",
+                        "name": "on_duplicate",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Distancia de Hamming máxima entre hashes perceptuales para considerar un duplicado (0 a 64, por defecto 4)",
+                        "name": "duplicate_threshold",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Duplicado con on_duplicate=return_existing: no se guardó nada",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UploadResponse"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UploadResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.DuplicateResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UploadErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UploadErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/upload/batch": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sube todos los archivos del campo \"images\" en una sola petición, procesando varios a la vez. Cada archivo se valida y se guarda como en POST /upload y el fallo de uno no afecta a los demás: la respuesta es 200 con un resultado por archivo, en el orden en que se enviaron, y el estado que habría tenido cada uno por separado. Las opciones compartidas son las de POST /upload (metadata, on_duplicate y duplicate_threshold) y se aplican a todos los archivos; no hay otras, y los demás campos se ignoran. Con on_duplicate, los archivos del lote iguales o parecidos entre sí se suben en orden y a partir del segundo se tratan como duplicados.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Sube varias imágenes",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Imágenes a subir (el campo se repite por cada archivo)",
+                        "name": "images",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "keep",
+                            "strip_gps",
+                            "strip_all"
+                        ],
+                        "type": "string",
+                        "description": "Metadatos a quitar de todos los archivos: keep, strip_gps o strip_all",
+                        "name": "metadata",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "allow",
+                            "reject",
+                            "return_existing"
+                        ],
+                        "type": "string",
+                        "description": "Qué hacer con los archivos que ya existen: allow (por defecto), reject o return_existing",
+                        "name": "on_duplicate",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Distancia de Hamming máxima entre hashes perceptuales para considerar un duplicado (0 a 64, por defecto 4)",
+                        "name": "duplicate_threshold",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BatchUploadResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UploadErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/uploads/tus": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Crea una subida tus de Upload-Length bytes. Upload-Metadata acepta las claves metadata, on_duplicate y duplicate_threshold con el mismo significado que en POST /upload; el resto se guarda y se devuelve sin interpretar. La subida vence si pasa el tiempo configurado sin recibir datos.",
+                "tags": [
+                    "uploads"
+                ],
+                "summary": "Inicia una subida reanudable",
+                "parameters": [
+                    {
+                        "enum": [
+                            "1.0.0"
+                        ],
+                        "type": "string",
+                        "description": "Versión del protocolo",
+                        "name": "Tus-Resumable",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Tamaño total en bytes",
+                        "name": "Upload-Length",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Pares clave valor-en-base64 separados por coma",
+                        "name": "Upload-Metadata",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Subida creada; la URL está en Location y el vencimiento en Upload-Expires"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UploadErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "options": {
+                "description": "Informa la versión y las extensiones de tus soportadas, el tamaño máximo de una subida y los algoritmos de checksum aceptados. No requiere autenticación.",
+                "tags": [
+                    "uploads"
+                ],
+                "summary": "Capacidades del servidor tus",
+                "responses": {
+                    "204": {
+                        "description": "Capacidades en los encabezados Tus-Version, Tus-Extension, Tus-Max-Size y Tus-Checksum-Algorithm"
+                    }
+                }
+            }
+        },
+        "/uploads/tus/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Borra la subida y los datos recibidos. Una imagen ya creada por la subida no se borra.",
+                "tags": [
+                    "uploads"
+                ],
+                "summary": "Cancela una subida reanudable",
+                "parameters": [
+                    {
+                        "enum": [
+                            "1.0.0"
+                        ],
+                        "type": "string",
+                        "description": "Versión del protocolo",
+                        "name": "Tus-Resumable",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID de la subida",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Subida cancelada"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "head": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve en Upload-Offset cuántos bytes se guardaron, para que el cliente retome desde ahí. Si la subida ya creó la imagen, su ID y URL van en X-Image-ID y X-Image-URL.",
+                "tags": [
+                    "uploads"
+                ],
+                "summary": "Estado de una subida reanudable",
+                "parameters": [
+                    {
+                        "enum": [
+                            "1.0.0"
+                        ],
+                        "type": "string",
+                        "description": "Versión del protocolo",
+                        "name": "Tus-Resumable",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID de la subida",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Estado en Upload-Offset, Upload-Length, Upload-Metadata y Upload-Expires"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Gone",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Guarda el cuerpo a partir de Upload-Offset, que debe coincidir con lo recibido hasta ahora. Sin Upload-Checksum se conserva lo que llegó aunque la conexión se corte; con checksum la petición se acepta entera o no se acepta. Al recibir el último byte la imagen se valida y se crea como en POST /upload; si eso falla por un error pasajero, una petición vacía con el offset final lo reintenta.",
+                "consumes": [
+                    "application/offset+octet-stream"
+                ],
+                "tags": [
+                    "uploads"
+                ],
+                "summary": "Envía datos de una subida reanudable",
+                "parameters": [
+                    {
+                        "enum": [
+                            "1.0.0"
+                        ],
+                        "type": "string",
+                        "description": "Versión del protocolo",
+                        "name": "Tus-Resumable",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset en el que empieza el cuerpo",
+                        "name": "Upload-Offset",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Algoritmo y checksum en base64 del cuerpo, por ejemplo: sha1 Kq5sNclPz7QV2+lfQIuc6R7oRu0=",
+                        "name": "Upload-Checksum",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID de la subida",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Nuevo offset en Upload-Offset; al completarse, la imagen en X-Image-ID y X-Image-URL"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "El offset no coincide o la imagen es un duplicado rechazado",
+                        "schema": {
+                            "$ref": "#/definitions/dto.DuplicateResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Gone",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UploadErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UploadErrorResponse"
+                        }
+                    },
+                    "460": {
+                        "description": "El checksum no coincide",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user-images": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve las imágenes subidas por el usuario autenticado con soporte de paginación.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Lista imágenes del usuario autenticado",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Número de página",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Cantidad por página",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PaginatedImagesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user-images/gps": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revisa los archivos guardados del usuario autenticado y lista los que todavía contienen coordenadas GPS, en EXIF o en XMP, y por lo tanto las exponen al servirse públicamente.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Imágenes con posición de captura",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GPSAuditResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
     "definitions": {
+        "dto.BatchUploadError": {
+            "type": "object",
+            "properties": {
+                "actual": {
+                    "type": "integer",
+                    "example": 100000000
+                },
+                "distance": {
+                    "description": "Distance es la distancia al duplicado; solo con error \"duplicate\"",
+                    "type": "integer",
+                    "example": 2
+                },
+                "error": {
+                    "type": "string",
+                    "enum": [
+                        "payload_too_large",
+                        "image_too_large",
+                        "unsupported_media_type",
+                        "invalid_image",
+                        "unreadable_metadata",
+                        "duplicate",
+                        "upload_failed"
+                    ],
+                    "example": "image_too_large"
+                },
+                "limit": {
+                    "type": "string",
+                    "enum": [
+                        "bytes",
+                        "width",
+                        "height",
+                        "pixels"
+                    ],
+                    "example": "pixels"
+                },
+                "max": {
+                    "type": "integer",
+                    "example": 50000000
+                },
+                "message": {
+                    "type": "string",
+                    "example": "la imagen supera el límite de pixels: 100000000 (máximo 50000000)"
+                }
+            }
+        },
+        "dto.BatchUploadResponse": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.BatchUploadResult"
+                    }
+                },
+                "succeeded": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "dto.BatchUploadResult": {
+            "type": "object",
+            "properties": {
+                "duplicate": {
+                    "type": "boolean"
+                },
+                "error": {
+                    "$ref": "#/definitions/dto.BatchUploadError"
+                },
+                "file_name": {
+                    "type": "string",
+                    "example": "vacaciones.jpg"
+                },
+                "image": {
+                    "description": "Image es la imagen subida o, si era un duplicado, la que ya existía",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.UploadedImageDetail"
+                        }
+                    ]
+                },
+                "index": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "status": {
+                    "description": "Status es el estado que habría tenido el archivo en POST /upload",
+                    "type": "integer",
+                    "example": 201
+                }
+            }
+        },
+        "dto.DerivativeListResponse": {
+            "type": "object",
+            "properties": {
+                "derivatives": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ImageNodeResponse"
+                    }
+                },
+                "image_id": {
+                    "type": "integer",
+                    "example": 7
+                }
+            }
+        },
+        "dto.DuplicateResponse": {
+            "type": "object",
+            "properties": {
+                "distance": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "error": {
+                    "type": "string",
+                    "example": "duplicate"
+                },
+                "image": {
+                    "$ref": "#/definitions/dto.UploadedImageDetail"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "la imagen ya existe: imagen123.jpg (distancia 2)"
+                }
+            }
+        },
         "dto.ErrorResponse": {
             "type": "object",
             "properties": {
-                "message": {
+                "message": {
+                    "type": "string",
+                    "example": "Descripción del error"
+                }
+            }
+        },
+        "dto.FontListResponse": {
+            "type": "object",
+            "properties": {
+                "embedded": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "goregular",
+                        "gobold",
+                        "gomono"
+                    ]
+                },
+                "fonts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FontResponse"
+                    }
+                }
+            }
+        },
+        "dto.FontResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "family": {
+                    "type": "string",
+                    "example": "Anton"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Anton"
+                },
+                "size": {
+                    "type": "integer",
+                    "example": 136000
+                }
+            }
+        },
+        "dto.GPSAuditItem": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "latitude": {
+                    "type": "number",
+                    "example": -34.6037
+                },
+                "longitude": {
+                    "type": "number",
+                    "example": -58.3816
+                },
+                "name": {
+                    "type": "string",
+                    "example": "imagen123.jpg"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "http://localhost:8080/images/rodrick/imagen123.jpg"
+                }
+            }
+        },
+        "dto.GPSAuditResponse": {
+            "type": "object",
+            "properties": {
+                "images": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.GPSAuditItem"
+                    }
+                },
+                "scanned": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "dto.ImageDetailResponse": {
+            "type": "object",
+            "properties": {
+                "format": {
+                    "type": "string",
+                    "example": "jpeg"
+                },
+                "height": {
+                    "type": "integer",
+                    "example": 1080
+                },
+                "name": {
+                    "type": "string",
+                    "example": "imagen123.jpg"
+                },
+                "parent_id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "renditions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.RenditionResponse"
+                    }
+                },
+                "size": {
+                    "type": "integer",
+                    "example": 204800
+                },
+                "url": {
+                    "type": "string",
+                    "example": "http://localhost:8080/images/rodrick/imagen123.jpg"
+                },
+                "width": {
+                    "type": "integer",
+                    "example": 1920
+                }
+            }
+        },
+        "dto.ImageItem": {
+            "type": "object",
+            "properties": {
+                "format": {
+                    "type": "string"
+                },
+                "height": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "width": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ImageMetadataGPS": {
+            "type": "object",
+            "properties": {
+                "altitude": {
+                    "type": "number",
+                    "example": 25
+                },
+                "latitude": {
+                    "type": "number",
+                    "example": -34.6037
+                },
+                "longitude": {
+                    "type": "number",
+                    "example": -58.3816
+                }
+            }
+        },
+        "dto.ImageMetadataResponse": {
+            "type": "object",
+            "properties": {
+                "camera_make": {
+                    "type": "string",
+                    "example": "Apple"
+                },
+                "camera_model": {
+                    "type": "string",
+                    "example": "iPhone 13"
+                },
+                "captured_at": {
+                    "type": "string"
+                },
+                "exposure_time": {
+                    "type": "string",
+                    "example": "1/125"
+                },
+                "f_number": {
+                    "type": "number",
+                    "example": 1.6
+                },
+                "focal_length": {
+                    "type": "number",
+                    "example": 5.1
+                },
+                "gps": {
+                    "$ref": "#/definitions/dto.ImageMetadataGPS"
+                },
+                "image_id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "iso": {
+                    "type": "integer",
+                    "example": 50
+                },
+                "lens_make": {
+                    "type": "string",
+                    "example": "Apple"
+                },
+                "lens_model": {
+                    "type": "string",
+                    "example": "iPhone 13 back dual wide camera"
+                },
+                "orientation": {
+                    "type": "integer",
+                    "example": 6
+                },
+                "software": {
+                    "type": "string",
+                    "example": "17.2"
+                }
+            }
+        },
+        "dto.ImageNodeResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-03-15T10:30:00Z"
+                },
+                "format": {
+                    "type": "string",
+                    "example": "png"
+                },
+                "height": {
+                    "type": "integer",
+                    "example": 600
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "name": {
+                    "type": "string",
+                    "example": "imagen123.png"
+                },
+                "operations": {
+                    "description": "pipeline aplicado al padre; vacío en las imágenes subidas",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.TransformationOperation"
+                    }
+                },
+                "parent_id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "size": {
+                    "type": "integer",
+                    "example": 204800
+                },
+                "url": {
+                    "type": "string",
+                    "example": "http://localhost:8080/images/rodrick/imagen123.png"
+                },
+                "width": {
+                    "type": "integer",
+                    "example": 800
+                }
+            }
+        },
+        "dto.JobResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "image_id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "result_url": {
+                    "type": "string",
+                    "example": "http://localhost:8080/images/rodrick/jobs/5f0e7c2b-3a91-4d6e-8b24-c1d9a7e6f350.png"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "queued",
+                        "running",
+                        "succeeded",
+                        "failed",
+                        "canceled"
+                    ],
+                    "example": "queued"
+                }
+            }
+        },
+        "dto.LineageResponse": {
+            "type": "object",
+            "properties": {
+                "complete": {
+                    "description": "false si se eliminó algún antecesor",
+                    "type": "boolean",
+                    "example": true
+                },
+                "image_id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "lineage": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ImageNodeResponse"
+                    }
+                }
+            }
+        },
+        "dto.LoginRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "user_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "expires_in": {
+                    "description": "segundos de vida del access token",
+                    "type": "integer"
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.LogoutRequest": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.MetadataPolicyRequest": {
+            "type": "object",
+            "properties": {
+                "policy": {
                     "type": "string",
-                    "example": "Descripción del error"
+                    "enum": [
+                        "keep",
+                        "strip_gps",
+                        "strip_all"
+                    ],
+                    "example": "strip_gps"
                 }
             }
         },
-        "dto.ImageDetailResponse": {
+        "dto.MetadataPolicyResponse": {
+            "type": "object",
+            "properties": {
+                "policy": {
+                    "type": "string",
+                    "example": "strip_gps"
+                }
+            }
+        },
+        "dto.PaginatedImagesResponse": {
+            "type": "object",
+            "properties": {
+                "images": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ImageItem"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.PresetRequest": {
             "type": "object",
             "properties": {
+                "compression": {
+                    "type": "string",
+                    "enum": [
+                        "default",
+                        "none",
+                        "fast",
+                        "best"
+                    ]
+                },
                 "format": {
                     "type": "string",
-                    "example": "jpeg"
+                    "enum": [
+                        "png",
+                        "jpeg",
+                        "gif",
+                        "tiff",
+                        "bmp",
+                        "webp"
+                    ],
+                    "example": "webp"
                 },
-                "height": {
+                "name": {
+                    "type": "string",
+                    "example": "thumb"
+                },
+                "operations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.TransformationOperation"
+                    }
+                },
+                "palette_size": {
                     "type": "integer",
-                    "example": 1080
+                    "example": 64
+                },
+                "quality": {
+                    "type": "integer",
+                    "example": 80
+                }
+            }
+        },
+        "dto.PresetResponse": {
+            "type": "object",
+            "properties": {
+                "compression": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "format": {
+                    "type": "string",
+                    "example": "webp"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 3
                 },
                 "name": {
                     "type": "string",
-                    "example": "imagen123.jpg"
+                    "example": "thumb"
+                },
+                "operations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.TransformationOperation"
+                    }
+                },
+                "palette_size": {
+                    "type": "integer"
+                },
+                "quality": {
+                    "type": "integer",
+                    "example": 80
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.RefreshRequest": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.RegisterRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "user_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.RenditionResponse": {
+            "type": "object",
+            "properties": {
+                "format": {
+                    "type": "string",
+                    "example": "webp"
+                },
+                "height": {
+                    "type": "integer",
+                    "example": 360
                 },
                 "size": {
                     "type": "integer",
-                    "example": 204800
+                    "example": 28410
                 },
                 "url": {
                     "type": "string",
-                    "example": "http://localhost:8080/images/rodrick/imagen123.jpg"
+                    "example": "http://localhost:8080/images/rodrick/renditions/12/9b2e4c1a-7d3f-4e8b-a6c5-2f1d0e9c8b7a/640.webp"
                 },
                 "width": {
                     "type": "integer",
-                    "example": 1920
+                    "example": 640
                 }
             }
         },
-        "dto.ImageItem": {
+        "dto.SignedURLRequest": {
+            "type": "object",
+            "properties": {
+                "format": {
+                    "type": "string",
+                    "example": "jpeg"
+                },
+                "operations": {
+                    "type": "string",
+                    "example": "rs:800:600/gs"
+                }
+            }
+        },
+        "dto.SignedURLResponse": {
+            "type": "object",
+            "properties": {
+                "url": {
+                    "type": "string",
+                    "example": "http://localhost:8080/t/Xk3.../rs:800:600/gs/f:jpeg/rodrick/imagen123.jpg"
+                }
+            }
+        },
+        "dto.SimilarImageItem": {
             "type": "object",
             "properties": {
+                "distance": {
+                    "description": "bits distintos entre los hashes, de 0 a 64",
+                    "type": "integer",
+                    "example": 3
+                },
                 "format": {
                     "type": "string"
                 },
@@ -425,59 +3030,117 @@ const docTemplate = `{
                 }
             }
         },
-        "dto.LoginRequest": {
+        "dto.SimilarImagesResponse": {
             "type": "object",
             "properties": {
-                "password": {
-                    "type": "string"
+                "image_id": {
+                    "type": "integer",
+                    "example": 12
                 },
-                "user_name": {
-                    "type": "string"
+                "images": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SimilarImageItem"
+                    }
+                },
+                "threshold": {
+                    "type": "integer",
+                    "example": 10
                 }
             }
         },
-        "dto.LoginResponse": {
+        "dto.StripMetadataRequest": {
             "type": "object",
             "properties": {
-                "token": {
-                    "type": "string"
+                "policy": {
+                    "type": "string",
+                    "enum": [
+                        "strip_gps",
+                        "strip_all"
+                    ],
+                    "example": "strip_all"
                 }
             }
         },
-        "dto.PaginatedImagesResponse": {
+        "dto.StripMetadataResponse": {
             "type": "object",
             "properties": {
-                "images": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/dto.ImageItem"
-                    }
+                "changed": {
+                    "type": "boolean",
+                    "example": true
                 },
-                "limit": {
-                    "type": "integer"
+                "image_id": {
+                    "type": "integer",
+                    "example": 7
                 },
-                "page": {
-                    "type": "integer"
+                "new_size": {
+                    "type": "integer",
+                    "example": 198400
                 },
-                "total": {
-                    "type": "integer"
+                "old_size": {
+                    "type": "integer",
+                    "example": 204800
+                },
+                "policy": {
+                    "type": "string",
+                    "example": "strip_all"
                 }
             }
         },
-        "dto.RegisterRequest": {
+        "dto.TransformationOperation": {
             "type": "object",
             "properties": {
-                "password": {
-                    "type": "string"
+                "params": {
+                    "type": "object",
+                    "additionalProperties": true
                 },
-                "user_name": {
-                    "type": "string"
+                "type": {
+                    "type": "string",
+                    "example": "resize"
                 }
             }
         },
         "dto.TransformationRequest": {
             "type": "object",
             "properties": {
+                "compression": {
+                    "description": "solo png",
+                    "type": "string",
+                    "enum": [
+                        "default",
+                        "none",
+                        "fast",
+                        "best"
+                    ]
+                },
+                "format": {
+                    "type": "string",
+                    "enum": [
+                        "png",
+                        "jpeg",
+                        "gif",
+                        "tiff",
+                        "bmp",
+                        "webp"
+                    ],
+                    "example": "png"
+                },
+                "operations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.TransformationOperation"
+                    }
+                },
+                "palette_size": {
+                    "description": "solo gif, 1 a 256",
+                    "type": "integer",
+                    "example": 64
+                },
+                "quality": {
+                    "description": "solo jpeg, 1 a 100",
+                    "type": "integer",
+                    "example": 85
+                },
                 "transformations": {
                     "type": "object",
                     "properties": {
@@ -515,9 +3178,40 @@ const docTemplate = `{
                         "resize": {
                             "type": "object",
                             "properties": {
+                                "background": {
+                                    "type": "string",
+                                    "example": "#ffffff"
+                                },
+                                "filter": {
+                                    "type": "string",
+                                    "enum": [
+                                        "lanczos",
+                                        "catmullrom",
+                                        "box",
+                                        "nearest"
+                                    ]
+                                },
+                                "gravity": {
+                                    "type": "string",
+                                    "example": "center"
+                                },
                                 "height": {
                                     "type": "integer"
                                 },
+                                "mode": {
+                                    "type": "string",
+                                    "enum": [
+                                        "exact",
+                                        "fit",
+                                        "fill",
+                                        "cover",
+                                        "contain",
+                                        "pad"
+                                    ]
+                                },
+                                "no_upscale": {
+                                    "type": "boolean"
+                                },
                                 "width": {
                                     "type": "integer"
                                 }
@@ -530,9 +3224,49 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.UploadErrorResponse": {
+            "type": "object",
+            "properties": {
+                "actual": {
+                    "type": "integer",
+                    "example": 100000000
+                },
+                "error": {
+                    "type": "string",
+                    "enum": [
+                        "payload_too_large",
+                        "image_too_large",
+                        "unsupported_media_type"
+                    ],
+                    "example": "image_too_large"
+                },
+                "limit": {
+                    "type": "string",
+                    "enum": [
+                        "bytes",
+                        "width",
+                        "height",
+                        "pixels"
+                    ],
+                    "example": "pixels"
+                },
+                "max": {
+                    "type": "integer",
+                    "example": 50000000
+                },
+                "message": {
+                    "type": "string",
+                    "example": "la imagen supera el límite de pixels: 100000000 (máximo 50000000)"
+                }
+            }
+        },
         "dto.UploadResponse": {
             "type": "object",
             "properties": {
+                "duplicate": {
+                    "description": "Duplicate indica que no se guardó nada: image es la imagen que ya existía",
+                    "type": "boolean"
+                },
                 "image": {
                     "$ref": "#/definitions/dto.UploadedImageDetail"
                 },
@@ -553,10 +3287,18 @@ const docTemplate = `{
                     "type": "integer",
                     "example": 1080
                 },
+                "id": {
+                    "type": "integer",
+                    "example": 12
+                },
                 "name": {
                     "type": "string",
                     "example": "imagen123.jpg"
                 },
+                "parent_id": {
+                    "type": "integer",
+                    "example": 7
+                },
                 "size": {
                     "type": "integer",
                     "example": 204800
@@ -570,6 +3312,64 @@ const docTemplate = `{
                     "example": 1920
                 }
             }
+        },
+        "dto.WatermarkRequest": {
+            "type": "object",
+            "properties": {
+                "anchor": {
+                    "type": "string",
+                    "enum": [
+                        "top-left",
+                        "top-right",
+                        "bottom-left",
+                        "bottom-right",
+                        "center",
+                        "tile"
+                    ],
+                    "example": "bottom-right"
+                },
+                "image_id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "margin": {
+                    "type": "integer",
+                    "example": 16
+                },
+                "opacity": {
+                    "type": "number",
+                    "example": 0.5
+                },
+                "scale": {
+                    "type": "number",
+                    "example": 0.25
+                }
+            }
+        },
+        "dto.WatermarkResponse": {
+            "type": "object",
+            "properties": {
+                "anchor": {
+                    "type": "string",
+                    "example": "bottom-right"
+                },
+                "image_id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "margin": {
+                    "type": "integer",
+                    "example": 16
+                },
+                "opacity": {
+                    "type": "number",
+                    "example": 0.5
+                },
+                "scale": {
+                    "type": "number",
+                    "example": 0.25
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -1,7 +1,9 @@
 package auth_test
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/RodrigoGonzalez78/internal/application/usecase/auth"
 	"github.com/RodrigoGonzalez78/internal/domain/entity"
@@ -155,7 +157,8 @@ func TestLoginUseCase_Execute(t *testing.T) {
 			mockRepo := mocks.NewMockUserRepository()
 			tt.setupMock(mockRepo)
 			tokenService := service.NewTokenService("test-secret", 24*60*60*1000000000)
-			useCase := auth.NewLoginUseCase(mockRepo, passwordService, tokenService)
+			refreshRepo := mocks.NewMockRefreshTokenRepository()
+			useCase := auth.NewLoginUseCase(mockRepo, passwordService, tokenService, refreshRepo, time.Hour)
 
 			// Execute
 			output, err := useCase.Execute(tt.input)
@@ -171,6 +174,137 @@ func TestLoginUseCase_Execute(t *testing.T) {
 			if !tt.wantErr && output.Token == "" {
 				t.Error("Execute() retornó un token vacío")
 			}
+			if !tt.wantErr && output.RefreshToken == "" {
+				t.Error("Execute() retornó un refresh token vacío")
+			}
+			if !tt.wantErr && refreshRepo.Tokens[output.RefreshToken] != nil {
+				t.Error("el refresh token no debe guardarse en texto plano")
+			}
 		})
 	}
 }
+
+func setupSession(t *testing.T) (*service.TokenService, *mocks.MockRefreshTokenRepository, *auth.LoginOutput) {
+	t.Helper()
+
+	passwordService := service.NewPasswordService()
+	hashedPassword, _ := passwordService.Hash("password123")
+
+	userRepo := mocks.NewMockUserRepository()
+	userRepo.Users["testuser"] = &entity.User{UserName: "testuser", Password: hashedPassword}
+
+	tokenService := service.NewTokenService("test-secret", 15*time.Minute)
+	refreshRepo := mocks.NewMockRefreshTokenRepository()
+	loginUC := auth.NewLoginUseCase(userRepo, passwordService, tokenService, refreshRepo, time.Hour)
+
+	output, err := loginUC.Execute(auth.LoginInput{UserName: "testuser", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Login error = %v", err)
+	}
+	return tokenService, refreshRepo, output
+}
+
+func TestRefreshUseCase_Execute(t *testing.T) {
+	t.Run("rota el refresh token", func(t *testing.T) {
+		tokenService, refreshRepo, session := setupSession(t)
+		useCase := auth.NewRefreshUseCase(refreshRepo, tokenService, time.Hour)
+
+		output, err := useCase.Execute(auth.RefreshInput{RefreshToken: session.RefreshToken})
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		if output.RefreshToken == session.RefreshToken {
+			t.Error("se esperaba un refresh token nuevo")
+		}
+		claims, err := tokenService.Validate("Bearer " + output.Token)
+		if err != nil {
+			t.Fatalf("Validate() error = %v", err)
+		}
+		if claims.UserName != "testuser" {
+			t.Errorf("UserName = %v, want testuser", claims.UserName)
+		}
+
+		// El nuevo token pertenece a la misma familia y sigue siendo válido
+		if _, err := useCase.Execute(auth.RefreshInput{RefreshToken: output.RefreshToken}); err != nil {
+			t.Errorf("Execute() con el token rotado error = %v", err)
+		}
+	})
+
+	t.Run("la reutilización revoca toda la familia", func(t *testing.T) {
+		tokenService, refreshRepo, session := setupSession(t)
+		useCase := auth.NewRefreshUseCase(refreshRepo, tokenService, time.Hour)
+
+		rotated, err := useCase.Execute(auth.RefreshInput{RefreshToken: session.RefreshToken})
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+
+		_, err = useCase.Execute(auth.RefreshInput{RefreshToken: session.RefreshToken})
+		if !errors.Is(err, auth.ErrRefreshTokenReused) {
+			t.Fatalf("Execute() error = %v, want ErrRefreshTokenReused", err)
+		}
+		if !refreshRepo.RevokeFamilyCalled {
+			t.Error("RevokeFamily() no fue llamado")
+		}
+
+		// El token legítimo más reciente también queda revocado
+		if _, err := useCase.Execute(auth.RefreshInput{RefreshToken: rotated.RefreshToken}); !errors.Is(err, auth.ErrInvalidRefreshToken) {
+			t.Errorf("Execute() error = %v, want ErrInvalidRefreshToken", err)
+		}
+	})
+
+	t.Run("token expirado", func(t *testing.T) {
+		tokenService, refreshRepo, session := setupSession(t)
+		for _, token := range refreshRepo.Tokens {
+			token.ExpiresAt = time.Now().Add(-time.Minute)
+		}
+		useCase := auth.NewRefreshUseCase(refreshRepo, tokenService, time.Hour)
+
+		if _, err := useCase.Execute(auth.RefreshInput{RefreshToken: session.RefreshToken}); !errors.Is(err, auth.ErrInvalidRefreshToken) {
+			t.Errorf("Execute() error = %v, want ErrInvalidRefreshToken", err)
+		}
+	})
+
+	t.Run("token desconocido", func(t *testing.T) {
+		tokenService, refreshRepo, _ := setupSession(t)
+		useCase := auth.NewRefreshUseCase(refreshRepo, tokenService, time.Hour)
+
+		for _, token := range []string{"", "no-existe"} {
+			if _, err := useCase.Execute(auth.RefreshInput{RefreshToken: token}); !errors.Is(err, auth.ErrInvalidRefreshToken) {
+				t.Errorf("Execute(%q) error = %v, want ErrInvalidRefreshToken", token, err)
+			}
+		}
+	})
+}
+
+func TestLogoutUseCase_Execute(t *testing.T) {
+	tokenService, refreshRepo, session := setupSession(t)
+	revokedRepo := mocks.NewMockRevokedTokenRepository()
+	useCase := auth.NewLogoutUseCase(refreshRepo, revokedRepo, tokenService)
+
+	claims, err := tokenService.Validate("Bearer " + session.Token)
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	// Un refresh token de otro usuario no puede revocarse
+	foreign := *claims
+	foreign.UserName = "otro"
+	if err := useCase.Execute(auth.LogoutInput{Claims: &foreign, RefreshToken: session.RefreshToken}); err == nil {
+		t.Error("se esperaba error al revocar el refresh token de otro usuario")
+	}
+
+	if err := useCase.Execute(auth.LogoutInput{Claims: claims, RefreshToken: session.RefreshToken}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	revoked, _ := revokedRepo.IsRevoked(claims.ID)
+	if !revoked {
+		t.Error("el jti del access token debe quedar revocado")
+	}
+
+	refreshUC := auth.NewRefreshUseCase(refreshRepo, tokenService, time.Hour)
+	if _, err := refreshUC.Execute(auth.RefreshInput{RefreshToken: session.RefreshToken}); !errors.Is(err, auth.ErrInvalidRefreshToken) {
+		t.Errorf("tras logout el refresh token debe ser inválido, error = %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"errors"
+	"time"
 
 	"github.com/RodrigoGonzalez78/internal/domain/repository"
 	"github.com/RodrigoGonzalez78/internal/domain/service"
@@ -10,18 +11,20 @@ import (
 type LoginUseCase struct {
 	userRepo        repository.UserRepository
 	passwordService *service.PasswordService
-	tokenService    *service.TokenService
+	issuer          *sessionIssuer
 }
 
 func NewLoginUseCase(
 	userRepo repository.UserRepository,
 	passwordService *service.PasswordService,
 	tokenService *service.TokenService,
+	refreshRepo repository.RefreshTokenRepository,
+	refreshExpiry time.Duration,
 ) *LoginUseCase {
 	return &LoginUseCase{
 		userRepo:        userRepo,
 		passwordService: passwordService,
-		tokenService:    tokenService,
+		issuer:          newSessionIssuer(tokenService, refreshRepo, refreshExpiry),
 	}
 }
 
@@ -31,7 +34,9 @@ type LoginInput struct {
 }
 
 type LoginOutput struct {
-	Token string
+	Token        string
+	RefreshToken string
+	ExpiresIn    time.Duration
 }
 
 func (uc *LoginUseCase) Execute(input LoginInput) (*LoginOutput, error) {
@@ -53,10 +58,11 @@ func (uc *LoginUseCase) Execute(input LoginInput) (*LoginOutput, error) {
 		return nil, errors.New("contraseña inválida")
 	}
 
-	token, err := uc.tokenService.Generate(input.UserName)
+	// Cada login abre una nueva familia de refresh tokens
+	familyID, err := service.NewRandomID()
 	if err != nil {
 		return nil, errors.New("error al generar token")
 	}
 
-	return &LoginOutput{Token: token}, nil
+	return uc.issuer.issue(input.UserName, familyID)
 }
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/RodrigoGonzalez78/internal/domain/repository"
+	"github.com/RodrigoGonzalez78/internal/domain/service"
+)
+
+type LogoutUseCase struct {
+	refreshRepo  repository.RefreshTokenRepository
+	revokedRepo  repository.RevokedTokenRepository
+	tokenService *service.TokenService
+}
+
+func NewLogoutUseCase(
+	refreshRepo repository.RefreshTokenRepository,
+	revokedRepo repository.RevokedTokenRepository,
+	tokenService *service.TokenService,
+) *LogoutUseCase {
+	return &LogoutUseCase{
+		refreshRepo:  refreshRepo,
+		revokedRepo:  revokedRepo,
+		tokenService: tokenService,
+	}
+}
+
+type LogoutInput struct {
+	Claims       *service.TokenClaims
+	RefreshToken string
+}
+
+// Execute revoca el access token actual y, si se envía, la familia del
+// refresh token asociado
+func (uc *LogoutUseCase) Execute(input LogoutInput) error {
+	if input.Claims == nil || input.Claims.ID == "" {
+		return errors.New("token inválido")
+	}
+
+	expiresAt := time.Now().Add(uc.tokenService.Expiry())
+	if input.Claims.ExpiresAt != nil {
+		expiresAt = input.Claims.ExpiresAt.Time
+	}
+
+	if err := uc.revokedRepo.Revoke(input.Claims.ID, expiresAt); err != nil {
+		return err
+	}
+
+	if len(input.RefreshToken) == 0 {
+		return nil
+	}
+
+	stored, err := uc.refreshRepo.FindByHash(uc.tokenService.HashRefreshToken(input.RefreshToken))
+	if err != nil {
+		// El refresh token ya no existe: no hay nada más que revocar
+		return nil
+	}
+
+	if stored.UserName != input.Claims.UserName {
+		return errors.New("el refresh token no pertenece al usuario")
+	}
+
+	return uc.refreshRepo.RevokeFamily(stored.FamilyID)
+}
@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/RodrigoGonzalez78/internal/domain/repository"
+	"github.com/RodrigoGonzalez78/internal/domain/service"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("refresh token inválido o expirado")
+	ErrRefreshTokenReused  = errors.New("refresh token reutilizado: la sesión fue revocada")
+)
+
+type RefreshUseCase struct {
+	refreshRepo  repository.RefreshTokenRepository
+	tokenService *service.TokenService
+	issuer       *sessionIssuer
+}
+
+func NewRefreshUseCase(
+	refreshRepo repository.RefreshTokenRepository,
+	tokenService *service.TokenService,
+	refreshExpiry time.Duration,
+) *RefreshUseCase {
+	return &RefreshUseCase{
+		refreshRepo:  refreshRepo,
+		tokenService: tokenService,
+		issuer:       newSessionIssuer(tokenService, refreshRepo, refreshExpiry),
+	}
+}
+
+type RefreshInput struct {
+	RefreshToken string
+}
+
+// Execute rota el refresh token: el recibido queda usado y se emite uno nuevo
+// de la misma familia. Presentar un token ya usado revoca toda la familia,
+// porque significa que alguien más tiene una copia.
+func (uc *RefreshUseCase) Execute(input RefreshInput) (*LoginOutput, error) {
+	if len(input.RefreshToken) == 0 {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	stored, err := uc.refreshRepo.FindByHash(uc.tokenService.HashRefreshToken(input.RefreshToken))
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if stored.RevokedAt != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if stored.UsedAt != nil {
+		return nil, uc.revokeFamily(stored.FamilyID)
+	}
+
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// MarkUsed es atómico: si dos peticiones rotan el mismo token a la vez,
+	// solo una gana y la otra se trata como reutilización
+	marked, err := uc.refreshRepo.MarkUsed(stored.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !marked {
+		return nil, uc.revokeFamily(stored.FamilyID)
+	}
+
+	return uc.issuer.issue(stored.UserName, stored.FamilyID)
+}
+
+func (uc *RefreshUseCase) revokeFamily(familyID string) error {
+	log.Printf("Reutilización de refresh token detectada, revocando familia %s", familyID)
+	if err := uc.refreshRepo.RevokeFamily(familyID); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/RodrigoGonzalez78/internal/domain/entity"
+	"github.com/RodrigoGonzalez78/internal/domain/repository"
+	"github.com/RodrigoGonzalez78/internal/domain/service"
+)
+
+// sessionIssuer emite el par access token + refresh token
+type sessionIssuer struct {
+	tokenService  *service.TokenService
+	refreshRepo   repository.RefreshTokenRepository
+	refreshExpiry time.Duration
+}
+
+func newSessionIssuer(
+	tokenService *service.TokenService,
+	refreshRepo repository.RefreshTokenRepository,
+	refreshExpiry time.Duration,
+) *sessionIssuer {
+	return &sessionIssuer{
+		tokenService:  tokenService,
+		refreshRepo:   refreshRepo,
+		refreshExpiry: refreshExpiry,
+	}
+}
+
+func (s *sessionIssuer) issue(userName, familyID string) (*LoginOutput, error) {
+	accessToken, err := s.tokenService.Generate(userName)
+	if err != nil {
+		return nil, errors.New("error al generar token")
+	}
+
+	refreshToken, refreshHash, err := s.tokenService.GenerateRefreshToken()
+	if err != nil {
+		return nil, errors.New("error al generar token")
+	}
+
+	stored := entity.NewRefreshToken(refreshHash, userName, familyID, time.Now().Add(s.refreshExpiry))
+	if err := s.refreshRepo.Create(stored); err != nil {
+		return nil, errors.New("error al guardar el refresh token")
+	}
+
+	return &LoginOutput{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    s.tokenService.Expiry(),
+	}, nil
+}
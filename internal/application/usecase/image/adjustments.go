@@ -0,0 +1,212 @@
+package image
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+// floatInRange obtiene un parámetro numérico y verifica que esté en
+// [min, max]. Si required es true el parámetro no puede omitirse. Float ya
+// rechaza NaN, que pasaría esta comparación.
+func floatInRange(params OperationParams, name string, fallback, min, max float64, required bool) (float64, error) {
+	if required && !params.Has(name) {
+		return 0, &FieldError{Field: name, Message: "es requerido"}
+	}
+	value, err := params.Float(name, fallback)
+	if err != nil {
+		return 0, err
+	}
+	if value < min || value > max {
+		return 0, &FieldError{Field: name, Message: fmt.Sprintf("debe estar entre %g y %g", min, max)}
+	}
+	return value, nil
+}
+
+func validateBrightness(params OperationParams) error {
+	_, err := floatInRange(params, "percentage", 0, -100, 100, true)
+	return err
+}
+
+func applyBrightness(ctx context.Context, img image.Image, params OperationParams) (image.Image, error) {
+	percentage, _ := params.Float("percentage", 0)
+	return imaging.AdjustBrightness(img, percentage), nil
+}
+
+func validateContrast(params OperationParams) error {
+	_, err := floatInRange(params, "percentage", 0, -100, 100, true)
+	return err
+}
+
+func applyContrast(ctx context.Context, img image.Image, params OperationParams) (image.Image, error) {
+	percentage, _ := params.Float("percentage", 0)
+	return imaging.AdjustContrast(img, percentage), nil
+}
+
+// validateSaturation admite hasta 500: 100 duplica la saturación y -100 la
+// anula (escala de grises)
+func validateSaturation(params OperationParams) error {
+	_, err := floatInRange(params, "percentage", 0, -100, 500, true)
+	return err
+}
+
+func applySaturation(ctx context.Context, img image.Image, params OperationParams) (image.Image, error) {
+	percentage, _ := params.Float("percentage", 0)
+	return imaging.AdjustSaturation(img, percentage), nil
+}
+
+// validateGamma gamma 1 deja la imagen igual; menor oscurece y mayor aclara
+func validateGamma(params OperationParams) error {
+	gamma, err := floatInRange(params, "gamma", 1, 0, 10, true)
+	if err != nil {
+		return err
+	}
+	if gamma == 0 {
+		return &FieldError{Field: "gamma", Message: "debe ser mayor que 0"}
+	}
+	return nil
+}
+
+func applyGamma(ctx context.Context, img image.Image, params OperationParams) (image.Image, error) {
+	gamma, _ := params.Float("gamma", 1)
+	return imaging.AdjustGamma(img, gamma), nil
+}
+
+// validateSigmoid contraste no lineal: midpoint es el tono medio (0 a 1) y
+// factor la intensidad; positivo aumenta el contraste y negativo lo reduce
+func validateSigmoid(params OperationParams) error {
+	if _, err := floatInRange(params, "midpoint", 0.5, 0, 1, false); err != nil {
+		return err
+	}
+	_, err := floatInRange(params, "factor", 0, -10, 10, true)
+	return err
+}
+
+func applySigmoid(ctx context.Context, img image.Image, params OperationParams) (image.Image, error) {
+	midpoint, _ := params.Float("midpoint", 0.5)
+	factor, _ := params.Float("factor", 0)
+	return imaging.AdjustSigmoid(img, midpoint, factor), nil
+}
+
+func validateHue(params OperationParams) error {
+	_, err := floatInRange(params, "degrees", 0, -360, 360, true)
+	return err
+}
+
+// applyHue rota el tono de cada píxel en el espacio HSL conservando la
+// saturación y la luminosidad
+func applyHue(ctx context.Context, img image.Image, params OperationParams) (image.Image, error) {
+	degrees, _ := params.Float("degrees", 0)
+	shift := math.Mod(degrees/360, 1)
+	if shift == 0 {
+		return img, nil
+	}
+
+	return imaging.AdjustFunc(img, func(c color.NRGBA) color.NRGBA {
+		h, s, l := rgbToHSL(c.R, c.G, c.B)
+		h = math.Mod(h+shift+1, 1)
+		r, g, b := hslToRGB(h, s, l)
+		return color.NRGBA{R: r, G: g, B: b, A: c.A}
+	}), nil
+}
+
+func validateSepia(params OperationParams) error {
+	_, err := floatInRange(params, "intensity", 1, 0, 1, false)
+	return err
+}
+
+// applySepia aplica la matriz de tono sepia clásica; intensity mezcla el
+// resultado con el color original
+func applySepia(ctx context.Context, img image.Image, params OperationParams) (image.Image, error) {
+	intensity, _ := params.Float("intensity", 1)
+
+	return imaging.AdjustFunc(img, func(c color.NRGBA) color.NRGBA {
+		r, g, b := float64(c.R), float64(c.G), float64(c.B)
+		sr := 0.393*r + 0.769*g + 0.189*b
+		sg := 0.349*r + 0.686*g + 0.168*b
+		sb := 0.272*r + 0.534*g + 0.131*b
+		return color.NRGBA{
+			R: clampUint8(r + (sr-r)*intensity),
+			G: clampUint8(g + (sg-g)*intensity),
+			B: clampUint8(b + (sb-b)*intensity),
+			A: c.A,
+		}
+	}), nil
+}
+
+func applyInvert(ctx context.Context, img image.Image, params OperationParams) (image.Image, error) {
+	return imaging.Invert(img), nil
+}
+
+func clampUint8(v float64) uint8 {
+	return uint8(math.Max(0, math.Min(255, math.Round(v))))
+}
+
+// rgbToHSL convierte un color a tono, saturación y luminosidad en [0, 1]
+func rgbToHSL(r8, g8, b8 uint8) (h, s, l float64) {
+	r, g, b := float64(r8)/255, float64(g8)/255, float64(b8)/255
+	maxC := math.Max(r, math.Max(g, b))
+	minC := math.Min(r, math.Min(g, b))
+	l = (maxC + minC) / 2
+
+	if maxC == minC {
+		return 0, 0, l
+	}
+
+	d := maxC - minC
+	if l > 0.5 {
+		s = d / (2 - maxC - minC)
+	} else {
+		s = d / (maxC + minC)
+	}
+
+	switch maxC {
+	case r:
+		h = (g - b) / d
+		if g < b {
+			h += 6
+		}
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+	return h / 6, s, l
+}
+
+func hslToRGB(h, s, l float64) (uint8, uint8, uint8) {
+	if s == 0 {
+		v := clampUint8(l * 255)
+		return v, v, v
+	}
+
+	var q float64
+	if l < 0.5 {
+		q = l * (1 + s)
+	} else {
+		q = l + s - l*s
+	}
+	p := 2*l - q
+
+	hueToRGB := func(t float64) float64 {
+		t = math.Mod(t+1, 1)
+		switch {
+		case t < 1.0/6:
+			return p + (q-p)*6*t
+		case t < 1.0/2:
+			return q
+		case t < 2.0/3:
+			return p + (q-p)*(2.0/3-t)*6
+		default:
+			return p
+		}
+	}
+
+	return clampUint8(hueToRGB(h+1.0/3) * 255),
+		clampUint8(hueToRGB(h) * 255),
+		clampUint8(hueToRGB(h-1.0/3) * 255)
+}
@@ -0,0 +1,143 @@
+package image_test
+
+import (
+	"context"
+	"errors"
+	stdimage "image"
+	"image/color"
+	"testing"
+
+	"github.com/RodrigoGonzalez78/internal/application/usecase/image"
+)
+
+// adjustPixel aplica una operación sobre una imagen de un solo color y
+// devuelve el color resultante
+func adjustPixel(t *testing.T, c color.NRGBA, op image.Operation) color.NRGBA {
+	t.Helper()
+	img := stdimage.NewNRGBA(stdimage.Rect(0, 0, 4, 4))
+	for i := 0; i < len(img.Pix); i += 4 {
+		copy(img.Pix[i:], []uint8{c.R, c.G, c.B, c.A})
+	}
+
+	result, err := image.NewOperationRegistry().Run(context.Background(), img, []image.Operation{op})
+	if err != nil {
+		t.Fatalf("Run(%s) error = %v", op.Type, err)
+	}
+	return color.NRGBAModel.Convert(result.At(1, 1)).(color.NRGBA)
+}
+
+func TestOperationRegistry_Adjustments(t *testing.T) {
+	base := color.NRGBA{R: 100, G: 150, B: 200, A: 255}
+
+	tests := []struct {
+		name  string
+		input color.NRGBA
+		op    image.Operation
+		check func(c color.NRGBA) bool
+	}{
+		{
+			name:  "brillo máximo",
+			op:    image.Operation{Type: "brightness", Params: image.OperationParams{"percentage": 100.0}},
+			check: func(c color.NRGBA) bool { return c == color.NRGBA{R: 255, G: 255, B: 255, A: 255} },
+		},
+		{
+			name:  "brillo mínimo",
+			op:    image.Operation{Type: "brightness", Params: image.OperationParams{"percentage": -100.0}},
+			check: func(c color.NRGBA) bool { return c == color.NRGBA{A: 255} },
+		},
+		{
+			name:  "sin contraste",
+			op:    image.Operation{Type: "contrast", Params: image.OperationParams{"percentage": -100.0}},
+			check: func(c color.NRGBA) bool { return c.R == c.G && c.G == c.B && c.R >= 127 && c.R <= 128 },
+		},
+		{
+			name:  "gamma aclara",
+			op:    image.Operation{Type: "gamma", Params: image.OperationParams{"gamma": 2.0}},
+			check: func(c color.NRGBA) bool { return c.R > base.R && c.G > base.G && c.B > base.B },
+		},
+		{
+			name:  "desaturar",
+			op:    image.Operation{Type: "saturation", Params: image.OperationParams{"percentage": -100.0}},
+			check: func(c color.NRGBA) bool { return c.R == c.G && c.G == c.B },
+		},
+		{
+			name:  "rotar tono de rojo a verde",
+			input: color.NRGBA{R: 255, A: 255},
+			op:    image.Operation{Type: "hue", Params: image.OperationParams{"degrees": 120.0}},
+			check: func(c color.NRGBA) bool { return c == color.NRGBA{G: 255, A: 255} },
+		},
+		{
+			name:  "rotar tono una vuelta completa",
+			op:    image.Operation{Type: "hue", Params: image.OperationParams{"degrees": -360.0}},
+			check: func(c color.NRGBA) bool { return c == base },
+		},
+		{
+			name: "contraste sigmoide",
+			op:   image.Operation{Type: "sigmoid", Params: image.OperationParams{"factor": 5.0}},
+			check: func(c color.NRGBA) bool {
+				return c.R < base.R && c.B > base.B
+			},
+		},
+		{
+			name:  "sepia",
+			op:    image.Operation{Type: "sepia"},
+			check: func(c color.NRGBA) bool { return c == color.NRGBA{R: 192, G: 171, B: 134, A: 255} },
+		},
+		{
+			name:  "sepia sin intensidad",
+			op:    image.Operation{Type: "sepia", Params: image.OperationParams{"intensity": 0.0}},
+			check: func(c color.NRGBA) bool { return c == base },
+		},
+		{
+			name:  "invertir",
+			op:    image.Operation{Type: "invert"},
+			check: func(c color.NRGBA) bool { return c == color.NRGBA{R: 155, G: 105, B: 55, A: 255} },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := tt.input
+			if input == (color.NRGBA{}) {
+				input = base
+			}
+			if got := adjustPixel(t, input, tt.op); !tt.check(got) {
+				t.Errorf("%s(%v) = %v", tt.op.Type, input, got)
+			}
+		})
+	}
+}
+
+func TestOperationRegistry_AdjustmentErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		op        image.Operation
+		wantField string
+	}{
+		{name: "brillo sin valor", op: image.Operation{Type: "brightness"}, wantField: "percentage"},
+		{name: "brillo fuera de rango", op: image.Operation{Type: "brightness", Params: image.OperationParams{"percentage": 101.0}}, wantField: "percentage"},
+		{name: "contraste fuera de rango", op: image.Operation{Type: "contrast", Params: image.OperationParams{"percentage": -150.0}}, wantField: "percentage"},
+		{name: "gamma en cero", op: image.Operation{Type: "gamma", Params: image.OperationParams{"gamma": 0.0}}, wantField: "gamma"},
+		{name: "gamma excesiva", op: image.Operation{Type: "gamma", Params: image.OperationParams{"gamma": 11.0}}, wantField: "gamma"},
+		{name: "saturación fuera de rango", op: image.Operation{Type: "saturation", Params: image.OperationParams{"percentage": 600.0}}, wantField: "percentage"},
+		{name: "tono fuera de rango", op: image.Operation{Type: "hue", Params: image.OperationParams{"degrees": 400.0}}, wantField: "degrees"},
+		{name: "tono no numérico", op: image.Operation{Type: "hue", Params: image.OperationParams{"degrees": "rojo"}}, wantField: "degrees"},
+		{name: "sigmoide sin factor", op: image.Operation{Type: "sigmoid", Params: image.OperationParams{"midpoint": 0.5}}, wantField: "factor"},
+		{name: "sigmoide punto medio inválido", op: image.Operation{Type: "sigmoid", Params: image.OperationParams{"midpoint": 1.5, "factor": 3.0}}, wantField: "midpoint"},
+		{name: "sepia intensidad excesiva", op: image.Operation{Type: "sepia", Params: image.OperationParams{"intensity": 2.0}}, wantField: "intensity"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := image.NewOperationRegistry().Validate([]image.Operation{tt.op})
+
+			var fieldErr *image.FieldError
+			if !errors.As(err, &fieldErr) {
+				t.Fatalf("Validate() error = %v, want FieldError", err)
+			}
+			if fieldErr.Field != tt.wantField {
+				t.Errorf("FieldError.Field = %v, want %v", fieldErr.Field, tt.wantField)
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package image
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/RodrigoGonzalez78/internal/pkg/dhash"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrEmptyBatch    = errors.New("el lote no tiene archivos")
+	ErrBatchTooLarge = errors.New("el lote tiene demasiados archivos")
+)
+
+// BatchUploadUseCase sube varios archivos en paralelo con una cantidad fija
+// de workers. Cada archivo pasa por UploadUseCase igual que una subida
+// individual y el fallo de uno no afecta a los demás.
+type BatchUploadUseCase struct {
+	uploadUC *UploadUseCase
+	limits   ImageLimits
+	workers  int
+	maxFiles int
+}
+
+// NewBatchUploadUseCase crea una nueva instancia de BatchUploadUseCase.
+// maxFiles en cero no limita la cantidad de archivos.
+func NewBatchUploadUseCase(uploadUC *UploadUseCase, limits ImageLimits, workers, maxFiles int) *BatchUploadUseCase {
+	if workers <= 0 {
+		workers = 1
+	}
+	return &BatchUploadUseCase{
+		uploadUC: uploadUC,
+		limits:   limits,
+		workers:  workers,
+		maxFiles: maxFiles,
+	}
+}
+
+// BatchFile es un archivo del lote. Se abre recién cuando un worker lo toma.
+type BatchFile struct {
+	// Name es el nombre original, que solo se usa para identificar el
+	// resultado; el archivo se guarda con un nombre aleatorio
+	Name string
+	Size int64
+	Open func() (io.ReadCloser, error)
+}
+
+// BatchUploadInput representa los datos de entrada de una subida por lotes.
+// Las opciones se aplican a todos los archivos.
+type BatchUploadInput struct {
+	UserName           string
+	Files              []BatchFile
+	MetadataPolicy     MetadataPolicy
+	OnDuplicate        DuplicatePolicy
+	DuplicateThreshold int
+}
+
+// BatchResult es el resultado de un archivo: Image si se subió (o si era un
+// duplicado con DuplicateReturnExisting) o Err si se rechazó
+type BatchResult struct {
+	Name  string
+	Image *UploadOutput
+	Err   error
+}
+
+// MaxFiles devuelve cuántos archivos acepta un lote; cero si no hay límite
+func (uc *BatchUploadUseCase) MaxFiles() int {
+	return uc.maxFiles
+}
+
+// Execute sube los archivos y devuelve un resultado por archivo, en el mismo
+// orden. Si se cancela ctx, los archivos que no se empezaron a subir
+// terminan con el error del contexto.
+//
+// Con una política de duplicados, los archivos del lote que se parecen entre
+// sí (idénticos o a distancia menor o igual que DuplicateThreshold) se suben
+// uno después del otro, en el orden del lote, así la detección de duplicados
+// encuentra el primero y aplica OnDuplicate igual que si se hubieran subido
+// por separado.
+func (uc *BatchUploadUseCase) Execute(ctx context.Context, input BatchUploadInput) ([]BatchResult, error) {
+	if len(input.Files) == 0 {
+		return nil, ErrEmptyBatch
+	}
+	if uc.maxFiles > 0 && len(input.Files) > uc.maxFiles {
+		return nil, fmt.Errorf("%w: %d (máximo %d)", ErrBatchTooLarge, len(input.Files), uc.maxFiles)
+	}
+
+	results := make([]BatchResult, len(input.Files))
+	for index, file := range input.Files {
+		results[index].Name = file.Name
+	}
+
+	// Primero se calcula el hash perceptual de cada archivo para agrupar los
+	// parecidos; un archivo que no se puede leer queda con su error
+	hashes := make([]string, len(input.Files))
+	if input.OnDuplicate != "" && input.OnDuplicate != DuplicateAllow {
+		uc.parallel(len(input.Files), func(index int) {
+			hashes[index], results[index].Err = uc.fingerprint(ctx, input.Files[index])
+		})
+	}
+
+	var pending []int
+	for index := range input.Files {
+		if results[index].Err == nil {
+			pending = append(pending, index)
+		}
+	}
+	groups := groupSimilar(pending, hashes, input.DuplicateThreshold)
+
+	uc.parallel(len(groups), func(group int) {
+		for _, index := range groups[group] {
+			image, err := uc.upload(ctx, input, input.Files[index])
+			results[index].Image, results[index].Err = image, err
+		}
+	})
+
+	return results, nil
+}
+
+// groupSimilar agrupa los índices cuyos hashes están a distancia menor o
+// igual que threshold, también a través de otros: si a se parece a b y b a
+// c, los tres van juntos. Cada grupo conserva el orden del lote y los
+// archivos sin hash quedan solos.
+func groupSimilar(indexes []int, hashes []string, threshold int) [][]int {
+	parent := make(map[int]int, len(indexes))
+	var root func(index int) int
+	root = func(index int) int {
+		if parent[index] != index {
+			parent[index] = root(parent[index])
+		}
+		return parent[index]
+	}
+
+	parsed := make(map[int]uint64, len(indexes))
+	for _, index := range indexes {
+		parent[index] = index
+		hash, err := dhash.Parse(hashes[index])
+		if err != nil {
+			continue
+		}
+		for other, otherHash := range parsed {
+			if dhash.Distance(hash, otherHash) <= threshold {
+				parent[root(other)] = root(index)
+			}
+		}
+		parsed[index] = hash
+	}
+
+	var groups [][]int
+	groupOf := make(map[int]int)
+	for _, index := range indexes {
+		group, ok := groupOf[root(index)]
+		if !ok {
+			group = len(groups)
+			groupOf[root(index)] = group
+			groups = append(groups, nil)
+		}
+		groups[group] = append(groups[group], index)
+	}
+	return groups
+}
+
+// parallel llama a fn con cada índice menor que n usando como mucho
+// uc.workers goroutines a la vez
+func (uc *BatchUploadUseCase) parallel(n int, fn func(index int)) {
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < min(uc.workers, n); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				fn(index)
+			}
+		}()
+	}
+
+	for index := 0; index < n; index++ {
+		indexes <- index
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// fingerprint calcula el hash perceptual de un archivo. Los que superan el
+// tamaño máximo se rechazan sin leerlos; los que no se pueden decodificar
+// quedan sin hash y la subida informa el motivo.
+func (uc *BatchUploadUseCase) fingerprint(ctx context.Context, file BatchFile) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if err := uc.limits.CheckSize(file.Size); err != nil {
+		return "", err
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		return "", fmt.Errorf("error al abrir el archivo: %w", err)
+	}
+	defer reader.Close()
+
+	return analyze(reader, uc.limits).Hash, nil
+}
+
+// upload valida el formato real de un archivo y lo sube
+func (uc *BatchUploadUseCase) upload(ctx context.Context, input BatchUploadInput, file BatchFile) (*UploadOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("error al abrir el archivo: %w", err)
+	}
+	defer reader.Close()
+
+	body := bufio.NewReader(reader)
+	head, err := body.Peek(SniffLen)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("error al leer el archivo: %w", err)
+	}
+	info, err := uc.limits.Sniff(head, file.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.uploadUC.Execute(ctx, UploadInput{
+		FileName:           uuid.New().String() + "." + info.Extension,
+		UserName:           input.UserName,
+		Body:               body,
+		Size:               file.Size,
+		ContentType:        info.ContentType,
+		Format:             info.Format,
+		MetadataPolicy:     input.MetadataPolicy,
+		OnDuplicate:        input.OnDuplicate,
+		DuplicateThreshold: input.DuplicateThreshold,
+	})
+}
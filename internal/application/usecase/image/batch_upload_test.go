@@ -0,0 +1,280 @@
+package image_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/RodrigoGonzalez78/internal/application/usecase/image"
+	"github.com/RodrigoGonzalez78/internal/domain/entity"
+	"github.com/RodrigoGonzalez78/internal/domain/repository"
+	"github.com/RodrigoGonzalez78/internal/pkg/exif"
+)
+
+func batchFile(name string, data []byte) image.BatchFile {
+	return image.BatchFile{
+		Name: name,
+		Size: int64(len(data)),
+		Open: func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(data)), nil },
+	}
+}
+
+func TestBatchUploadUseCase_Execute(t *testing.T) {
+	f := setupUpload(image.DefaultImageLimits())
+	batchUC := image.NewBatchUploadUseCase(f.uploadUC, image.DefaultImageLimits(), 3, 10)
+
+	huge := craftPNG(20000, 20000)
+	results, err := batchUC.Execute(context.Background(), image.BatchUploadInput{
+		UserName: "testuser",
+		Files: []image.BatchFile{
+			batchFile("foto.jpg", phoneJPEG(t, phoneEXIF)),
+			batchFile("notas.txt", []byte("esto no es una imagen")),
+			batchFile("enorme.png", huge),
+			batchFile("otra.gif", encodeTestGIF(t, 8, 8)),
+			{Name: "perdido.jpg", Open: func() (io.ReadCloser, error) { return nil, errors.New("sin archivo") }},
+		},
+		MetadataPolicy: image.MetadataStripGPS,
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	var limitErr *image.LimitError
+	checks := []struct {
+		name string
+		ok   func(image.BatchResult) bool
+	}{
+		{"foto.jpg", func(r image.BatchResult) bool { return r.Err == nil && r.Image != nil }},
+		{"notas.txt", func(r image.BatchResult) bool { return errors.Is(r.Err, image.ErrUnsupportedMediaType) }},
+		{"enorme.png", func(r image.BatchResult) bool { return errors.As(r.Err, &limitErr) }},
+		{"otra.gif", func(r image.BatchResult) bool { return r.Err == nil && r.Image != nil }},
+		{"perdido.jpg", func(r image.BatchResult) bool { return r.Err != nil }},
+	}
+	if len(results) != len(checks) {
+		t.Fatalf("resultados = %d, want %d", len(results), len(checks))
+	}
+	for i, check := range checks {
+		if results[i].Name != check.name {
+			t.Errorf("resultado %d = %s, want %s (mismo orden que los archivos)", i, results[i].Name, check.name)
+		}
+		if !check.ok(results[i]) {
+			t.Errorf("resultado de %s: Image = %v, Err = %v", check.name, results[i].Image, results[i].Err)
+		}
+	}
+
+	if len(f.imageRepo.Images) != 2 {
+		t.Errorf("imágenes guardadas = %d, want 2", len(f.imageRepo.Images))
+	}
+	// Las opciones compartidas se aplican a cada archivo
+	stored := f.fileStorage.Files[f.imageRepo.Images[results[0].Image.ID].Path]
+	if exif.ContainsGPS(stored) {
+		t.Error("el original guardado conserva la posición")
+	}
+}
+
+func TestBatchUploadUseCase_SharedDuplicatePolicy(t *testing.T) {
+	f := setupUpload(image.DefaultImageLimits())
+	batchUC := image.NewBatchUploadUseCase(f.uploadUC, image.DefaultImageLimits(), 2, 10)
+	data := phoneJPEG(t, phoneEXIF)
+
+	existing, err := f.uploadUC.Execute(context.Background(), streamInput(data))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	results, err := batchUC.Execute(context.Background(), image.BatchUploadInput{
+		UserName:           "testuser",
+		Files:              []image.BatchFile{batchFile("copia.jpg", data), batchFile("patron.jpg", patternJPEG(t, 64, 64, 90, false))},
+		OnDuplicate:        image.DuplicateReject,
+		DuplicateThreshold: image.DefaultDuplicateThreshold,
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	var duplicateErr *image.DuplicateError
+	if !errors.As(results[0].Err, &duplicateErr) || duplicateErr.Existing.ID != existing.ID {
+		t.Errorf("copia.jpg Err = %v, want DuplicateError con la imagen %d", results[0].Err, existing.ID)
+	}
+	if results[1].Err != nil {
+		t.Errorf("patron.jpg Err = %v, want nil", results[1].Err)
+	}
+}
+
+func TestBatchUploadUseCase_DuplicatesInBatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     image.DuplicatePolicy
+		wantImages int
+		// check recibe el resultado de una copia y la imagen de su original
+		check func(result image.BatchResult, original *image.UploadOutput) bool
+	}{
+		{
+			name:       "reject",
+			policy:     image.DuplicateReject,
+			wantImages: 2,
+			check: func(result image.BatchResult, original *image.UploadOutput) bool {
+				var duplicateErr *image.DuplicateError
+				return errors.As(result.Err, &duplicateErr) && duplicateErr.Existing.ID == original.ID
+			},
+		},
+		{
+			name:       "return_existing",
+			policy:     image.DuplicateReturnExisting,
+			wantImages: 2,
+			check: func(result image.BatchResult, original *image.UploadOutput) bool {
+				return result.Err == nil && result.Image.ID == original.ID
+			},
+		},
+		{
+			name:       "allow",
+			policy:     image.DuplicateAllow,
+			wantImages: 5,
+			check: func(result image.BatchResult, original *image.UploadOutput) bool {
+				return result.Err == nil && result.Image.ID != original.ID
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Con la búsqueda de duplicados lenta, las copias que se suben a la
+			// vez no se ven entre sí
+			f := setupUpload(image.DefaultImageLimits())
+			uploadUC := image.NewUploadUseCase(&slowHashes{ImageRepository: f.imageRepo}, f.metadataRepo, f.blobs, nil, f.limits, "http://localhost", "8080")
+			batchUC := image.NewBatchUploadUseCase(uploadUC, image.DefaultImageLimits(), 4, 10)
+			data := phoneJPEG(t, phoneEXIF)
+
+			results, err := batchUC.Execute(context.Background(), image.BatchUploadInput{
+				UserName: "testuser",
+				Files: []image.BatchFile{
+					batchFile("a.jpg", data),
+					batchFile("patron.jpg", patternJPEG(t, 64, 64, 90, false)),
+					batchFile("b.jpg", data),
+					batchFile("c.jpg", data),
+					// Reducida y con otra calidad: no es idéntica pero está
+					// dentro del umbral
+					batchFile("patron-chica.jpg", patternJPEG(t, 32, 32, 40, false)),
+				},
+				OnDuplicate:        tt.policy,
+				DuplicateThreshold: image.DefaultDuplicateThreshold,
+			})
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			// El primero de cada grupo en el lote es el que se sube
+			if results[0].Err != nil || results[1].Err != nil {
+				t.Fatalf("a.jpg Err = %v, patron.jpg Err = %v", results[0].Err, results[1].Err)
+			}
+			copies := map[int]int{2: 0, 3: 0, 4: 1}
+			for index, original := range copies {
+				if result := results[index]; !tt.check(result, results[original].Image) {
+					t.Errorf("%s: Image = %+v, Err = %v", result.Name, result.Image, result.Err)
+				}
+			}
+			if len(f.imageRepo.Images) != tt.wantImages {
+				t.Errorf("imágenes guardadas = %d, want %d", len(f.imageRepo.Images), tt.wantImages)
+			}
+		})
+	}
+}
+
+func TestBatchUploadUseCase_BoundedWorkers(t *testing.T) {
+	storage := &concurrencyStorage{}
+	f := setupUpload(image.DefaultImageLimits()).withStorage(func(fileStorage repository.FileStorage) repository.FileStorage {
+		storage.FileStorage = fileStorage
+		return storage
+	})
+	batchUC := image.NewBatchUploadUseCase(f.uploadUC, image.DefaultImageLimits(), 2, 0)
+
+	var files []image.BatchFile
+	for i := 0; i < 6; i++ {
+		files = append(files, batchFile("foto.gif", encodeTestGIF(t, 8+i, 8)))
+	}
+
+	results, err := batchUC.Execute(context.Background(), image.BatchUploadInput{UserName: "testuser", Files: files})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	for i, result := range results {
+		if result.Err != nil {
+			t.Errorf("archivo %d: Err = %v", i, result.Err)
+		}
+	}
+	if storage.peak != 2 {
+		t.Errorf("subidas simultáneas = %d, want 2", storage.peak)
+	}
+}
+
+func TestBatchUploadUseCase_InvalidBatch(t *testing.T) {
+	f := setupUpload(image.DefaultImageLimits())
+	batchUC := image.NewBatchUploadUseCase(f.uploadUC, image.DefaultImageLimits(), 2, 2)
+	gif := encodeTestGIF(t, 8, 8)
+
+	tests := []struct {
+		name    string
+		files   []image.BatchFile
+		wantErr error
+	}{
+		{name: "sin archivos", wantErr: image.ErrEmptyBatch},
+		{
+			name:    "más archivos que el máximo",
+			files:   []image.BatchFile{batchFile("a.gif", gif), batchFile("b.gif", gif), batchFile("c.gif", gif)},
+			wantErr: image.ErrBatchTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := batchUC.Execute(context.Background(), image.BatchUploadInput{UserName: "testuser", Files: tt.files})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Execute() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(f.imageRepo.Images) != 0 {
+				t.Errorf("imágenes guardadas = %d, want 0", len(f.imageRepo.Images))
+			}
+		})
+	}
+}
+
+// slowHashes demora la respuesta de la búsqueda de duplicados, que refleja
+// las imágenes guardadas al momento de la consulta
+type slowHashes struct {
+	repository.ImageRepository
+}
+
+func (r *slowHashes) FindHashedByUser(userName string) ([]entity.Image, error) {
+	images, err := r.ImageRepository.FindHashedByUser(userName)
+	time.Sleep(20 * time.Millisecond)
+	return images, err
+}
+
+// concurrencyStorage registra cuántas subidas al storage hubo a la vez
+type concurrencyStorage struct {
+	repository.FileStorage
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+}
+
+func (s *concurrencyStorage) Upload(ctx context.Context, path string, reader io.Reader, size int64, contentType string) error {
+	s.mu.Lock()
+	s.inFlight++
+	s.peak = max(s.peak, s.inFlight)
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.inFlight--
+		s.mu.Unlock()
+	}()
+
+	// Da tiempo a que los demás workers empiecen su subida
+	time.Sleep(20 * time.Millisecond)
+	return s.FileStorage.Upload(ctx, path, reader, size, contentType)
+}
@@ -0,0 +1,288 @@
+package image
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/RodrigoGonzalez78/internal/domain/entity"
+	"github.com/RodrigoGonzalez78/internal/domain/repository"
+	"github.com/google/uuid"
+)
+
+// blobPrefix es el prefijo del storage donde se guarda el contenido de las
+// imágenes bajo su SHA-256
+const blobPrefix = "blobs/"
+
+// stagingPrefix guarda las subidas en curso mientras todavía no se conoce su
+// checksum
+const stagingPrefix = blobPrefix + "staging/"
+
+// ErrChecksumMismatch indica que el contenido leído de un blob no coincide
+// con el SHA-256 con el que se guardó
+var ErrChecksumMismatch = errors.New("el contenido del blob no coincide con su checksum")
+
+// BlobPath devuelve la ruta del blob con ese checksum. El primer byte se usa
+// como directorio para no acumular todos los objetos en uno solo.
+func BlobPath(checksum string) string {
+	return blobPrefix + checksum[:2] + "/" + checksum
+}
+
+// IsBlobPath indica si un objeto del storage es un blob. Los blobs no se
+// sirven por su ruta sino por la URL de cada imagen que los usa.
+func IsBlobPath(objectPath string) bool {
+	if _, ok := blobChecksum(objectPath); ok {
+		return true
+	}
+	name, ok := strings.CutPrefix(objectPath, stagingPrefix)
+	return ok && uuid.Validate(name) == nil
+}
+
+// blobChecksum extrae el checksum de la ruta de un blob
+func blobChecksum(objectPath string) (string, bool) {
+	rest, ok := strings.CutPrefix(objectPath, blobPrefix)
+	if !ok {
+		return "", false
+	}
+	dir, checksum, ok := strings.Cut(rest, "/")
+	if !ok || len(checksum) != 2*sha256.Size || dir != checksum[:2] || strings.ToLower(checksum) != checksum {
+		return "", false
+	}
+	if _, err := hex.DecodeString(checksum); err != nil {
+		return "", false
+	}
+	return checksum, true
+}
+
+// BlobStore guarda el contenido de las imágenes una sola vez aunque se suba
+// varias veces, y lo borra del storage cuando ninguna imagen lo referencia.
+// Las operaciones sobre un mismo checksum se serializan para que un blob no
+// se borre mientras otra subida lo está reutilizando.
+type BlobStore struct {
+	blobRepo    repository.BlobRepository
+	fileStorage repository.FileStorage
+	locks       [64]sync.Mutex
+}
+
+// NewBlobStore crea una nueva instancia de BlobStore
+func NewBlobStore(blobRepo repository.BlobRepository, fileStorage repository.FileStorage) *BlobStore {
+	return &BlobStore{
+		blobRepo:    blobRepo,
+		fileStorage: fileStorage,
+	}
+}
+
+// Put suma una referencia al blob de data y lo sube si todavía no existe
+func (s *BlobStore) Put(ctx context.Context, data []byte, contentType string) (*entity.Blob, error) {
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+	blob := entity.NewBlob(checksum, BlobPath(checksum), contentType, int64(len(data)))
+
+	lock := s.lock(checksum)
+	lock.Lock()
+	defer lock.Unlock()
+
+	store, err := s.acquire(ctx, blob)
+	if err != nil {
+		return nil, err
+	}
+
+	if store {
+		if err := s.fileStorage.Upload(ctx, blob.Path, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+			s.undo(checksum)
+			return nil, fmt.Errorf("error al subir imagen: %w", err)
+		}
+	}
+
+	return blob, nil
+}
+
+// PutStream hace lo mismo que Put leyendo el contenido de reader a medida
+// que se sube, sin cargarlo en memoria. Como el checksum se conoce recién al
+// terminar, el objeto se sube a una ruta temporal y después se mueve a la del
+// blob, o se descarta si el blob ya existía. size puede ser
+// repository.UnknownSize.
+func (s *BlobStore) PutStream(ctx context.Context, reader io.Reader, size int64, contentType string) (*entity.Blob, error) {
+	staging := stagingPrefix + uuid.New().String()
+	hash := sha256.New()
+	counter := &byteCounter{}
+
+	tee := io.TeeReader(reader, io.MultiWriter(hash, counter))
+	if err := s.fileStorage.Upload(ctx, staging, tee, size, contentType); err != nil {
+		return nil, fmt.Errorf("error al subir imagen: %w", err)
+	}
+
+	checksum := hex.EncodeToString(hash.Sum(nil))
+	blob := entity.NewBlob(checksum, BlobPath(checksum), contentType, counter.n)
+
+	lock := s.lock(checksum)
+	lock.Lock()
+	defer lock.Unlock()
+
+	store, err := s.acquire(ctx, blob)
+	if err != nil {
+		s.discard(ctx, staging)
+		return nil, err
+	}
+
+	if !store {
+		s.discard(ctx, staging)
+		return blob, nil
+	}
+	if err := s.fileStorage.Move(ctx, staging, blob.Path); err != nil {
+		s.undo(checksum)
+		s.discard(ctx, staging)
+		return nil, fmt.Errorf("error al guardar el blob: %w", err)
+	}
+	return blob, nil
+}
+
+// acquire suma la referencia al blob e indica si hay que escribir su objeto.
+// Debe llamarse con el lock del checksum tomado.
+func (s *BlobStore) acquire(ctx context.Context, blob *entity.Blob) (bool, error) {
+	created, err := s.blobRepo.Acquire(blob)
+	if err != nil {
+		return false, fmt.Errorf("error al registrar el blob: %w", err)
+	}
+	if created {
+		return true, nil
+	}
+
+	// El registro puede haber sobrevivido a su objeto si una eliminación
+	// anterior falló a medias
+	_, err = s.fileStorage.Stat(ctx, blob.Path)
+	switch {
+	case errors.Is(err, repository.ErrObjectNotFound):
+		return true, nil
+	case err != nil:
+		s.undo(blob.Checksum)
+		return false, fmt.Errorf("error al consultar el blob: %w", err)
+	}
+	return false, nil
+}
+
+// Release quita la referencia de una imagen a su objeto y lo borra del
+// storage si era la última. Indica si el objeto se borró. Los objetos
+// anteriores a los blobs pertenecen a una sola imagen y se borran siempre.
+func (s *BlobStore) Release(ctx context.Context, objectPath string) (bool, error) {
+	checksum, ok := blobChecksum(objectPath)
+	if !ok {
+		if err := s.fileStorage.Delete(ctx, objectPath); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	lock := s.lock(checksum)
+	lock.Lock()
+	defer lock.Unlock()
+
+	remaining, err := s.blobRepo.Release(checksum)
+	if err != nil {
+		return false, fmt.Errorf("error al liberar el blob: %w", err)
+	}
+	if remaining > 0 {
+		return false, nil
+	}
+
+	// Si el borrado falla el objeto queda sin registro; una subida posterior
+	// del mismo contenido lo vuelve a registrar y lo sobrescribe
+	if err := s.fileStorage.Delete(ctx, objectPath); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// undo devuelve la referencia sumada por un Put que no se pudo completar
+func (s *BlobStore) undo(checksum string) {
+	if _, err := s.blobRepo.Release(checksum); err != nil {
+		log.Printf("  No se pudo liberar el blob %s: %v", checksum, err)
+	}
+}
+
+// discard borra una subida temporal que no llegó a ser un blob
+func (s *BlobStore) discard(ctx context.Context, staging string) {
+	if err := s.fileStorage.Delete(ctx, staging); err != nil {
+		log.Printf("  No se pudo borrar la subida temporal %s: %v", staging, err)
+	}
+}
+
+func (s *BlobStore) lock(checksum string) *sync.Mutex {
+	b, _ := hex.DecodeString(checksum[:2])
+	return &s.locks[int(b[0])%len(s.locks)]
+}
+
+// byteCounter cuenta los bytes que pasan por él
+type byteCounter struct {
+	n int64
+}
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
+
+// verifiedStorage comprueba el checksum de los blobs al leerlos
+type verifiedStorage struct {
+	repository.FileStorage
+}
+
+// NewVerifiedStorage envuelve un FileStorage para que cada Get de un blob
+// compruebe que el contenido coincide con su SHA-256. El resto de los objetos
+// se leen sin cambios.
+func NewVerifiedStorage(fileStorage repository.FileStorage) repository.FileStorage {
+	return &verifiedStorage{FileStorage: fileStorage}
+}
+
+// Get devuelve un lector que calcula el SHA-256 a medida que se lee el blob.
+// Si al llegar al final no coincide, la última lectura devuelve
+// ErrChecksumMismatch en lugar de io.EOF, así quien lo consume entero nunca
+// toma por bueno un contenido alterado, y quien lee solo el comienzo no
+// carga el resto.
+func (s *verifiedStorage) Get(ctx context.Context, objectPath string) (io.ReadCloser, error) {
+	reader, err := s.FileStorage.Get(ctx, objectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	checksum, ok := blobChecksum(objectPath)
+	if !ok {
+		return reader, nil
+	}
+	return &verifyingReader{ReadCloser: reader, hash: sha256.New(), path: objectPath, checksum: checksum}, nil
+}
+
+// verifyingReader compara el SHA-256 de lo leído con el del blob al llegar a
+// io.EOF
+type verifyingReader struct {
+	io.ReadCloser
+	hash     hash.Hash
+	path     string
+	checksum string
+	err      error
+}
+
+func (r *verifyingReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+
+	n, err := r.ReadCloser.Read(p)
+	r.hash.Write(p[:n])
+	if errors.Is(err, io.EOF) && hex.EncodeToString(r.hash.Sum(nil)) != r.checksum {
+		log.Printf("  El blob %s está dañado: el checksum no coincide", r.path)
+		err = fmt.Errorf("%w: %s", ErrChecksumMismatch, r.path)
+	}
+	if err != nil {
+		r.err = err
+	}
+	return n, err
+}
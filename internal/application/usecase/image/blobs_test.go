@@ -0,0 +1,188 @@
+package image_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/RodrigoGonzalez78/internal/application/usecase/image"
+	"github.com/RodrigoGonzalez78/internal/domain/repository/mocks"
+)
+
+type blobFixture struct {
+	*uploadFixture
+	deleteUC    *image.DeleteUseCase
+	transformUC *image.TransformUseCase
+}
+
+func setupBlobs() *blobFixture {
+	f := &blobFixture{uploadFixture: setupUpload(image.ImageLimits{}).withStorage(image.NewVerifiedStorage)}
+	f.deleteUC = image.NewDeleteUseCase(f.imageRepo, nil, f.storage, f.blobs, nil)
+	f.transformUC = image.NewTransformUseCase(f.imageRepo, mocks.NewMockFontRepository(), f.storage, nil, image.DefaultImageLimits())
+	return f
+}
+
+func (f *blobFixture) upload(t *testing.T, userName, name string, data []byte) *image.UploadOutput {
+	t.Helper()
+	output, err := f.uploadUC.Execute(context.Background(), image.UploadInput{
+		FileName: name, UserName: userName, Data: data, ContentType: "image/png", Format: "png",
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	return output
+}
+
+func checksumOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestBlobStore_Deduplicates(t *testing.T) {
+	f := setupBlobs()
+	data := encodeTestPNG(t, 40, 30)
+	blobPath := image.BlobPath(checksumOf(data))
+
+	f.upload(t, "testuser", "a.png", data)
+	f.upload(t, "testuser", "b.png", data)
+	f.upload(t, "otro", "c.png", data)
+	f.upload(t, "testuser", "d.png", encodeTestPNG(t, 41, 30))
+
+	if len(f.fileStorage.Files) != 2 {
+		t.Fatalf("objetos guardados = %d, want 2", len(f.fileStorage.Files))
+	}
+	for id := int64(1); id <= 3; id++ {
+		if got := f.imageRepo.Images[id].Path; got != blobPath {
+			t.Errorf("Path de la imagen %d = %q, want %q", id, got, blobPath)
+		}
+	}
+	if blob, err := f.blobRepo.FindByChecksum(checksumOf(data)); err != nil || blob.RefCount != 3 {
+		t.Fatalf("blob = %+v, %v; want 3 referencias", blob, err)
+	}
+
+	// El blob se conserva mientras alguna imagen lo use
+	for _, input := range []image.DeleteInput{{ImageID: 1, UserName: "testuser"}, {ImageID: 3, UserName: "otro"}} {
+		if _, err := f.deleteUC.Execute(context.Background(), input); err != nil {
+			t.Fatalf("Execute() de eliminación error = %v", err)
+		}
+		if _, exists := f.fileStorage.Files[blobPath]; !exists {
+			t.Fatal("el blob se borró con imágenes que todavía lo usan")
+		}
+	}
+	if blob, _ := f.blobRepo.FindByChecksum(checksumOf(data)); blob == nil || blob.RefCount != 1 {
+		t.Errorf("blob = %+v, want 1 referencia", blob)
+	}
+
+	if _, err := f.deleteUC.Execute(context.Background(), image.DeleteInput{ImageID: 2, UserName: "testuser"}); err != nil {
+		t.Fatalf("Execute() de eliminación error = %v", err)
+	}
+	if _, exists := f.fileStorage.Files[blobPath]; exists {
+		t.Error("el blob debe borrarse con su última referencia")
+	}
+	if _, err := f.blobRepo.FindByChecksum(checksumOf(data)); err == nil {
+		t.Error("el registro del blob debe borrarse con su última referencia")
+	}
+
+	// Si el objeto se perdió, la siguiente subida lo vuelve a guardar
+	f.upload(t, "testuser", "e.png", encodeTestPNG(t, 41, 30))
+	delete(f.fileStorage.Files, f.imageRepo.Images[4].Path)
+	f.upload(t, "testuser", "f.png", encodeTestPNG(t, 41, 30))
+	if _, exists := f.fileStorage.Files[f.imageRepo.Images[4].Path]; !exists {
+		t.Error("el objeto perdido debe volver a subirse")
+	}
+}
+
+func TestBlobStore_UploadError(t *testing.T) {
+	f := setupBlobs()
+	f.fileStorage.UploadError = errors.New("minio caído")
+
+	_, err := f.uploadUC.Execute(context.Background(), image.UploadInput{
+		FileName: "a.png", UserName: "testuser", Data: encodeTestPNG(t, 10, 10), ContentType: "image/png", Format: "png",
+	})
+	if err == nil {
+		t.Fatal("Execute() se esperaba error")
+	}
+	if len(f.blobRepo.Blobs) != 0 || len(f.imageRepo.Images) != 0 {
+		t.Error("no debe quedar ninguna referencia si falla la subida")
+	}
+}
+
+func TestVerifiedStorage_Get(t *testing.T) {
+	f := setupBlobs()
+	data := encodeTestPNG(t, 40, 30)
+	f.upload(t, "testuser", "a.png", data)
+	blobPath := f.imageRepo.Images[1].Path
+
+	// La URL pública se resuelve al blob
+	reader, err := f.transformUC.ServeImage(context.Background(), "testuser/a.png")
+	if err != nil {
+		t.Fatalf("ServeImage() error = %v", err)
+	}
+	if got, _ := io.ReadAll(reader); string(got) != string(data) {
+		t.Error("ServeImage() devolvió otro contenido")
+	}
+	reader.Close()
+
+	output, err := f.transformUC.ExecutePath(context.Background(), image.TransformPathInput{
+		ObjectPath: "testuser/a.png",
+		Operations: []image.Operation{{Type: "grayscale"}},
+		Format:     "png",
+	})
+	if err != nil {
+		t.Fatalf("ExecutePath() error = %v", err)
+	}
+	if b := decodeOutput(t, output).Bounds(); b.Dx() != 40 {
+		t.Errorf("ancho = %d, want 40", b.Dx())
+	}
+
+	// Un blob alterado en el storage no se entrega
+	corrupted := append([]byte(nil), data...)
+	corrupted[len(corrupted)-20] ^= 0xFF
+	f.fileStorage.Files[blobPath] = corrupted
+
+	reader, err = f.transformUC.ServeImage(context.Background(), "testuser/a.png")
+	if err != nil {
+		t.Fatalf("ServeImage() error = %v", err)
+	}
+	// El comienzo se lee sin esperar al resto del blob
+	if head, err := io.ReadAll(io.LimitReader(reader, 8)); err != nil || string(head) != string(data[:8]) {
+		t.Errorf("comienzo del blob = %q, %v", head, err)
+	}
+	if _, err := io.ReadAll(reader); !errors.Is(err, image.ErrChecksumMismatch) {
+		t.Errorf("lectura completa error = %v, want ErrChecksumMismatch", err)
+	}
+	reader.Close()
+	if _, err := f.transformUC.Execute(context.Background(), image.TransformInput{ImageID: 1, UserName: "testuser", Rotate: 90}); err == nil {
+		t.Error("Execute() con un blob alterado se esperaba error")
+	}
+
+	// Los objetos que no son blobs se leen sin comprobar
+	f.fileStorage.Files["testuser/renditions/1/320.png"] = []byte("cualquier cosa")
+	if _, err := f.transformUC.ServeImage(context.Background(), "testuser/renditions/1/320.png"); err != nil {
+		t.Errorf("ServeImage() de otro objeto error = %v", err)
+	}
+}
+
+func TestIsBlobPath(t *testing.T) {
+	checksum := checksumOf([]byte("hola"))
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: image.BlobPath(checksum), want: true},
+		{path: "blobs/" + checksum, want: false},
+		{path: "blobs/00/" + checksum, want: false},
+		{path: "blobs/foto.jpg", want: false},
+		{path: "blobs/staging/6f1c2a9e-3b4d-4e5f-8a7b-9c0d1e2f3a4b", want: true},
+		{path: "blobs/staging/foto.jpg", want: false},
+		{path: "testuser/foto.jpg", want: false},
+	}
+	for _, tt := range tests {
+		if got := image.IsBlobPath(tt.path); got != tt.want {
+			t.Errorf("IsBlobPath(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
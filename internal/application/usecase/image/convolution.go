@@ -0,0 +1,150 @@
+package image
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+const (
+	maxBlurSigma   = 50
+	maxKernelValue = 1000
+)
+
+var (
+	// edgeDetectKernel laplaciano que resalta los bordes en cualquier dirección
+	edgeDetectKernel = [9]float64{
+		-1, -1, -1,
+		-1, 8, -1,
+		-1, -1, -1,
+	}
+
+	// embossKernel relieve con luz desde arriba a la izquierda
+	embossKernel = [9]float64{
+		-1, -1, 0,
+		-1, 1, 1,
+		0, 1, 1,
+	}
+)
+
+func validateBlur(params OperationParams) error {
+	sigma, err := floatInRange(params, "sigma", 0, 0, maxBlurSigma, true)
+	if err != nil {
+		return err
+	}
+	if sigma == 0 {
+		return &FieldError{Field: "sigma", Message: "debe ser mayor que 0"}
+	}
+	return nil
+}
+
+func applyBlur(ctx context.Context, img image.Image, params OperationParams) (image.Image, error) {
+	sigma, _ := params.Float("sigma", 0)
+	return imaging.Blur(img, sigma), nil
+}
+
+func validateSharpen(params OperationParams) error {
+	sigma, err := floatInRange(params, "sigma", 1, 0, maxBlurSigma, false)
+	if err != nil {
+		return err
+	}
+	if sigma == 0 {
+		return &FieldError{Field: "sigma", Message: "debe ser mayor que 0"}
+	}
+	if _, err := floatInRange(params, "amount", 1, 0, 10, false); err != nil {
+		return err
+	}
+	_, err = floatInRange(params, "threshold", 0, 0, 255, false)
+	return err
+}
+
+// applySharpen aplica una máscara de enfoque (unsharp mask): suma a cada
+// canal amount veces su diferencia con la versión desenfocada, solo donde
+// esa diferencia alcanza threshold para no amplificar el ruido en zonas lisas
+func applySharpen(ctx context.Context, img image.Image, params OperationParams) (image.Image, error) {
+	sigma, _ := params.Float("sigma", 1)
+	amount, _ := params.Float("amount", 1)
+	threshold, _ := params.Float("threshold", 0)
+
+	src := imaging.Clone(img)
+	blurred := imaging.Blur(src, sigma)
+	dst := imaging.Clone(src)
+
+	for i := 0; i < len(dst.Pix); i += 4 {
+		if i%(dst.Stride*64) == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		for c := 0; c < 3; c++ {
+			diff := float64(src.Pix[i+c]) - float64(blurred.Pix[i+c])
+			if math.Abs(diff) < threshold {
+				continue
+			}
+			dst.Pix[i+c] = clampUint8(float64(src.Pix[i+c]) + amount*diff)
+		}
+	}
+	return dst, nil
+}
+
+func validateConvolve(params OperationParams) error {
+	kernel, err := params.Floats("kernel")
+	if err != nil {
+		return err
+	}
+	if len(kernel) != 9 && len(kernel) != 25 {
+		return &FieldError{Field: "kernel", Message: "debe tener 9 (3x3) o 25 (5x5) valores"}
+	}
+	for _, value := range kernel {
+		if math.IsNaN(value) || math.Abs(value) > maxKernelValue {
+			return &FieldError{Field: "kernel", Message: fmt.Sprintf("los valores deben estar entre %d y %d", -maxKernelValue, maxKernelValue)}
+		}
+	}
+
+	for _, field := range []string{"normalize", "abs"} {
+		if _, err := params.Bool(field, false); err != nil {
+			return err
+		}
+	}
+
+	bias, err := floatInRange(params, "bias", 0, -255, 255, false)
+	if err != nil {
+		return err
+	}
+	if bias != math.Trunc(bias) {
+		return &FieldError{Field: "bias", Message: "debe ser un número entero"}
+	}
+	return nil
+}
+
+// applyConvolve aplica un kernel 3x3 o 5x5. Con normalize el kernel se
+// divide por la suma de sus valores (o de los positivos si suman cero); abs
+// toma el valor absoluto del resultado y bias se suma a cada canal.
+func applyConvolve(ctx context.Context, img image.Image, params OperationParams) (image.Image, error) {
+	kernel, _ := params.Floats("kernel")
+	normalize, _ := params.Bool("normalize", true)
+	abs, _ := params.Bool("abs", false)
+	bias, _ := params.Int("bias", 0)
+
+	options := &imaging.ConvolveOptions{Normalize: normalize, Abs: abs, Bias: bias}
+	if len(kernel) == 9 {
+		var k [9]float64
+		copy(k[:], kernel)
+		return imaging.Convolve3x3(img, k, options), nil
+	}
+
+	var k [25]float64
+	copy(k[:], kernel)
+	return imaging.Convolve5x5(img, k, options), nil
+}
+
+func applyEdgeDetect(ctx context.Context, img image.Image, params OperationParams) (image.Image, error) {
+	return imaging.Convolve3x3(img, edgeDetectKernel, nil), nil
+}
+
+func applyEmboss(ctx context.Context, img image.Image, params OperationParams) (image.Image, error) {
+	return imaging.Convolve3x3(img, embossKernel, nil), nil
+}
@@ -0,0 +1,191 @@
+package image_test
+
+import (
+	"context"
+	"errors"
+	stdimage "image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/RodrigoGonzalez78/internal/application/usecase/image"
+)
+
+// edgeImage crea una imagen gris de 20x6 con un borde vertical en x=10
+func edgeImage(left, right uint8) *stdimage.NRGBA {
+	img := stdimage.NewNRGBA(stdimage.Rect(0, 0, 20, 6))
+	for y := 0; y < 6; y++ {
+		for x := 0; x < 20; x++ {
+			v := left
+			if x >= 10 {
+				v = right
+			}
+			img.SetNRGBA(x, y, color.NRGBA{R: v, G: v, B: v, A: 255})
+		}
+	}
+	return img
+}
+
+func runOperation(t *testing.T, img stdimage.Image, op image.Operation) stdimage.Image {
+	t.Helper()
+	result, err := image.NewOperationRegistry().Run(context.Background(), img, []image.Operation{op})
+	if err != nil {
+		t.Fatalf("Run(%s) error = %v", op.Type, err)
+	}
+	return result
+}
+
+func grayAt(img stdimage.Image, x, y int) uint8 {
+	return color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA).R
+}
+
+func TestOperationRegistry_BlurAndSharpen(t *testing.T) {
+	src := edgeImage(100, 150)
+
+	blurred := runOperation(t, src, image.Operation{Type: "blur", Params: image.OperationParams{"sigma": 2.0}})
+	if v := grayAt(blurred, 9, 3); v <= 100 || v >= 150 {
+		t.Errorf("blur: píxel junto al borde = %d, se esperaba un valor intermedio", v)
+	}
+
+	sharpened := runOperation(t, src, image.Operation{Type: "sharpen", Params: image.OperationParams{"sigma": 1.0, "amount": 2.0}})
+	if left, right := grayAt(sharpened, 9, 3), grayAt(sharpened, 10, 3); left >= 100 || right <= 150 {
+		t.Errorf("sharpen: borde = %d | %d, se esperaba más contraste que 100 | 150", left, right)
+	}
+	if v := grayAt(sharpened, 0, 3); v != 100 {
+		t.Errorf("sharpen: zona lisa = %d, no debe cambiar", v)
+	}
+
+	// Con un umbral mayor que la diferencia en el borde no se modifica nada
+	untouched := runOperation(t, src, image.Operation{Type: "sharpen", Params: image.OperationParams{"threshold": 60.0}})
+	if left, right := grayAt(untouched, 9, 3), grayAt(untouched, 10, 3); left != 100 || right != 150 {
+		t.Errorf("sharpen con threshold: borde = %d | %d, want 100 | 150", left, right)
+	}
+}
+
+func TestOperationRegistry_Convolve(t *testing.T) {
+	src := edgeImage(100, 150)
+
+	tests := []struct {
+		name      string
+		op        image.Operation
+		wantLeft  uint8
+		wantRight uint8
+	}{
+		{
+			name:      "identidad",
+			op:        image.Operation{Type: "convolve", Params: image.OperationParams{"kernel": []interface{}{0.0, 0.0, 0.0, 0.0, 1.0, 0.0, 0.0, 0.0, 0.0}}},
+			wantLeft:  100,
+			wantRight: 150,
+		},
+		{
+			name:      "identidad desde una URL",
+			op:        image.Operation{Type: "convolve", Params: image.OperationParams{"kernel": "0,0,0,0,1,0,0,0,0"}},
+			wantLeft:  100,
+			wantRight: 150,
+		},
+		{
+			// Promedio horizontal de 3 píxeles: (100+100+150)/3 y (100+150+150)/3
+			name:      "promedio normalizado",
+			op:        image.Operation{Type: "convolve", Params: image.OperationParams{"kernel": []interface{}{0.0, 0.0, 0.0, 1.0, 1.0, 1.0, 0.0, 0.0, 0.0}}},
+			wantLeft:  117,
+			wantRight: 133,
+		},
+		{
+			name: "sin normalizar con bias",
+			op: image.Operation{Type: "convolve", Params: image.OperationParams{
+				"kernel":    []interface{}{0.0, 0.0, 0.0, 0.0, 0.5, 0.0, 0.0, 0.0, 0.0},
+				"normalize": false,
+				"bias":      10.0,
+			}},
+			wantLeft:  60,
+			wantRight: 85,
+		},
+		{
+			name: "kernel 5x5",
+			op: image.Operation{Type: "convolve", Params: image.OperationParams{"kernel": []interface{}{
+				0.0, 0.0, 0.0, 0.0, 0.0,
+				0.0, 0.0, 0.0, 0.0, 0.0,
+				1.0, 0.0, 0.0, 0.0, 0.0,
+				0.0, 0.0, 0.0, 0.0, 0.0,
+				0.0, 0.0, 0.0, 0.0, 0.0,
+			}}},
+			// Cada píxel toma el valor de dos posiciones a la izquierda
+			wantLeft:  100,
+			wantRight: 100,
+		},
+		{
+			// 8×150 menos tres vecinos de 100 y cinco de 150
+			name:      "bordes",
+			op:        image.Operation{Type: "edge_detect"},
+			wantLeft:  0,
+			wantRight: 150,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := runOperation(t, src, tt.op)
+			if left, right := grayAt(result, 9, 3), grayAt(result, 10, 3); left != tt.wantLeft || right != tt.wantRight {
+				t.Errorf("borde = %d | %d, want %d | %d", left, right, tt.wantLeft, tt.wantRight)
+			}
+		})
+	}
+
+	// En una zona lisa el relieve no cambia el color porque el kernel suma 1
+	embossed := runOperation(t, src, image.Operation{Type: "emboss"})
+	if v := grayAt(embossed, 2, 3); v != 100 {
+		t.Errorf("emboss en zona lisa = %d, want 100", v)
+	}
+}
+
+func TestOperationRegistry_ConvolutionErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		op        image.Operation
+		wantField string
+	}{
+		{name: "blur sin sigma", op: image.Operation{Type: "blur"}, wantField: "sigma"},
+		{name: "blur sigma en cero", op: image.Operation{Type: "blur", Params: image.OperationParams{"sigma": 0.0}}, wantField: "sigma"},
+		{name: "blur sigma NaN", op: image.Operation{Type: "blur", Params: image.OperationParams{"sigma": math.NaN()}}, wantField: "sigma"},
+		{name: "blur sigma infinito", op: image.Operation{Type: "blur", Params: image.OperationParams{"sigma": math.Inf(1)}}, wantField: "sigma"},
+		{name: "blur sigma excesivo", op: image.Operation{Type: "blur", Params: image.OperationParams{"sigma": 51.0}}, wantField: "sigma"},
+		{name: "sharpen cantidad NaN", op: image.Operation{Type: "sharpen", Params: image.OperationParams{"amount": math.NaN()}}, wantField: "amount"},
+		{name: "sharpen cantidad excesiva", op: image.Operation{Type: "sharpen", Params: image.OperationParams{"amount": 11.0}}, wantField: "amount"},
+		{name: "sharpen umbral fuera de rango", op: image.Operation{Type: "sharpen", Params: image.OperationParams{"threshold": 300.0}}, wantField: "threshold"},
+		{name: "kernel ausente", op: image.Operation{Type: "convolve"}, wantField: "kernel"},
+		{name: "kernel de 2x2", op: image.Operation{Type: "convolve", Params: image.OperationParams{"kernel": []interface{}{1.0, 0.0, 0.0, 1.0}}}, wantField: "kernel"},
+		{name: "kernel no numérico", op: image.Operation{Type: "convolve", Params: image.OperationParams{"kernel": "1,a,0,0,1,0,0,0,0"}}, wantField: "kernel"},
+		{name: "kernel con valor excesivo", op: image.Operation{Type: "convolve", Params: image.OperationParams{"kernel": "0,0,0,0,2000,0,0,0,0"}}, wantField: "kernel"},
+		{name: "bias no entero", op: image.Operation{Type: "convolve", Params: image.OperationParams{"kernel": "0,0,0,0,1,0,0,0,0", "bias": 1.5}}, wantField: "bias"},
+		{name: "normalize no booleano", op: image.Operation{Type: "convolve", Params: image.OperationParams{"kernel": "0,0,0,0,1,0,0,0,0", "normalize": "si"}}, wantField: "normalize"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := image.NewOperationRegistry().Validate([]image.Operation{tt.op})
+
+			var fieldErr *image.FieldError
+			if !errors.As(err, &fieldErr) {
+				t.Fatalf("Validate() error = %v, want FieldError", err)
+			}
+			if fieldErr.Field != tt.wantField {
+				t.Errorf("FieldError.Field = %v, want %v", fieldErr.Field, tt.wantField)
+			}
+		})
+	}
+}
+
+// Las URLs firmadas admiten "NaN" como número; si pasara la validación,
+// imaging.Blur entraría en pánico en cada GET de la URL
+func TestOperationRegistry_RejectsNaNFromURL(t *testing.T) {
+	for _, segment := range []string{"blur:sigma=NaN", "blur:sigma=+Inf", "sharpen:sigma=NaN", "brightness:percentage=NaN"} {
+		ops, _, err := image.ParseURLOperations([]string{segment})
+		if err != nil {
+			t.Fatalf("ParseURLOperations(%s) error = %v", segment, err)
+		}
+		var fieldErr *image.FieldError
+		if err := image.NewOperationRegistry().Validate(ops); !errors.As(err, &fieldErr) {
+			t.Errorf("Validate(%s) error = %v, want FieldError", segment, err)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package image
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/RodrigoGonzalez78/internal/domain/entity"
+	"github.com/RodrigoGonzalez78/internal/domain/repository"
+)
+
+// DeleteUseCase maneja el caso de uso de eliminar una imagen.
+//
+// La eliminación se hace en dos fases: primero se marca la fila como
+// pendiente (deja de ser visible) y después se borra el objeto del storage y
+// la fila. Si la segunda fase falla, la marca permanece y ReconcilePending la
+// reintenta más tarde, de modo que no quedan ni objetos huérfanos ni filas
+// apuntando a objetos inexistentes.
+type DeleteUseCase struct {
+	imageRepo   repository.ImageRepository
+	jobRepo     repository.TransformJobRepository
+	fileStorage repository.FileStorage
+	blobs       *BlobStore
+	cache       *DerivativeCache
+}
+
+// NewDeleteUseCase crea una nueva instancia de DeleteUseCase. jobRepo puede
+// ser nil si no hay trabajos de transformación que borrar con la imagen.
+func NewDeleteUseCase(
+	imageRepo repository.ImageRepository,
+	jobRepo repository.TransformJobRepository,
+	fileStorage repository.FileStorage,
+	blobs *BlobStore,
+	cache *DerivativeCache,
+) *DeleteUseCase {
+	return &DeleteUseCase{
+		imageRepo:   imageRepo,
+		jobRepo:     jobRepo,
+		fileStorage: fileStorage,
+		blobs:       blobs,
+		cache:       cache,
+	}
+}
+
+// DeleteInput representa los datos de entrada
+type DeleteInput struct {
+	ImageID  int64
+	UserName string
+}
+
+// DeleteOutput representa los datos de salida
+type DeleteOutput struct {
+	// Pending indica que la limpieza del storage quedó pendiente de reintento
+	Pending bool
+}
+
+// Execute ejecuta el caso de uso
+func (uc *DeleteUseCase) Execute(ctx context.Context, input DeleteInput) (*DeleteOutput, error) {
+	image, err := uc.imageRepo.FindByID(input.ImageID)
+	if err != nil {
+		return nil, errors.New("imagen no encontrada")
+	}
+
+	if image.UserName != input.UserName {
+		return nil, errors.New("no tienes permiso para esta imagen")
+	}
+
+	if err := uc.imageRepo.MarkPendingDelete(image.ID); err != nil {
+		return nil, fmt.Errorf("error al eliminar imagen: %w", err)
+	}
+
+	if err := uc.purge(ctx, image); err != nil {
+		log.Printf("  Eliminación de la imagen %d pendiente de reintento: %v", image.ID, err)
+		return &DeleteOutput{Pending: true}, nil
+	}
+
+	return &DeleteOutput{}, nil
+}
+
+// ReconcilePending reintenta las eliminaciones que quedaron a medias y
+// devuelve cuántas se completaron
+func (uc *DeleteUseCase) ReconcilePending(ctx context.Context, limit int) (int, error) {
+	images, err := uc.imageRepo.FindPendingDelete(limit)
+	if err != nil {
+		return 0, err
+	}
+
+	completed := 0
+	for i := range images {
+		if err := uc.purge(ctx, &images[i]); err != nil {
+			log.Printf("  No se pudo completar la eliminación de la imagen %d: %v", images[i].ID, err)
+			continue
+		}
+		completed++
+	}
+
+	return completed, nil
+}
+
+// StartReconciler ejecuta ReconcilePending periódicamente hasta que se
+// cancele el contexto
+func (uc *DeleteUseCase) StartReconciler(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			completed, err := uc.ReconcilePending(ctx, 100)
+			if err != nil {
+				log.Printf("  Error al reconciliar eliminaciones pendientes: %v", err)
+				continue
+			}
+			if completed > 0 {
+				log.Printf(" Eliminaciones pendientes completadas: %d", completed)
+			}
+		}
+	}
+}
+
+// purge borra las versiones, los trabajos con sus resultados, el objeto y
+// finalmente la fila. Es idempotente para poder reintentarse.
+func (uc *DeleteUseCase) purge(ctx context.Context, image *entity.Image) error {
+	if err := uc.fileStorage.DeletePrefix(ctx, renditionPrefix(image.UserName, image.ID)); err != nil {
+		return fmt.Errorf("error al eliminar las versiones: %w", err)
+	}
+
+	if uc.jobRepo != nil {
+		jobs, err := uc.jobRepo.FindByImage(image.ID)
+		if err != nil {
+			return fmt.Errorf("error al buscar los trabajos: %w", err)
+		}
+		for i := range jobs {
+			if err := deleteJob(ctx, uc.jobRepo, uc.fileStorage, &jobs[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Los objetos anteriores a los blobs son solo de esta imagen y se pueden
+	// borrar antes que la fila, así un fallo se reintenta
+	shared := IsBlobPath(image.Path)
+	if !shared {
+		if err := uc.release(ctx, image.Path); err != nil {
+			return fmt.Errorf("error al eliminar el objeto: %w", err)
+		}
+	}
+
+	if err := uc.imageRepo.Delete(image.ID); err != nil {
+		return fmt.Errorf("error al eliminar el registro: %w", err)
+	}
+
+	// La referencia a un blob se libera una sola vez, después de borrar la
+	// fila: si falla, el blob queda con una referencia de más en lugar de
+	// borrarse mientras otra imagen lo usa
+	if shared {
+		if err := uc.release(ctx, image.Path); err != nil {
+			log.Printf("  No se pudo liberar el blob %s de la imagen %d: %v", image.Path, image.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// release quita la referencia al objeto y, si se borró, descarta sus derivados
+func (uc *DeleteUseCase) release(ctx context.Context, objectPath string) error {
+	removed, err := uc.blobs.Release(ctx, objectPath)
+	if err != nil {
+		return err
+	}
+
+	if removed && uc.cache != nil {
+		if err := uc.cache.Invalidate(ctx, objectPath); err != nil {
+			// Los derivados ya no son alcanzables porque el original no existe
+			log.Printf("  No se pudieron eliminar los derivados de %s: %v", objectPath, err)
+		}
+	}
+	return nil
+}
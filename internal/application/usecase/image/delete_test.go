@@ -0,0 +1,104 @@
+package image_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/RodrigoGonzalez78/internal/application/usecase/image"
+	"github.com/RodrigoGonzalez78/internal/domain/repository/mocks"
+)
+
+func TestDeleteUseCase_Execute(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       image.DeleteInput
+		storageErr  error
+		wantErr     bool
+		wantPending bool
+	}{
+		{
+			name:  "eliminación exitosa",
+			input: image.DeleteInput{ImageID: 1, UserName: "testuser"},
+		},
+		{
+			name:    "imagen de otro usuario",
+			input:   image.DeleteInput{ImageID: 1, UserName: "otroUsuario"},
+			wantErr: true,
+		},
+		{
+			name:    "imagen no encontrada",
+			input:   image.DeleteInput{ImageID: 999, UserName: "testuser"},
+			wantErr: true,
+		},
+		{
+			name:        "falla el storage",
+			input:       image.DeleteInput{ImageID: 1, UserName: "testuser"},
+			storageErr:  errors.New("minio caído"),
+			wantPending: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, imageRepo, fileStorage := setupTransform(t)
+			cache := image.NewDerivativeCache(fileStorage, 1<<20)
+			transformUC := image.NewTransformUseCase(imageRepo, mocks.NewMockFontRepository(), fileStorage, cache, image.DefaultImageLimits())
+			useCase := image.NewDeleteUseCase(imageRepo, nil, fileStorage, image.NewBlobStore(mocks.NewMockBlobRepository(), fileStorage), cache)
+
+			// Generar un derivado cacheado
+			_, err := transformUC.Execute(context.Background(), image.TransformInput{
+				ImageID:    1,
+				UserName:   "testuser",
+				Operations: []image.Operation{{Type: "grayscale"}},
+			})
+			if err != nil {
+				t.Fatalf("Execute() de transformación error = %v", err)
+			}
+
+			fileStorage.DeleteError = tt.storageErr
+			output, err := useCase.Execute(context.Background(), tt.input)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if _, exists := imageRepo.Images[1]; !exists {
+					t.Error("la imagen no debe eliminarse si falla la validación")
+				}
+				return
+			}
+			if output.Pending != tt.wantPending {
+				t.Errorf("Pending = %v, want %v", output.Pending, tt.wantPending)
+			}
+
+			// Tanto si terminó como si quedó pendiente, la imagen deja de ser visible
+			if _, err := imageRepo.FindByID(1); err == nil {
+				t.Error("la imagen no debe ser visible tras eliminarla")
+			}
+
+			if tt.wantPending {
+				if _, exists := fileStorage.Files["testuser/test.png"]; !exists {
+					t.Error("el objeto no debería haberse eliminado")
+				}
+
+				// La reconciliación completa la eliminación cuando el storage vuelve
+				fileStorage.DeleteError = nil
+				completed, err := useCase.ReconcilePending(context.Background(), 10)
+				if err != nil || completed != 1 {
+					t.Fatalf("ReconcilePending() = %d, %v; want 1, nil", completed, err)
+				}
+			}
+
+			if _, exists := imageRepo.Images[1]; exists {
+				t.Error("el registro debe eliminarse")
+			}
+			for path := range fileStorage.Files {
+				if path == "testuser/test.png" || strings.HasPrefix(path, "cache/") {
+					t.Errorf("quedó el objeto %s en el storage", path)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package image
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/RodrigoGonzalez78/internal/domain/repository"
+	"golang.org/x/sync/singleflight"
+)
+
+// cachePrefix agrupa en el storage los derivados cacheados
+const cachePrefix = "cache/"
+
+// DerivativeCache guarda los resultados ya codificados de las
+// transformaciones. Tiene una LRU en memoria delante de un prefijo propio del
+// FileStorage y colapsa las peticiones idénticas concurrentes en una sola.
+type DerivativeCache struct {
+	fileStorage repository.FileStorage
+	memory      *lruCache
+	group       singleflight.Group
+}
+
+// NewDerivativeCache crea la caché. Con maxMemoryBytes <= 0 no se usa la LRU
+// en memoria y solo se consulta el storage.
+func NewDerivativeCache(fileStorage repository.FileStorage, maxMemoryBytes int64) *DerivativeCache {
+	return &DerivativeCache{
+		fileStorage: fileStorage,
+		memory:      newLRUCache(maxMemoryBytes),
+	}
+}
+
+// canonicalTransform es la forma estable que se hashea para obtener la clave
+type canonicalTransform struct {
+	Source     string      `json:"source"`
+	Owner      string      `json:"owner"`
+	ETag       string      `json:"etag"`
+	Size       int64       `json:"size"`
+	Operations []Operation `json:"operations"`
+	Format     string      `json:"format"`
+}
+
+// DerivativeKey calcula la clave de caché a partir del objeto original y de
+// los parámetros canónicos. El ETag y el tamaño del original forman parte de
+// la clave, así que reemplazar la imagen invalida sus derivados. El dueño
+// también, porque varios usuarios pueden compartir el mismo blob y las
+// fuentes y marcas de agua se resuelven con las de cada uno.
+func DerivativeKey(source *repository.ObjectInfo, owner string, ops []Operation, format string) (string, error) {
+	canonical := canonicalTransform{
+		Source:     source.Path,
+		Owner:      owner,
+		ETag:       source.ETag,
+		Size:       source.Size,
+		Operations: make([]Operation, len(ops)),
+		Format:     format,
+	}
+
+	// encoding/json ordena las claves de los mapas, lo que hace estable la
+	// serialización de los parámetros
+	for i, op := range ops {
+		canonical.Operations[i] = Operation{Type: op.Type}
+		if len(op.Params) > 0 {
+			canonical.Operations[i].Params = op.Params
+		}
+	}
+
+	data, err := json.Marshal(canonical)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// GetOrCreate devuelve el derivado cacheado de sourcePath o lo genera con
+// create. Indica además si el resultado vino de la caché.
+func (c *DerivativeCache) GetOrCreate(
+	ctx context.Context,
+	sourcePath, key, contentType string,
+	create func(ctx context.Context) ([]byte, error),
+) ([]byte, bool, error) {
+	objectPath := c.objectPath(sourcePath, key)
+
+	if data, ok := c.memory.get(objectPath); ok {
+		return data, true, nil
+	}
+
+	type result struct {
+		data []byte
+		hit  bool
+	}
+
+	// La generación es compartida por todas las peticiones con la misma clave,
+	// así que no debe cancelarse porque se corte la primera de ellas
+	shared := context.WithoutCancel(ctx)
+
+	value, err, _ := c.group.Do(objectPath, func() (interface{}, error) {
+		if data, ok := c.readStorage(shared, objectPath); ok {
+			c.memory.add(objectPath, data)
+			return result{data: data, hit: true}, nil
+		}
+
+		data, err := create(shared)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := c.fileStorage.Upload(shared, objectPath, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+			log.Printf("  No se pudo guardar el derivado %s en la caché: %v", objectPath, err)
+		}
+		c.memory.add(objectPath, data)
+
+		return result{data: data}, nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	res := value.(result)
+	return res.data, res.hit, nil
+}
+
+func (c *DerivativeCache) readStorage(ctx context.Context, objectPath string) ([]byte, bool) {
+	reader, err := c.fileStorage.Get(ctx, objectPath)
+	if err != nil {
+		return nil, false
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil || len(data) == 0 {
+		return nil, false
+	}
+	return data, true
+}
+
+// Invalidate descarta los derivados de un original, en memoria y en el storage
+func (c *DerivativeCache) Invalidate(ctx context.Context, sourcePath string) error {
+	prefix := c.sourcePrefix(sourcePath)
+	c.memory.removePrefix(prefix)
+	return c.fileStorage.DeletePrefix(ctx, prefix)
+}
+
+// sourcePrefix agrupa los derivados de un mismo original bajo un prefijo
+func (c *DerivativeCache) sourcePrefix(sourcePath string) string {
+	sum := sha256.Sum256([]byte(sourcePath))
+	return cachePrefix + hex.EncodeToString(sum[:8]) + "/"
+}
+
+// IsCachePath indica si un objeto del storage es un derivado cacheado. Se
+// generan con la marca de agua del dueño de cada petición, así que no se
+// sirven por su ruta.
+func IsCachePath(objectPath string) bool {
+	rest, ok := strings.CutPrefix(objectPath, cachePrefix)
+	if !ok {
+		return false
+	}
+	dir, key, ok := strings.Cut(rest, "/")
+	return ok && len(dir) == 16 && len(key) == 2*sha256.Size && isLowerHex(dir) && isLowerHex(key)
+}
+
+func isLowerHex(s string) bool {
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *DerivativeCache) objectPath(sourcePath, key string) string {
+	return c.sourcePrefix(sourcePath) + key
+}
+
+// lruCache es una LRU en memoria acotada por la suma de bytes almacenados
+type lruCache struct {
+	mu       sync.Mutex
+	maxBytes int64
+	size     int64
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+type lruEntry struct {
+	key  string
+	data []byte
+}
+
+func newLRUCache(maxBytes int64) *lruCache {
+	return &lruCache{
+		maxBytes: maxBytes,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func (l *lruCache) get(key string) ([]byte, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	element, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+	l.order.MoveToFront(element)
+	return element.Value.(*lruEntry).data, true
+}
+
+func (l *lruCache) add(key string, data []byte) {
+	size := int64(len(data))
+	if size > l.maxBytes {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if element, ok := l.items[key]; ok {
+		l.size -= int64(len(element.Value.(*lruEntry).data))
+		element.Value = &lruEntry{key: key, data: data}
+		l.size += size
+		l.order.MoveToFront(element)
+	} else {
+		l.items[key] = l.order.PushFront(&lruEntry{key: key, data: data})
+		l.size += size
+	}
+
+	for l.size > l.maxBytes {
+		oldest := l.order.Back()
+		entry := oldest.Value.(*lruEntry)
+		l.order.Remove(oldest)
+		delete(l.items, entry.key)
+		l.size -= int64(len(entry.data))
+	}
+}
+
+func (l *lruCache) removePrefix(prefix string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, element := range l.items {
+		if strings.HasPrefix(key, prefix) {
+			l.order.Remove(element)
+			delete(l.items, key)
+			l.size -= int64(len(element.Value.(*lruEntry).data))
+		}
+	}
+}
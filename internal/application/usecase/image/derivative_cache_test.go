@@ -0,0 +1,145 @@
+package image_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/RodrigoGonzalez78/internal/application/usecase/image"
+	"github.com/RodrigoGonzalez78/internal/domain/repository"
+	"github.com/RodrigoGonzalez78/internal/domain/repository/mocks"
+)
+
+func TestDerivativeKey(t *testing.T) {
+	source := &repository.ObjectInfo{Path: "testuser/test.png", ETag: "abc", Size: 10}
+
+	ops := []image.Operation{
+		{Type: "resize", Params: image.OperationParams{"width": 100.0, "height": 50.0}},
+		{Type: "grayscale"},
+	}
+	reordered := []image.Operation{
+		{Type: "resize", Params: image.OperationParams{"height": 50, "width": 100}},
+		{Type: "grayscale", Params: image.OperationParams{}},
+	}
+
+	key1, _ := image.DerivativeKey(source, "testuser", ops, "image/png")
+	key2, _ := image.DerivativeKey(source, "testuser", reordered, "image/png")
+	if key1 != key2 {
+		t.Error("parámetros equivalentes deben producir la misma clave")
+	}
+
+	replaced := &repository.ObjectInfo{Path: "testuser/test.png", ETag: "def", Size: 10}
+	if key3, _ := image.DerivativeKey(replaced, "testuser", ops, "image/png"); key3 == key1 {
+		t.Error("un original reemplazado debe producir otra clave")
+	}
+
+	if key4, _ := image.DerivativeKey(source, "testuser", ops, "image/jpeg"); key4 == key1 {
+		t.Error("otro formato debe producir otra clave")
+	}
+
+	// Un blob compartido se transforma con las fuentes y marcas de cada dueño
+	if key5, _ := image.DerivativeKey(source, "otro", ops, "image/png"); key5 == key1 {
+		t.Error("otro dueño debe producir otra clave")
+	}
+}
+
+func TestTransformUseCase_Cache(t *testing.T) {
+	_, imageRepo, fileStorage := setupTransform(t)
+	cache := image.NewDerivativeCache(fileStorage, 1<<20)
+	useCase := image.NewTransformUseCase(imageRepo, mocks.NewMockFontRepository(), fileStorage, cache, image.DefaultImageLimits())
+
+	input := image.TransformInput{
+		ImageID:  1,
+		UserName: "testuser",
+		Operations: []image.Operation{
+			{Type: "resize", Params: image.OperationParams{"width": 40.0, "height": 30.0}},
+		},
+	}
+
+	// Peticiones idénticas concurrentes se colapsan en un único procesamiento
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := useCase.Execute(context.Background(), input); err != nil {
+				t.Errorf("Execute() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	sourceReads := fileStorage.GetCount
+	if sourceReads > 2 {
+		t.Errorf("lecturas del storage = %d, se esperaba a lo sumo 2 (caché + original)", sourceReads)
+	}
+
+	output, err := useCase.Execute(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !output.Cached {
+		t.Error("la segunda petición debe venir de la caché")
+	}
+	if fileStorage.GetCount != sourceReads {
+		t.Error("un acierto en memoria no debe leer del storage")
+	}
+
+	// Una caché nueva (sin memoria) encuentra el derivado persistido
+	coldUseCase := image.NewTransformUseCase(imageRepo, mocks.NewMockFontRepository(), fileStorage, image.NewDerivativeCache(fileStorage, 0), image.DefaultImageLimits())
+	output, err = coldUseCase.Execute(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !output.Cached {
+		t.Error("el derivado debe encontrarse en el storage")
+	}
+
+	// Reemplazar el original invalida los derivados
+	fileStorage.Files["testuser/test.png"] = encodeTestPNG(t, 200, 100)
+	output, err = useCase.Execute(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if output.Cached {
+		t.Error("tras reemplazar el original no debe usarse el derivado anterior")
+	}
+
+	// Eliminar el original impide servir derivados
+	delete(fileStorage.Files, "testuser/test.png")
+	if _, err := useCase.Execute(context.Background(), input); err == nil {
+		t.Error("no deben servirse derivados de un original eliminado")
+	}
+}
+
+func TestIsCachePath(t *testing.T) {
+	_, imageRepo, fileStorage := setupTransform(t)
+	cache := image.NewDerivativeCache(fileStorage, 0)
+	useCase := image.NewTransformUseCase(imageRepo, mocks.NewMockFontRepository(), fileStorage, cache, image.DefaultImageLimits())
+	if _, err := useCase.Execute(context.Background(), image.TransformInput{ImageID: 1, UserName: "testuser", Format: "jpeg"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	var cached string
+	for path := range fileStorage.Files {
+		if path != "testuser/test.png" {
+			cached = path
+		}
+	}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: cached, want: true},
+		{path: "cache/0123456789abcdef", want: false},
+		{path: "cache/foto.jpg", want: false},
+		{path: "cache/renditions/1/640.webp", want: false},
+		{path: "testuser/test.png", want: false},
+	}
+	for _, tt := range tests {
+		if got := image.IsCachePath(tt.path); got != tt.want {
+			t.Errorf("IsCachePath(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package image
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/RodrigoGonzalez78/internal/domain/entity"
+	"github.com/RodrigoGonzalez78/internal/domain/repository"
+	"github.com/google/uuid"
+)
+
+// ErrImageNotFound indica que la imagen no existe o pertenece a otro usuario
+var ErrImageNotFound = errors.New("imagen no encontrada")
+
+// DerivativeUseCase guarda el resultado de una transformación como una imagen
+// nueva que recuerda de qué imagen salió y con qué operaciones, de modo que
+// las ediciones forman un árbol
+type DerivativeUseCase struct {
+	imageRepo   repository.ImageRepository
+	transformUC *TransformUseCase
+	uploadUC    *UploadUseCase
+	baseURL     string
+	port        string
+}
+
+// NewDerivativeUseCase crea una nueva instancia de DerivativeUseCase
+func NewDerivativeUseCase(
+	imageRepo repository.ImageRepository,
+	transformUC *TransformUseCase,
+	uploadUC *UploadUseCase,
+	baseURL, port string,
+) *DerivativeUseCase {
+	return &DerivativeUseCase{
+		imageRepo:   imageRepo,
+		transformUC: transformUC,
+		uploadUC:    uploadUC,
+		baseURL:     baseURL,
+		port:        port,
+	}
+}
+
+// ImageNode es una imagen dentro del árbol de ediciones
+type ImageNode struct {
+	ID       int64
+	ParentID *int64
+	URL      string
+	Name     string
+	Size     int64
+	Format   string
+	Width    int
+	Height   int
+	// Operations es el pipeline aplicado al padre; vacío para las subidas
+	Operations []Operation
+	CreatedAt  time.Time
+}
+
+// LineageOutput representa la cadena de ediciones que llevó a una imagen
+type LineageOutput struct {
+	ImageID int64
+	// Lineage va desde la imagen subida hasta la pedida, inclusive
+	Lineage []ImageNode
+	// Complete es false si algún antecesor fue eliminado; la cadena empieza
+	// entonces en el primero que todavía existe
+	Complete bool
+}
+
+// Save aplica la transformación y guarda el resultado como una imagen nueva
+// del usuario, igual que una subida. Sin formato se conserva el de la imagen
+// de origen.
+func (uc *DerivativeUseCase) Save(ctx context.Context, input TransformInput) (*UploadOutput, error) {
+	parent, err := uc.findOwned(input.ImageID, input.UserName)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Format == "" {
+		input.Format = parent.Format
+	}
+
+	output, err := uc.transformUC.Execute(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := uc.transformUC.limits.decodeConfig(output.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	ops := input.Pipeline()
+	if ops == nil {
+		ops = []Operation{}
+	}
+	operations, err := json.Marshal(ops)
+	if err != nil {
+		return nil, fmt.Errorf("error al serializar las operaciones: %w", err)
+	}
+
+	return uc.uploadUC.Execute(ctx, UploadInput{
+		FileName:    uuid.New().String() + "." + output.Extension,
+		UserName:    input.UserName,
+		Data:        output.Data,
+		ContentType: output.ContentType,
+		Format:      output.Format,
+		Width:       config.Width,
+		Height:      config.Height,
+		ParentID:    &parent.ID,
+		Operations:  string(operations),
+	})
+}
+
+// Derivatives devuelve las imágenes guardadas directamente a partir de la
+// imagen indicada
+func (uc *DerivativeUseCase) Derivatives(imageID int64, userName string) ([]ImageNode, error) {
+	if _, err := uc.findOwned(imageID, userName); err != nil {
+		return nil, err
+	}
+
+	children, err := uc.imageRepo.FindChildren(imageID)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]ImageNode, len(children))
+	for i := range children {
+		nodes[i] = uc.toNode(&children[i])
+	}
+	return nodes, nil
+}
+
+// Lineage recorre los padres de la imagen hasta llegar a la imagen subida
+func (uc *DerivativeUseCase) Lineage(imageID int64, userName string) (*LineageOutput, error) {
+	image, err := uc.findOwned(imageID, userName)
+	if err != nil {
+		return nil, err
+	}
+
+	chain := []ImageNode{uc.toNode(image)}
+	complete := true
+	for image.ParentID != nil {
+		// Los padres siempre se crean antes que sus derivados, así que un ID
+		// que no decrece indica datos corruptos y evita un ciclo
+		parent, err := uc.imageRepo.FindByID(*image.ParentID)
+		if err != nil || parent.ID >= image.ID || parent.UserName != userName {
+			complete = false
+			break
+		}
+		image = parent
+		chain = append(chain, uc.toNode(image))
+	}
+
+	// Ordenar desde la raíz
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+
+	return &LineageOutput{
+		ImageID:  imageID,
+		Lineage:  chain,
+		Complete: complete,
+	}, nil
+}
+
+func (uc *DerivativeUseCase) findOwned(imageID int64, userName string) (*entity.Image, error) {
+	image, err := uc.imageRepo.FindByID(imageID)
+	if err != nil || image.UserName != userName {
+		return nil, ErrImageNotFound
+	}
+	return image, nil
+}
+
+func (uc *DerivativeUseCase) toNode(image *entity.Image) ImageNode {
+	node := ImageNode{
+		ID:         image.ID,
+		ParentID:   image.ParentID,
+		URL:        fmt.Sprintf("%s:%s/images/%s/%s", uc.baseURL, uc.port, image.UserName, image.Name),
+		Name:       image.Name,
+		Size:       image.Size,
+		Format:     image.Format,
+		Width:      image.Width,
+		Height:     image.Height,
+		Operations: []Operation{},
+		CreatedAt:  image.CreatedAt,
+	}
+	if image.Operations != "" {
+		if err := json.Unmarshal([]byte(image.Operations), &node.Operations); err != nil {
+			log.Printf("  Operaciones inválidas en la imagen %d: %v", image.ID, err)
+		}
+	}
+	return node
+}
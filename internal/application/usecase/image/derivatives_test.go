@@ -0,0 +1,125 @@
+package image_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/RodrigoGonzalez78/internal/application/usecase/image"
+	"github.com/RodrigoGonzalez78/internal/domain/repository/mocks"
+)
+
+func setupDerivatives(t *testing.T) (*image.DerivativeUseCase, *mocks.MockImageRepository, *mocks.MockFileStorage) {
+	t.Helper()
+	transformUC, imageRepo, fileStorage := setupTransform(t)
+	imageRepo.NextID = 2
+	uploadUC := image.NewUploadUseCase(imageRepo, mocks.NewMockImageMetadataRepository(), image.NewBlobStore(mocks.NewMockBlobRepository(), fileStorage), nil, image.ImageLimits{}, "http://localhost", "8080")
+	return image.NewDerivativeUseCase(imageRepo, transformUC, uploadUC, "http://localhost", "8080"), imageRepo, fileStorage
+}
+
+func TestDerivativeUseCase_Save(t *testing.T) {
+	derivativeUC, imageRepo, fileStorage := setupDerivatives(t)
+
+	output, err := derivativeUC.Save(context.Background(), image.TransformInput{
+		ImageID:    1,
+		UserName:   "testuser",
+		Operations: []image.Operation{{Type: "resize", Params: image.OperationParams{"width": 200.0}}},
+	})
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// Sin formato se conserva el de la imagen de origen
+	if output.ID != 2 || output.Format != "png" || output.Width != 200 || output.Height != 150 {
+		t.Errorf("Save() = %+v", output)
+	}
+	if output.ParentID == nil || *output.ParentID != 1 {
+		t.Errorf("ParentID = %v, want 1", output.ParentID)
+	}
+
+	saved := imageRepo.Images[2]
+	if saved == nil || saved.Operations != `[{"Type":"resize","Params":{"width":200}}]` {
+		t.Fatalf("imagen guardada = %+v", saved)
+	}
+	if data := fileStorage.Files[saved.Path]; int64(len(data)) != saved.Size || saved.Size == 0 {
+		t.Errorf("objeto %s: %d bytes, want %d", saved.Path, len(data), saved.Size)
+	}
+
+	// Con formato explícito
+	output, err = derivativeUC.Save(context.Background(), image.TransformInput{ImageID: 1, UserName: "testuser", Rotate: 90, Format: "webp"})
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if output.Format != "webp" || output.Width != 300 || output.Height != 400 {
+		t.Errorf("Save() = %+v", output)
+	}
+
+	if _, err := derivativeUC.Save(context.Background(), image.TransformInput{ImageID: 1, UserName: "otro", Rotate: 90}); !errors.Is(err, image.ErrImageNotFound) {
+		t.Errorf("Save() de otro usuario error = %v, want ErrImageNotFound", err)
+	}
+	if _, err := derivativeUC.Save(context.Background(), image.TransformInput{ImageID: 1, UserName: "testuser", Operations: []image.Operation{{Type: "pixelar"}}}); err == nil {
+		t.Error("Save() con operación inválida se esperaba error")
+	}
+	if len(imageRepo.Images) != 3 {
+		t.Errorf("imágenes = %d, want 3", len(imageRepo.Images))
+	}
+}
+
+func TestDerivativeUseCase_Tree(t *testing.T) {
+	derivativeUC, imageRepo, _ := setupDerivatives(t)
+	ctx := context.Background()
+
+	// 1 → 2 → 4 y 1 → 3
+	save := func(parent int64, op string) int64 {
+		t.Helper()
+		output, err := derivativeUC.Save(ctx, image.TransformInput{ImageID: parent, UserName: "testuser", Operations: []image.Operation{{Type: op}}})
+		if err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		return output.ID
+	}
+	gray := save(1, "grayscale")
+	save(1, "sepia")
+	leaf := save(gray, "invert")
+
+	children, err := derivativeUC.Derivatives(1, "testuser")
+	if err != nil || len(children) != 2 || children[0].ID != gray || children[1].Operations[0].Type != "sepia" {
+		t.Fatalf("Derivatives() = %+v, %v", children, err)
+	}
+	if children, err := derivativeUC.Derivatives(leaf, "testuser"); err != nil || len(children) != 0 {
+		t.Errorf("Derivatives() de una hoja = %+v, %v", children, err)
+	}
+	if _, err := derivativeUC.Derivatives(1, "otro"); !errors.Is(err, image.ErrImageNotFound) {
+		t.Errorf("Derivatives() de otro usuario error = %v, want ErrImageNotFound", err)
+	}
+
+	lineage, err := derivativeUC.Lineage(leaf, "testuser")
+	if err != nil {
+		t.Fatalf("Lineage() error = %v", err)
+	}
+	if !lineage.Complete || len(lineage.Lineage) != 3 {
+		t.Fatalf("Lineage() = %+v", lineage)
+	}
+	for i, want := range []struct {
+		id int64
+		op string
+	}{{1, ""}, {gray, "grayscale"}, {leaf, "invert"}} {
+		node := lineage.Lineage[i]
+		var op string
+		if len(node.Operations) > 0 {
+			op = node.Operations[0].Type
+		}
+		if node.ID != want.id || op != want.op {
+			t.Errorf("Lineage[%d] = %d %q, want %d %q", i, node.ID, op, want.id, want.op)
+		}
+	}
+
+	// Si se elimina un antecesor la cadena queda incompleta
+	if err := imageRepo.Delete(gray); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	lineage, err = derivativeUC.Lineage(leaf, "testuser")
+	if err != nil || lineage.Complete || len(lineage.Lineage) != 1 || lineage.Lineage[0].ID != leaf {
+		t.Errorf("Lineage() sin el padre = %+v, %v", lineage, err)
+	}
+}
@@ -0,0 +1,141 @@
+package image
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+
+	"github.com/RodrigoGonzalez78/internal/pkg/webp"
+	"github.com/disintegration/imaging"
+)
+
+// ErrUnsupportedFormat indica que se pidió un formato de salida desconocido
+var ErrUnsupportedFormat = errors.New("formato de salida no soportado (png, jpeg, gif, tiff, bmp o webp)")
+
+const defaultJPEGQuality = 95
+
+// EncodeOptions ajusta la codificación del formato de salida. Un valor en
+// cero usa el valor por defecto del formato.
+type EncodeOptions struct {
+	Quality     int    // calidad JPEG, 1 a 100
+	Compression string // compresión PNG: default, none, fast o best
+	PaletteSize int    // colores de la paleta GIF, 1 a 256
+}
+
+// Empty indica si no se pidió ninguna opción
+func (o EncodeOptions) Empty() bool {
+	return o == EncodeOptions{}
+}
+
+var pngCompressionLevels = map[string]png.CompressionLevel{
+	"default": png.DefaultCompression,
+	"none":    png.NoCompression,
+	"fast":    png.BestSpeed,
+	"best":    png.BestCompression,
+}
+
+// outputEncoding describe cómo se codifica el resultado de una transformación
+type outputEncoding struct {
+	Name        string
+	ContentType string
+	Extension   string
+	Options     EncodeOptions
+	format      imaging.Format
+}
+
+// resolveEncoding valida el formato pedido y sus opciones. Sin formato se
+// devuelve PNG, como hasta ahora.
+func resolveEncoding(format string, opts EncodeOptions) (*outputEncoding, error) {
+	var enc *outputEncoding
+	switch format {
+	case "", "png":
+		enc = &outputEncoding{Name: "png", ContentType: "image/png", Extension: "png", format: imaging.PNG}
+	case "jpg", "jpeg":
+		enc = &outputEncoding{Name: "jpeg", ContentType: "image/jpeg", Extension: "jpg", format: imaging.JPEG}
+	case "gif":
+		enc = &outputEncoding{Name: "gif", ContentType: "image/gif", Extension: "gif", format: imaging.GIF}
+	case "tif", "tiff":
+		enc = &outputEncoding{Name: "tiff", ContentType: "image/tiff", Extension: "tiff", format: imaging.TIFF}
+	case "bmp":
+		enc = &outputEncoding{Name: "bmp", ContentType: "image/bmp", Extension: "bmp", format: imaging.BMP}
+	case "webp":
+		enc = &outputEncoding{Name: "webp", ContentType: "image/webp", Extension: "webp", format: -1}
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+
+	if opts.Quality != 0 {
+		if enc.Name != "jpeg" {
+			return nil, &FieldError{Field: "quality", Message: "solo se aplica a jpeg"}
+		}
+		if opts.Quality < 1 || opts.Quality > 100 {
+			return nil, &FieldError{Field: "quality", Message: "debe estar entre 1 y 100"}
+		}
+	}
+
+	if opts.Compression != "" {
+		if enc.Name != "png" {
+			return nil, &FieldError{Field: "compression", Message: "solo se aplica a png"}
+		}
+		if _, ok := pngCompressionLevels[opts.Compression]; !ok {
+			return nil, &FieldError{Field: "compression", Message: "debe ser default, none, fast o best"}
+		}
+	}
+
+	if opts.PaletteSize != 0 {
+		if enc.Name != "gif" {
+			return nil, &FieldError{Field: "palette_size", Message: "solo se aplica a gif"}
+		}
+		if opts.PaletteSize < 1 || opts.PaletteSize > 256 {
+			return nil, &FieldError{Field: "palette_size", Message: "debe estar entre 1 y 256"}
+		}
+	}
+
+	enc.Options = opts
+	return enc, nil
+}
+
+// cacheFormat identifica formato y opciones en la clave de los derivados,
+// para que dos calidades distintas no compartan la misma entrada
+func (e *outputEncoding) cacheFormat() string {
+	key := e.ContentType
+	if e.Options.Quality != 0 {
+		key += fmt.Sprintf(";q=%d", e.Options.Quality)
+	}
+	if e.Options.Compression != "" {
+		key += ";compression=" + e.Options.Compression
+	}
+	if e.Options.PaletteSize != 0 {
+		key += fmt.Sprintf(";colors=%d", e.Options.PaletteSize)
+	}
+	return key
+}
+
+// encode escribe la imagen en el formato y con las opciones resueltas
+func (e *outputEncoding) encode(w io.Writer, img image.Image) error {
+	if e.Name == "webp" {
+		return webp.Encode(w, img)
+	}
+
+	var opts []imaging.EncodeOption
+	switch e.Name {
+	case "jpeg":
+		quality := e.Options.Quality
+		if quality == 0 {
+			quality = defaultJPEGQuality
+		}
+		opts = append(opts, imaging.JPEGQuality(quality))
+	case "png":
+		if e.Options.Compression != "" {
+			opts = append(opts, imaging.PNGCompressionLevel(pngCompressionLevels[e.Options.Compression]))
+		}
+	case "gif":
+		if e.Options.PaletteSize != 0 {
+			opts = append(opts, imaging.GIFNumColors(e.Options.PaletteSize))
+		}
+	}
+
+	return imaging.Encode(w, img, e.format, opts...)
+}
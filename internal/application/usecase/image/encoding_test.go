@@ -0,0 +1,179 @@
+package image_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	stdimage "image"
+	"testing"
+
+	"github.com/RodrigoGonzalez78/internal/application/usecase/image"
+	"github.com/RodrigoGonzalez78/internal/domain/repository/mocks"
+)
+
+var encodingResize = []image.Operation{
+	{Type: "resize", Params: image.OperationParams{"width": 120.0, "height": 90.0}},
+}
+
+func TestTransformUseCase_OutputFormats(t *testing.T) {
+	tests := []struct {
+		format          string
+		wantFormat      string
+		wantContentType string
+		wantExtension   string
+	}{
+		{format: "", wantFormat: "png", wantContentType: "image/png", wantExtension: "png"},
+		{format: "png", wantFormat: "png", wantContentType: "image/png", wantExtension: "png"},
+		{format: "jpg", wantFormat: "jpeg", wantContentType: "image/jpeg", wantExtension: "jpg"},
+		{format: "gif", wantFormat: "gif", wantContentType: "image/gif", wantExtension: "gif"},
+		{format: "tif", wantFormat: "tiff", wantContentType: "image/tiff", wantExtension: "tiff"},
+		{format: "bmp", wantFormat: "bmp", wantContentType: "image/bmp", wantExtension: "bmp"},
+		{format: "webp", wantFormat: "webp", wantContentType: "image/webp", wantExtension: "webp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.wantFormat+"/"+tt.format, func(t *testing.T) {
+			useCase, _, _ := setupTransform(t)
+
+			output, err := useCase.Execute(context.Background(), image.TransformInput{
+				ImageID:    1,
+				UserName:   "testuser",
+				Operations: encodingResize,
+				Format:     tt.format,
+			})
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			if output.Format != tt.wantFormat || output.ContentType != tt.wantContentType || output.Extension != tt.wantExtension {
+				t.Errorf("salida = %s %s %s, want %s %s %s", output.Format, output.ContentType, output.Extension,
+					tt.wantFormat, tt.wantContentType, tt.wantExtension)
+			}
+
+			img, format, err := stdimage.Decode(bytes.NewReader(output.Data))
+			if err != nil {
+				t.Fatalf("no se pudo decodificar la salida: %v", err)
+			}
+			if format != tt.wantFormat {
+				t.Errorf("formato decodificado = %s, want %s", format, tt.wantFormat)
+			}
+			if b := img.Bounds(); b.Dx() != 120 || b.Dy() != 90 {
+				t.Errorf("dimensiones = %v, want 120x90", b.Size())
+			}
+		})
+	}
+}
+
+func TestTransformUseCase_EncodeOptions(t *testing.T) {
+	useCase, _, _ := setupTransform(t)
+
+	encode := func(format string, opts image.EncodeOptions) []byte {
+		t.Helper()
+		output, err := useCase.Execute(context.Background(), image.TransformInput{
+			ImageID:    1,
+			UserName:   "testuser",
+			Operations: encodingResize,
+			Format:     format,
+			Encoding:   opts,
+		})
+		if err != nil {
+			t.Fatalf("Execute(%s, %+v) error = %v", format, opts, err)
+		}
+		return output.Data
+	}
+
+	low := encode("jpeg", image.EncodeOptions{Quality: 10})
+	high := encode("jpeg", image.EncodeOptions{Quality: 100})
+	if len(low) >= len(high) {
+		t.Errorf("calidad 10 = %d bytes, calidad 100 = %d bytes; se esperaba menos bytes con menor calidad", len(low), len(high))
+	}
+
+	uncompressed := encode("png", image.EncodeOptions{Compression: "none"})
+	best := encode("png", image.EncodeOptions{Compression: "best"})
+	if len(best) >= len(uncompressed) {
+		t.Errorf("png best = %d bytes, none = %d bytes", len(best), len(uncompressed))
+	}
+
+	data := encode("gif", image.EncodeOptions{PaletteSize: 4})
+	img, _, err := stdimage.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("no se pudo decodificar el gif: %v", err)
+	}
+	paletted, ok := img.(*stdimage.Paletted)
+	if !ok {
+		t.Fatalf("gif decodificado = %T, want *image.Paletted", img)
+	}
+	if len(paletted.Palette) > 4 {
+		t.Errorf("paleta = %d colores, want <= 4", len(paletted.Palette))
+	}
+}
+
+func TestTransformUseCase_InvalidFormat(t *testing.T) {
+	tests := []struct {
+		name      string
+		format    string
+		opts      image.EncodeOptions
+		wantField string
+	}{
+		{name: "formato desconocido", format: "heic"},
+		{name: "calidad fuera de rango", format: "jpeg", opts: image.EncodeOptions{Quality: 101}, wantField: "quality"},
+		{name: "calidad en png", format: "png", opts: image.EncodeOptions{Quality: 80}, wantField: "quality"},
+		{name: "compresión desconocida", format: "png", opts: image.EncodeOptions{Compression: "max"}, wantField: "compression"},
+		{name: "compresión en webp", format: "webp", opts: image.EncodeOptions{Compression: "best"}, wantField: "compression"},
+		{name: "paleta fuera de rango", format: "gif", opts: image.EncodeOptions{PaletteSize: 257}, wantField: "palette_size"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCase, _, fileStorage := setupTransform(t)
+
+			_, err := useCase.Execute(context.Background(), image.TransformInput{
+				ImageID:    1,
+				UserName:   "testuser",
+				Operations: encodingResize,
+				Format:     tt.format,
+				Encoding:   tt.opts,
+			})
+
+			if tt.wantField == "" {
+				if !errors.Is(err, image.ErrUnsupportedFormat) {
+					t.Errorf("Execute() error = %v, want ErrUnsupportedFormat", err)
+				}
+			} else {
+				var fieldErr *image.FieldError
+				if !errors.As(err, &fieldErr) || fieldErr.Field != tt.wantField {
+					t.Errorf("Execute() error = %v, want FieldError en %s", err, tt.wantField)
+				}
+			}
+			if fileStorage.GetCalled {
+				t.Error("no debe leerse el storage si el formato es inválido")
+			}
+		})
+	}
+}
+
+func TestTransformUseCase_CacheSeparatesEncodeOptions(t *testing.T) {
+	_, imageRepo, fileStorage := setupTransform(t)
+	cache := image.NewDerivativeCache(fileStorage, 1<<20)
+	useCase := image.NewTransformUseCase(imageRepo, mocks.NewMockFontRepository(), fileStorage, cache, image.DefaultImageLimits())
+
+	input := image.TransformInput{
+		ImageID:    1,
+		UserName:   "testuser",
+		Operations: encodingResize,
+		Format:     "jpeg",
+		Encoding:   image.EncodeOptions{Quality: 20},
+	}
+	if _, err := useCase.Execute(context.Background(), input); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	input.Encoding.Quality = 90
+	output, err := useCase.Execute(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if output.Cached {
+		t.Error("otra calidad no debe reutilizar el derivado cacheado")
+	}
+}
@@ -0,0 +1,250 @@
+package image
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/RodrigoGonzalez78/internal/domain/entity"
+	"github.com/RodrigoGonzalez78/internal/domain/repository"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/gobolditalic"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/gomedium"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/gomonobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/gofont/gosmallcaps"
+	"golang.org/x/image/font/sfnt"
+)
+
+// MaxFontBytes es el tamaño máximo de una fuente subida por un usuario
+const MaxFontBytes = 10 << 20
+
+const defaultFont = "goregular"
+
+// ErrInvalidFont indica que el archivo no es una fuente TrueType u OpenType
+// que se pueda usar para dibujar texto
+var ErrInvalidFont = errors.New("el archivo no es una fuente TrueType u OpenType válida")
+
+// embeddedFonts son las fuentes Go (licencia BSD) que se incluyen en el
+// binario y pueden usarse sin subir nada
+var embeddedFonts = map[string][]byte{
+	"goregular":    goregular.TTF,
+	"gobold":       gobold.TTF,
+	"goitalic":     goitalic.TTF,
+	"gobolditalic": gobolditalic.TTF,
+	"gomedium":     gomedium.TTF,
+	"gomono":       gomono.TTF,
+	"gomonobold":   gomonobold.TTF,
+	"gosmallcaps":  gosmallcaps.TTF,
+}
+
+var (
+	parsedFontsMu sync.Mutex
+	parsedFonts   = map[string]*sfnt.Font{}
+)
+
+// EmbeddedFonts devuelve los nombres de las fuentes incluidas ordenados
+func EmbeddedFonts() []string {
+	names := make([]string, 0, len(embeddedFonts))
+	for name := range embeddedFonts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// embeddedFont parsea una fuente incluida una sola vez; *sfnt.Font se puede
+// compartir entre goroutines mientras cada una use su propio sfnt.Buffer
+func embeddedFont(name string) (*sfnt.Font, error) {
+	parsedFontsMu.Lock()
+	defer parsedFontsMu.Unlock()
+
+	if f, ok := parsedFonts[name]; ok {
+		return f, nil
+	}
+	data, ok := embeddedFonts[name]
+	if !ok {
+		return nil, &FieldError{Field: "font", Message: "fuente desconocida"}
+	}
+	f, err := sfnt.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("fuente incluida %s inválida: %w", name, err)
+	}
+	parsedFonts[name] = f
+	return f, nil
+}
+
+// parseFont valida una fuente subida: debe poder parsearse y tener contornos
+// vectoriales para el texto latino básico
+func parseFont(data []byte) (*sfnt.Font, error) {
+	f, err := sfnt.Parse(data)
+	if err != nil {
+		return nil, ErrInvalidFont
+	}
+
+	var buf sfnt.Buffer
+	idx, err := f.GlyphIndex(&buf, 'a')
+	if err != nil || idx == 0 {
+		return nil, ErrInvalidFont
+	}
+	if _, err := f.LoadGlyph(&buf, idx, 1<<6, nil); err != nil {
+		return nil, ErrInvalidFont
+	}
+	return f, nil
+}
+
+// IsFontPath indica si un objeto del storage es una fuente subida. Las
+// fuentes no se sirven públicamente porque su licencia puede no permitirlo.
+func IsFontPath(objectPath string) bool {
+	_, rest, _ := strings.Cut(objectPath, "/")
+	return strings.HasPrefix(rest, "fonts/")
+}
+
+// FontUseCase administra las fuentes que suben los usuarios
+type FontUseCase struct {
+	fontRepo    repository.FontRepository
+	fileStorage repository.FileStorage
+}
+
+// NewFontUseCase crea una nueva instancia de FontUseCase
+func NewFontUseCase(fontRepo repository.FontRepository, fileStorage repository.FileStorage) *FontUseCase {
+	return &FontUseCase{
+		fontRepo:    fontRepo,
+		fileStorage: fileStorage,
+	}
+}
+
+// FontUploadInput representa los datos de entrada para subir una fuente
+type FontUploadInput struct {
+	UserName string
+	FileName string
+	Name     string
+	Data     []byte
+}
+
+// FontOutput representa una fuente subida por el usuario
+type FontOutput struct {
+	ID        int64
+	Name      string
+	Family    string
+	Size      int64
+	CreatedAt time.Time
+}
+
+func toFontOutput(font *entity.Font) FontOutput {
+	return FontOutput{
+		ID:        font.ID,
+		Name:      font.Name,
+		Family:    font.Family,
+		Size:      font.Size,
+		CreatedAt: font.CreatedAt,
+	}
+}
+
+// Upload valida y guarda una fuente del usuario. Si no se indica un nombre se
+// usa la familia declarada en la fuente.
+func (uc *FontUseCase) Upload(ctx context.Context, input FontUploadInput) (*FontOutput, error) {
+	if len(input.Data) > MaxFontBytes {
+		return nil, &LimitError{Limit: "bytes", Actual: int64(len(input.Data)), Max: MaxFontBytes}
+	}
+
+	f, err := parseFont(input.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	family, _ := f.Name(nil, sfnt.NameIDFamily)
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		name = family
+	}
+	if name == "" {
+		name = input.FileName
+	}
+
+	objectPath := fmt.Sprintf("%s/fonts/%s", input.UserName, input.FileName)
+	if err := uc.fileStorage.Upload(ctx, objectPath, bytes.NewReader(input.Data), int64(len(input.Data)), "font/ttf"); err != nil {
+		return nil, fmt.Errorf("error al subir la fuente: %w", err)
+	}
+
+	font := entity.NewFont(input.UserName, name, family, objectPath, int64(len(input.Data)))
+	if err := uc.fontRepo.Create(font); err != nil {
+		if delErr := uc.fileStorage.Delete(ctx, objectPath); delErr != nil {
+			log.Printf("  No se pudo eliminar el objeto huérfano %s: %v", objectPath, delErr)
+		}
+		return nil, fmt.Errorf("error al guardar la fuente en BD: %w", err)
+	}
+
+	output := toFontOutput(font)
+	return &output, nil
+}
+
+// List devuelve las fuentes subidas por el usuario
+func (uc *FontUseCase) List(userName string) ([]FontOutput, error) {
+	fonts, err := uc.fontRepo.FindByUser(userName)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make([]FontOutput, len(fonts))
+	for i := range fonts {
+		outputs[i] = toFontOutput(&fonts[i])
+	}
+	return outputs, nil
+}
+
+// Delete elimina una fuente del usuario. Los derivados cacheados que la usan
+// no se invalidan: siguen siendo el resultado correcto de ese pipeline.
+func (uc *FontUseCase) Delete(ctx context.Context, userName string, fontID int64) error {
+	font, err := uc.fontRepo.FindByID(fontID)
+	if err != nil || font.UserName != userName {
+		return errors.New("fuente no encontrada")
+	}
+
+	if err := uc.fileStorage.Delete(ctx, font.Path); err != nil {
+		return fmt.Errorf("error al eliminar la fuente: %w", err)
+	}
+	if err := uc.fontRepo.Delete(font.ID); err != nil {
+		return fmt.Errorf("error al eliminar el registro: %w", err)
+	}
+	return nil
+}
+
+// loadFont obtiene la fuente pedida por la operación text: una incluida por
+// nombre o una subida por el dueño de la imagen procesada
+func (uc *TransformUseCase) loadFont(ctx context.Context, name string, fontID int64) (*sfnt.Font, error) {
+	if fontID == 0 {
+		return embeddedFont(name)
+	}
+
+	font, err := uc.fontRepo.FindByID(fontID)
+	if err != nil || font.UserName != ownerFromContext(ctx) {
+		return nil, &FieldError{Field: "font_id", Message: "la fuente no existe"}
+	}
+
+	reader, err := uc.fileStorage.Get(ctx, font.Path)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo leer la fuente: %w", err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(io.LimitReader(reader, MaxFontBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo leer la fuente: %w", err)
+	}
+
+	f, err := parseFont(data)
+	if err != nil {
+		return nil, &FieldError{Field: "font_id", Message: err.Error()}
+	}
+	return f, nil
+}
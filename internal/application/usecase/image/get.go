@@ -10,17 +10,19 @@ import (
 
 // GetUseCase maneja el caso de uso de obtener una imagen
 type GetUseCase struct {
-	imageRepo repository.ImageRepository
-	baseURL   string
-	port      string
+	imageRepo     repository.ImageRepository
+	renditionRepo repository.RenditionRepository
+	baseURL       string
+	port          string
 }
 
 // NewGetUseCase crea una nueva instancia de GetUseCase
-func NewGetUseCase(imageRepo repository.ImageRepository, baseURL, port string) *GetUseCase {
+func NewGetUseCase(imageRepo repository.ImageRepository, renditionRepo repository.RenditionRepository, baseURL, port string) *GetUseCase {
 	return &GetUseCase{
-		imageRepo: imageRepo,
-		baseURL:   baseURL,
-		port:      port,
+		imageRepo:     imageRepo,
+		renditionRepo: renditionRepo,
+		baseURL:       baseURL,
+		port:          port,
 	}
 }
 
@@ -38,6 +40,12 @@ type GetOutput struct {
 	Format string
 	Width  int
 	Height int
+	// ParentID es la imagen de origen si se guardó a partir de una
+	// transformación
+	ParentID *int64
+	// Renditions son las versiones redimensionadas, de menor a mayor ancho;
+	// está vacía mientras se generan
+	Renditions []RenditionItem
 }
 
 // Execute ejecuta el caso de uso
@@ -54,13 +62,20 @@ func (uc *GetUseCase) Execute(input GetInput) (*GetOutput, error) {
 
 	url := fmt.Sprintf("%s:%s/images/%s/%s", uc.baseURL, uc.port, image.UserName, image.Name)
 
+	renditions, err := listRenditions(uc.renditionRepo, image, uc.baseURL, uc.port)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener las versiones: %w", err)
+	}
+
 	return &GetOutput{
-		URL:    url,
-		Name:   image.Name,
-		Size:   image.Size,
-		Format: image.Format,
-		Width:  image.Width,
-		Height: image.Height,
+		URL:        url,
+		Name:       image.Name,
+		Size:       image.Size,
+		Format:     image.Format,
+		Width:      image.Width,
+		Height:     image.Height,
+		ParentID:   image.ParentID,
+		Renditions: renditions,
 	}, nil
 }
 
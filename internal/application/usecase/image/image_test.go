@@ -2,6 +2,7 @@ package image_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/RodrigoGonzalez78/internal/application/usecase/image"
@@ -53,7 +54,7 @@ func TestUploadUseCase_Execute(t *testing.T) {
 			mockImageRepo := mocks.NewMockImageRepository()
 			mockFileStorage := mocks.NewMockFileStorage()
 			tt.setupMocks(mockImageRepo, mockFileStorage)
-			useCase := image.NewUploadUseCase(mockImageRepo, mockFileStorage, "http://localhost", "8080")
+			useCase := image.NewUploadUseCase(mockImageRepo, mocks.NewMockImageMetadataRepository(), image.NewBlobStore(mocks.NewMockBlobRepository(), mockFileStorage), nil, image.ImageLimits{}, "http://localhost", "8080")
 
 			// Execute
 			output, err := useCase.Execute(context.Background(), tt.input)
@@ -140,7 +141,7 @@ func TestGetUseCase_Execute(t *testing.T) {
 			// Setup
 			mockRepo := mocks.NewMockImageRepository()
 			tt.setupMock(mockRepo)
-			useCase := image.NewGetUseCase(mockRepo, "http://localhost", "8080")
+			useCase := image.NewGetUseCase(mockRepo, mocks.NewMockRenditionRepository(), "http://localhost", "8080")
 
 			// Execute
 			output, err := useCase.Execute(tt.input)
@@ -231,3 +232,24 @@ func TestListUseCase_Execute(t *testing.T) {
 		})
 	}
 }
+
+func TestUploadUseCase_RemovesObjectOnDBError(t *testing.T) {
+	mockImageRepo := mocks.NewMockImageRepository()
+	mockImageRepo.CreateError = errors.New("base de datos no disponible")
+	mockFileStorage := mocks.NewMockFileStorage()
+	useCase := image.NewUploadUseCase(mockImageRepo, mocks.NewMockImageMetadataRepository(), image.NewBlobStore(mocks.NewMockBlobRepository(), mockFileStorage), nil, image.ImageLimits{}, "http://localhost", "8080")
+
+	_, err := useCase.Execute(context.Background(), image.UploadInput{
+		FileName:    "test.jpg",
+		UserName:    "testuser",
+		Data:        []byte("fake image data"),
+		ContentType: "image/jpeg",
+		Format:      "jpeg",
+	})
+	if err == nil {
+		t.Fatal("Execute() debe fallar si no se puede guardar en BD")
+	}
+	if len(mockFileStorage.Files) != 0 {
+		t.Error("el objeto subido no debe quedar huérfano en el storage")
+	}
+}
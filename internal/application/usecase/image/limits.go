@@ -0,0 +1,267 @@
+package image
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"math"
+
+	"github.com/RodrigoGonzalez78/internal/pkg/exif"
+
+	// Decodificador para originales y resultados guardados en WebP
+	_ "golang.org/x/image/webp"
+)
+
+// ErrUnsupportedMediaType indica que el contenido no es de un formato de
+// imagen aceptado, sin importar la extensión del archivo
+var ErrUnsupportedMediaType = errors.New("el archivo no es una imagen jpeg, png o gif")
+
+// ErrCorruptImage indica que no se pudo leer la cabecera de la imagen
+var ErrCorruptImage = errors.New("imagen corrupta o ilegible")
+
+// LimitError indica que una imagen supera uno de los límites configurados
+type LimitError struct {
+	Limit  string // bytes, width, height o pixels
+	Actual int64
+	Max    int64
+}
+
+func (e *LimitError) Error() string {
+	// El tamaño real no se conoce cuando se corta la lectura del cuerpo
+	if e.Actual == 0 {
+		return fmt.Sprintf("la imagen supera el límite de %s (máximo %d)", e.Limit, e.Max)
+	}
+	return fmt.Sprintf("la imagen supera el límite de %s: %d (máximo %d)", e.Limit, e.Actual, e.Max)
+}
+
+// ImageLimits acota el tamaño de las imágenes que se aceptan y se decodifican.
+// Un valor en cero desactiva ese límite.
+type ImageLimits struct {
+	MaxBytes  int64
+	MaxWidth  int
+	MaxHeight int
+	MaxPixels int64
+}
+
+// DefaultImageLimits devuelve límites razonables para un servidor pequeño
+func DefaultImageLimits() ImageLimits {
+	return ImageLimits{
+		MaxBytes:  20 << 20,
+		MaxWidth:  10000,
+		MaxHeight: 10000,
+		MaxPixels: 50_000_000,
+	}
+}
+
+// ImageInfo describe una imagen validada a partir de su contenido real
+type ImageInfo struct {
+	Format      string
+	ContentType string
+	Extension   string
+	Width       int
+	Height      int
+}
+
+// CheckSize verifica el tamaño en bytes del archivo
+func (l ImageLimits) CheckSize(size int64) error {
+	if l.MaxBytes > 0 && size > l.MaxBytes {
+		return &LimitError{Limit: "bytes", Actual: size, Max: l.MaxBytes}
+	}
+	return nil
+}
+
+// CheckDimensions verifica ancho, alto y cantidad total de píxeles
+func (l ImageLimits) CheckDimensions(width, height int) error {
+	if l.MaxWidth > 0 && width > l.MaxWidth {
+		return &LimitError{Limit: "width", Actual: int64(width), Max: int64(l.MaxWidth)}
+	}
+	if l.MaxHeight > 0 && height > l.MaxHeight {
+		return &LimitError{Limit: "height", Actual: int64(height), Max: int64(l.MaxHeight)}
+	}
+	if pixels := int64(width) * int64(height); l.MaxPixels > 0 && pixels > l.MaxPixels {
+		return &LimitError{Limit: "pixels", Actual: pixels, Max: l.MaxPixels}
+	}
+	return nil
+}
+
+// Inspect valida una imagen subida: tamaño, formato real según los magic
+// bytes y dimensiones declaradas en la cabecera. Solo lee la cabecera, así
+// que una bomba de descompresión se rechaza sin llegar a decodificarse.
+func (l ImageLimits) Inspect(data []byte) (*ImageInfo, error) {
+	info, err := l.Sniff(data, int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := l.decodeConfig(data)
+	if err != nil {
+		return nil, err
+	}
+
+	info.Width = config.Width
+	info.Height = config.Height
+	return info, nil
+}
+
+// SniffLen es la cantidad de bytes del comienzo que necesita Sniff
+const SniffLen = 8
+
+// Sniff valida el tamaño y el formato real mirando solo los primeros bytes,
+// para rechazar una subida antes de recibir el resto. size puede ser
+// repository.UnknownSize. No completa las dimensiones.
+func (l ImageLimits) Sniff(head []byte, size int64) (*ImageInfo, error) {
+	if err := l.CheckSize(size); err != nil {
+		return nil, err
+	}
+
+	info, ok := sniffFormat(head)
+	if !ok {
+		return nil, ErrUnsupportedMediaType
+	}
+	return info, nil
+}
+
+// decodeConfig lee solo la cabecera de la imagen y verifica sus dimensiones.
+// Devuelve las de la imagen ya orientada, que son las que se guardan y las
+// que tendrá al decodificarse, así que los límites se aplican sobre ellas.
+func (l ImageLimits) decodeConfig(data []byte) (image.Config, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return image.Config{}, fmt.Errorf("%w: %v", ErrCorruptImage, err)
+	}
+
+	if swapsAxes(exif.Orientation(data)) {
+		config.Width, config.Height = config.Height, config.Width
+	}
+	if err := l.CheckDimensions(config.Width, config.Height); err != nil {
+		return image.Config{}, err
+	}
+	return config, nil
+}
+
+// decode decodifica la imagen solo si su cabecera respeta los límites y la
+// orienta según su etiqueta EXIF
+func (l ImageLimits) decode(data []byte) (image.Image, error) {
+	if _, err := l.decodeConfig(data); err != nil {
+		return nil, err
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("error al decodificar la imagen")
+	}
+	return autoOrient(img, exif.Orientation(data)), nil
+}
+
+// checkOperations impide que un paso pida una imagen de salida más grande que
+// los límites (por ejemplo un resize a 100000x100000). Solo mira los
+// parámetros, así que rechaza lo evidente antes de leer el original;
+// checkPipeline completa la verificación con el tamaño real.
+func (l ImageLimits) checkOperations(ops []Operation) error {
+	for i, op := range ops {
+		width, _ := op.Params.Int("width", 0)
+		height, _ := op.Params.Int("height", 0)
+
+		if err := l.checkOutputSize(width, height); err != nil {
+			return &StepError{Step: i, Type: op.Type, Err: err}
+		}
+	}
+	return nil
+}
+
+// checkPipeline recorre el pipeline calculando el tamaño que deja cada paso
+// sobre una imagen de src, para rechazar antes de aplicarlo uno que supere
+// los límites, incluidos los píxeles totales (por ejemplo un rotate de 45°
+// sobre una imagen grande o un resize con una sola dimensión)
+func (l ImageLimits) checkPipeline(ops []Operation, src image.Point) error {
+	size := src
+	for i, op := range ops {
+		size = outputSize(op, size)
+		if err := l.checkOutputSize(size.X, size.Y); err != nil {
+			return &StepError{Step: i, Type: op.Type, Err: err}
+		}
+	}
+	return nil
+}
+
+// outputSize devuelve el tamaño de la imagen después de aplicar op sobre
+// una de src. Las operaciones que no cambian el lienzo devuelven src.
+func outputSize(op Operation, src image.Point) image.Point {
+	switch op.Type {
+	case "resize":
+		opts, err := parseResizeOptions(op.Params)
+		if err != nil {
+			return src
+		}
+		return resizedSize(opts, src)
+	case "crop":
+		x, _ := op.Params.Int("x", 0)
+		y, _ := op.Params.Int("y", 0)
+		width, _ := op.Params.Int("width", 0)
+		height, _ := op.Params.Int("height", 0)
+		return image.Rect(x, y, x+width, y+height).Intersect(image.Rectangle{Max: src}).Size()
+	case "rotate":
+		angle, _ := op.Params.Float("angle", 0)
+		return rotatedSize(src, angle)
+	}
+	return src
+}
+
+// rotatedSize calcula el lienzo de imaging.Rotate, que crece para contener
+// la imagen girada
+func rotatedSize(src image.Point, angle float64) image.Point {
+	angle = angle - math.Floor(angle/360)*360
+	switch {
+	case angle == 0, angle == 180:
+		return src
+	case angle == 90, angle == 270:
+		return image.Pt(src.Y, src.X)
+	case src.X <= 0 || src.Y <= 0:
+		return image.Point{}
+	}
+
+	sin, cos := math.Sincos(math.Pi * angle / 180)
+	rotate := func(x, y float64) (float64, float64) {
+		return x*cos - y*sin, x*sin + y*cos
+	}
+	x1, y1 := rotate(float64(src.X-1), 0)
+	x2, y2 := rotate(float64(src.X-1), float64(src.Y-1))
+	x3, y3 := rotate(0, float64(src.Y-1))
+
+	extent := func(a, b, c float64) int {
+		size := math.Max(a, math.Max(b, math.Max(c, 0))) - math.Min(a, math.Min(b, math.Min(c, 0))) + 1
+		if size-math.Floor(size) > 0.1 {
+			size++
+		}
+		return int(size)
+	}
+	return image.Pt(extent(x1, x2, x3), extent(y1, y2, y3))
+}
+
+// checkOutputSize verifica las dimensiones que produciría una operación
+func (l ImageLimits) checkOutputSize(width, height int) error {
+	switch {
+	case l.MaxWidth > 0 && width > l.MaxWidth:
+		return &FieldError{Field: "width", Message: fmt.Sprintf("no puede superar %d", l.MaxWidth)}
+	case l.MaxHeight > 0 && height > l.MaxHeight:
+		return &FieldError{Field: "height", Message: fmt.Sprintf("no puede superar %d", l.MaxHeight)}
+	case l.MaxPixels > 0 && int64(width)*int64(height) > l.MaxPixels:
+		return &FieldError{Field: "width", Message: fmt.Sprintf("width × height no puede superar %d píxeles", l.MaxPixels)}
+	}
+	return nil
+}
+
+// sniffFormat identifica el formato a partir de los primeros bytes
+func sniffFormat(data []byte) (*ImageInfo, bool) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8, 0xFF}):
+		return &ImageInfo{Format: "jpeg", ContentType: "image/jpeg", Extension: "jpg"}, true
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return &ImageInfo{Format: "png", ContentType: "image/png", Extension: "png"}, true
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		return &ImageInfo{Format: "gif", ContentType: "image/gif", Extension: "gif"}, true
+	default:
+		return nil, false
+	}
+}
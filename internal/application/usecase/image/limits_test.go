@@ -0,0 +1,334 @@
+package image_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	stdimage "image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"testing"
+
+	"github.com/RodrigoGonzalez78/internal/application/usecase/image"
+	"github.com/RodrigoGonzalez78/internal/domain/repository/mocks"
+)
+
+// craftPNG arma un PNG cuya cabecera declara las dimensiones indicadas pero
+// que no contiene datos de píxeles: DecodeConfig lo acepta y una
+// decodificación completa reservaría width*height*4 bytes
+func craftPNG(width, height uint32) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:4], width)
+	binary.BigEndian.PutUint32(ihdr[4:8], height)
+	ihdr[8] = 8 // profundidad de bits
+	ihdr[9] = 6 // RGBA
+
+	writeChunk := func(kind string, data []byte) {
+		binary.Write(&buf, binary.BigEndian, uint32(len(data)))
+		crc := crc32.NewIEEE()
+		crc.Write([]byte(kind))
+		crc.Write(data)
+		buf.WriteString(kind)
+		buf.Write(data)
+		binary.Write(&buf, binary.BigEndian, crc.Sum32())
+	}
+
+	writeChunk("IHDR", ihdr)
+	writeChunk("IEND", nil)
+	return buf.Bytes()
+}
+
+func encodeTestJPEG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, stdimage.NewRGBA(stdimage.Rect(0, 0, width, height)), nil); err != nil {
+		t.Fatalf("no se pudo codificar el JPEG de prueba: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func encodeTestGIF(t *testing.T, width, height int) []byte {
+	t.Helper()
+	palette := color.Palette{color.Black, color.White}
+	var buf bytes.Buffer
+	if err := gif.Encode(&buf, stdimage.NewPaletted(stdimage.Rect(0, 0, width, height), palette), nil); err != nil {
+		t.Fatalf("no se pudo codificar el GIF de prueba: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestImageLimits_Inspect(t *testing.T) {
+	limits := image.ImageLimits{
+		MaxBytes:  1 << 20,
+		MaxWidth:  5000,
+		MaxHeight: 5000,
+		MaxPixels: 10_000_000,
+	}
+
+	tests := []struct {
+		name        string
+		data        []byte
+		wantFormat  string
+		wantWidth   int
+		wantLimit   string
+		wantUnknown bool
+		wantErr     bool
+	}{
+		{
+			name:       "png válido",
+			data:       encodeTestPNG(t, 40, 30),
+			wantFormat: "png",
+			wantWidth:  40,
+		},
+		{
+			name:       "jpeg válido",
+			data:       encodeTestJPEG(t, 20, 10),
+			wantFormat: "jpeg",
+			wantWidth:  20,
+		},
+		{
+			name:       "gif válido",
+			data:       encodeTestGIF(t, 8, 8),
+			wantFormat: "gif",
+			wantWidth:  8,
+		},
+		{
+			name:      "ancho declarado enorme",
+			data:      craftPNG(100000, 10),
+			wantLimit: "width",
+		},
+		{
+			name:      "alto declarado enorme",
+			data:      craftPNG(10, 100000),
+			wantLimit: "height",
+		},
+		{
+			name:      "demasiados píxeles dentro de ancho y alto",
+			data:      craftPNG(4000, 4000),
+			wantLimit: "pixels",
+		},
+		{
+			name:      "archivo demasiado grande",
+			data:      append(encodeTestPNG(t, 4, 4), make([]byte, 1<<20)...),
+			wantLimit: "bytes",
+		},
+		{
+			name:        "texto con extensión de imagen",
+			data:        []byte("esto no es una imagen"),
+			wantUnknown: true,
+		},
+		{
+			name:        "html disfrazado",
+			data:        []byte("<html><script>alert(1)</script></html>"),
+			wantUnknown: true,
+		},
+		{
+			name:    "cabecera png truncada",
+			data:    []byte("\x89PNG\r\n\x1a\n\x00\x00"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := limits.Inspect(tt.data)
+
+			var limitErr *image.LimitError
+			switch {
+			case tt.wantLimit != "":
+				if !errors.As(err, &limitErr) {
+					t.Fatalf("Inspect() error = %v, want LimitError", err)
+				}
+				if limitErr.Limit != tt.wantLimit {
+					t.Errorf("Limit = %v, want %v", limitErr.Limit, tt.wantLimit)
+				}
+			case tt.wantUnknown:
+				if !errors.Is(err, image.ErrUnsupportedMediaType) {
+					t.Errorf("Inspect() error = %v, want ErrUnsupportedMediaType", err)
+				}
+			case tt.wantErr:
+				if err == nil {
+					t.Error("Inspect() se esperaba error")
+				}
+				if errors.As(err, &limitErr) || errors.Is(err, image.ErrUnsupportedMediaType) {
+					t.Errorf("Inspect() error = %v, se esperaba un error de imagen corrupta", err)
+				}
+			default:
+				if err != nil {
+					t.Fatalf("Inspect() error = %v", err)
+				}
+				if info.Format != tt.wantFormat || info.Width != tt.wantWidth {
+					t.Errorf("Inspect() = %+v, want formato %v y ancho %v", info, tt.wantFormat, tt.wantWidth)
+				}
+			}
+		})
+	}
+}
+
+func TestTransformUseCase_RejectsDecompressionBomb(t *testing.T) {
+	_, imageRepo, fileStorage := setupTransform(t)
+	useCase := image.NewTransformUseCase(imageRepo, mocks.NewMockFontRepository(), fileStorage, nil, image.ImageLimits{
+		MaxWidth:  5000,
+		MaxHeight: 5000,
+		MaxPixels: 10_000_000,
+	})
+
+	// El original guardado declara 60000x60000 (14 GB decodificado)
+	fileStorage.Files["testuser/test.png"] = craftPNG(60000, 60000)
+
+	_, err := useCase.Execute(context.Background(), image.TransformInput{
+		ImageID:    1,
+		UserName:   "testuser",
+		Operations: []image.Operation{{Type: "grayscale"}},
+	})
+
+	var limitErr *image.LimitError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("Execute() error = %v, want LimitError", err)
+	}
+}
+
+func TestTransformUseCase_RejectsOversizedOutput(t *testing.T) {
+	useCase, _, _ := setupTransform(t)
+
+	tests := []struct {
+		name   string
+		params image.OperationParams
+	}{
+		{name: "ancho excesivo", params: image.OperationParams{"width": 100000.0, "height": 10.0}},
+		{name: "alto excesivo", params: image.OperationParams{"width": 10.0, "height": 100000.0}},
+		{name: "demasiados píxeles", params: image.OperationParams{"width": 9000.0, "height": 9000.0}},
+		// El alto proporcional (6750) recién se conoce al aplicar el paso
+		{name: "solo ancho con demasiados píxeles", params: image.OperationParams{"width": 9000.0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := useCase.Execute(context.Background(), image.TransformInput{
+				ImageID:    1,
+				UserName:   "testuser",
+				Operations: []image.Operation{{Type: "resize", Params: tt.params}},
+			})
+
+			var stepErr *image.StepError
+			if !errors.As(err, &stepErr) {
+				t.Fatalf("Execute() error = %v, want StepError", err)
+			}
+			var fieldErr *image.FieldError
+			if !errors.As(err, &fieldErr) {
+				t.Errorf("Execute() error = %v, want FieldError", err)
+			}
+		})
+	}
+}
+
+func TestTransformUseCase_ChecksPipelineOutputSize(t *testing.T) {
+	_, imageRepo, fileStorage := setupTransform(t)
+	// El original es de 400x300 (120000 píxeles)
+	useCase := image.NewTransformUseCase(imageRepo, mocks.NewMockFontRepository(), fileStorage, nil, image.ImageLimits{
+		MaxWidth:  500,
+		MaxHeight: 500,
+		MaxPixels: 150_000,
+	})
+
+	tests := []struct {
+		name     string
+		ops      []image.Operation
+		wantStep int
+		wantErr  bool
+	}{
+		{
+			name:    "rotate de 90 grados",
+			ops:     []image.Operation{{Type: "rotate", Params: image.OperationParams{"angle": 90.0}}},
+			wantErr: false,
+		},
+		{
+			// El lienzo girado mide unos 496x496
+			name:     "rotate de 45 grados",
+			ops:      []image.Operation{{Type: "rotate", Params: image.OperationParams{"angle": 45.0}}},
+			wantStep: 0,
+			wantErr:  true,
+		},
+		{
+			name:    "rotate de 45 grados después de un crop",
+			ops:     []image.Operation{{Type: "crop", Params: image.OperationParams{"x": 0.0, "y": 0.0, "width": 200.0, "height": 200.0}}, {Type: "rotate", Params: image.OperationParams{"angle": 45.0}}},
+			wantErr: false,
+		},
+		{
+			// El lienzo de contain es la caja completa aunque la imagen no la llene
+			name:     "pad con demasiados píxeles",
+			ops:      []image.Operation{{Type: "grayscale"}, {Type: "resize", Params: image.OperationParams{"width": 400.0, "height": 400.0, "mode": "pad"}}},
+			wantStep: 1,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := useCase.Execute(context.Background(), image.TransformInput{
+				ImageID:    1,
+				UserName:   "testuser",
+				Operations: tt.ops,
+			})
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("Execute() error = %v", err)
+				}
+				return
+			}
+
+			var stepErr *image.StepError
+			if !errors.As(err, &stepErr) || stepErr.Step != tt.wantStep {
+				t.Fatalf("Execute() error = %v, want StepError en el paso %d", err, tt.wantStep)
+			}
+			var fieldErr *image.FieldError
+			if !errors.As(err, &fieldErr) {
+				t.Errorf("Execute() error = %v, want FieldError", err)
+			}
+		})
+	}
+}
+
+func TestImageLimits_OrientedDimensions(t *testing.T) {
+	// phoneJPEG guarda 40x20 pero la imagen orientada mide 20x40
+	data := phoneJPEG(t, phoneEXIF)
+
+	tests := []struct {
+		name      string
+		limits    image.ImageLimits
+		wantLimit string
+	}{
+		{name: "entra una vez orientada", limits: image.ImageLimits{MaxWidth: 30, MaxHeight: 50}},
+		{name: "alto orientado excesivo", limits: image.ImageLimits{MaxWidth: 50, MaxHeight: 30}, wantLimit: "height"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := func(what string, err error) {
+				t.Helper()
+				var limitErr *image.LimitError
+				switch {
+				case tt.wantLimit == "" && err != nil:
+					t.Errorf("%s error = %v", what, err)
+				case tt.wantLimit != "" && (!errors.As(err, &limitErr) || limitErr.Limit != tt.wantLimit):
+					t.Errorf("%s error = %v, want LimitError de %s", what, err, tt.wantLimit)
+				}
+			}
+
+			info, err := tt.limits.Inspect(data)
+			check("Inspect()", err)
+			if err == nil && (info.Width != 20 || info.Height != 40) {
+				t.Errorf("Inspect() = %dx%d, want 20x40", info.Width, info.Height)
+			}
+
+			_, err = setupUpload(tt.limits).uploadUC.Execute(context.Background(), streamInput(data))
+			check("Execute()", err)
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package image
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"log"
+	"math"
+	"time"
+
+	"github.com/RodrigoGonzalez78/internal/domain/entity"
+	"github.com/RodrigoGonzalez78/internal/domain/repository"
+	"github.com/RodrigoGonzalez78/internal/pkg/exif"
+	"github.com/disintegration/imaging"
+)
+
+// ErrNoMetadata indica que la imagen no tiene metadatos EXIF guardados
+var ErrNoMetadata = errors.New("la imagen no tiene metadatos EXIF")
+
+// autoOrient gira o refleja la imagen según la etiqueta EXIF Orientation para
+// que se vea derecha; los codificadores no copian EXIF, así que el resultado
+// tiene que quedar orientado en los píxeles
+func autoOrient(img image.Image, orientation int) image.Image {
+	switch orientation {
+	case 2:
+		return imaging.FlipH(img)
+	case 3:
+		return imaging.Rotate180(img)
+	case 4:
+		return imaging.FlipV(img)
+	case 5:
+		return imaging.Transpose(img)
+	case 6:
+		return imaging.Rotate270(img)
+	case 7:
+		return imaging.Transverse(img)
+	case 8:
+		return imaging.Rotate90(img)
+	}
+	return img
+}
+
+// swapsAxes indica si la orientación intercambia ancho y alto
+func swapsAxes(orientation int) bool {
+	return orientation >= 5 && orientation <= 8
+}
+
+// extractMetadata interpreta los datos EXIF de una imagen subida. Devuelve
+// nil si no tiene o si están dañados; eso nunca impide la subida.
+func extractMetadata(data []byte) *entity.ImageMetadata {
+	parsed, err := exif.Parse(data)
+	if err != nil {
+		if !errors.Is(err, exif.ErrNotFound) {
+			log.Printf("  Metadatos EXIF ignorados: %v", err)
+		}
+		return nil
+	}
+
+	metadata := &entity.ImageMetadata{
+		Orientation:  parsed.Orientation,
+		CameraMake:   parsed.Make,
+		CameraModel:  parsed.Model,
+		LensMake:     parsed.LensMake,
+		LensModel:    parsed.LensModel,
+		Software:     parsed.Software,
+		ExposureTime: parsed.ExposureTime,
+		FNumber:      parsed.FNumber,
+		ISO:          parsed.ISO,
+		FocalLength:  parsed.FocalLength,
+	}
+	if !parsed.CapturedAt.IsZero() {
+		metadata.CapturedAt = &parsed.CapturedAt
+	}
+	if parsed.GPS != nil {
+		metadata.Latitude = &parsed.GPS.Latitude
+		metadata.Longitude = &parsed.GPS.Longitude
+		metadata.Altitude = parsed.GPS.Altitude
+	}
+	return metadata
+}
+
+// MetadataUseCase maneja la consulta de los metadatos EXIF de una imagen
+type MetadataUseCase struct {
+	imageRepo    repository.ImageRepository
+	metadataRepo repository.ImageMetadataRepository
+}
+
+// NewMetadataUseCase crea una nueva instancia de MetadataUseCase
+func NewMetadataUseCase(imageRepo repository.ImageRepository, metadataRepo repository.ImageMetadataRepository) *MetadataUseCase {
+	return &MetadataUseCase{
+		imageRepo:    imageRepo,
+		metadataRepo: metadataRepo,
+	}
+}
+
+// MetadataInput representa los datos de entrada
+type MetadataInput struct {
+	ImageID  int64
+	UserName string
+}
+
+// MetadataGPS posición de captura en grados decimales
+type MetadataGPS struct {
+	Latitude  float64
+	Longitude float64
+	Altitude  *float64
+}
+
+// MetadataOutput representa los metadatos de la imagen
+type MetadataOutput struct {
+	ImageID     int64
+	Orientation int
+	CameraMake  string
+	CameraModel string
+	LensMake    string
+	LensModel   string
+	Software    string
+	CapturedAt  *time.Time
+	GPS         *MetadataGPS
+	// ExposureTime con la notación de las cámaras ("1/125", "2s")
+	ExposureTime string
+	FNumber      float64
+	ISO          int
+	FocalLength  float64
+}
+
+// Execute ejecuta el caso de uso
+func (uc *MetadataUseCase) Execute(input MetadataInput) (*MetadataOutput, error) {
+	image, err := uc.imageRepo.FindByID(input.ImageID)
+	if err != nil {
+		return nil, errors.New("imagen no encontrada")
+	}
+
+	if image.UserName != input.UserName {
+		return nil, errors.New("no tienes permiso para esta imagen")
+	}
+
+	metadata, err := uc.metadataRepo.FindByImageID(image.ID)
+	if err != nil {
+		return nil, ErrNoMetadata
+	}
+
+	output := &MetadataOutput{
+		ImageID:      image.ID,
+		Orientation:  metadata.Orientation,
+		CameraMake:   metadata.CameraMake,
+		CameraModel:  metadata.CameraModel,
+		LensMake:     metadata.LensMake,
+		LensModel:    metadata.LensModel,
+		Software:     metadata.Software,
+		CapturedAt:   metadata.CapturedAt,
+		ExposureTime: formatExposure(metadata.ExposureTime),
+		FNumber:      metadata.FNumber,
+		ISO:          metadata.ISO,
+		FocalLength:  metadata.FocalLength,
+	}
+	if metadata.Latitude != nil && metadata.Longitude != nil {
+		output.GPS = &MetadataGPS{
+			Latitude:  *metadata.Latitude,
+			Longitude: *metadata.Longitude,
+			Altitude:  metadata.Altitude,
+		}
+	}
+	return output, nil
+}
+
+// formatExposure expresa los tiempos menores a un segundo como fracción
+func formatExposure(seconds float64) string {
+	switch {
+	case seconds <= 0:
+		return ""
+	case seconds < 1:
+		return fmt.Sprintf("1/%d", int(math.Round(1/seconds)))
+	}
+	return fmt.Sprintf("%gs", seconds)
+}
@@ -0,0 +1,190 @@
+package image_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	stdimage "image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+	"time"
+
+	"github.com/RodrigoGonzalez78/internal/application/usecase/image"
+	"github.com/RodrigoGonzalez78/internal/pkg/exif/exiftest"
+)
+
+// phoneJPEG simula una foto de teléfono tomada en vertical: los píxeles
+// están guardados de costado (40x20, mitad izquierda roja y derecha azul) y
+// la etiqueta Orientation 6 indica girarlos 90° en sentido horario
+func phoneJPEG(t *testing.T, block exiftest.Block) []byte {
+	t.Helper()
+	img := stdimage.NewNRGBA(stdimage.Rect(0, 0, 40, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 40; x++ {
+			c := color.NRGBA{R: 255, A: 255}
+			if x >= 20 {
+				c = color.NRGBA{B: 255, A: 255}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+	return block.JPEG(buf.Bytes())
+}
+
+var phoneEXIF = exiftest.Block{
+	IFD0: []exiftest.Tag{
+		exiftest.ASCII(0x010F, "Apple"),
+		exiftest.ASCII(0x0110, "iPhone 13"),
+		exiftest.Short(0x0112, 6),
+	},
+	Exif: []exiftest.Tag{
+		exiftest.Rationals(0x829A, [2]uint32{1, 125}),
+		exiftest.Rationals(0x829D, [2]uint32{16, 10}),
+		exiftest.Short(0x8827, 50),
+		exiftest.ASCII(0x9003, "2024:03:15 10:30:00"),
+		exiftest.ASCII(0xA434, "iPhone 13 back dual wide camera"),
+	},
+	GPS: []exiftest.Tag{
+		exiftest.ASCII(0x0001, "S"),
+		exiftest.Rationals(0x0002, [2]uint32{34, 1}, [2]uint32{36, 1}, [2]uint32{0, 1}),
+		exiftest.ASCII(0x0003, "W"),
+		exiftest.Rationals(0x0004, [2]uint32{58, 1}, [2]uint32{30, 1}, [2]uint32{0, 1}),
+	},
+}
+
+func isRed(c color.Color) bool {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return n.R > 200 && n.B < 60
+}
+
+func isBlue(c color.Color) bool {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return n.B > 200 && n.R < 60
+}
+
+func TestUploadUseCase_StoresMetadata(t *testing.T) {
+	f := setupUpload(image.ImageLimits{})
+	metadataUC := image.NewMetadataUseCase(f.imageRepo, f.metadataRepo)
+
+	data := phoneJPEG(t, phoneEXIF)
+	info, err := image.DefaultImageLimits().Inspect(data)
+	if err != nil {
+		t.Fatalf("Inspect() error = %v", err)
+	}
+	if info.Width != 20 || info.Height != 40 {
+		t.Errorf("Inspect() = %dx%d, want 20x40 (dimensiones ya orientadas)", info.Width, info.Height)
+	}
+
+	_, err = f.uploadUC.Execute(context.Background(), image.UploadInput{
+		FileName: "foto.jpg", UserName: "testuser", Data: data,
+		ContentType: info.ContentType, Format: info.Format, Width: info.Width, Height: info.Height,
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	got, err := metadataUC.Execute(image.MetadataInput{ImageID: 1, UserName: "testuser"})
+	if err != nil {
+		t.Fatalf("Metadata Execute() error = %v", err)
+	}
+	if got.Orientation != 6 || got.CameraMake != "Apple" || got.CameraModel != "iPhone 13" || got.LensModel != "iPhone 13 back dual wide camera" {
+		t.Errorf("metadatos = %+v", got)
+	}
+	if got.ExposureTime != "1/125" || got.FNumber != 1.6 || got.ISO != 50 {
+		t.Errorf("exposición = %s f/%v ISO %d", got.ExposureTime, got.FNumber, got.ISO)
+	}
+	if want := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC); got.CapturedAt == nil || !got.CapturedAt.Equal(want) {
+		t.Errorf("CapturedAt = %v, want %v", got.CapturedAt, want)
+	}
+	if got.GPS == nil || got.GPS.Latitude != -34.6 || got.GPS.Longitude != -58.5 || got.GPS.Altitude != nil {
+		t.Errorf("GPS = %+v", got.GPS)
+	}
+
+	if _, err := metadataUC.Execute(image.MetadataInput{ImageID: 1, UserName: "otro"}); err == nil {
+		t.Error("Execute() de otro usuario se esperaba error")
+	}
+}
+
+func TestUploadUseCase_WithoutMetadata(t *testing.T) {
+	f := setupUpload(image.ImageLimits{})
+	f.metadataRepo.SaveError = errors.New("base de datos caída")
+
+	// Un error al guardar los metadatos no impide la subida
+	if _, err := f.uploadUC.Execute(context.Background(), image.UploadInput{FileName: "a.jpg", UserName: "testuser", Data: phoneJPEG(t, phoneEXIF)}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	f.metadataRepo.SaveError = nil
+	if _, err := f.uploadUC.Execute(context.Background(), image.UploadInput{FileName: "b.jpg", UserName: "testuser", Data: encodeTestJPEG(t, 10, 10)}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	metadataUC := image.NewMetadataUseCase(f.imageRepo, f.metadataRepo)
+	for _, id := range []int64{1, 2} {
+		if _, err := metadataUC.Execute(image.MetadataInput{ImageID: id, UserName: "testuser"}); !errors.Is(err, image.ErrNoMetadata) {
+			t.Errorf("imagen %d: error = %v, want ErrNoMetadata", id, err)
+		}
+	}
+}
+
+func TestTransformUseCase_AutoOrient(t *testing.T) {
+	tests := []struct {
+		name        string
+		orientation uint16
+		width       int
+		height      int
+		check       func(img stdimage.Image) bool
+	}{
+		{
+			// Girada 90° en sentido horario: la mitad izquierda queda arriba
+			name: "rotada 90", orientation: 6, width: 20, height: 40,
+			check: func(img stdimage.Image) bool { return isRed(img.At(10, 5)) && isBlue(img.At(10, 35)) },
+		},
+		{
+			name: "rotada 180", orientation: 3, width: 40, height: 20,
+			check: func(img stdimage.Image) bool { return isBlue(img.At(5, 10)) && isRed(img.At(35, 10)) },
+		},
+		{
+			name: "espejada", orientation: 2, width: 40, height: 20,
+			check: func(img stdimage.Image) bool { return isBlue(img.At(5, 10)) && isRed(img.At(35, 10)) },
+		},
+		{
+			name: "rotada 270", orientation: 8, width: 20, height: 40,
+			check: func(img stdimage.Image) bool { return isBlue(img.At(10, 5)) && isRed(img.At(10, 35)) },
+		},
+		{
+			name: "normal", orientation: 1, width: 40, height: 20,
+			check: func(img stdimage.Image) bool { return isRed(img.At(5, 10)) && isBlue(img.At(35, 10)) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCase, _, fileStorage := setupTransform(t)
+			fileStorage.Files["testuser/foto.jpg"] = phoneJPEG(t, exiftest.WithOrientation(tt.orientation))
+
+			// Cualquier transformación parte de la imagen ya orientada
+			output, err := useCase.ExecutePath(context.Background(), image.TransformPathInput{
+				ObjectPath: "testuser/foto.jpg",
+				Format:     "png",
+			})
+			if err != nil {
+				t.Fatalf("ExecutePath() error = %v", err)
+			}
+
+			result := decodeOutput(t, output)
+			if b := result.Bounds(); b.Dx() != tt.width || b.Dy() != tt.height {
+				t.Fatalf("dimensiones = %dx%d, want %dx%d", b.Dx(), b.Dy(), tt.width, tt.height)
+			}
+			if !tt.check(result) {
+				t.Error("los píxeles no quedaron orientados")
+			}
+		})
+	}
+}
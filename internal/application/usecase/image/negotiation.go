@@ -0,0 +1,125 @@
+package image
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrNotAcceptable indica que ningún formato que acepta el cliente puede generarse
+var ErrNotAcceptable = errors.New("ninguno de los formatos aceptados por el cliente puede generarse (png, jpeg, webp, gif, tiff o bmp)")
+
+// outputTypes son los formatos que se pueden generar, en orden de preferencia
+// del servidor para los empates
+var outputTypes = []struct {
+	format      string
+	contentType string
+}{
+	{"png", "image/png"},
+	{"jpeg", "image/jpeg"},
+	{"webp", "image/webp"},
+	{"gif", "image/gif"},
+	{"tiff", "image/tiff"},
+	{"bmp", "image/bmp"},
+}
+
+// ContentTypeFormat devuelve el formato de salida que corresponde a un
+// content type, o "" si no se puede generar
+func ContentTypeFormat(contentType string) string {
+	for _, t := range outputTypes {
+		if t.contentType == contentType {
+			return t.format
+		}
+	}
+	return ""
+}
+
+// mediaRange es un elemento del header Accept
+type mediaRange struct {
+	mainType string
+	subType  string
+	q        float64
+}
+
+// matches indica si el rango incluye el content type y con qué
+// especificidad: tipo exacto (3), "image/*" (2) o "*/*" (1)
+func (m mediaRange) matches(contentType string) (int, bool) {
+	mainType, subType, _ := strings.Cut(contentType, "/")
+	switch {
+	case m.mainType == mainType && m.subType == subType:
+		return 3, true
+	case m.mainType == mainType && m.subType == "*":
+		return 2, true
+	case m.mainType == "*" && m.subType == "*":
+		return 1, true
+	default:
+		return 0, false
+	}
+}
+
+// parseAccept interpreta el header Accept. Los rangos sin q valen 1 y los
+// elementos mal formados se ignoran.
+func parseAccept(header string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		mainType, subType, ok := strings.Cut(strings.ToLower(strings.TrimSpace(params[0])), "/")
+		if !ok || mainType == "" || subType == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range params[1:] {
+			name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.ToLower(name) != "q" {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil || parsed < 0 || parsed > 1 {
+				parsed = 0
+			}
+			q = parsed
+		}
+
+		ranges = append(ranges, mediaRange{mainType: mainType, subType: subType, q: q})
+	}
+	return ranges
+}
+
+// NegotiateFormat elige el formato de salida a partir del header Accept.
+// Cada formato toma el q del rango más específico que lo incluye; gana el de
+// mayor q y, en un empate, preferred (el formato del original si se quiere
+// evitar una conversión), luego el que aparece antes en el header y por
+// último el orden del servidor. Sin header devuelve preferred.
+func NegotiateFormat(accept, preferred string) (string, error) {
+	if strings.TrimSpace(accept) == "" {
+		return preferred, nil
+	}
+
+	ranges := parseAccept(accept)
+
+	best, bestQ, bestIndex := "", 0.0, len(ranges)
+	for _, t := range outputTypes {
+		q, index, specificity := 0.0, len(ranges), 0
+		for i, r := range ranges {
+			if s, ok := r.matches(t.contentType); ok && s > specificity {
+				q, index, specificity = r.q, i, s
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+
+		switch {
+		case q > bestQ,
+			q == bestQ && t.format == preferred,
+			q == bestQ && best != preferred && index < bestIndex:
+			best, bestQ, bestIndex = t.format, q, index
+		}
+	}
+
+	if best == "" {
+		return "", ErrNotAcceptable
+	}
+	return best, nil
+}
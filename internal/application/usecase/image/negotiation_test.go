@@ -0,0 +1,72 @@
+package image_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/RodrigoGonzalez78/internal/application/usecase/image"
+)
+
+func TestNegotiateFormat(t *testing.T) {
+	tests := []struct {
+		name      string
+		accept    string
+		preferred string
+		want      string
+		wantErr   bool
+	}{
+		{name: "sin header", accept: "", want: ""},
+		{name: "sin header con original", accept: "", preferred: "jpeg", want: "jpeg"},
+		{name: "tipo exacto", accept: "image/jpeg", want: "jpeg"},
+		{name: "mayúsculas y espacios", accept: " Image/WebP ", want: "webp"},
+		{name: "gana el mayor q", accept: "image/png;q=0.5, image/webp;q=0.9", want: "webp"},
+		{name: "empate por orden del header", accept: "image/webp,image/jpeg", want: "webp"},
+		{name: "comodín usa el orden del servidor", accept: "*/*", want: "png"},
+		{name: "image/* con original", accept: "image/*", preferred: "gif", want: "gif"},
+		{name: "el original gana el empate", accept: "image/webp,image/*", preferred: "png", want: "png"},
+		{name: "el rango específico pisa al comodín", accept: "image/*, image/png;q=0", want: "jpeg"},
+		{name: "original excluido", accept: "image/webp, image/png;q=0", preferred: "png", want: "webp"},
+		{name: "navegador", accept: "text/html,application/xhtml+xml,image/avif,image/webp,*/*;q=0.8", preferred: "jpeg", want: "webp"},
+		{name: "solo tipos no soportados", accept: "image/avif, application/json", wantErr: true},
+		{name: "todo con q=0", accept: "*/*;q=0", wantErr: true},
+		{name: "q inválido", accept: "image/png;q=abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := image.NegotiateFormat(tt.accept, tt.preferred)
+			if tt.wantErr {
+				if !errors.Is(err, image.ErrNotAcceptable) {
+					t.Errorf("NegotiateFormat() error = %v, want ErrNotAcceptable", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NegotiateFormat() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("NegotiateFormat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransformUseCase_Transcode(t *testing.T) {
+	useCase, _, _ := setupTransform(t)
+
+	// Sin operaciones solo cambia el formato del original
+	output, err := useCase.ExecutePath(context.Background(), image.TransformPathInput{
+		ObjectPath: "testuser/test.png",
+		Format:     "webp",
+	})
+	if err != nil {
+		t.Fatalf("ExecutePath() error = %v", err)
+	}
+	if output.ContentType != "image/webp" {
+		t.Errorf("ContentType = %v, want image/webp", output.ContentType)
+	}
+	if b := decodeOutput(t, output).Bounds(); b.Dx() != 400 || b.Dy() != 300 {
+		t.Errorf("dimensiones = %v, want 400x300", b.Size())
+	}
+}
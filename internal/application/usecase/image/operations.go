@@ -0,0 +1,369 @@
+package image
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/disintegration/imaging"
+)
+
+// OperationParams parámetros de una operación del pipeline
+type OperationParams map[string]interface{}
+
+// Operation representa un paso del pipeline de transformación
+type Operation struct {
+	Type   string
+	Params OperationParams
+}
+
+// FieldError error de validación asociado a un parámetro concreto
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// StepError error producido en un paso concreto del pipeline
+type StepError struct {
+	Step int
+	Type string
+	Err  error
+}
+
+func (e *StepError) Error() string {
+	return fmt.Sprintf("paso %d (%s): %v", e.Step, e.Type, e.Err)
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// Has indica si el parámetro fue enviado
+func (p OperationParams) Has(name string) bool {
+	_, ok := p[name]
+	return ok
+}
+
+// Float obtiene un parámetro numérico. NaN e infinito se rechazan: pasan
+// cualquier comparación de rango y las URLs firmadas los admiten como texto.
+func (p OperationParams) Float(name string, fallback float64) (float64, error) {
+	value, ok := p[name]
+	if !ok || value == nil {
+		return fallback, nil
+	}
+
+	var number float64
+	switch v := value.(type) {
+	case float64:
+		number = v
+	case float32:
+		number = float64(v)
+	case int:
+		number = float64(v)
+	case int64:
+		number = float64(v)
+	default:
+		return 0, &FieldError{Field: name, Message: "debe ser numérico"}
+	}
+
+	if math.IsNaN(number) || math.IsInf(number, 0) {
+		return 0, &FieldError{Field: name, Message: "debe ser un número finito"}
+	}
+	return number, nil
+}
+
+// Int obtiene un parámetro entero
+func (p OperationParams) Int(name string, fallback int) (int, error) {
+	value, err := p.Float(name, float64(fallback))
+	if err != nil {
+		return 0, err
+	}
+	if value != math.Trunc(value) {
+		return 0, &FieldError{Field: name, Message: "debe ser un número entero"}
+	}
+	return int(value), nil
+}
+
+// Bool obtiene un parámetro booleano
+func (p OperationParams) Bool(name string, fallback bool) (bool, error) {
+	value, ok := p[name]
+	if !ok || value == nil {
+		return fallback, nil
+	}
+
+	v, ok := value.(bool)
+	if !ok {
+		return false, &FieldError{Field: name, Message: "debe ser booleano"}
+	}
+	return v, nil
+}
+
+// String obtiene un parámetro de texto
+func (p OperationParams) String(name string, fallback string) (string, error) {
+	value, ok := p[name]
+	if !ok || value == nil {
+		return fallback, nil
+	}
+
+	v, ok := value.(string)
+	if !ok {
+		return "", &FieldError{Field: name, Message: "debe ser un texto"}
+	}
+	return v, nil
+}
+
+// Floats obtiene una lista de números. Acepta un arreglo JSON o un texto con
+// los valores separados por comas (como llegan desde una URL).
+func (p OperationParams) Floats(name string) ([]float64, error) {
+	value, ok := p[name]
+	if !ok || value == nil {
+		return nil, nil
+	}
+
+	invalid := &FieldError{Field: name, Message: "debe ser una lista de números"}
+	switch v := value.(type) {
+	case []float64:
+		return v, nil
+	case []interface{}:
+		values := make([]float64, len(v))
+		for i, item := range v {
+			number, ok := item.(float64)
+			if !ok {
+				return nil, invalid
+			}
+			values[i] = number
+		}
+		return values, nil
+	case string:
+		parts := strings.Split(v, ",")
+		values := make([]float64, len(parts))
+		for i, part := range parts {
+			number, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+			if err != nil {
+				return nil, invalid
+			}
+			values[i] = number
+		}
+		return values, nil
+	}
+	return nil, invalid
+}
+
+// ValidateFunc valida los parámetros de una operación
+type ValidateFunc func(params OperationParams) error
+
+// ApplyFunc aplica una operación sobre una imagen
+type ApplyFunc func(ctx context.Context, img image.Image, params OperationParams) (image.Image, error)
+
+type operationDefinition struct {
+	validate ValidateFunc
+	apply    ApplyFunc
+}
+
+// OperationRegistry registro de los tipos de operación disponibles
+type OperationRegistry struct {
+	definitions map[string]operationDefinition
+}
+
+// NewOperationRegistry crea un registro con las operaciones básicas
+func NewOperationRegistry() *OperationRegistry {
+	r := &OperationRegistry{definitions: make(map[string]operationDefinition)}
+
+	r.Register("resize", validateResize, resizeApplier(ImageLimits{}))
+	r.Register("crop", validateCrop, applyCrop)
+	r.Register("rotate", validateRotate, applyRotate)
+	r.Register("grayscale", validateNoParams, applyGrayscale)
+	r.Register("sepia", validateSepia, applySepia)
+	r.Register("invert", validateNoParams, applyInvert)
+
+	// Ajustes de color y tono
+	r.Register("brightness", validateBrightness, applyBrightness)
+	r.Register("contrast", validateContrast, applyContrast)
+	r.Register("gamma", validateGamma, applyGamma)
+	r.Register("saturation", validateSaturation, applySaturation)
+	r.Register("hue", validateHue, applyHue)
+	r.Register("sigmoid", validateSigmoid, applySigmoid)
+
+	// Desenfoque, nitidez y convolución
+	r.Register("blur", validateBlur, applyBlur)
+	r.Register("sharpen", validateSharpen, applySharpen)
+	r.Register("convolve", validateConvolve, applyConvolve)
+	r.Register("edge_detect", validateNoParams, applyEdgeDetect)
+	r.Register("emboss", validateNoParams, applyEmboss)
+
+	return r
+}
+
+// Register agrega (o reemplaza) un tipo de operación
+func (r *OperationRegistry) Register(name string, validate ValidateFunc, apply ApplyFunc) {
+	r.definitions[name] = operationDefinition{validate: validate, apply: apply}
+}
+
+// Types devuelve los tipos de operación registrados ordenados
+func (r *OperationRegistry) Types() []string {
+	types := make([]string, 0, len(r.definitions))
+	for name := range r.definitions {
+		types = append(types, name)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Validate valida todos los pasos del pipeline sin ejecutarlos
+func (r *OperationRegistry) Validate(ops []Operation) error {
+	for i, op := range ops {
+		def, ok := r.definitions[op.Type]
+		if !ok {
+			return &StepError{Step: i, Type: op.Type, Err: errors.New("tipo de operación desconocido")}
+		}
+		if def.validate == nil {
+			continue
+		}
+		if err := def.validate(op.Params); err != nil {
+			return &StepError{Step: i, Type: op.Type, Err: err}
+		}
+	}
+	return nil
+}
+
+// Run ejecuta los pasos del pipeline en orden
+func (r *OperationRegistry) Run(ctx context.Context, img image.Image, ops []Operation) (image.Image, error) {
+	if err := r.Validate(ops); err != nil {
+		return nil, err
+	}
+
+	for i, op := range ops {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		result, err := r.definitions[op.Type].apply(ctx, img, op.Params)
+		if err != nil {
+			return nil, &StepError{Step: i, Type: op.Type, Err: err}
+		}
+		img = result
+	}
+
+	return img, nil
+}
+
+// LegacyOperations convierte los parámetros del formato anterior en un
+// pipeline respetando el orden fijo resize → crop → rotate → filtros
+func LegacyOperations(resize ResizeParams, crop CropParams, rotate float64, filters FilterParams) []Operation {
+	var ops []Operation
+
+	if resize.Width > 0 || resize.Height > 0 {
+		params := OperationParams{"width": float64(resize.Width), "height": float64(resize.Height)}
+		for name, value := range map[string]string{
+			"mode":       resize.Mode,
+			"gravity":    resize.Gravity,
+			"background": resize.Background,
+			"filter":     resize.Filter,
+		} {
+			if value != "" {
+				params[name] = value
+			}
+		}
+		if resize.NoUpscale {
+			params["no_upscale"] = true
+		}
+		ops = append(ops, Operation{Type: "resize", Params: params})
+	}
+
+	if crop.Width > 0 && crop.Height > 0 {
+		ops = append(ops, Operation{
+			Type: "crop",
+			Params: OperationParams{
+				"x":      float64(crop.X),
+				"y":      float64(crop.Y),
+				"width":  float64(crop.Width),
+				"height": float64(crop.Height),
+			},
+		})
+	}
+
+	if rotate != 0 {
+		ops = append(ops, Operation{Type: "rotate", Params: OperationParams{"angle": rotate}})
+	}
+
+	if filters.Grayscale {
+		ops = append(ops, Operation{Type: "grayscale"})
+	}
+
+	if filters.Sepia {
+		ops = append(ops, Operation{Type: "sepia"})
+	}
+
+	return ops
+}
+
+func validateNoParams(params OperationParams) error {
+	return nil
+}
+
+func validateCrop(params OperationParams) error {
+	for _, field := range []string{"x", "y"} {
+		value, err := params.Int(field, 0)
+		if err != nil {
+			return err
+		}
+		if value < 0 {
+			return &FieldError{Field: field, Message: "no puede ser negativo"}
+		}
+	}
+	for _, field := range []string{"width", "height"} {
+		value, err := params.Int(field, 0)
+		if err != nil {
+			return err
+		}
+		if value <= 0 {
+			return &FieldError{Field: field, Message: "debe ser mayor que 0"}
+		}
+	}
+	return nil
+}
+
+func applyCrop(ctx context.Context, img image.Image, params OperationParams) (image.Image, error) {
+	x, _ := params.Int("x", 0)
+	y, _ := params.Int("y", 0)
+	width, _ := params.Int("width", 0)
+	height, _ := params.Int("height", 0)
+
+	bounds := img.Bounds()
+	cropRect := image.Rect(x, y, x+width, y+height).Add(bounds.Min)
+	if !cropRect.Overlaps(bounds) {
+		return nil, errors.New("el área de recorte está fuera de la imagen")
+	}
+
+	return imaging.Crop(img, cropRect), nil
+}
+
+func validateRotate(params OperationParams) error {
+	if !params.Has("angle") {
+		return &FieldError{Field: "angle", Message: "es requerido"}
+	}
+	_, err := params.Float("angle", 0)
+	return err
+}
+
+func applyRotate(ctx context.Context, img image.Image, params OperationParams) (image.Image, error) {
+	angle, _ := params.Float("angle", 0)
+	if angle == 0 {
+		return img, nil
+	}
+	return imaging.Rotate(img, angle, color.Transparent), nil
+}
+
+func applyGrayscale(ctx context.Context, img image.Image, params OperationParams) (image.Image, error) {
+	return imaging.Grayscale(img), nil
+}
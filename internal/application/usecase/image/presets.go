@@ -0,0 +1,237 @@
+package image
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/RodrigoGonzalez78/internal/domain/entity"
+	"github.com/RodrigoGonzalez78/internal/domain/repository"
+)
+
+// ErrPresetNotFound indica que el usuario no tiene un preset con ese nombre o ID
+var ErrPresetNotFound = errors.New("preset no encontrado")
+
+// ErrPresetExists indica que el usuario ya tiene un preset con ese nombre
+var ErrPresetExists = errors.New("ya existe un preset con ese nombre")
+
+// presetNamePattern limita los nombres a lo que se puede escribir en una
+// query string sin escapar
+var presetNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// PresetUseCase administra los presets de transformación de cada usuario.
+// Se validan con el mismo registro de operaciones que TransformUseCase.
+type PresetUseCase struct {
+	presetRepo  repository.PresetRepository
+	transformUC *TransformUseCase
+}
+
+// NewPresetUseCase crea una nueva instancia de PresetUseCase
+func NewPresetUseCase(presetRepo repository.PresetRepository, transformUC *TransformUseCase) *PresetUseCase {
+	return &PresetUseCase{
+		presetRepo:  presetRepo,
+		transformUC: transformUC,
+	}
+}
+
+// PresetInput representa los datos de un preset a crear o reemplazar
+type PresetInput struct {
+	UserName   string
+	Name       string
+	Operations []Operation
+	Format     string
+	Encoding   EncodeOptions
+}
+
+// PresetOutput representa un preset guardado
+type PresetOutput struct {
+	ID         int64
+	Name       string
+	Operations []Operation
+	Format     string
+	Encoding   EncodeOptions
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// Create valida y guarda un preset nuevo
+func (uc *PresetUseCase) Create(input PresetInput) (*PresetOutput, error) {
+	operations, encoding, err := uc.validate(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := uc.presetRepo.FindByName(input.UserName, input.Name); err == nil {
+		return nil, ErrPresetExists
+	}
+
+	preset := entity.NewPreset(input.UserName, input.Name, operations, input.Format, encoding)
+	if err := uc.presetRepo.Create(preset); err != nil {
+		return nil, fmt.Errorf("error al guardar el preset: %w", err)
+	}
+
+	return toPresetOutput(preset)
+}
+
+// Update reemplaza el nombre y el pipeline de un preset del usuario
+func (uc *PresetUseCase) Update(id int64, input PresetInput) (*PresetOutput, error) {
+	preset, err := uc.findOwned(id, input.UserName)
+	if err != nil {
+		return nil, err
+	}
+
+	operations, encoding, err := uc.validate(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if other, err := uc.presetRepo.FindByName(input.UserName, input.Name); err == nil && other.ID != preset.ID {
+		return nil, ErrPresetExists
+	}
+
+	preset.Name = input.Name
+	preset.Operations = operations
+	preset.Format = input.Format
+	preset.Encoding = encoding
+	preset.UpdatedAt = time.Now()
+	if err := uc.presetRepo.Update(preset); err != nil {
+		return nil, fmt.Errorf("error al guardar el preset: %w", err)
+	}
+
+	return toPresetOutput(preset)
+}
+
+// Get devuelve un preset del usuario
+func (uc *PresetUseCase) Get(id int64, userName string) (*PresetOutput, error) {
+	preset, err := uc.findOwned(id, userName)
+	if err != nil {
+		return nil, err
+	}
+	return toPresetOutput(preset)
+}
+
+// List devuelve los presets del usuario ordenados por nombre
+func (uc *PresetUseCase) List(userName string) ([]PresetOutput, error) {
+	presets, err := uc.presetRepo.FindByUser(userName)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make([]PresetOutput, len(presets))
+	for i := range presets {
+		output, err := toPresetOutput(&presets[i])
+		if err != nil {
+			return nil, err
+		}
+		outputs[i] = *output
+	}
+	return outputs, nil
+}
+
+// Delete elimina un preset del usuario
+func (uc *PresetUseCase) Delete(id int64, userName string) error {
+	if _, err := uc.findOwned(id, userName); err != nil {
+		return err
+	}
+	if err := uc.presetRepo.Delete(id); err != nil {
+		return fmt.Errorf("error al eliminar el preset: %w", err)
+	}
+	return nil
+}
+
+// Apply antepone el pipeline del preset a las operaciones de la petición. El
+// formato y las opciones del preset solo se usan si la petición no pidió otro
+// formato.
+func (uc *PresetUseCase) Apply(userName, name string, input TransformInput) (TransformInput, error) {
+	preset, err := uc.presetRepo.FindByName(userName, name)
+	if err != nil {
+		return input, ErrPresetNotFound
+	}
+	output, err := toPresetOutput(preset)
+	if err != nil {
+		return input, err
+	}
+
+	extra := input.Pipeline()
+	ops := make([]Operation, 0, len(output.Operations)+len(extra))
+	ops = append(ops, output.Operations...)
+	input.Operations = append(ops, extra...)
+
+	if input.Format == "" || input.Format == output.Format {
+		input.Format = output.Format
+		if input.Encoding.Empty() {
+			input.Encoding = output.Encoding
+		}
+	}
+	return input, nil
+}
+
+// validate comprueba el nombre, el pipeline y el formato, y devuelve el
+// pipeline y las opciones serializados para guardarlos
+func (uc *PresetUseCase) validate(input PresetInput) (string, string, error) {
+	if !presetNamePattern.MatchString(input.Name) {
+		return "", "", &FieldError{Field: "name", Message: "debe tener de 1 a 64 caracteres: minúsculas, números, - o _"}
+	}
+	if len(input.Operations) == 0 && input.Format == "" {
+		return "", "", &FieldError{Field: "operations", Message: "el preset debe tener al menos una operación o un formato"}
+	}
+
+	if err := uc.transformUC.ValidateOperations(input.Operations); err != nil {
+		return "", "", err
+	}
+	if input.Format != "" || !input.Encoding.Empty() {
+		if err := uc.transformUC.ValidateFormat(input.Format, input.Encoding); err != nil {
+			return "", "", err
+		}
+	}
+
+	ops := input.Operations
+	if ops == nil {
+		ops = []Operation{}
+	}
+	operations, err := json.Marshal(ops)
+	if err != nil {
+		return "", "", fmt.Errorf("error al serializar las operaciones: %w", err)
+	}
+
+	var encoding string
+	if !input.Encoding.Empty() {
+		options, err := json.Marshal(input.Encoding)
+		if err != nil {
+			return "", "", fmt.Errorf("error al serializar las opciones de formato: %w", err)
+		}
+		encoding = string(options)
+	}
+
+	return string(operations), encoding, nil
+}
+
+func (uc *PresetUseCase) findOwned(id int64, userName string) (*entity.Preset, error) {
+	preset, err := uc.presetRepo.FindByID(id)
+	if err != nil || preset.UserName != userName {
+		return nil, ErrPresetNotFound
+	}
+	return preset, nil
+}
+
+func toPresetOutput(preset *entity.Preset) (*PresetOutput, error) {
+	output := &PresetOutput{
+		ID:         preset.ID,
+		Name:       preset.Name,
+		Operations: []Operation{},
+		Format:     preset.Format,
+		CreatedAt:  preset.CreatedAt,
+		UpdatedAt:  preset.UpdatedAt,
+	}
+	if err := json.Unmarshal([]byte(preset.Operations), &output.Operations); err != nil {
+		return nil, fmt.Errorf("preset %d inválido: %w", preset.ID, err)
+	}
+	if preset.Encoding != "" {
+		if err := json.Unmarshal([]byte(preset.Encoding), &output.Encoding); err != nil {
+			return nil, fmt.Errorf("preset %d inválido: %w", preset.ID, err)
+		}
+	}
+	return output, nil
+}
@@ -0,0 +1,226 @@
+package image_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/RodrigoGonzalez78/internal/application/usecase/image"
+	"github.com/RodrigoGonzalez78/internal/domain/repository/mocks"
+)
+
+var thumbOperations = []image.Operation{
+	{Type: "resize", Params: image.OperationParams{"width": 100.0, "height": 100.0, "mode": "fill"}},
+}
+
+func TestPresetUseCase_Validation(t *testing.T) {
+	transformUC, _, _ := setupTransform(t)
+	presetUC := image.NewPresetUseCase(mocks.NewMockPresetRepository(), transformUC)
+
+	tests := []struct {
+		name      string
+		input     image.PresetInput
+		wantField string
+		wantStep  bool
+	}{
+		{
+			name:      "nombre vacío",
+			input:     image.PresetInput{Operations: thumbOperations},
+			wantField: "name",
+		},
+		{
+			name:      "nombre con espacios",
+			input:     image.PresetInput{Name: "mi preset", Operations: thumbOperations},
+			wantField: "name",
+		},
+		{
+			name:      "sin operaciones ni formato",
+			input:     image.PresetInput{Name: "vacio"},
+			wantField: "operations",
+		},
+		{
+			name: "operación desconocida",
+			input: image.PresetInput{Name: "malo", Operations: []image.Operation{
+				{Type: "resize", Params: image.OperationParams{"width": 10.0}},
+				{Type: "pixelar"},
+			}},
+			wantStep: true,
+		},
+		{
+			name: "parámetro inválido",
+			input: image.PresetInput{Name: "malo", Operations: []image.Operation{
+				{Type: "blur", Params: image.OperationParams{"sigma": -1.0}},
+			}},
+			wantStep: true,
+		},
+		{
+			name:  "calidad fuera de rango",
+			input: image.PresetInput{Name: "malo", Operations: thumbOperations, Format: "jpeg", Encoding: image.EncodeOptions{Quality: 300}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.input.UserName = "testuser"
+			_, err := presetUC.Create(tt.input)
+			if err == nil {
+				t.Fatal("Create() se esperaba error")
+			}
+
+			var stepErr *image.StepError
+			var fieldErr *image.FieldError
+			switch {
+			case tt.wantStep && !errors.As(err, &stepErr):
+				t.Errorf("error = %v, want StepError", err)
+			case tt.wantField != "" && (!errors.As(err, &fieldErr) || fieldErr.Field != tt.wantField):
+				t.Errorf("error = %v, want FieldError en %q", err, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestPresetUseCase_CRUD(t *testing.T) {
+	transformUC, _, _ := setupTransform(t)
+	presetUC := image.NewPresetUseCase(mocks.NewMockPresetRepository(), transformUC)
+
+	created, err := presetUC.Create(image.PresetInput{UserName: "testuser", Name: "thumb", Operations: thumbOperations, Format: "jpeg", Encoding: image.EncodeOptions{Quality: 70}})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if created.ID == 0 || len(created.Operations) != 1 || created.Encoding.Quality != 70 {
+		t.Errorf("Create() = %+v", created)
+	}
+
+	if _, err := presetUC.Create(image.PresetInput{UserName: "testuser", Name: "thumb", Format: "png"}); !errors.Is(err, image.ErrPresetExists) {
+		t.Errorf("Create() duplicado error = %v, want ErrPresetExists", err)
+	}
+	// El nombre es único por usuario
+	if _, err := presetUC.Create(image.PresetInput{UserName: "otro", Name: "thumb", Format: "png"}); err != nil {
+		t.Errorf("Create() de otro usuario error = %v", err)
+	}
+	if _, err := presetUC.Create(image.PresetInput{UserName: "testuser", Name: "gris", Operations: []image.Operation{{Type: "grayscale"}}}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	list, err := presetUC.List("testuser")
+	if err != nil || len(list) != 2 || list[0].Name != "gris" || list[1].Name != "thumb" {
+		t.Fatalf("List() = %+v, %v", list, err)
+	}
+
+	if _, err := presetUC.Update(created.ID, image.PresetInput{UserName: "testuser", Name: "gris", Format: "png"}); !errors.Is(err, image.ErrPresetExists) {
+		t.Errorf("Update() a un nombre usado error = %v, want ErrPresetExists", err)
+	}
+	if _, err := presetUC.Update(created.ID, image.PresetInput{UserName: "otro", Name: "thumb", Format: "png"}); !errors.Is(err, image.ErrPresetNotFound) {
+		t.Errorf("Update() de otro usuario error = %v, want ErrPresetNotFound", err)
+	}
+	updated, err := presetUC.Update(created.ID, image.PresetInput{UserName: "testuser", Name: "miniatura", Format: "webp"})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if updated.Name != "miniatura" || len(updated.Operations) != 0 || updated.Format != "webp" || !updated.Encoding.Empty() {
+		t.Errorf("Update() = %+v", updated)
+	}
+
+	if err := presetUC.Delete(created.ID, "otro"); !errors.Is(err, image.ErrPresetNotFound) {
+		t.Errorf("Delete() de otro usuario error = %v, want ErrPresetNotFound", err)
+	}
+	if err := presetUC.Delete(created.ID, "testuser"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := presetUC.Get(created.ID, "testuser"); !errors.Is(err, image.ErrPresetNotFound) {
+		t.Errorf("Get() después de borrar error = %v, want ErrPresetNotFound", err)
+	}
+}
+
+func TestPresetUseCase_Apply(t *testing.T) {
+	transformUC, _, _ := setupTransform(t)
+	presetUC := image.NewPresetUseCase(mocks.NewMockPresetRepository(), transformUC)
+
+	if _, err := presetUC.Create(image.PresetInput{UserName: "testuser", Name: "thumb", Operations: thumbOperations, Format: "jpeg", Encoding: image.EncodeOptions{Quality: 70}}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		input       image.TransformInput
+		wantOps     []string
+		wantFormat  string
+		wantQuality int
+		wantWidth   int
+	}{
+		{
+			name:        "solo el preset",
+			input:       image.TransformInput{},
+			wantOps:     []string{"resize"},
+			wantFormat:  "jpeg",
+			wantQuality: 70,
+			wantWidth:   100,
+		},
+		{
+			name: "preset y operaciones extra",
+			input: image.TransformInput{Operations: []image.Operation{
+				{Type: "crop", Params: image.OperationParams{"x": 0.0, "y": 0.0, "width": 50.0, "height": 50.0}},
+				{Type: "grayscale"},
+			}},
+			wantOps:     []string{"resize", "crop", "grayscale"},
+			wantFormat:  "jpeg",
+			wantQuality: 70,
+			wantWidth:   50,
+		},
+		{
+			name:        "formato anterior como extra",
+			input:       image.TransformInput{Rotate: 90},
+			wantOps:     []string{"resize", "rotate"},
+			wantFormat:  "jpeg",
+			wantQuality: 70,
+			wantWidth:   100,
+		},
+		{
+			// Las opciones del preset son de jpeg y no se mezclan con png
+			name:       "la petición cambia el formato",
+			input:      image.TransformInput{Format: "png"},
+			wantOps:    []string{"resize"},
+			wantFormat: "png",
+			wantWidth:  100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.input.ImageID = 1
+			tt.input.UserName = "testuser"
+			input, err := presetUC.Apply("testuser", "thumb", tt.input)
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+
+			var got []string
+			for _, op := range input.Pipeline() {
+				got = append(got, op.Type)
+			}
+			if len(got) != len(tt.wantOps) {
+				t.Fatalf("operaciones = %v, want %v", got, tt.wantOps)
+			}
+			for i := range got {
+				if got[i] != tt.wantOps[i] {
+					t.Fatalf("operaciones = %v, want %v", got, tt.wantOps)
+				}
+			}
+			if input.Format != tt.wantFormat || input.Encoding.Quality != tt.wantQuality {
+				t.Errorf("formato = %s q%d, want %s q%d", input.Format, input.Encoding.Quality, tt.wantFormat, tt.wantQuality)
+			}
+
+			output, err := transformUC.Execute(context.Background(), input)
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if b := decodeOutput(t, output).Bounds(); b.Dx() != tt.wantWidth {
+				t.Errorf("ancho = %d, want %d", b.Dx(), tt.wantWidth)
+			}
+		})
+	}
+
+	if _, err := presetUC.Apply("otro", "thumb", image.TransformInput{}); !errors.Is(err, image.ErrPresetNotFound) {
+		t.Errorf("Apply() de otro usuario error = %v, want ErrPresetNotFound", err)
+	}
+}
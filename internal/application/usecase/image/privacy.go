@@ -0,0 +1,330 @@
+package image
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/RodrigoGonzalez78/internal/domain/repository"
+	"github.com/RodrigoGonzalez78/internal/pkg/exif"
+)
+
+// MetadataPolicy indica qué metadatos se quitan de una imagen antes de
+// guardarla
+type MetadataPolicy string
+
+const (
+	// MetadataKeep conserva todos los metadatos
+	MetadataKeep MetadataPolicy = "keep"
+	// MetadataStripGPS quita solo la posición de captura
+	MetadataStripGPS MetadataPolicy = "strip_gps"
+	// MetadataStripAll quita EXIF, XMP, IPTC y comentarios; se conserva
+	// únicamente la orientación
+	MetadataStripAll MetadataPolicy = "strip_all"
+)
+
+// ErrInvalidMetadataPolicy indica un valor de política desconocido
+var ErrInvalidMetadataPolicy = errors.New("política de metadatos inválida (valores válidos: keep, strip_gps, strip_all)")
+
+// ErrUnreadableMetadata indica que los segmentos de metadatos del archivo
+// están dañados y no se pueden reescribir
+var ErrUnreadableMetadata = errors.New("no se pudieron quitar los metadatos: el archivo está dañado")
+
+// gpsAuditBytes es cuánto se lee de cada objeto al buscar coordenadas; los
+// metadatos van al comienzo del archivo
+const gpsAuditBytes = 256 << 10
+
+// ParseMetadataPolicy valida una política; la cadena vacía equivale a keep
+func ParseMetadataPolicy(value string) (MetadataPolicy, error) {
+	switch policy := MetadataPolicy(value); policy {
+	case "":
+		return MetadataKeep, nil
+	case MetadataKeep, MetadataStripGPS, MetadataStripAll:
+		return policy, nil
+	}
+	return "", ErrInvalidMetadataPolicy
+}
+
+// applyMetadataPolicy reescribe los segmentos de metadatos sin volver a
+// codificar los píxeles
+func applyMetadataPolicy(data []byte, policy MetadataPolicy) ([]byte, error) {
+	mode, ok := stripMode(policy)
+	if !ok {
+		return data, nil
+	}
+
+	stripped, err := exif.Strip(data, mode)
+	if err != nil {
+		return nil, fmt.Errorf("%w (%v)", ErrUnreadableMetadata, err)
+	}
+	return stripped, nil
+}
+
+// stripMode indica qué quitar según la política; false si no se quita nada
+func stripMode(policy MetadataPolicy) (exif.StripMode, bool) {
+	switch policy {
+	case MetadataStripGPS:
+		return exif.StripGPS, true
+	case MetadataStripAll:
+		return exif.StripAll, true
+	}
+	return 0, false
+}
+
+// PrivacyUseCase administra la política de metadatos de cada usuario y la
+// limpieza de las imágenes ya guardadas
+type PrivacyUseCase struct {
+	userRepo     repository.UserRepository
+	imageRepo    repository.ImageRepository
+	metadataRepo repository.ImageMetadataRepository
+	fileStorage  repository.FileStorage
+	blobs        *BlobStore
+	cache        *DerivativeCache
+	baseURL      string
+	port         string
+}
+
+// NewPrivacyUseCase crea una nueva instancia de PrivacyUseCase
+func NewPrivacyUseCase(
+	userRepo repository.UserRepository,
+	imageRepo repository.ImageRepository,
+	metadataRepo repository.ImageMetadataRepository,
+	fileStorage repository.FileStorage,
+	blobs *BlobStore,
+	cache *DerivativeCache,
+	baseURL, port string,
+) *PrivacyUseCase {
+	return &PrivacyUseCase{
+		userRepo:     userRepo,
+		imageRepo:    imageRepo,
+		metadataRepo: metadataRepo,
+		fileStorage:  fileStorage,
+		blobs:        blobs,
+		cache:        cache,
+		baseURL:      baseURL,
+		port:         port,
+	}
+}
+
+// GetPolicy devuelve la política por defecto del usuario
+func (uc *PrivacyUseCase) GetPolicy(userName string) (MetadataPolicy, error) {
+	user, err := uc.userRepo.FindByUserName(userName)
+	if err != nil {
+		return "", fmt.Errorf("error al buscar el usuario: %w", err)
+	}
+	policy, err := ParseMetadataPolicy(user.MetadataPolicy)
+	if err != nil {
+		return "", fmt.Errorf("política guardada inválida: %w", err)
+	}
+	return policy, nil
+}
+
+// SetPolicy valida y guarda la política por defecto del usuario
+func (uc *PrivacyUseCase) SetPolicy(userName, value string) (MetadataPolicy, error) {
+	if value == "" {
+		return "", ErrInvalidMetadataPolicy
+	}
+	policy, err := ParseMetadataPolicy(value)
+	if err != nil {
+		return "", err
+	}
+	if err := uc.userRepo.UpdateMetadataPolicy(userName, string(policy)); err != nil {
+		return "", fmt.Errorf("error al guardar la política de metadatos: %w", err)
+	}
+	return policy, nil
+}
+
+// ResolvePolicy devuelve la política de una subida: la pedida en la propia
+// subida o, si no se indicó, la del usuario
+func (uc *PrivacyUseCase) ResolvePolicy(userName, requested string) (MetadataPolicy, error) {
+	if requested != "" {
+		return ParseMetadataPolicy(requested)
+	}
+	return uc.GetPolicy(userName)
+}
+
+// StripMetadataInput representa los datos de entrada
+type StripMetadataInput struct {
+	ImageID  int64
+	UserName string
+	// Policy vacía equivale a strip_all
+	Policy string
+}
+
+// StripMetadataOutput representa los datos de salida
+type StripMetadataOutput struct {
+	ImageID int64
+	Policy  MetadataPolicy
+	// Changed indica si el archivo tenía metadatos que quitar
+	Changed bool
+	OldSize int64
+	NewSize int64
+}
+
+// StripStored quita los metadatos de una imagen ya guardada. El resultado se
+// guarda como un blob nuevo y se libera el anterior, que puede seguir en uso
+// por otras imágenes con el mismo contenido. Se actualizan el objeto y el
+// tamaño de la imagen y los metadatos guardados.
+func (uc *PrivacyUseCase) StripStored(ctx context.Context, input StripMetadataInput) (*StripMetadataOutput, error) {
+	policy := MetadataStripAll
+	if input.Policy != "" {
+		var err error
+		if policy, err = ParseMetadataPolicy(input.Policy); err != nil {
+			return nil, err
+		}
+		if policy == MetadataKeep {
+			return nil, ErrInvalidMetadataPolicy
+		}
+	}
+
+	image, err := uc.imageRepo.FindByID(input.ImageID)
+	if err != nil {
+		return nil, errors.New("imagen no encontrada")
+	}
+
+	if image.UserName != input.UserName {
+		return nil, errors.New("no tienes permiso para esta imagen")
+	}
+
+	data, err := uc.readObject(ctx, image.Path, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	stripped, err := applyMetadataPolicy(data, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &StripMetadataOutput{
+		ImageID: image.ID,
+		Policy:  policy,
+		OldSize: int64(len(data)),
+		NewSize: int64(len(stripped)),
+	}
+	if bytes.Equal(stripped, data) {
+		return output, nil
+	}
+	output.Changed = true
+
+	contentType := "application/octet-stream"
+	if info, ok := sniffFormat(stripped); ok {
+		contentType = info.ContentType
+	}
+	oldPath := image.Path
+	blob, err := uc.blobs.Put(ctx, stripped, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("error al guardar la imagen: %w", err)
+	}
+
+	if err := uc.imageRepo.UpdateObject(image.ID, blob.Path, output.NewSize); err != nil {
+		if _, relErr := uc.blobs.Release(ctx, blob.Path); relErr != nil {
+			log.Printf("  No se pudo liberar el blob %s: %v", blob.Path, relErr)
+		}
+		return nil, fmt.Errorf("error al actualizar la imagen: %w", err)
+	}
+
+	removed, err := uc.blobs.Release(ctx, oldPath)
+	if err != nil {
+		log.Printf("  No se pudo liberar el objeto anterior %s: %v", oldPath, err)
+	}
+
+	if metadata := extractMetadata(stripped); metadata != nil {
+		metadata.ImageID = image.ID
+		err = uc.metadataRepo.Save(metadata)
+	} else {
+		err = uc.metadataRepo.Delete(image.ID)
+	}
+	if err != nil {
+		log.Printf("  No se pudieron actualizar los metadatos de la imagen %d: %v", image.ID, err)
+	}
+
+	if removed && uc.cache != nil {
+		if err := uc.cache.Invalidate(ctx, oldPath); err != nil {
+			log.Printf("  No se pudieron eliminar los derivados de %s: %v", oldPath, err)
+		}
+	}
+
+	return output, nil
+}
+
+// GPSAuditItem es una imagen que todavía contiene su posición de captura
+type GPSAuditItem struct {
+	ID   int64
+	URL  string
+	Name string
+	// Latitude y Longitude son nil cuando la posición solo está en XMP
+	Latitude  *float64
+	Longitude *float64
+}
+
+// GPSAuditOutput representa el resultado de la auditoría
+type GPSAuditOutput struct {
+	Scanned int
+	Images  []GPSAuditItem
+}
+
+// AuditGPS revisa los archivos guardados del usuario y lista los que
+// contienen coordenadas. Se leen los bytes reales y no los metadatos de la
+// base, que no existen para las imágenes anteriores a su extracción.
+func (uc *PrivacyUseCase) AuditGPS(ctx context.Context, userName string) (*GPSAuditOutput, error) {
+	const pageSize = 100
+
+	output := &GPSAuditOutput{Images: []GPSAuditItem{}}
+	for page := 1; ; page++ {
+		images, total, err := uc.imageRepo.FindByUser(userName, page, pageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, image := range images {
+			output.Scanned++
+			head, err := uc.readObject(ctx, image.Path, gpsAuditBytes)
+			if err != nil {
+				log.Printf("  Auditoría GPS: no se pudo leer %s: %v", image.Path, err)
+				continue
+			}
+			if !exif.ContainsGPS(head) {
+				continue
+			}
+
+			item := GPSAuditItem{
+				ID:   image.ID,
+				URL:  fmt.Sprintf("%s:%s/images/%s/%s", uc.baseURL, uc.port, image.UserName, image.Name),
+				Name: image.Name,
+			}
+			if parsed, err := exif.Parse(head); err == nil && parsed.GPS != nil {
+				item.Latitude = &parsed.GPS.Latitude
+				item.Longitude = &parsed.GPS.Longitude
+			}
+			output.Images = append(output.Images, item)
+		}
+
+		if len(images) < pageSize || int64(page*pageSize) >= total {
+			return output, nil
+		}
+	}
+}
+
+// readObject lee un objeto completo, o sus primeros limit bytes si limit es
+// positivo
+func (uc *PrivacyUseCase) readObject(ctx context.Context, path string, limit int64) ([]byte, error) {
+	reader, err := uc.fileStorage.Get(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("error al leer la imagen: %w", err)
+	}
+	defer reader.Close()
+
+	src := io.Reader(reader)
+	if limit > 0 {
+		src = io.LimitReader(reader, limit)
+	}
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return nil, fmt.Errorf("error al leer la imagen: %w", err)
+	}
+	return data, nil
+}
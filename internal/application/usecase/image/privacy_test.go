@@ -0,0 +1,192 @@
+package image_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/RodrigoGonzalez78/internal/application/usecase/image"
+	"github.com/RodrigoGonzalez78/internal/domain/entity"
+	"github.com/RodrigoGonzalez78/internal/domain/repository/mocks"
+	"github.com/RodrigoGonzalez78/internal/pkg/exif"
+)
+
+type privacyFixture struct {
+	*uploadFixture
+	userRepo  *mocks.MockUserRepository
+	privacyUC *image.PrivacyUseCase
+}
+
+func setupPrivacy(t *testing.T) *privacyFixture {
+	t.Helper()
+	f := &privacyFixture{
+		uploadFixture: setupUpload(image.ImageLimits{}),
+		userRepo:      mocks.NewMockUserRepository(),
+	}
+	f.userRepo.Users["testuser"] = entity.NewUser("testuser", "hash")
+	f.privacyUC = image.NewPrivacyUseCase(f.userRepo, f.imageRepo, f.metadataRepo, f.storage, f.blobs, nil, "http://localhost", "8080")
+	return f
+}
+
+// upload sube la foto con GPS aplicando la política indicada
+func (f *privacyFixture) upload(t *testing.T, name string, policy image.MetadataPolicy) {
+	t.Helper()
+	_, err := f.uploadUC.Execute(context.Background(), image.UploadInput{
+		FileName: name, UserName: "testuser", Data: phoneJPEG(t, phoneEXIF),
+		ContentType: "image/jpeg", Format: "jpeg", MetadataPolicy: policy,
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+}
+
+func TestPrivacyUseCase_Policy(t *testing.T) {
+	f := setupPrivacy(t)
+
+	if got, err := f.privacyUC.GetPolicy("testuser"); err != nil || got != image.MetadataKeep {
+		t.Fatalf("GetPolicy() = %q, %v; want keep por defecto", got, err)
+	}
+	if _, err := f.privacyUC.SetPolicy("testuser", "borrar"); !errors.Is(err, image.ErrInvalidMetadataPolicy) {
+		t.Errorf("SetPolicy(borrar) error = %v, want ErrInvalidMetadataPolicy", err)
+	}
+	if _, err := f.privacyUC.SetPolicy("testuser", "strip_gps"); err != nil {
+		t.Fatalf("SetPolicy() error = %v", err)
+	}
+
+	tests := []struct {
+		requested string
+		want      image.MetadataPolicy
+		wantErr   bool
+	}{
+		{requested: "", want: image.MetadataStripGPS},
+		{requested: "keep", want: image.MetadataKeep},
+		{requested: "strip_all", want: image.MetadataStripAll},
+		{requested: "todo", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := f.privacyUC.ResolvePolicy("testuser", tt.requested)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ResolvePolicy(%q) = %q, %v; want %q", tt.requested, got, err, tt.want)
+		}
+	}
+}
+
+func TestUploadUseCase_MetadataPolicy(t *testing.T) {
+	tests := []struct {
+		policy     image.MetadataPolicy
+		wantGPS    bool
+		wantCamera bool
+	}{
+		{policy: image.MetadataKeep, wantGPS: true, wantCamera: true},
+		{policy: image.MetadataStripGPS, wantGPS: false, wantCamera: true},
+		{policy: image.MetadataStripAll, wantGPS: false, wantCamera: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			f := setupPrivacy(t)
+			f.upload(t, "foto.jpg", tt.policy)
+
+			stored := f.fileStorage.Files[f.imageRepo.Images[1].Path]
+			parsed, err := exif.Parse(stored)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if (parsed.GPS != nil) != tt.wantGPS || (parsed.Make != "") != tt.wantCamera {
+				t.Errorf("archivo guardado: GPS = %v, cámara = %q", parsed.GPS, parsed.Make)
+			}
+			// La orientación siempre se conserva
+			if parsed.Orientation != 6 {
+				t.Errorf("Orientation = %d, want 6", parsed.Orientation)
+			}
+			if got := f.imageRepo.Images[1].Size; got != int64(len(stored)) {
+				t.Errorf("Size = %d, want %d", got, len(stored))
+			}
+
+			metadata := f.metadataRepo.Metadata[1]
+			if metadata == nil || (metadata.Latitude != nil) != tt.wantGPS {
+				t.Errorf("metadatos guardados = %+v", metadata)
+			}
+		})
+	}
+}
+
+func TestPrivacyUseCase_StripStored(t *testing.T) {
+	f := setupPrivacy(t)
+	f.upload(t, "foto.jpg", image.MetadataKeep)
+	originalPath := f.imageRepo.Images[1].Path
+	original := len(f.fileStorage.Files[originalPath])
+
+	if _, err := f.privacyUC.StripStored(context.Background(), image.StripMetadataInput{ImageID: 1, UserName: "otro"}); err == nil {
+		t.Error("StripStored() de otro usuario se esperaba error")
+	}
+	if _, err := f.privacyUC.StripStored(context.Background(), image.StripMetadataInput{ImageID: 1, UserName: "testuser", Policy: "keep"}); !errors.Is(err, image.ErrInvalidMetadataPolicy) {
+		t.Errorf("StripStored(keep) error = %v, want ErrInvalidMetadataPolicy", err)
+	}
+
+	output, err := f.privacyUC.StripStored(context.Background(), image.StripMetadataInput{ImageID: 1, UserName: "testuser", Policy: "strip_gps"})
+	if err != nil {
+		t.Fatalf("StripStored() error = %v", err)
+	}
+	stored := f.fileStorage.Files[f.imageRepo.Images[1].Path]
+	if !output.Changed || output.OldSize != int64(original) || output.NewSize != int64(len(stored)) {
+		t.Errorf("StripStored() = %+v", output)
+	}
+	if exif.ContainsGPS(stored) || f.metadataRepo.Metadata[1].Latitude != nil {
+		t.Error("la posición sigue en el archivo o en los metadatos guardados")
+	}
+	// El resultado es un blob nuevo y el anterior ya no lo usa nadie
+	if _, exists := f.fileStorage.Files[originalPath]; exists {
+		t.Error("el blob anterior debe eliminarse")
+	}
+	if f.metadataRepo.Metadata[1].CameraMake != "Apple" {
+		t.Error("strip_gps no debe quitar los datos de la cámara")
+	}
+
+	// Sin política se quita todo; la segunda vez no queda nada que cambiar
+	output, err = f.privacyUC.StripStored(context.Background(), image.StripMetadataInput{ImageID: 1, UserName: "testuser"})
+	if err != nil || !output.Changed || output.Policy != image.MetadataStripAll {
+		t.Fatalf("StripStored() = %+v, %v", output, err)
+	}
+	if m := f.metadataRepo.Metadata[1]; m == nil || m.CameraMake != "" || m.Orientation != 6 {
+		t.Errorf("metadatos guardados = %+v, want solo la orientación", m)
+	}
+	if got := f.imageRepo.Images[1].Size; got != output.NewSize {
+		t.Errorf("Size = %d, want %d", got, output.NewSize)
+	}
+
+	output, err = f.privacyUC.StripStored(context.Background(), image.StripMetadataInput{ImageID: 1, UserName: "testuser"})
+	if err != nil || output.Changed {
+		t.Errorf("StripStored() repetido = %+v, %v; want sin cambios", output, err)
+	}
+}
+
+func TestPrivacyUseCase_AuditGPS(t *testing.T) {
+	f := setupPrivacy(t)
+	f.upload(t, "con-gps.jpg", image.MetadataKeep)
+	f.upload(t, "sin-gps.jpg", image.MetadataStripGPS)
+	f.upload(t, "limpia.jpg", image.MetadataStripAll)
+
+	output, err := f.privacyUC.AuditGPS(context.Background(), "testuser")
+	if err != nil {
+		t.Fatalf("AuditGPS() error = %v", err)
+	}
+	if output.Scanned != 3 || len(output.Images) != 1 {
+		t.Fatalf("AuditGPS() = %+v, want 1 de 3", output)
+	}
+	item := output.Images[0]
+	if item.ID != 1 || item.Name != "con-gps.jpg" || item.URL != "http://localhost:8080/images/testuser/con-gps.jpg" {
+		t.Errorf("item = %+v", item)
+	}
+	if item.Latitude == nil || *item.Latitude != -34.6 || item.Longitude == nil || *item.Longitude != -58.5 {
+		t.Errorf("coordenadas = %v, %v", item.Latitude, item.Longitude)
+	}
+
+	if _, err := f.privacyUC.StripStored(context.Background(), image.StripMetadataInput{ImageID: 1, UserName: "testuser", Policy: "strip_gps"}); err != nil {
+		t.Fatalf("StripStored() error = %v", err)
+	}
+	output, err = f.privacyUC.AuditGPS(context.Background(), "testuser")
+	if err != nil || len(output.Images) != 0 {
+		t.Errorf("AuditGPS() después de limpiar = %+v, %v", output, err)
+	}
+}
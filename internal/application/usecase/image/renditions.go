@@ -0,0 +1,287 @@
+package image
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/RodrigoGonzalez78/internal/domain/entity"
+	"github.com/RodrigoGonzalez78/internal/domain/repository"
+	"github.com/disintegration/imaging"
+	"github.com/google/uuid"
+)
+
+// RenditionOriginalFormat en la lista de formatos representa el formato de la
+// imagen subida
+const RenditionOriginalFormat = "original"
+
+// RenditionUseCase genera en segundo plano versiones redimensionadas de cada
+// imagen subida para que el frontend arme atributos srcset sin llamar a
+// transform. La cola vive en memoria: si el servidor se reinicia antes de
+// procesarla, esas imágenes quedan sin versiones.
+type RenditionUseCase struct {
+	imageRepo     repository.ImageRepository
+	renditionRepo repository.RenditionRepository
+	fileStorage   repository.FileStorage
+	limits        ImageLimits
+	widths        []int
+	formats       []string
+
+	queue chan int64
+}
+
+// NewRenditionUseCase crea una nueva instancia de RenditionUseCase. Los
+// anchos se ordenan y los formatos se validan contra los de transform.
+func NewRenditionUseCase(
+	imageRepo repository.ImageRepository,
+	renditionRepo repository.RenditionRepository,
+	fileStorage repository.FileStorage,
+	limits ImageLimits,
+	widths []int,
+	formats []string,
+	queueSize int,
+) (*RenditionUseCase, error) {
+	if len(widths) == 0 {
+		return nil, errors.New("se necesita al menos un ancho de versión")
+	}
+	sorted := make([]int, 0, len(widths))
+	seen := make(map[int]bool)
+	for _, width := range widths {
+		if width <= 0 {
+			return nil, fmt.Errorf("ancho de versión inválido: %d", width)
+		}
+		if err := limits.checkOutputSize(width, 0); err != nil {
+			return nil, fmt.Errorf("ancho de versión inválido: %d: %w", width, err)
+		}
+		if !seen[width] {
+			seen[width] = true
+			sorted = append(sorted, width)
+		}
+	}
+	sort.Ints(sorted)
+
+	if len(formats) == 0 {
+		return nil, errors.New("se necesita al menos un formato de versión")
+	}
+	for _, format := range formats {
+		if format == RenditionOriginalFormat {
+			continue
+		}
+		if _, err := resolveEncoding(format, EncodeOptions{}); err != nil {
+			return nil, fmt.Errorf("formato de versión inválido: %w", err)
+		}
+	}
+
+	if queueSize <= 0 {
+		queueSize = 1
+	}
+
+	return &RenditionUseCase{
+		imageRepo:     imageRepo,
+		renditionRepo: renditionRepo,
+		fileStorage:   fileStorage,
+		limits:        limits,
+		widths:        sorted,
+		formats:       formats,
+		queue:         make(chan int64, queueSize),
+	}, nil
+}
+
+// RenditionItem representa una versión lista para usar en un srcset
+type RenditionItem struct {
+	Width  int
+	Height int
+	Format string
+	URL    string
+	Size   int64
+}
+
+// Enqueue agrega la imagen a la cola sin bloquear la subida. Si la cola está
+// llena la imagen se queda sin versiones.
+func (uc *RenditionUseCase) Enqueue(imageID int64) {
+	select {
+	case uc.queue <- imageID:
+	default:
+		log.Printf("  Cola de versiones llena: la imagen %d queda sin versiones", imageID)
+	}
+}
+
+// Start lanza los workers y bloquea hasta que se cancele el contexto
+func (uc *RenditionUseCase) Start(ctx context.Context, workers int) {
+	if workers <= 0 {
+		return
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case id := <-uc.queue:
+					if err := uc.Generate(ctx, id); err != nil {
+						log.Printf("  No se pudieron generar las versiones de la imagen %d: %v", id, err)
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// Generate crea las versiones de una imagen y reemplaza las registradas. Solo
+// se generan los anchos menores que el original; el original ya cubre el
+// resto del srcset.
+func (uc *RenditionUseCase) Generate(ctx context.Context, imageID int64) error {
+	img, err := uc.imageRepo.FindByID(imageID)
+	if err != nil {
+		return fmt.Errorf("imagen no encontrada: %w", err)
+	}
+
+	src, err := uc.decodeOriginal(ctx, img.Path)
+	if err != nil {
+		return err
+	}
+
+	encodings, err := uc.encodings(img.Format)
+	if err != nil {
+		return err
+	}
+
+	previous, err := uc.renditionRepo.FindByImageID(img.ID)
+	if err != nil {
+		return fmt.Errorf("error al leer las versiones: %w", err)
+	}
+
+	// Cada generación va a una carpeta aleatoria: la ruta pública de una
+	// versión no se puede deducir del ID de la imagen
+	prefix := renditionPrefix(img.UserName, img.ID) + uuid.New().String() + "/"
+	var renditions []entity.Rendition
+	for _, width := range uc.widths {
+		if width >= src.Bounds().Dx() {
+			break
+		}
+		resized := imaging.Resize(src, width, 0, imaging.Lanczos)
+
+		for _, enc := range encodings {
+			var buf bytes.Buffer
+			if err := enc.encode(&buf, resized); err != nil {
+				uc.discard(ctx, prefix)
+				return fmt.Errorf("error al codificar la versión de %dpx en %s: %w", width, enc.Name, err)
+			}
+
+			objectPath := fmt.Sprintf("%s%d.%s", prefix, width, enc.Extension)
+			if err := uc.fileStorage.Upload(ctx, objectPath, bytes.NewReader(buf.Bytes()), int64(buf.Len()), enc.ContentType); err != nil {
+				uc.discard(ctx, prefix)
+				return fmt.Errorf("error al guardar la versión: %w", err)
+			}
+
+			renditions = append(renditions, *entity.NewRendition(
+				img.ID,
+				resized.Bounds().Dx(),
+				resized.Bounds().Dy(),
+				enc.Name,
+				objectPath,
+				int64(buf.Len()),
+			))
+		}
+	}
+
+	saved, err := uc.renditionRepo.ReplaceForImage(img.ID, renditions)
+	if err != nil {
+		uc.discard(ctx, prefix)
+		return fmt.Errorf("error al registrar las versiones: %w", err)
+	}
+	// La imagen se borró mientras se generaban las versiones. Su eliminación
+	// pudo limpiar el prefijo antes de que se subieran, así que se limpia de
+	// nuevo.
+	if !saved {
+		uc.discard(ctx, prefix)
+		return nil
+	}
+
+	// Las versiones de una generación anterior ya no están registradas
+	for _, rendition := range previous {
+		if err := uc.fileStorage.Delete(context.WithoutCancel(ctx), rendition.Path); err != nil {
+			log.Printf("  No se pudo eliminar la versión anterior %s: %v", rendition.Path, err)
+		}
+	}
+	return nil
+}
+
+func (uc *RenditionUseCase) decodeOriginal(ctx context.Context, objectPath string) (image.Image, error) {
+	reader, err := uc.fileStorage.Get(ctx, objectPath)
+	if err != nil {
+		return nil, fmt.Errorf("error al leer la imagen: %w", err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("error al leer la imagen: %w", err)
+	}
+	return uc.limits.decode(data)
+}
+
+// encodings resuelve los formatos configurados para una imagen, sin repetir
+// el original si también figura explícitamente
+func (uc *RenditionUseCase) encodings(original string) ([]*outputEncoding, error) {
+	var encodings []*outputEncoding
+	seen := make(map[string]bool)
+	for _, format := range uc.formats {
+		if format == RenditionOriginalFormat {
+			format = original
+		}
+		enc, err := resolveEncoding(format, EncodeOptions{})
+		if err != nil {
+			return nil, err
+		}
+		if !seen[enc.Name] {
+			seen[enc.Name] = true
+			encodings = append(encodings, enc)
+		}
+	}
+	return encodings, nil
+}
+
+func (uc *RenditionUseCase) discard(ctx context.Context, prefix string) {
+	if err := uc.fileStorage.DeletePrefix(context.WithoutCancel(ctx), prefix); err != nil {
+		log.Printf("  No se pudieron eliminar las versiones de %s: %v", prefix, err)
+	}
+}
+
+// renditionPrefix agrupa las versiones de una imagen bajo la carpeta del
+// usuario, así se sirven por la misma ruta pública que el original. Generate
+// agrega debajo una carpeta aleatoria por generación.
+func renditionPrefix(userName string, imageID int64) string {
+	return fmt.Sprintf("%s/renditions/%d/", userName, imageID)
+}
+
+// listRenditions arma las URLs públicas de las versiones de una imagen
+func listRenditions(renditionRepo repository.RenditionRepository, image *entity.Image, baseURL, port string) ([]RenditionItem, error) {
+	renditions, err := renditionRepo.FindByImageID(image.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]RenditionItem, len(renditions))
+	for i, rendition := range renditions {
+		items[i] = RenditionItem{
+			Width:  rendition.Width,
+			Height: rendition.Height,
+			Format: rendition.Format,
+			URL:    fmt.Sprintf("%s:%s/images/%s", baseURL, port, rendition.Path),
+			Size:   rendition.Size,
+		}
+	}
+	return items, nil
+}
@@ -0,0 +1,230 @@
+package image_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	stdimage "image"
+
+	"github.com/RodrigoGonzalez78/internal/application/usecase/image"
+	"github.com/RodrigoGonzalez78/internal/domain/repository/mocks"
+)
+
+func TestNewRenditionUseCase_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		widths  []int
+		formats []string
+	}{
+		{name: "sin anchos", formats: []string{"original"}},
+		{name: "ancho negativo", widths: []int{320, -1}, formats: []string{"original"}},
+		{name: "ancho mayor al límite", widths: []int{20000}, formats: []string{"original"}},
+		{name: "sin formatos", widths: []int{320}},
+		{name: "formato desconocido", widths: []int{320}, formats: []string{"original", "avif"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := image.NewRenditionUseCase(mocks.NewMockImageRepository(), mocks.NewMockRenditionRepository(), mocks.NewMockFileStorage(), image.DefaultImageLimits(), tt.widths, tt.formats, 10)
+			if err == nil {
+				t.Error("NewRenditionUseCase() se esperaba error")
+			}
+		})
+	}
+}
+
+// renditionPathPattern es la forma de la ruta de una versión de la imagen 1:
+// una carpeta aleatoria entre el ID y el archivo
+var renditionPathPattern = regexp.MustCompile(`^testuser/renditions/1/[0-9a-f-]{36}/[0-9]+\.[a-z]+$`)
+
+func TestRenditionUseCase_Generate(t *testing.T) {
+	_, imageRepo, fileStorage := setupTransform(t)
+	renditionRepo := mocks.NewMockRenditionRepository()
+
+	// El original mide 400px: 400 y 800 no se generan; png se repite con original
+	renditionUC, err := image.NewRenditionUseCase(imageRepo, renditionRepo, fileStorage, image.DefaultImageLimits(), []int{800, 200, 100, 400, 200}, []string{"original", "webp", "png"}, 10)
+	if err != nil {
+		t.Fatalf("NewRenditionUseCase() error = %v", err)
+	}
+
+	if err := renditionUC.Generate(context.Background(), 1); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	getUC := image.NewGetUseCase(imageRepo, renditionRepo, "http://localhost", "8080")
+	output, err := getUC.Execute(image.GetInput{ImageID: 1, UserName: "testuser"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	want := []struct {
+		width, height int
+		format, path  string
+	}{
+		{100, 75, "png", "100.png"},
+		{100, 75, "webp", "100.webp"},
+		{200, 150, "png", "200.png"},
+		{200, 150, "webp", "200.webp"},
+	}
+	if len(output.Renditions) != len(want) {
+		t.Fatalf("Renditions = %+v, want %d", output.Renditions, len(want))
+	}
+	for i, w := range want {
+		got := output.Renditions[i]
+		objectPath := strings.TrimPrefix(got.URL, "http://localhost:8080/images/")
+		if got.Width != w.width || got.Height != w.height || got.Format != w.format || !renditionPathPattern.MatchString(objectPath) || path.Base(objectPath) != w.path {
+			t.Errorf("Renditions[%d] = %+v, want %dx%d %s %s", i, got, w.width, w.height, w.format, w.path)
+		}
+
+		data, exists := fileStorage.Files[objectPath]
+		if !exists || got.Size != int64(len(data)) {
+			t.Errorf("objeto %s: existe = %v, tamaño = %d, want %d", objectPath, exists, len(data), got.Size)
+			continue
+		}
+		if w.format == "webp" {
+			if !bytes.HasPrefix(data, []byte("RIFF")) {
+				t.Errorf("%s no es WebP", w.path)
+			}
+			continue
+		}
+		config, _, err := stdimage.DecodeConfig(bytes.NewReader(data))
+		if err != nil || config.Width != w.width || config.Height != w.height {
+			t.Errorf("%s: %dx%d, %v", w.path, config.Width, config.Height, err)
+		}
+	}
+
+	// Al borrar la imagen también se borran sus versiones
+	deleteUC := image.NewDeleteUseCase(imageRepo, nil, fileStorage, image.NewBlobStore(mocks.NewMockBlobRepository(), fileStorage), nil)
+	if _, err := deleteUC.Execute(context.Background(), image.DeleteInput{ImageID: 1, UserName: "testuser"}); err != nil {
+		t.Fatalf("Execute() de eliminación error = %v", err)
+	}
+	for path := range fileStorage.Files {
+		if strings.HasPrefix(path, "testuser/renditions/") {
+			t.Errorf("quedó la versión %s en el storage", path)
+		}
+	}
+}
+
+func TestRenditionUseCase_GeneratedAfterUpload(t *testing.T) {
+	imageRepo := mocks.NewMockImageRepository()
+	renditionRepo := mocks.NewMockRenditionRepository()
+	fileStorage := mocks.NewMockFileStorage()
+
+	renditionUC, err := image.NewRenditionUseCase(imageRepo, renditionRepo, fileStorage, image.DefaultImageLimits(), []int{320}, []string{"original"}, 10)
+	if err != nil {
+		t.Fatalf("NewRenditionUseCase() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go renditionUC.Start(ctx, 1)
+
+	uploadUC := image.NewUploadUseCase(imageRepo, mocks.NewMockImageMetadataRepository(), image.NewBlobStore(mocks.NewMockBlobRepository(), fileStorage), renditionUC, image.ImageLimits{}, "http://localhost", "8080")
+	_, err = uploadUC.Execute(context.Background(), image.UploadInput{
+		FileName: "foto.jpg", UserName: "testuser", Data: encodeTestJPEG(t, 640, 480),
+		ContentType: "image/jpeg", Format: "jpeg", Width: 640, Height: 480,
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		renditions, _ := renditionRepo.FindByImageID(1)
+		if len(renditions) == 1 {
+			if r := renditions[0]; r.Width != 320 || r.Height != 240 || r.Format != "jpeg" || !renditionPathPattern.MatchString(r.Path) || path.Base(r.Path) != "320.jpg" {
+				t.Errorf("versión = %+v", r)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("no se generaron las versiones: %+v", renditions)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRenditionUseCase_ImageDeletedWhileGenerating(t *testing.T) {
+	_, imageRepo, fileStorage := setupTransform(t)
+	renditionRepo := mocks.NewMockRenditionRepository()
+	renditionRepo.Images = imageRepo
+	deleteUC := image.NewDeleteUseCase(imageRepo, nil, fileStorage, image.NewBlobStore(mocks.NewMockBlobRepository(), fileStorage), nil)
+
+	// La imagen se elimina por completo justo antes de subir la versión, así
+	// que la limpieza del prefijo ya pasó cuando el objeto llega al storage
+	storage := &deletingStorage{MockFileStorage: fileStorage, delete: func() {
+		if _, err := deleteUC.Execute(context.Background(), image.DeleteInput{ImageID: 1, UserName: "testuser"}); err != nil {
+			t.Errorf("Execute() de eliminación error = %v", err)
+		}
+	}}
+	renditionUC, err := image.NewRenditionUseCase(imageRepo, renditionRepo, storage, image.DefaultImageLimits(), []int{100}, []string{"original"}, 10)
+	if err != nil {
+		t.Fatalf("NewRenditionUseCase() error = %v", err)
+	}
+
+	if err := renditionUC.Generate(context.Background(), 1); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if renditions, _ := renditionRepo.FindByImageID(1); len(renditions) != 0 {
+		t.Errorf("versiones registradas de una imagen borrada: %+v", renditions)
+	}
+	for path := range fileStorage.Files {
+		if strings.HasPrefix(path, "testuser/renditions/") {
+			t.Errorf("quedó la versión %s en el storage", path)
+		}
+	}
+}
+
+// deletingStorage ejecuta delete antes de la primera subida
+type deletingStorage struct {
+	*mocks.MockFileStorage
+	delete func()
+	once   sync.Once
+}
+
+func (s *deletingStorage) Upload(ctx context.Context, path string, reader io.Reader, size int64, contentType string) error {
+	s.once.Do(s.delete)
+	return s.MockFileStorage.Upload(ctx, path, reader, size, contentType)
+}
+
+func TestRenditionUseCase_UnguessablePaths(t *testing.T) {
+	_, imageRepo, fileStorage := setupTransform(t)
+	renditionRepo := mocks.NewMockRenditionRepository()
+	renditionUC, err := image.NewRenditionUseCase(imageRepo, renditionRepo, fileStorage, image.DefaultImageLimits(), []int{100}, []string{"original"}, 10)
+	if err != nil {
+		t.Fatalf("NewRenditionUseCase() error = %v", err)
+	}
+
+	var paths []string
+	for i := 0; i < 2; i++ {
+		if err := renditionUC.Generate(context.Background(), 1); err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		renditions, _ := renditionRepo.FindByImageID(1)
+		if len(renditions) != 1 {
+			t.Fatalf("versiones = %+v, want 1", renditions)
+		}
+		paths = append(paths, renditions[0].Path)
+	}
+
+	// La ruta que se armaría con el ID y el ancho no existe
+	if _, exists := fileStorage.Files["testuser/renditions/1/100.png"]; exists {
+		t.Error("la versión se puede leer en una ruta deducida del ID de la imagen")
+	}
+	if paths[0] == paths[1] {
+		t.Errorf("dos generaciones usaron la misma ruta %s", paths[0])
+	}
+	// Regenerar borra los objetos de la generación anterior
+	if _, exists := fileStorage.Files[paths[0]]; exists {
+		t.Errorf("quedó la versión anterior %s", paths[0])
+	}
+	if _, exists := fileStorage.Files[paths[1]]; !exists {
+		t.Errorf("no existe la versión registrada %s", paths[1])
+	}
+}
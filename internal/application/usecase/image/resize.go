@@ -0,0 +1,226 @@
+package image
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+const (
+	defaultResizeMode    = "exact"
+	defaultResizeGravity = "center"
+	defaultResizeFilter  = "lanczos"
+)
+
+// resizeModes normaliza los modos admitidos; cover y pad son alias de fill
+// y contain
+var resizeModes = map[string]string{
+	"exact":   "exact",
+	"fit":     "fit",
+	"fill":    "fill",
+	"cover":   "fill",
+	"contain": "contain",
+	"pad":     "contain",
+}
+
+// resizeGravities parte de la imagen que se conserva al recortar en fill
+var resizeGravities = map[string]imaging.Anchor{
+	"top-left":     imaging.TopLeft,
+	"top":          imaging.Top,
+	"top-right":    imaging.TopRight,
+	"left":         imaging.Left,
+	"center":       imaging.Center,
+	"right":        imaging.Right,
+	"bottom-left":  imaging.BottomLeft,
+	"bottom":       imaging.Bottom,
+	"bottom-right": imaging.BottomRight,
+}
+
+var resizeFilters = map[string]imaging.ResampleFilter{
+	"lanczos":    imaging.Lanczos,
+	"catmullrom": imaging.CatmullRom,
+	"box":        imaging.Box,
+	"nearest":    imaging.NearestNeighbor,
+}
+
+// resizeOptions parámetros de resize ya validados
+type resizeOptions struct {
+	width      int
+	height     int
+	mode       string
+	gravity    string
+	background color.NRGBA
+	filter     imaging.ResampleFilter
+	noUpscale  bool
+}
+
+func parseResizeOptions(params OperationParams) (resizeOptions, error) {
+	var opts resizeOptions
+	var err error
+
+	if opts.width, err = params.Int("width", 0); err != nil {
+		return opts, err
+	}
+	if opts.height, err = params.Int("height", 0); err != nil {
+		return opts, err
+	}
+	if opts.width < 0 {
+		return opts, &FieldError{Field: "width", Message: "no puede ser negativo"}
+	}
+	if opts.height < 0 {
+		return opts, &FieldError{Field: "height", Message: "no puede ser negativo"}
+	}
+	if opts.width == 0 && opts.height == 0 {
+		return opts, &FieldError{Field: "width", Message: "se requiere width, height o ambos"}
+	}
+
+	mode, err := params.String("mode", defaultResizeMode)
+	if err != nil {
+		return opts, err
+	}
+	var ok bool
+	if opts.mode, ok = resizeModes[mode]; !ok {
+		return opts, &FieldError{Field: "mode", Message: "debe ser exact, fit, fill, cover, contain o pad"}
+	}
+	if opts.mode == "fill" || opts.mode == "contain" {
+		if opts.width == 0 {
+			return opts, &FieldError{Field: "width", Message: "es requerido con mode=" + mode}
+		}
+		if opts.height == 0 {
+			return opts, &FieldError{Field: "height", Message: "es requerido con mode=" + mode}
+		}
+	}
+
+	if opts.gravity, err = params.String("gravity", defaultResizeGravity); err != nil {
+		return opts, err
+	}
+	if _, ok := resizeGravities[opts.gravity]; !ok {
+		return opts, &FieldError{Field: "gravity", Message: "debe ser top-left, top, top-right, left, center, right, bottom-left, bottom o bottom-right"}
+	}
+
+	background, err := params.String("background", "")
+	if err != nil {
+		return opts, err
+	}
+	if background != "" {
+		if opts.background, err = parseHexColor("background", background); err != nil {
+			return opts, err
+		}
+	}
+
+	filter, err := params.String("filter", defaultResizeFilter)
+	if err != nil {
+		return opts, err
+	}
+	if opts.filter, ok = resizeFilters[filter]; !ok {
+		return opts, &FieldError{Field: "filter", Message: "debe ser lanczos, catmullrom, box o nearest"}
+	}
+
+	if opts.noUpscale, err = params.Bool("no_upscale", false); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+func validateResize(params OperationParams) error {
+	_, err := parseResizeOptions(params)
+	return err
+}
+
+// resizeGeometry calcula el modo efectivo y el tamaño al que se escala la
+// imagen antes de recortarla (fill) o centrarla en el lienzo (contain)
+func resizeGeometry(opts resizeOptions, src image.Point) (string, image.Point) {
+	mode := opts.mode
+	if opts.width == 0 || opts.height == 0 {
+		mode = "fit"
+	}
+
+	scaleX := float64(opts.width) / float64(src.X)
+	scaleY := float64(opts.height) / float64(src.Y)
+
+	var scale float64
+	switch {
+	case opts.width == 0:
+		scale = scaleY
+	case opts.height == 0:
+		scale = scaleX
+	case mode == "fill":
+		scale = math.Max(scaleX, scaleY)
+	default:
+		scale = math.Min(scaleX, scaleY)
+	}
+	if opts.noUpscale && scale > 1 {
+		scale = 1
+	}
+	scaled := image.Pt(scaledSize(src.X, scale), scaledSize(src.Y, scale))
+
+	if mode == "exact" {
+		scaled = image.Pt(opts.width, opts.height)
+		if opts.noUpscale {
+			scaled = image.Pt(min(scaled.X, src.X), min(scaled.Y, src.Y))
+		}
+	}
+	return mode, scaled
+}
+
+// resizedSize devuelve el tamaño final de resize sobre una imagen de src
+func resizedSize(opts resizeOptions, src image.Point) image.Point {
+	if src.X == 0 || src.Y == 0 {
+		return src
+	}
+	mode, scaled := resizeGeometry(opts, src)
+	switch mode {
+	case "fill":
+		return image.Pt(min(opts.width, scaled.X), min(opts.height, scaled.Y))
+	case "contain":
+		return image.Pt(opts.width, opts.height)
+	}
+	return scaled
+}
+
+// resizeApplier devuelve la operación resize. Con una sola dimensión la otra
+// se calcula según la proporción, así que el tamaño final se verifica contra
+// los límites recién al conocer la imagen.
+func resizeApplier(limits ImageLimits) ApplyFunc {
+	return func(ctx context.Context, img image.Image, params OperationParams) (image.Image, error) {
+		opts, err := parseResizeOptions(params)
+		if err != nil {
+			return nil, err
+		}
+
+		src := img.Bounds().Size()
+		if src.X == 0 || src.Y == 0 {
+			return img, nil
+		}
+
+		mode, scaled := resizeGeometry(opts, src)
+		if err := limits.checkOutputSize(scaled.X, scaled.Y); err != nil {
+			return nil, err
+		}
+
+		resized := img
+		if scaled != src {
+			resized = imaging.Resize(img, scaled.X, scaled.Y, opts.filter)
+		}
+
+		switch mode {
+		case "fill":
+			// Sin agrandar la imagen puede quedar más chica que la caja pedida
+			width, height := min(opts.width, scaled.X), min(opts.height, scaled.Y)
+			return imaging.CropAnchor(resized, width, height, resizeGravities[opts.gravity]), nil
+		case "contain":
+			canvas := imaging.New(opts.width, opts.height, opts.background)
+			position := anchorPosition(opts.gravity, canvas.Bounds().Size(), scaled, 0)
+			return imaging.Overlay(canvas, resized, position, 1), nil
+		}
+		return resized, nil
+	}
+}
+
+// scaledSize escala una dimensión sin bajar de un píxel
+func scaledSize(size int, scale float64) int {
+	return max(1, int(math.Round(float64(size)*scale)))
+}
@@ -0,0 +1,133 @@
+package image_test
+
+import (
+	"errors"
+	stdimage "image"
+	"image/color"
+	"testing"
+
+	"github.com/RodrigoGonzalez78/internal/application/usecase/image"
+)
+
+// stripedImage crea una imagen de 400x200 con la mitad izquierda roja y la
+// derecha azul
+func stripedImage() *stdimage.NRGBA {
+	img := stdimage.NewNRGBA(stdimage.Rect(0, 0, 400, 200))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 400; x++ {
+			c := color.NRGBA{R: 255, A: 255}
+			if x >= 200 {
+				c = color.NRGBA{B: 255, A: 255}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestOperationRegistry_ResizeModes(t *testing.T) {
+	tests := []struct {
+		name       string
+		params     image.OperationParams
+		wantWidth  int
+		wantHeight int
+	}{
+		{name: "exact deforma", params: image.OperationParams{"width": 100.0, "height": 100.0}, wantWidth: 100, wantHeight: 100},
+		{name: "solo ancho", params: image.OperationParams{"width": 100.0}, wantWidth: 100, wantHeight: 50},
+		{name: "solo alto", params: image.OperationParams{"height": 100.0}, wantWidth: 200, wantHeight: 100},
+		{name: "fit", params: image.OperationParams{"width": 100.0, "height": 100.0, "mode": "fit"}, wantWidth: 100, wantHeight: 50},
+		{name: "fit agranda", params: image.OperationParams{"width": 800.0, "height": 800.0, "mode": "fit"}, wantWidth: 800, wantHeight: 400},
+		{name: "fit sin agrandar", params: image.OperationParams{"width": 800.0, "height": 800.0, "mode": "fit", "no_upscale": true}, wantWidth: 400, wantHeight: 200},
+		{name: "exact sin agrandar", params: image.OperationParams{"width": 800.0, "height": 100.0, "no_upscale": true}, wantWidth: 400, wantHeight: 100},
+		{name: "fill", params: image.OperationParams{"width": 100.0, "height": 100.0, "mode": "fill"}, wantWidth: 100, wantHeight: 100},
+		{name: "cover sin agrandar", params: image.OperationParams{"width": 300.0, "height": 300.0, "mode": "cover", "no_upscale": true}, wantWidth: 300, wantHeight: 200},
+		{name: "contain", params: image.OperationParams{"width": 100.0, "height": 100.0, "mode": "contain"}, wantWidth: 100, wantHeight: 100},
+		{name: "filtro nearest", params: image.OperationParams{"width": 40.0, "filter": "nearest"}, wantWidth: 40, wantHeight: 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := runOperation(t, stripedImage(), image.Operation{Type: "resize", Params: tt.params})
+			if b := result.Bounds(); b.Dx() != tt.wantWidth || b.Dy() != tt.wantHeight {
+				t.Errorf("dimensiones = %dx%d, want %dx%d", b.Dx(), b.Dy(), tt.wantWidth, tt.wantHeight)
+			}
+		})
+	}
+}
+
+func TestOperationRegistry_ResizeGravityAndBackground(t *testing.T) {
+	red := color.NRGBA{R: 255, A: 255}
+	blue := color.NRGBA{B: 255, A: 255}
+	white := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	at := func(img stdimage.Image, x, y int) color.NRGBA {
+		return color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+	}
+
+	// fill recorta los costados; la gravedad elige qué mitad se conserva
+	left := runOperation(t, stripedImage(), image.Operation{Type: "resize", Params: image.OperationParams{
+		"width": 100.0, "height": 100.0, "mode": "fill", "gravity": "left",
+	}})
+	if c := at(left, 50, 50); c != red {
+		t.Errorf("fill con gravity=left = %v, want rojo", c)
+	}
+	right := runOperation(t, stripedImage(), image.Operation{Type: "resize", Params: image.OperationParams{
+		"width": 100.0, "height": 100.0, "mode": "fill", "gravity": "right",
+	}})
+	if c := at(right, 50, 50); c != blue {
+		t.Errorf("fill con gravity=right = %v, want azul", c)
+	}
+
+	// contain deja bandas del color de fondo arriba y abajo (100x50 centrado)
+	padded := runOperation(t, stripedImage(), image.Operation{Type: "resize", Params: image.OperationParams{
+		"width": 100.0, "height": 100.0, "mode": "pad", "background": "#fff",
+	}})
+	if top, bottom := at(padded, 50, 10), at(padded, 50, 90); top != white || bottom != white {
+		t.Errorf("bandas de contain = %v y %v, want blanco", top, bottom)
+	}
+	if c := at(padded, 10, 50); c != red {
+		t.Errorf("centro de contain = %v, want la imagen", c)
+	}
+
+	// Con gravity=top la imagen queda arriba y la banda abajo es transparente
+	top := runOperation(t, stripedImage(), image.Operation{Type: "resize", Params: image.OperationParams{
+		"width": 100.0, "height": 100.0, "mode": "contain", "gravity": "top",
+	}})
+	if c := at(top, 10, 10); c != red {
+		t.Errorf("contain con gravity=top arriba = %v, want rojo", c)
+	}
+	if c := at(top, 10, 90); c.A != 0 {
+		t.Errorf("contain sin background abajo = %v, want transparente", c)
+	}
+}
+
+func TestOperationRegistry_ResizeErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    image.OperationParams
+		wantField string
+	}{
+		{name: "sin dimensiones", params: image.OperationParams{}, wantField: "width"},
+		{name: "alto negativo", params: image.OperationParams{"width": 10.0, "height": -1.0}, wantField: "height"},
+		{name: "modo desconocido", params: image.OperationParams{"width": 10.0, "mode": "stretch"}, wantField: "mode"},
+		{name: "fill sin alto", params: image.OperationParams{"width": 10.0, "mode": "fill"}, wantField: "height"},
+		{name: "pad sin ancho", params: image.OperationParams{"height": 10.0, "mode": "pad"}, wantField: "width"},
+		{name: "gravedad inválida", params: image.OperationParams{"width": 10.0, "gravity": "norte"}, wantField: "gravity"},
+		{name: "fondo inválido", params: image.OperationParams{"width": 10.0, "background": "blanco"}, wantField: "background"},
+		{name: "filtro desconocido", params: image.OperationParams{"width": 10.0, "filter": "bicubic"}, wantField: "filter"},
+		{name: "no_upscale no booleano", params: image.OperationParams{"width": 10.0, "no_upscale": "si"}, wantField: "no_upscale"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := image.NewOperationRegistry().Validate([]image.Operation{{Type: "resize", Params: tt.params}})
+
+			var fieldErr *image.FieldError
+			if !errors.As(err, &fieldErr) {
+				t.Fatalf("Validate() error = %v, want FieldError", err)
+			}
+			if fieldErr.Field != tt.wantField {
+				t.Errorf("FieldError.Field = %v, want %v", fieldErr.Field, tt.wantField)
+			}
+		})
+	}
+}
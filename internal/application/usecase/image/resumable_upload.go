@@ -0,0 +1,597 @@
+package image
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/RodrigoGonzalez78/internal/domain/entity"
+	"github.com/RodrigoGonzalez78/internal/domain/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUploadNotFound       = errors.New("subida no encontrada")
+	ErrUploadExpired        = errors.New("la subida venció")
+	ErrEmptyUpload          = errors.New("la subida no puede estar vacía")
+	ErrOffsetMismatch       = errors.New("el offset no coincide con lo recibido hasta ahora")
+	ErrUploadExceedsLength  = errors.New("el contenido supera el largo declarado de la subida")
+	ErrUnsupportedChecksum  = errors.New("algoritmo de checksum no soportado")
+	ErrPartChecksumMismatch = errors.New("el checksum no coincide con el contenido recibido")
+)
+
+// partContentType es el tipo con el que se guardan las partes recibidas
+const partContentType = "application/offset+octet-stream"
+
+// checksumAlgorithms son los algoritmos con los que el cliente puede pedir
+// que se verifique cada petición
+var checksumAlgorithms = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+}
+
+// ChecksumAlgorithms devuelve los algoritmos de checksum soportados
+func ChecksumAlgorithms() []string {
+	names := make([]string, 0, len(checksumAlgorithms))
+	for name := range checksumAlgorithms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsUploadPath indica si un objeto del storage es una parte de una subida
+// reanudable. Como las fuentes, comparten el storage pero no son públicas.
+func IsUploadPath(objectPath string) bool {
+	_, rest, _ := strings.Cut(objectPath, "/")
+	return strings.HasPrefix(rest, "uploads/")
+}
+
+func uploadPrefix(userName, id string) string {
+	return fmt.Sprintf("%s/uploads/%s/", userName, id)
+}
+
+func partPath(userName, id string, offset int64) string {
+	return uploadPrefix(userName, id) + strconv.FormatInt(offset, 10)
+}
+
+// ResumableUploadUseCase recibe subidas en varias peticiones, de modo que
+// una conexión cortada se retoma desde el último byte guardado. Cada petición
+// se guarda en el storage en partes de hasta partSize bytes; al completarse
+// la subida, las partes se leen en orden y pasan por UploadUseCase igual que
+// una subida multipart.
+type ResumableUploadUseCase struct {
+	uploadRepo  repository.ResumableUploadRepository
+	imageRepo   repository.ImageRepository
+	fileStorage repository.FileStorage
+	uploadUC    *UploadUseCase
+	limits      ImageLimits
+	expiration  time.Duration
+	partSize    int64
+	// Las peticiones sobre una misma subida se serializan; entre instancias
+	// las protege el avance condicional del repositorio. Cada subida tiene
+	// su propio candado para que una petición lenta no frene a las demás.
+	mu    sync.Mutex
+	locks map[string]*uploadLock
+}
+
+// uploadLock candado de una subida; refs cuenta las peticiones que lo usan
+// para borrarlo cuando ya nadie lo espera
+type uploadLock struct {
+	sync.Mutex
+	refs int
+}
+
+// NewResumableUploadUseCase crea una nueva instancia de ResumableUploadUseCase.
+// expiration es cuánto vive una subida desde su última petición.
+func NewResumableUploadUseCase(
+	uploadRepo repository.ResumableUploadRepository,
+	imageRepo repository.ImageRepository,
+	fileStorage repository.FileStorage,
+	uploadUC *UploadUseCase,
+	limits ImageLimits,
+	expiration time.Duration,
+	partSize int64,
+) *ResumableUploadUseCase {
+	return &ResumableUploadUseCase{
+		uploadRepo:  uploadRepo,
+		imageRepo:   imageRepo,
+		fileStorage: fileStorage,
+		uploadUC:    uploadUC,
+		limits:      limits,
+		expiration:  expiration,
+		partSize:    partSize,
+		locks:       make(map[string]*uploadLock),
+	}
+}
+
+// CreateResumableInput representa los datos de entrada para iniciar una
+// subida. Las opciones se aplican cuando la subida se completa.
+type CreateResumableInput struct {
+	UserName           string
+	Length             int64
+	Metadata           string
+	MetadataPolicy     MetadataPolicy
+	OnDuplicate        DuplicatePolicy
+	DuplicateThreshold int
+}
+
+// AppendInput representa una petición con contenido a partir de Offset
+type AppendInput struct {
+	ID       string
+	UserName string
+	Offset   int64
+	Body     io.Reader
+	// Checksum, si no es nil, debe coincidir con todo Body o la petición
+	// se descarta entera
+	Checksum *PartChecksum
+}
+
+// PartChecksum checksum del contenido de una petición
+type PartChecksum struct {
+	Algorithm string
+	Sum       []byte
+}
+
+// ResumableOutput representa el estado de una subida
+type ResumableOutput struct {
+	ID        string
+	Length    int64
+	Offset    int64
+	Metadata  string
+	ExpiresAt time.Time
+	// Image es la imagen creada al completarse; nil mientras falten datos
+	Image *UploadOutput
+}
+
+// Create registra una subida de length bytes
+func (uc *ResumableUploadUseCase) Create(input CreateResumableInput) (*ResumableOutput, error) {
+	if input.Length <= 0 {
+		return nil, ErrEmptyUpload
+	}
+	if err := uc.limits.CheckSize(input.Length); err != nil {
+		return nil, err
+	}
+
+	upload := entity.NewResumableUpload(uuid.New().String(), input.UserName, input.Length, time.Now().Add(uc.expiration))
+	upload.Metadata = input.Metadata
+	upload.MetadataPolicy = string(input.MetadataPolicy)
+	upload.OnDuplicate = string(input.OnDuplicate)
+	upload.DuplicateThreshold = input.DuplicateThreshold
+
+	if err := uc.uploadRepo.Create(upload); err != nil {
+		return nil, fmt.Errorf("error al registrar la subida: %w", err)
+	}
+	return uc.toOutput(upload), nil
+}
+
+// Get devuelve el estado de una subida del usuario
+func (uc *ResumableUploadUseCase) Get(id, userName string) (*ResumableOutput, error) {
+	upload, err := uc.findOwned(id, userName)
+	if err != nil {
+		return nil, err
+	}
+	return uc.toOutput(upload), nil
+}
+
+// Append guarda el contenido de una petición. Sin checksum se conserva lo
+// que llegó a guardarse aunque la conexión se corte. Cuando se recibe el
+// último byte se crea la imagen; si eso falla por un error pasajero, una
+// petición vacía con el offset final lo vuelve a intentar.
+func (uc *ResumableUploadUseCase) Append(ctx context.Context, input AppendInput) (*ResumableOutput, error) {
+	var verify hash.Hash
+	if input.Checksum != nil {
+		newHash, ok := checksumAlgorithms[input.Checksum.Algorithm]
+		if !ok {
+			return nil, ErrUnsupportedChecksum
+		}
+		verify = newHash()
+	}
+
+	defer uc.lock(input.ID)()
+
+	upload, err := uc.findOwned(input.ID, input.UserName)
+	if err != nil {
+		return nil, err
+	}
+	if input.Offset != upload.Offset {
+		return nil, ErrOffsetMismatch
+	}
+
+	if !upload.Completed() {
+		if err := uc.receive(ctx, upload, input.Body, verify, input.Checksum); err != nil {
+			return nil, err
+		}
+	}
+
+	if upload.Completed() && upload.ImageID == nil {
+		if err := uc.finish(ctx, upload); err != nil {
+			return nil, err
+		}
+	}
+	return uc.toOutput(upload), nil
+}
+
+// Terminate cancela una subida y borra lo recibido
+func (uc *ResumableUploadUseCase) Terminate(ctx context.Context, id, userName string) error {
+	defer uc.lock(id)()
+
+	upload, err := uc.findOwned(id, userName)
+	if err != nil && !errors.Is(err, ErrUploadExpired) {
+		return err
+	}
+	return uc.discard(ctx, upload)
+}
+
+// ExpireStale borra hasta limit subidas vencidas con sus partes y devuelve
+// cuántas se borraron
+func (uc *ResumableUploadUseCase) ExpireStale(ctx context.Context, limit int) (int, error) {
+	uploads, err := uc.uploadRepo.FindExpired(time.Now(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, candidate := range uploads {
+		if uc.expire(ctx, candidate.ID) {
+			expired++
+		}
+	}
+	return expired, nil
+}
+
+// StartJanitor borra las subidas vencidas cada interval hasta que se cancele
+// ctx
+func (uc *ResumableUploadUseCase) StartJanitor(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expired, err := uc.ExpireStale(ctx, 100)
+			if err != nil {
+				log.Printf("  Error al borrar subidas vencidas: %v", err)
+				continue
+			}
+			if expired > 0 {
+				log.Printf(" Subidas vencidas borradas: %d", expired)
+			}
+		}
+	}
+}
+
+// expire borra una subida si sigue vencida; una petición pudo extenderla
+// desde que se listó
+func (uc *ResumableUploadUseCase) expire(ctx context.Context, id string) bool {
+	defer uc.lock(id)()
+
+	upload, err := uc.uploadRepo.FindByID(id)
+	if err != nil || !time.Now().After(upload.ExpiresAt) {
+		return false
+	}
+	if err := uc.discard(ctx, upload); err != nil {
+		log.Printf("  No se pudo borrar la subida vencida %s: %v", id, err)
+		return false
+	}
+	return true
+}
+
+// receive guarda el cuerpo de una petición en partes y registra el avance
+func (uc *ResumableUploadUseCase) receive(ctx context.Context, upload *entity.ResumableUpload, body io.Reader, verify hash.Hash, checksum *PartChecksum) error {
+	from := upload.Offset
+	offset := from
+	reader := bufio.NewReader(io.LimitReader(body, upload.Length-from))
+
+	var added []int64
+	var readErr error
+	for offset < upload.Length {
+		if _, err := reader.Peek(1); err != nil {
+			if !errors.Is(err, io.EOF) {
+				readErr = err
+			}
+			break
+		}
+
+		counter := &byteCounter{}
+		sink := io.Writer(counter)
+		if verify != nil {
+			sink = io.MultiWriter(counter, verify)
+		}
+		part := io.TeeReader(io.LimitReader(reader, uc.partSize), sink)
+
+		// Con checksum, si la lectura se corta el storage no guarda la parte
+		// incompleta. Sin checksum el corte se entrega como el final de la
+		// parte, que se guarda con lo recibido aunque el cliente ya no esté,
+		// y el offset avanza hasta ahí.
+		uploadCtx := ctx
+		var cut *cutReader
+		if verify == nil {
+			cut = &cutReader{reader: part}
+			part = cut
+			uploadCtx = context.WithoutCancel(ctx)
+		}
+
+		path := partPath(upload.UserName, upload.ID, offset)
+		if err := uc.fileStorage.Upload(uploadCtx, path, part, repository.UnknownSize, partContentType); err != nil {
+			readErr = err
+			break
+		}
+		if cut != nil && cut.err != nil && counter.n == 0 {
+			if err := uc.fileStorage.Delete(uploadCtx, path); err != nil {
+				log.Printf("  No se pudo borrar la parte vacía %s: %v", path, err)
+			}
+			readErr = cut.err
+			break
+		}
+		added = append(added, offset)
+		offset += counter.n
+		if cut != nil && cut.err != nil {
+			readErr = cut.err
+			break
+		}
+	}
+
+	if readErr == nil && offset == upload.Length {
+		if n, _ := io.ReadFull(body, make([]byte, 1)); n > 0 {
+			readErr = ErrUploadExceedsLength
+		}
+	}
+	if readErr == nil && verify != nil && !bytes.Equal(verify.Sum(nil), checksum.Sum) {
+		readErr = ErrPartChecksumMismatch
+	}
+
+	// Con checksum la petición se acepta entera o no se acepta
+	if readErr != nil && (verify != nil || errors.Is(readErr, ErrUploadExceedsLength)) {
+		uc.deleteParts(ctx, upload, added)
+		return readErr
+	}
+	if len(added) == 0 && readErr != nil {
+		return readErr
+	}
+
+	upload.Offset = offset
+	upload.Parts = append(upload.Parts, added...)
+	upload.ExpiresAt = time.Now().Add(uc.expiration)
+
+	// Si otra instancia avanzó la subida, sus partes pueden tener los mismos
+	// nombres que las de esta petición, así que no se borran
+	advanced, err := uc.uploadRepo.Advance(upload, from)
+	if err != nil {
+		return fmt.Errorf("error al guardar el avance de la subida: %w", err)
+	}
+	if !advanced {
+		return ErrOffsetMismatch
+	}
+	return readErr
+}
+
+// cutReader entrega como io.EOF el error de lectura del cuerpo y lo guarda
+// en err, para que el storage termine la parte con lo leído hasta el corte
+type cutReader struct {
+	reader io.Reader
+	err    error
+}
+
+func (r *cutReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if err != nil && !errors.Is(err, io.EOF) {
+		r.err = err
+		err = io.EOF
+	}
+	return n, err
+}
+
+// finish lee las partes en orden y crea la imagen. Si el contenido se
+// rechaza la subida se borra, porque reintentarla no cambia nada. La imagen
+// se llama como la subida, así un reintento encuentra la que creó un intento
+// anterior y solo le falta registrarla.
+func (uc *ResumableUploadUseCase) finish(ctx context.Context, upload *entity.ResumableUpload) error {
+	parts := &partsReader{ctx: ctx, fileStorage: uc.fileStorage}
+	for _, offset := range upload.Parts {
+		parts.paths = append(parts.paths, partPath(upload.UserName, upload.ID, offset))
+	}
+	defer parts.Close()
+
+	body := bufio.NewReader(parts)
+	head, err := body.Peek(SniffLen)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("error al leer la subida: %w", err)
+	}
+	info, err := uc.limits.Sniff(head, upload.Length)
+	if err != nil {
+		uc.discardLogged(ctx, upload)
+		return err
+	}
+
+	fileName := upload.ID + "." + info.Extension
+	var imageID int64
+	if existing, err := uc.imageRepo.FindByName(upload.UserName, fileName); err == nil {
+		imageID = existing.ID
+	} else {
+		output, err := uc.uploadUC.Execute(ctx, UploadInput{
+			FileName:           fileName,
+			UserName:           upload.UserName,
+			Body:               body,
+			Size:               upload.Length,
+			ContentType:        info.ContentType,
+			Format:             info.Format,
+			MetadataPolicy:     MetadataPolicy(upload.MetadataPolicy),
+			OnDuplicate:        DuplicatePolicy(upload.OnDuplicate),
+			DuplicateThreshold: upload.DuplicateThreshold,
+		})
+		if err != nil {
+			if rejectsContent(err) {
+				uc.discardLogged(ctx, upload)
+			}
+			return err
+		}
+		imageID = output.ID
+	}
+
+	// Las partes se borran recién con la subida registrada como completa: si
+	// esto falla, el reintento vuelve a leerlas
+	if err := uc.uploadRepo.Complete(upload.ID, imageID); err != nil {
+		return fmt.Errorf("error al registrar la subida completa: %w", err)
+	}
+	upload.ImageID = &imageID
+
+	// El registro queda hasta vencer para que el cliente pueda consultar el
+	// resultado; las partes ya no hacen falta
+	if err := uc.fileStorage.DeletePrefix(ctx, uploadPrefix(upload.UserName, upload.ID)); err != nil {
+		log.Printf("  No se pudieron borrar las partes de la subida %s: %v", upload.ID, err)
+	}
+	return nil
+}
+
+// rejectsContent indica si la subida falló por su contenido y no por un
+// error pasajero
+func rejectsContent(err error) bool {
+	var limitErr *LimitError
+	var duplicateErr *DuplicateError
+	return errors.As(err, &limitErr) || errors.As(err, &duplicateErr) ||
+		errors.Is(err, ErrUnsupportedMediaType) || errors.Is(err, ErrCorruptImage) ||
+		errors.Is(err, ErrUnreadableMetadata)
+}
+
+// discard borra las partes y el registro de una subida
+func (uc *ResumableUploadUseCase) discard(ctx context.Context, upload *entity.ResumableUpload) error {
+	if err := uc.fileStorage.DeletePrefix(ctx, uploadPrefix(upload.UserName, upload.ID)); err != nil {
+		return fmt.Errorf("error al borrar las partes: %w", err)
+	}
+	if err := uc.uploadRepo.Delete(upload.ID); err != nil {
+		return fmt.Errorf("error al borrar la subida: %w", err)
+	}
+	return nil
+}
+
+func (uc *ResumableUploadUseCase) discardLogged(ctx context.Context, upload *entity.ResumableUpload) {
+	if err := uc.discard(ctx, upload); err != nil {
+		log.Printf("  No se pudo borrar la subida rechazada %s: %v", upload.ID, err)
+	}
+}
+
+// deleteParts borra las partes guardadas por una petición que no se aceptó
+func (uc *ResumableUploadUseCase) deleteParts(ctx context.Context, upload *entity.ResumableUpload, offsets []int64) {
+	for _, offset := range offsets {
+		path := partPath(upload.UserName, upload.ID, offset)
+		if err := uc.fileStorage.Delete(ctx, path); err != nil {
+			log.Printf("  No se pudo borrar la parte %s: %v", path, err)
+		}
+	}
+}
+
+// findOwned busca una subida vigente del usuario. La de otro usuario se
+// informa como inexistente.
+func (uc *ResumableUploadUseCase) findOwned(id, userName string) (*entity.ResumableUpload, error) {
+	upload, err := uc.uploadRepo.FindByID(id)
+	if err != nil || upload.UserName != userName {
+		return nil, ErrUploadNotFound
+	}
+	if time.Now().After(upload.ExpiresAt) {
+		return upload, ErrUploadExpired
+	}
+	return upload, nil
+}
+
+func (uc *ResumableUploadUseCase) toOutput(upload *entity.ResumableUpload) *ResumableOutput {
+	output := &ResumableOutput{
+		ID:        upload.ID,
+		Length:    upload.Length,
+		Offset:    upload.Offset,
+		Metadata:  upload.Metadata,
+		ExpiresAt: upload.ExpiresAt,
+	}
+	// La imagen pudo borrarse después de completarse la subida
+	if upload.ImageID != nil {
+		if image, err := uc.imageRepo.FindByID(*upload.ImageID); err == nil {
+			output.Image = uc.uploadUC.toOutput(image)
+		}
+	}
+	return output
+}
+
+// lock toma el candado de una subida y devuelve la función que lo libera
+func (uc *ResumableUploadUseCase) lock(id string) func() {
+	uc.mu.Lock()
+	l, ok := uc.locks[id]
+	if !ok {
+		l = &uploadLock{}
+		uc.locks[id] = l
+	}
+	l.refs++
+	uc.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		uc.mu.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(uc.locks, id)
+		}
+		uc.mu.Unlock()
+	}
+}
+
+// partsReader lee en orden las partes de una subida, abriendo cada una
+// recién cuando se termina la anterior
+type partsReader struct {
+	ctx         context.Context
+	fileStorage repository.FileStorage
+	paths       []string
+	current     io.ReadCloser
+}
+
+func (r *partsReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.paths) == 0 {
+				return 0, io.EOF
+			}
+			reader, err := r.fileStorage.Get(r.ctx, r.paths[0])
+			if err != nil {
+				return 0, fmt.Errorf("error al leer la parte %s: %w", r.paths[0], err)
+			}
+			r.current = reader
+			r.paths = r.paths[1:]
+		}
+
+		n, err := r.current.Read(p)
+		if errors.Is(err, io.EOF) {
+			r.current.Close()
+			r.current = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+func (r *partsReader) Close() error {
+	if r.current == nil {
+		return nil
+	}
+	return r.current.Close()
+}
@@ -0,0 +1,465 @@
+package image_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/RodrigoGonzalez78/internal/application/usecase/image"
+	"github.com/RodrigoGonzalez78/internal/domain/repository/mocks"
+)
+
+type resumableFixture struct {
+	*uploadFixture
+	uploadRepo  *mocks.MockResumableUploadRepository
+	resumableUC *image.ResumableUploadUseCase
+}
+
+// setupResumable usa partes de 64 bytes para que cada petición se guarde en
+// varias
+func setupResumable() *resumableFixture {
+	f := &resumableFixture{
+		uploadFixture: setupUpload(image.DefaultImageLimits()),
+		uploadRepo:    mocks.NewMockResumableUploadRepository(),
+	}
+	f.resumableUC = image.NewResumableUploadUseCase(f.uploadRepo, f.imageRepo, f.fileStorage, f.uploadUC, image.DefaultImageLimits(), time.Hour, 64)
+	return f
+}
+
+func (f *resumableFixture) create(t *testing.T, length int) *image.ResumableOutput {
+	t.Helper()
+	output, err := f.resumableUC.Create(image.CreateResumableInput{UserName: "testuser", Length: int64(length)})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	return output
+}
+
+func (f *resumableFixture) append(id string, offset int, data []byte) (*image.ResumableOutput, error) {
+	return f.resumableUC.Append(context.Background(), image.AppendInput{
+		ID: id, UserName: "testuser", Offset: int64(offset), Body: bytes.NewReader(data),
+	})
+}
+
+// partCount cuenta los objetos guardados como partes de subidas
+func (f *resumableFixture) partCount() int {
+	count := 0
+	for path := range f.fileStorage.Files {
+		if image.IsUploadPath(path) {
+			count++
+		}
+	}
+	return count
+}
+
+func TestResumableUpload_Complete(t *testing.T) {
+	f := setupResumable()
+	data := phoneJPEG(t, phoneEXIF)
+	upload := f.create(t, len(data))
+	half := len(data) / 2
+
+	output, err := f.append(upload.ID, 0, data[:half])
+	if err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if output.Offset != int64(half) || output.Image != nil {
+		t.Fatalf("Offset = %d, Image = %v; want %d sin imagen", output.Offset, output.Image, half)
+	}
+	if f.partCount() < 2 {
+		t.Errorf("partes = %d, want varias", f.partCount())
+	}
+
+	output, err = f.append(upload.ID, half, data[half:])
+	if err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if output.Image == nil {
+		t.Fatal("la subida completa no creó la imagen")
+	}
+
+	stored := f.imageRepo.Images[output.Image.ID]
+	if !bytes.Equal(f.fileStorage.Files[stored.Path], data) {
+		t.Error("el contenido guardado no coincide con el subido")
+	}
+	if stored.Width != 20 || stored.Height != 40 {
+		t.Errorf("dimensiones = %dx%d, want 20x40", stored.Width, stored.Height)
+	}
+	if f.partCount() != 0 {
+		t.Errorf("partes = %d, want 0 al completarse", f.partCount())
+	}
+
+	// Consultar o reenviar una subida completa devuelve la misma imagen
+	again, err := f.append(upload.ID, len(data), nil)
+	if err != nil || again.Image == nil || again.Image.ID != output.Image.ID {
+		t.Errorf("Append() vacío = %+v, %v; want la misma imagen", again, err)
+	}
+	if len(f.imageRepo.Images) != 1 {
+		t.Errorf("imágenes = %d, want 1", len(f.imageRepo.Images))
+	}
+}
+
+func TestResumableUpload_Create(t *testing.T) {
+	f := setupResumable()
+
+	if _, err := f.resumableUC.Create(image.CreateResumableInput{UserName: "testuser"}); !errors.Is(err, image.ErrEmptyUpload) {
+		t.Errorf("Create() vacío error = %v, want ErrEmptyUpload", err)
+	}
+
+	var limitErr *image.LimitError
+	_, err := f.resumableUC.Create(image.CreateResumableInput{UserName: "testuser", Length: 1 << 40})
+	if !errors.As(err, &limitErr) {
+		t.Errorf("Create() gigante error = %v, want LimitError", err)
+	}
+
+	upload := f.create(t, 100)
+	if _, err := f.resumableUC.Get(upload.ID, "otro"); !errors.Is(err, image.ErrUploadNotFound) {
+		t.Errorf("Get() de otro usuario error = %v, want ErrUploadNotFound", err)
+	}
+}
+
+func TestResumableUpload_AppendRejects(t *testing.T) {
+	data := phoneJPEG(t, phoneEXIF)
+
+	tests := []struct {
+		name       string
+		offset     int
+		body       []byte
+		checksum   *image.PartChecksum
+		wantErr    error
+		wantOffset int64
+	}{
+		{
+			name:    "offset distinto del recibido",
+			offset:  10,
+			body:    data[:10],
+			wantErr: image.ErrOffsetMismatch,
+		},
+		{
+			name:    "más bytes que el largo declarado",
+			body:    append(append([]byte{}, data...), 0),
+			wantErr: image.ErrUploadExceedsLength,
+		},
+		{
+			name:     "checksum que no coincide",
+			body:     data[:200],
+			checksum: &image.PartChecksum{Algorithm: "sha1", Sum: make([]byte, sha1.Size)},
+			wantErr:  image.ErrPartChecksumMismatch,
+		},
+		{
+			name:     "algoritmo de checksum desconocido",
+			body:     data[:200],
+			checksum: &image.PartChecksum{Algorithm: "crc32"},
+			wantErr:  image.ErrUnsupportedChecksum,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := setupResumable()
+			upload := f.create(t, len(data))
+
+			_, err := f.resumableUC.Append(context.Background(), image.AppendInput{
+				ID: upload.ID, UserName: "testuser", Offset: int64(tt.offset),
+				Body: bytes.NewReader(tt.body), Checksum: tt.checksum,
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Append() error = %v, want %v", err, tt.wantErr)
+			}
+
+			// Nada de la petición rechazada queda guardado
+			state, err := f.resumableUC.Get(upload.ID, "testuser")
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if state.Offset != tt.wantOffset {
+				t.Errorf("Offset = %d, want %d", state.Offset, tt.wantOffset)
+			}
+			if f.partCount() != 0 {
+				t.Errorf("partes = %d, want 0", f.partCount())
+			}
+		})
+	}
+}
+
+func TestResumableUpload_Checksum(t *testing.T) {
+	f := setupResumable()
+	data := phoneJPEG(t, phoneEXIF)
+	upload := f.create(t, len(data))
+
+	sum := sha1.Sum(data)
+	output, err := f.resumableUC.Append(context.Background(), image.AppendInput{
+		ID: upload.ID, UserName: "testuser", Body: bytes.NewReader(data),
+		Checksum: &image.PartChecksum{Algorithm: "sha1", Sum: sum[:]},
+	})
+	if err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if output.Image == nil {
+		t.Error("la subida con checksum correcto no creó la imagen")
+	}
+}
+
+func TestResumableUpload_KeepsPartialBody(t *testing.T) {
+	tests := []struct {
+		name string
+		// cancel indica si el corte también cancela el contexto de la
+		// petición, como cuando el cliente se desconecta
+		cancel bool
+	}{
+		{name: "error de lectura"},
+		{name: "cliente desconectado", cancel: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := setupResumable()
+			data := phoneJPEG(t, phoneEXIF)
+			upload := f.create(t, len(data))
+
+			// La conexión se corta después de 150 bytes, a mitad de la tercera
+			// parte: se conserva todo lo recibido y la subida se retoma desde ahí
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			body := &failingAfter{data: data[:150]}
+			if tt.cancel {
+				body.cancel = cancel
+			}
+			_, err := f.resumableUC.Append(ctx, image.AppendInput{
+				ID: upload.ID, UserName: "testuser", Body: body,
+			})
+			if err == nil {
+				t.Fatal("Append() error = nil, want el error de lectura")
+			}
+
+			state, _ := f.resumableUC.Get(upload.ID, "testuser")
+			if state.Offset != 150 {
+				t.Fatalf("Offset = %d, want 150", state.Offset)
+			}
+			if f.partCount() != 3 {
+				t.Errorf("partes = %d, want 3", f.partCount())
+			}
+			output, err := f.append(upload.ID, 150, data[150:])
+			if err != nil || output.Image == nil {
+				t.Fatalf("Append() = %+v, %v; want la imagen", output, err)
+			}
+			if !bytes.Equal(f.fileStorage.Files[f.imageRepo.Images[output.Image.ID].Path], data) {
+				t.Error("el contenido retomado no coincide con el subido")
+			}
+		})
+	}
+}
+
+func TestResumableUpload_CutAtPartBoundary(t *testing.T) {
+	f := setupResumable()
+	data := phoneJPEG(t, phoneEXIF)
+	upload := f.create(t, len(data))
+
+	// El corte llega justo al empezar una parte: no queda una parte vacía
+	if _, err := f.resumableUC.Append(context.Background(), image.AppendInput{
+		ID: upload.ID, UserName: "testuser", Body: &failingAfter{data: data[:64]},
+	}); err == nil {
+		t.Fatal("Append() error = nil, want el error de lectura")
+	}
+	state, _ := f.resumableUC.Get(upload.ID, "testuser")
+	if state.Offset != 64 || f.partCount() != 1 {
+		t.Errorf("Offset = %d, partes = %d; want 64 y 1", state.Offset, f.partCount())
+	}
+}
+
+func TestResumableUpload_InvalidContent(t *testing.T) {
+	f := setupResumable()
+	data := []byte(strings.Repeat("no es una imagen ", 10))
+	upload := f.create(t, len(data))
+
+	_, err := f.append(upload.ID, 0, data)
+	if !errors.Is(err, image.ErrUnsupportedMediaType) {
+		t.Fatalf("Append() error = %v, want ErrUnsupportedMediaType", err)
+	}
+
+	// Reintentar no cambia el contenido, así que la subida se descarta
+	if _, err := f.resumableUC.Get(upload.ID, "testuser"); !errors.Is(err, image.ErrUploadNotFound) {
+		t.Errorf("Get() error = %v, want ErrUploadNotFound", err)
+	}
+	if f.partCount() != 0 {
+		t.Errorf("partes = %d, want 0", f.partCount())
+	}
+}
+
+func TestResumableUpload_RetryFinish(t *testing.T) {
+	tests := []struct {
+		name string
+		fail func(f *resumableFixture, err error)
+	}{
+		{
+			name: "falla al crear la imagen",
+			fail: func(f *resumableFixture, err error) { f.imageRepo.CreateError = err },
+		},
+		{
+			// La imagen ya se creó: el reintento la registra sin crear otra
+			name: "falla al registrar la subida completa",
+			fail: func(f *resumableFixture, err error) { f.uploadRepo.CompleteError = err },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := setupResumable()
+			data := phoneJPEG(t, phoneEXIF)
+			upload := f.create(t, len(data))
+
+			tt.fail(f, errors.New("base de datos caída"))
+			if _, err := f.append(upload.ID, 0, data); err == nil {
+				t.Fatal("Append() error = nil, want el error de la base de datos")
+			}
+
+			// Un error pasajero conserva lo recibido para reintentar sin reenviarlo
+			tt.fail(f, nil)
+			output, err := f.append(upload.ID, len(data), nil)
+			if err != nil || output.Image == nil {
+				t.Fatalf("Append() vacío = %+v, %v; want la imagen", output, err)
+			}
+			if !bytes.Equal(f.fileStorage.Files[f.imageRepo.Images[output.Image.ID].Path], data) {
+				t.Error("el contenido guardado no coincide con el subido")
+			}
+			if len(f.imageRepo.Images) != 1 {
+				t.Errorf("imágenes = %d, want 1", len(f.imageRepo.Images))
+			}
+			if f.partCount() != 0 {
+				t.Errorf("partes = %d, want 0 al completarse", f.partCount())
+			}
+		})
+	}
+}
+
+// signalReader avisa en started cuando se empieza a leer el cuerpo
+type signalReader struct {
+	reader  *io.PipeReader
+	started chan struct{}
+	once    sync.Once
+}
+
+func (r *signalReader) Read(p []byte) (int, error) {
+	r.once.Do(func() { close(r.started) })
+	return r.reader.Read(p)
+}
+
+func TestResumableUpload_IndependentLocks(t *testing.T) {
+	f := setupResumable()
+
+	// Con más subidas que candados compartidos, al menos dos caerían en el
+	// mismo; cada subida debe poder recibir su cuerpo aunque las demás sigan
+	// esperando datos
+	const count = 65
+	writers := make([]*io.PipeWriter, count)
+	bodies := make([]*signalReader, count)
+	var wg sync.WaitGroup
+	for i := range count {
+		upload := f.create(t, 100)
+		reader, writer := io.Pipe()
+		writers[i] = writer
+		bodies[i] = &signalReader{reader: reader, started: make(chan struct{})}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f.resumableUC.Append(context.Background(), image.AppendInput{
+				ID: upload.ID, UserName: "testuser", Body: bodies[i],
+			})
+		}()
+	}
+	defer func() {
+		for _, writer := range writers {
+			writer.Close()
+		}
+		wg.Wait()
+	}()
+
+	timeout := time.After(2 * time.Second)
+	for i, body := range bodies {
+		select {
+		case <-body.started:
+		case <-timeout:
+			t.Fatalf("la subida %d quedó esperando el candado de otra", i)
+		}
+	}
+}
+
+func TestResumableUpload_Terminate(t *testing.T) {
+	f := setupResumable()
+	data := phoneJPEG(t, phoneEXIF)
+	upload := f.create(t, len(data))
+	if _, err := f.append(upload.ID, 0, data[:100]); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	if err := f.resumableUC.Terminate(context.Background(), upload.ID, "otro"); !errors.Is(err, image.ErrUploadNotFound) {
+		t.Errorf("Terminate() de otro usuario error = %v, want ErrUploadNotFound", err)
+	}
+	if err := f.resumableUC.Terminate(context.Background(), upload.ID, "testuser"); err != nil {
+		t.Fatalf("Terminate() error = %v", err)
+	}
+	if _, err := f.append(upload.ID, 100, data[100:]); !errors.Is(err, image.ErrUploadNotFound) {
+		t.Errorf("Append() error = %v, want ErrUploadNotFound", err)
+	}
+	if f.partCount() != 0 {
+		t.Errorf("partes = %d, want 0", f.partCount())
+	}
+}
+
+func TestResumableUpload_ExpireStale(t *testing.T) {
+	f := setupResumable()
+	data := phoneJPEG(t, phoneEXIF)
+	stale := f.create(t, len(data))
+	fresh := f.create(t, len(data))
+	for _, upload := range []*image.ResumableOutput{stale, fresh} {
+		if _, err := f.append(upload.ID, 0, data[:100]); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+	f.uploadRepo.Uploads[stale.ID].ExpiresAt = time.Now().Add(-time.Minute)
+
+	if _, err := f.append(stale.ID, 100, data[100:]); !errors.Is(err, image.ErrUploadExpired) {
+		t.Errorf("Append() vencida error = %v, want ErrUploadExpired", err)
+	}
+
+	expired, err := f.resumableUC.ExpireStale(context.Background(), 10)
+	if err != nil || expired != 1 {
+		t.Fatalf("ExpireStale() = %d, %v; want 1", expired, err)
+	}
+	if _, ok := f.uploadRepo.Uploads[stale.ID]; ok {
+		t.Error("la subida vencida sigue registrada")
+	}
+	if _, err := f.resumableUC.Get(fresh.ID, "testuser"); err != nil {
+		t.Errorf("Get() vigente error = %v", err)
+	}
+	for path := range f.fileStorage.Files {
+		if strings.Contains(path, stale.ID) {
+			t.Errorf("quedó la parte %s de la subida vencida", path)
+		}
+	}
+}
+
+// failingAfter devuelve data y después un error, como una conexión cortada.
+// Si cancel no es nil lo llama al cortarse.
+type failingAfter struct {
+	data   []byte
+	cancel context.CancelFunc
+}
+
+func (r *failingAfter) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		if r.cancel != nil {
+			r.cancel()
+		}
+		return 0, errors.New("conexión cortada")
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
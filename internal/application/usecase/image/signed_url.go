@@ -0,0 +1,123 @@
+package image
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/RodrigoGonzalez78/internal/domain/repository"
+	"github.com/RodrigoGonzalez78/internal/domain/service"
+)
+
+// ErrInvalidSignature indica que la firma de la URL no corresponde a la ruta
+var ErrInvalidSignature = errors.New("firma inválida")
+
+// SignedURLUseCase genera y resuelve URLs de transformación firmadas con HMAC
+type SignedURLUseCase struct {
+	imageRepo   repository.ImageRepository
+	transformUC *TransformUseCase
+	signer      *service.URLSigner
+	baseURL     string
+	port        string
+}
+
+// NewSignedURLUseCase crea una nueva instancia de SignedURLUseCase
+func NewSignedURLUseCase(
+	imageRepo repository.ImageRepository,
+	transformUC *TransformUseCase,
+	signer *service.URLSigner,
+	baseURL, port string,
+) *SignedURLUseCase {
+	return &SignedURLUseCase{
+		imageRepo:   imageRepo,
+		transformUC: transformUC,
+		signer:      signer,
+		baseURL:     baseURL,
+		port:        port,
+	}
+}
+
+// SignURLInput representa los datos de entrada para firmar una URL
+type SignURLInput struct {
+	ImageID    int64
+	UserName   string
+	Operations string
+	Format     string
+}
+
+// SignURLOutput representa la URL firmada
+type SignURLOutput struct {
+	URL  string
+	Path string
+}
+
+// Sign valida las operaciones y devuelve la URL firmada para una imagen del usuario
+func (uc *SignedURLUseCase) Sign(input SignURLInput) (*SignURLOutput, error) {
+	if !uc.signer.Enabled() {
+		return nil, errors.New("las URLs firmadas están deshabilitadas en el servidor")
+	}
+
+	image, err := uc.imageRepo.FindByID(input.ImageID)
+	if err != nil {
+		return nil, errors.New("imagen no encontrada")
+	}
+
+	if image.UserName != input.UserName {
+		return nil, errors.New("no tienes permiso para esta imagen")
+	}
+
+	var segments []string
+	for _, segment := range strings.Split(input.Operations, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	if input.Format != "" {
+		segments = append(segments, "f:"+input.Format)
+	}
+
+	ops, format, err := ParseURLOperations(segments)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.transformUC.ValidateOperations(ops); err != nil {
+		return nil, err
+	}
+	if err := uc.transformUC.ValidateFormat(format, EncodeOptions{}); err != nil {
+		return nil, err
+	}
+
+	segments = append(segments, image.UserName, image.Name)
+	path := "/" + strings.Join(segments, "/")
+	signature := uc.signer.Sign(path)
+
+	return &SignURLOutput{
+		URL:  fmt.Sprintf("%s:%s/t/%s%s", uc.baseURL, uc.port, signature, path),
+		Path: path,
+	}, nil
+}
+
+// Resolve verifica la firma de una ruta "ops.../usuario/archivo" y la
+// convierte en la entrada del caso de uso de transformación
+func (uc *SignedURLUseCase) Resolve(signature, rest string) (*TransformPathInput, error) {
+	path := "/" + strings.Trim(rest, "/")
+	if !uc.signer.Verify(signature, path) {
+		return nil, ErrInvalidSignature
+	}
+
+	segments, userName, fileName, err := SplitTransformPath(rest)
+	if err != nil {
+		return nil, err
+	}
+
+	ops, format, err := ParseURLOperations(segments)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TransformPathInput{
+		ObjectPath: userName + "/" + fileName,
+		Operations: ops,
+		Format:     format,
+	}, nil
+}
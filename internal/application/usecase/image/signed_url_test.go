@@ -0,0 +1,126 @@
+package image_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/RodrigoGonzalez78/internal/application/usecase/image"
+	"github.com/RodrigoGonzalez78/internal/domain/service"
+)
+
+func TestParseURLOperations(t *testing.T) {
+	tests := []struct {
+		name       string
+		segments   []string
+		wantTypes  []string
+		wantFormat string
+		wantErr    bool
+	}{
+		{
+			name:      "alias posicionales",
+			segments:  []string{"c:100:50:10:20", "rs:800:600", "gs"},
+			wantTypes: []string{"crop", "resize", "grayscale"},
+		},
+		{
+			name:       "formato de salida",
+			segments:   []string{"rot:90", "f:jpeg"},
+			wantTypes:  []string{"rotate"},
+			wantFormat: "jpeg",
+		},
+		{
+			name:      "clave=valor",
+			segments:  []string{"resize:width=10:height=20"},
+			wantTypes: []string{"resize"},
+		},
+		{
+			name:     "demasiados argumentos",
+			segments: []string{"rs:1:2:fit:4"},
+			wantErr:  true,
+		},
+		{
+			name:     "clave sin valor",
+			segments: []string{"sepia:fuerte"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops, format, err := image.ParseURLOperations(tt.segments)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseURLOperations() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if format != tt.wantFormat {
+				t.Errorf("format = %q, want %q", format, tt.wantFormat)
+			}
+			if len(ops) != len(tt.wantTypes) {
+				t.Fatalf("len(ops) = %d, want %d", len(ops), len(tt.wantTypes))
+			}
+			for i, op := range ops {
+				if op.Type != tt.wantTypes[i] {
+					t.Errorf("ops[%d].Type = %s, want %s", i, op.Type, tt.wantTypes[i])
+				}
+			}
+		})
+	}
+
+	ops, _, _ := image.ParseURLOperations([]string{"c:100:50:10:20"})
+	if ops[0].Params["width"] != 100.0 || ops[0].Params["x"] != 10.0 {
+		t.Errorf("parámetros de crop = %v", ops[0].Params)
+	}
+}
+
+func TestSignedURLUseCase_SignAndResolve(t *testing.T) {
+	transformUC, imageRepo, _ := setupTransform(t)
+	signer := service.NewURLSigner("secreto-de-urls")
+	useCase := image.NewSignedURLUseCase(imageRepo, transformUC, signer, "http://localhost", "8080")
+
+	output, err := useCase.Sign(image.SignURLInput{
+		ImageID:    1,
+		UserName:   "testuser",
+		Operations: "c:100:100:0:0/rs:50:50",
+		Format:     "jpeg",
+	})
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	prefix := "http://localhost:8080/t/"
+	if !strings.HasPrefix(output.URL, prefix) {
+		t.Fatalf("URL = %s, want prefijo %s", output.URL, prefix)
+	}
+
+	signature, rest, _ := strings.Cut(strings.TrimPrefix(output.URL, prefix), "/")
+	input, err := useCase.Resolve(signature, rest)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if input.ObjectPath != "testuser/test.png" || input.Format != "jpeg" || len(input.Operations) != 2 {
+		t.Errorf("Resolve() = %+v", input)
+	}
+
+	result, err := transformUC.ExecutePath(context.Background(), *input)
+	if err != nil {
+		t.Fatalf("ExecutePath() error = %v", err)
+	}
+	if b := decodeOutput(t, result).Bounds(); b.Dx() != 50 || b.Dy() != 50 {
+		t.Errorf("dimensiones = %dx%d, want 50x50", b.Dx(), b.Dy())
+	}
+
+	if _, err := useCase.Resolve(signature, strings.Replace(rest, "rs:50:50", "rs:5000:5000", 1)); !errors.Is(err, image.ErrInvalidSignature) {
+		t.Errorf("Resolve() con ruta alterada error = %v, want ErrInvalidSignature", err)
+	}
+
+	if _, err := useCase.Sign(image.SignURLInput{ImageID: 1, UserName: "otroUsuario"}); err == nil {
+		t.Error("Sign() debe fallar para imágenes de otro usuario")
+	}
+
+	if _, err := useCase.Sign(image.SignURLInput{ImageID: 1, UserName: "testuser", Operations: "rs:0:0"}); err == nil {
+		t.Error("Sign() debe validar las operaciones")
+	}
+}
@@ -0,0 +1,251 @@
+package image
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+
+	"github.com/RodrigoGonzalez78/internal/domain/entity"
+	"github.com/RodrigoGonzalez78/internal/domain/repository"
+	"github.com/RodrigoGonzalez78/internal/pkg/dhash"
+)
+
+const (
+	// DefaultSimilarityThreshold es la distancia de Hamming por defecto de
+	// la búsqueda de imágenes parecidas
+	DefaultSimilarityThreshold = 10
+	// DefaultDuplicateThreshold es la distancia por defecto a partir de la
+	// cual una subida ya no se considera un duplicado
+	DefaultDuplicateThreshold = 4
+	// MaxHashDistance es la distancia máxima entre dos hashes de 64 bits
+	MaxHashDistance = 64
+)
+
+// DuplicatePolicy indica qué hacer cuando la imagen subida ya existe
+type DuplicatePolicy string
+
+const (
+	// DuplicateAllow guarda la imagen aunque exista otra igual
+	DuplicateAllow DuplicatePolicy = "allow"
+	// DuplicateReject rechaza la subida con DuplicateError
+	DuplicateReject DuplicatePolicy = "reject"
+	// DuplicateReturnExisting no guarda nada y devuelve la imagen existente
+	DuplicateReturnExisting DuplicatePolicy = "return_existing"
+)
+
+// ErrInvalidDuplicatePolicy indica un valor de política desconocido
+var ErrInvalidDuplicatePolicy = errors.New("política de duplicados inválida (valores válidos: allow, reject, return_existing)")
+
+// ErrHashUnavailable indica que la imagen todavía no tiene hash perceptual
+var ErrHashUnavailable = errors.New("la imagen todavía no tiene hash perceptual")
+
+// ParseDuplicatePolicy valida una política; la cadena vacía equivale a allow
+func ParseDuplicatePolicy(value string) (DuplicatePolicy, error) {
+	switch policy := DuplicatePolicy(value); policy {
+	case "":
+		return DuplicateAllow, nil
+	case DuplicateAllow, DuplicateReject, DuplicateReturnExisting:
+		return policy, nil
+	}
+	return "", ErrInvalidDuplicatePolicy
+}
+
+// DuplicateError indica que el usuario ya tiene una imagen igual o casi igual
+type DuplicateError struct {
+	Existing *UploadOutput
+	Distance int
+}
+
+func (e *DuplicateError) Error() string {
+	return fmt.Sprintf("la imagen ya existe: %s (distancia %d)", e.Existing.Name, e.Distance)
+}
+
+// perceptualHash decodifica la imagen ya orientada y devuelve su dHash
+func perceptualHash(limits ImageLimits, data []byte) (string, error) {
+	img, err := limits.decode(data)
+	if err != nil {
+		return "", err
+	}
+	return dhash.Format(dhash.Compute(img)), nil
+}
+
+// hashMatch es una imagen a cierta distancia de un hash
+type hashMatch struct {
+	image    entity.Image
+	distance int
+}
+
+// findSimilar devuelve las imágenes del usuario a distancia menor o igual
+// que threshold, de la más parecida a la menos parecida
+func findSimilar(imageRepo repository.ImageRepository, userName, hash string, threshold int) ([]hashMatch, error) {
+	target, err := dhash.Parse(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	images, err := imageRepo.FindHashedByUser(userName)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []hashMatch
+	for _, image := range images {
+		other, err := dhash.Parse(image.PerceptualHash)
+		if err != nil {
+			continue
+		}
+		if distance := dhash.Distance(target, other); distance <= threshold {
+			matches = append(matches, hashMatch{image: image, distance: distance})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].distance < matches[j].distance })
+	return matches, nil
+}
+
+// SimilarityUseCase busca imágenes parecidas por hash perceptual y calcula el
+// hash de las imágenes guardadas antes de que existiera
+type SimilarityUseCase struct {
+	imageRepo   repository.ImageRepository
+	fileStorage repository.FileStorage
+	limits      ImageLimits
+	baseURL     string
+	port        string
+}
+
+// NewSimilarityUseCase crea una nueva instancia de SimilarityUseCase
+func NewSimilarityUseCase(
+	imageRepo repository.ImageRepository,
+	fileStorage repository.FileStorage,
+	limits ImageLimits,
+	baseURL, port string,
+) *SimilarityUseCase {
+	return &SimilarityUseCase{
+		imageRepo:   imageRepo,
+		fileStorage: fileStorage,
+		limits:      limits,
+		baseURL:     baseURL,
+		port:        port,
+	}
+}
+
+// SimilarImage es una imagen parecida y su distancia a la buscada
+type SimilarImage struct {
+	ImageItem
+	Distance int
+}
+
+// SimilarOutput representa los datos de salida
+type SimilarOutput struct {
+	ImageID   int64
+	Threshold int
+	Images    []SimilarImage
+}
+
+// Similar devuelve las demás imágenes del usuario a distancia de Hamming
+// menor o igual que threshold
+func (uc *SimilarityUseCase) Similar(imageID int64, userName string, threshold int) (*SimilarOutput, error) {
+	if threshold < 0 || threshold > MaxHashDistance {
+		return nil, &FieldError{Field: "threshold", Message: fmt.Sprintf("debe estar entre 0 y %d", MaxHashDistance)}
+	}
+
+	image, err := uc.imageRepo.FindByID(imageID)
+	if err != nil || image.UserName != userName {
+		return nil, ErrImageNotFound
+	}
+	if image.PerceptualHash == "" {
+		return nil, ErrHashUnavailable
+	}
+
+	matches, err := findSimilar(uc.imageRepo, userName, image.PerceptualHash, threshold)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &SimilarOutput{
+		ImageID:   imageID,
+		Threshold: threshold,
+		Images:    []SimilarImage{},
+	}
+	for _, match := range matches {
+		if match.image.ID == image.ID {
+			continue
+		}
+		output.Images = append(output.Images, SimilarImage{
+			ImageItem: ImageItem{
+				ID:     match.image.ID,
+				URL:    fmt.Sprintf("%s:%s/images/%s/%s", uc.baseURL, uc.port, match.image.UserName, match.image.Name),
+				Name:   match.image.Name,
+				Size:   match.image.Size,
+				Format: match.image.Format,
+				Width:  match.image.Width,
+				Height: match.image.Height,
+			},
+			Distance: match.distance,
+		})
+	}
+	return output, nil
+}
+
+// BackfillOutput resume el cálculo de hashes pendientes
+type BackfillOutput struct {
+	Hashed int
+	Failed int
+}
+
+// Backfill calcula el hash perceptual de las imágenes guardadas que no lo
+// tienen, de a batchSize por consulta. Las que no se pueden leer o
+// decodificar se cuentan como fallidas y se saltean.
+func (uc *SimilarityUseCase) Backfill(ctx context.Context, batchSize int) (*BackfillOutput, error) {
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	output := &BackfillOutput{}
+	var afterID int64
+	for {
+		images, err := uc.imageRepo.FindUnhashed(afterID, batchSize)
+		if err != nil {
+			return output, err
+		}
+		if len(images) == 0 {
+			return output, nil
+		}
+
+		for _, image := range images {
+			if err := ctx.Err(); err != nil {
+				return output, err
+			}
+			afterID = image.ID
+
+			if err := uc.hashStored(ctx, &image); err != nil {
+				log.Printf("  No se pudo calcular el hash de la imagen %d: %v", image.ID, err)
+				output.Failed++
+				continue
+			}
+			output.Hashed++
+		}
+	}
+}
+
+func (uc *SimilarityUseCase) hashStored(ctx context.Context, image *entity.Image) error {
+	reader, err := uc.fileStorage.Get(ctx, image.Path)
+	if err != nil {
+		return fmt.Errorf("error al leer la imagen: %w", err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("error al leer la imagen: %w", err)
+	}
+
+	hash, err := perceptualHash(uc.limits, data)
+	if err != nil {
+		return err
+	}
+	return uc.imageRepo.UpdatePerceptualHash(image.ID, hash)
+}
@@ -0,0 +1,159 @@
+package image_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	stdimage "image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+
+	"github.com/RodrigoGonzalez78/internal/application/usecase/image"
+	"github.com/RodrigoGonzalez78/internal/domain/entity"
+)
+
+// patternJPEG codifica un degradé con un círculo; mirrored lo espeja para
+// obtener una imagen distinta
+func patternJPEG(t *testing.T, width, height, quality int, mirrored bool) []byte {
+	t.Helper()
+	img := stdimage.NewNRGBA(stdimage.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			fx, fy := float64(x)/float64(width), float64(y)/float64(height)
+			if mirrored {
+				fx = 1 - fx
+			}
+			v := uint8(255 * fx * fy)
+			if (fx-0.3)*(fx-0.3)+(fy-0.6)*(fy-0.6) < 0.04 {
+				v = 240
+			}
+			img.SetNRGBA(x, y, color.NRGBA{R: v, G: v / 2, B: 255 - v, A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		t.Fatalf("no se pudo codificar el JPEG de prueba: %v", err)
+	}
+	return buf.Bytes()
+}
+
+type similarityFixture struct {
+	*uploadFixture
+	similarityUC *image.SimilarityUseCase
+}
+
+func setupSimilarity() *similarityFixture {
+	f := &similarityFixture{uploadFixture: setupUpload(image.ImageLimits{})}
+	f.similarityUC = image.NewSimilarityUseCase(f.imageRepo, f.fileStorage, image.DefaultImageLimits(), "http://localhost", "8080")
+	return f
+}
+
+func (f *similarityFixture) upload(t *testing.T, name string, data []byte, policy image.DuplicatePolicy) (*image.UploadOutput, error) {
+	t.Helper()
+	return f.uploadUC.Execute(context.Background(), image.UploadInput{
+		FileName: name, UserName: "testuser", Data: data, ContentType: "image/jpeg", Format: "jpeg",
+		OnDuplicate: policy, DuplicateThreshold: image.DefaultDuplicateThreshold,
+	})
+}
+
+func TestUploadUseCase_Duplicates(t *testing.T) {
+	f := setupSimilarity()
+	if _, err := f.upload(t, "original.jpg", patternJPEG(t, 640, 480, 90, false), image.DuplicateReject); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if hash := f.imageRepo.Images[1].PerceptualHash; len(hash) != 16 {
+		t.Fatalf("PerceptualHash = %q", hash)
+	}
+
+	// La misma foto más chica y más comprimida
+	copyData := patternJPEG(t, 320, 240, 40, false)
+
+	_, err := f.upload(t, "copia.jpg", copyData, image.DuplicateReject)
+	var duplicateErr *image.DuplicateError
+	if !errors.As(err, &duplicateErr) || duplicateErr.Existing.ID != 1 || duplicateErr.Existing.Name != "original.jpg" {
+		t.Fatalf("Execute(reject) error = %v, want DuplicateError de la imagen 1", err)
+	}
+
+	output, err := f.upload(t, "copia.jpg", copyData, image.DuplicateReturnExisting)
+	if err != nil || !output.Duplicate || output.ID != 1 || output.URL != "http://localhost:8080/images/testuser/original.jpg" {
+		t.Fatalf("Execute(return_existing) = %+v, %v", output, err)
+	}
+	if len(f.fileStorage.Files) != 1 || len(f.imageRepo.Images) != 1 {
+		t.Error("un duplicado devuelto no debe guardarse")
+	}
+
+	if output, err := f.upload(t, "copia.jpg", copyData, image.DuplicateAllow); err != nil || output.Duplicate || output.ID != 2 {
+		t.Errorf("Execute(allow) = %+v, %v", output, err)
+	}
+	if output, err := f.upload(t, "otra.jpg", patternJPEG(t, 640, 480, 90, true), image.DuplicateReject); err != nil || output.ID != 3 {
+		t.Errorf("Execute() de otra imagen = %+v, %v", output, err)
+	}
+}
+
+func TestSimilarityUseCase_Similar(t *testing.T) {
+	f := setupSimilarity()
+	for _, upload := range []struct {
+		name string
+		data []byte
+	}{
+		{"original.jpg", patternJPEG(t, 640, 480, 90, false)},
+		{"espejada.jpg", patternJPEG(t, 640, 480, 90, true)},
+		{"copia.jpg", patternJPEG(t, 320, 240, 40, false)},
+	} {
+		if _, err := f.upload(t, upload.name, upload.data, ""); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+	}
+
+	output, err := f.similarityUC.Similar(1, "testuser", image.DefaultSimilarityThreshold)
+	if err != nil {
+		t.Fatalf("Similar() error = %v", err)
+	}
+	if len(output.Images) != 1 || output.Images[0].ID != 3 || output.Images[0].Distance > image.DefaultDuplicateThreshold {
+		t.Errorf("Similar() = %+v, want solo la copia", output.Images)
+	}
+
+	output, err = f.similarityUC.Similar(1, "testuser", image.MaxHashDistance)
+	if err != nil || len(output.Images) != 2 || output.Images[0].ID != 3 || output.Images[1].ID != 2 {
+		t.Errorf("Similar(64) = %+v, %v; want las otras dos, la copia primero", output, err)
+	}
+
+	var fieldErr *image.FieldError
+	if _, err := f.similarityUC.Similar(1, "testuser", 65); !errors.As(err, &fieldErr) {
+		t.Errorf("Similar(65) error = %v, want FieldError", err)
+	}
+	if _, err := f.similarityUC.Similar(1, "otro", 10); !errors.Is(err, image.ErrImageNotFound) {
+		t.Errorf("Similar() de otro usuario error = %v, want ErrImageNotFound", err)
+	}
+
+	f.imageRepo.Images[2].PerceptualHash = ""
+	if _, err := f.similarityUC.Similar(2, "testuser", 10); !errors.Is(err, image.ErrHashUnavailable) {
+		t.Errorf("Similar() sin hash error = %v, want ErrHashUnavailable", err)
+	}
+}
+
+func TestSimilarityUseCase_Backfill(t *testing.T) {
+	_, imageRepo, fileStorage := setupTransform(t)
+	imageRepo.Images[2] = &entity.Image{ID: 2, Name: "rota.png", UserName: "testuser", Path: "testuser/rota.png", Format: "png"}
+	fileStorage.Files["testuser/rota.png"] = []byte("no es una imagen")
+	imageRepo.Images[3] = &entity.Image{ID: 3, Name: "lista.png", UserName: "testuser", Path: "testuser/lista.png", Format: "png", PerceptualHash: "0123456789abcdef"}
+
+	similarityUC := image.NewSimilarityUseCase(imageRepo, fileStorage, image.DefaultImageLimits(), "http://localhost", "8080")
+
+	// Con lotes de 1 se recorren varias páginas y la imagen rota no se repite
+	output, err := similarityUC.Backfill(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Backfill() error = %v", err)
+	}
+	if output.Hashed != 1 || output.Failed != 1 {
+		t.Errorf("Backfill() = %+v, want 1 calculado y 1 fallido", output)
+	}
+	if hash := imageRepo.Images[1].PerceptualHash; len(hash) != 16 {
+		t.Errorf("PerceptualHash = %q", hash)
+	}
+	if imageRepo.Images[3].PerceptualHash != "0123456789abcdef" {
+		t.Error("no se deben recalcular los hashes existentes")
+	}
+}
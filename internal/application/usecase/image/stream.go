@@ -0,0 +1,122 @@
+package image
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+
+	"github.com/RodrigoGonzalez78/internal/pkg/dhash"
+	"github.com/RodrigoGonzalez78/internal/pkg/exif"
+)
+
+// headSize es cuánto del comienzo del archivo se guarda para leer los
+// metadatos. Alcanza para el segmento EXIF de un JPEG, que no supera 64 KB.
+const headSize = 256 << 10
+
+// streamAnalysis es lo que se obtiene de una imagen mientras se sube
+type streamAnalysis struct {
+	Width  int
+	Height int
+	// Head son los primeros bytes del archivo, para los metadatos
+	Head []byte
+	Hash string
+	// Err es el error que invalida la subida; HashErr solo se registra
+	Err     error
+	HashErr error
+}
+
+// analyzer recibe una copia del contenido que se está subiendo. Lee las
+// dimensiones de la cabecera y, si respetan los límites, decodifica los
+// píxeles para el hash perceptual. Así nada de eso espera a tener el archivo
+// entero en memoria.
+type analyzer struct {
+	pw     *io.PipeWriter
+	done   chan struct{}
+	result streamAnalysis
+}
+
+// startAnalysis lanza el análisis. Si la cabecera no se puede leer y
+// requireConfig es true, o si las dimensiones superan los límites, la copia
+// se corta con ese error y la subida falla sin terminar de leerse.
+func startAnalysis(limits ImageLimits, requireConfig bool) *analyzer {
+	pr, pw := io.Pipe()
+	a := &analyzer{pw: pw, done: make(chan struct{})}
+
+	go func() {
+		defer close(a.done)
+		a.result = analyze(pr, limits)
+
+		var limitErr *LimitError
+		if errors.As(a.result.Err, &limitErr) || (requireConfig && a.result.Err != nil) {
+			pr.CloseWithError(a.result.Err)
+			return
+		}
+		// Consumir el resto para no bloquear la subida
+		io.Copy(io.Discard, pr)
+	}()
+	return a
+}
+
+// Tee devuelve un reader que entrega r y a la vez alimenta el análisis
+func (a *analyzer) Tee(r io.Reader) io.Reader {
+	return io.TeeReader(r, a.pw)
+}
+
+// Wait cierra la copia y espera el resultado. uploadErr es el error de la
+// subida, que también interrumpe el análisis.
+func (a *analyzer) Wait(uploadErr error) streamAnalysis {
+	a.pw.CloseWithError(uploadErr)
+	<-a.done
+	return a.result
+}
+
+func analyze(r io.Reader, limits ImageLimits) (result streamAnalysis) {
+	head := &headBuffer{max: headSize}
+	r = io.TeeReader(r, head)
+	defer func() { result.Head = head.Bytes() }()
+
+	// Lo que lee DecodeConfig se guarda para volver a entregarlo al
+	// decodificador; es solo la cabecera
+	var consumed bytes.Buffer
+	config, _, err := image.DecodeConfig(io.TeeReader(r, &consumed))
+	if err != nil {
+		result.Err = fmt.Errorf("%w: %v", ErrCorruptImage, err)
+		return result
+	}
+
+	// Los segmentos EXIF van antes que la cabecera con las dimensiones, así
+	// que ya se recibieron. Las dimensiones guardadas y las que se verifican
+	// son las de la imagen ya orientada.
+	orientation := exif.Orientation(head.Bytes())
+	result.Width, result.Height = config.Width, config.Height
+	if swapsAxes(orientation) {
+		result.Width, result.Height = result.Height, result.Width
+	}
+	if err := limits.CheckDimensions(result.Width, result.Height); err != nil {
+		result.Err = err
+		return result
+	}
+
+	img, _, err := image.Decode(io.MultiReader(&consumed, r))
+	if err != nil {
+		result.HashErr = errors.New("error al decodificar la imagen")
+		return result
+	}
+	result.Hash = dhash.Format(dhash.Compute(autoOrient(img, orientation)))
+	return result
+}
+
+// headBuffer guarda los primeros max bytes que recibe y descarta el resto
+type headBuffer struct {
+	bytes.Buffer
+	max int
+}
+
+func (b *headBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.Len(); room > 0 {
+		b.Buffer.Write(p[:min(room, len(p))])
+	}
+	return len(p), nil
+}
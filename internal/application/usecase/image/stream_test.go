@@ -0,0 +1,308 @@
+package image_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"os"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	stdimage "image"
+	"image/color"
+	"image/jpeg"
+
+	"github.com/RodrigoGonzalez78/internal/application/usecase/image"
+	"github.com/RodrigoGonzalez78/internal/domain/repository"
+	"github.com/RodrigoGonzalez78/internal/domain/repository/mocks"
+	"github.com/RodrigoGonzalez78/internal/pkg/exif"
+)
+
+// streamInput arma una subida que se lee de a un byte y sin largo conocido,
+// como el cuerpo de una petición
+func streamInput(data []byte) image.UploadInput {
+	return image.UploadInput{
+		FileName: "foto.jpg", UserName: "testuser",
+		Body: iotest.OneByteReader(bytes.NewReader(data)), Size: repository.UnknownSize,
+		ContentType: "image/jpeg", Format: "jpeg",
+	}
+}
+
+func TestUploadUseCase_Stream(t *testing.T) {
+	f := setupUpload(image.DefaultImageLimits())
+	data := phoneJPEG(t, phoneEXIF)
+
+	output, err := f.uploadUC.Execute(context.Background(), streamInput(data))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	// Las dimensiones salen de la cabecera, ya orientadas
+	if output.Width != 20 || output.Height != 40 {
+		t.Errorf("dimensiones = %dx%d, want 20x40", output.Width, output.Height)
+	}
+	if output.Size != int64(len(data)) {
+		t.Errorf("Size = %d, want %d", output.Size, len(data))
+	}
+
+	stored := f.imageRepo.Images[1]
+	if stored.Path != image.BlobPath(checksumOf(data)) {
+		t.Errorf("Path = %s, want el blob del contenido", stored.Path)
+	}
+	if stored.PerceptualHash == "" {
+		t.Error("no se calculó el hash perceptual")
+	}
+	if !bytes.Equal(f.fileStorage.Files[stored.Path], data) {
+		t.Error("el contenido guardado no coincide con el subido")
+	}
+	if metadata := f.metadataRepo.Metadata[1]; metadata == nil || metadata.CameraModel != "iPhone 13" {
+		t.Errorf("metadatos = %+v, want los de la cabecera", metadata)
+	}
+	if len(f.fileStorage.Files) != 1 {
+		t.Errorf("objetos en storage = %d, want 1 (sin temporales)", len(f.fileStorage.Files))
+	}
+}
+
+func TestUploadUseCase_StreamStripsMetadata(t *testing.T) {
+	f := setupUpload(image.DefaultImageLimits())
+	input := streamInput(phoneJPEG(t, phoneEXIF))
+	input.MetadataPolicy = image.MetadataStripGPS
+
+	if _, err := f.uploadUC.Execute(context.Background(), input); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if stored := f.fileStorage.Files[f.imageRepo.Images[1].Path]; exif.ContainsGPS(stored) {
+		t.Error("el original guardado conserva la posición")
+	}
+
+	// Un bloque de metadatos cortado no llega al storage
+	input = streamInput(phoneJPEG(t, phoneEXIF)[:60])
+	input.MetadataPolicy = image.MetadataStripAll
+	if _, err := f.uploadUC.Execute(context.Background(), input); !errors.Is(err, image.ErrUnreadableMetadata) {
+		t.Errorf("Execute() error = %v, want ErrUnreadableMetadata", err)
+	}
+	if len(f.fileStorage.Files) != 1 {
+		t.Errorf("objetos en storage = %d, want 1", len(f.fileStorage.Files))
+	}
+}
+
+func TestUploadUseCase_StreamRejects(t *testing.T) {
+	limits := image.DefaultImageLimits()
+
+	tests := []struct {
+		name    string
+		data    []byte
+		size    int64
+		wantErr func(error) bool
+	}{
+		{
+			name: "dimensiones fuera de límite",
+			data: craftPNG(60000, 60000),
+			size: repository.UnknownSize,
+			wantErr: func(err error) bool {
+				var limitErr *image.LimitError
+				return errors.As(err, &limitErr) && limitErr.Limit == "width"
+			},
+		},
+		{
+			name:    "cabecera ilegible",
+			data:    []byte("\xFF\xD8\xFF no es un jpeg"),
+			size:    repository.UnknownSize,
+			wantErr: func(err error) bool { return errors.Is(err, image.ErrCorruptImage) },
+		},
+		{
+			name: "tamaño declarado fuera de límite",
+			data: encodeTestJPEG(t, 10, 10),
+			size: limits.MaxBytes + 1,
+			wantErr: func(err error) bool {
+				var limitErr *image.LimitError
+				return errors.As(err, &limitErr) && limitErr.Limit == "bytes"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := setupUpload(limits)
+			input := streamInput(tt.data)
+			input.Size = tt.size
+
+			if _, err := f.uploadUC.Execute(context.Background(), input); !tt.wantErr(err) {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if len(f.fileStorage.Files) != 0 || len(f.blobRepo.Blobs) != 0 || len(f.imageRepo.Images) != 0 {
+				t.Errorf("quedaron restos: %d objetos, %d blobs, %d imágenes",
+					len(f.fileStorage.Files), len(f.blobRepo.Blobs), len(f.imageRepo.Images))
+			}
+		})
+	}
+}
+
+func TestBlobStore_PutStream(t *testing.T) {
+	ctx := context.Background()
+	blobRepo := mocks.NewMockBlobRepository()
+	fileStorage := mocks.NewMockFileStorage()
+	blobs := image.NewBlobStore(blobRepo, fileStorage)
+	data := encodeTestPNG(t, 40, 30)
+	checksum := checksumOf(data)
+
+	for i := 0; i < 2; i++ {
+		blob, err := blobs.PutStream(ctx, bytes.NewReader(data), repository.UnknownSize, "image/png")
+		if err != nil {
+			t.Fatalf("PutStream() error = %v", err)
+		}
+		if blob.Checksum != checksum || blob.Size != int64(len(data)) {
+			t.Errorf("blob = %s (%d bytes), want %s (%d bytes)", blob.Checksum, blob.Size, checksum, len(data))
+		}
+	}
+
+	if got := blobRepo.Blobs[checksum].RefCount; got != 2 {
+		t.Errorf("RefCount = %d, want 2", got)
+	}
+	for path := range fileStorage.Files {
+		if path != image.BlobPath(checksum) {
+			t.Errorf("quedó el objeto %s además del blob", path)
+		}
+	}
+
+	// Una subida cortada no registra nada
+	failing := io.MultiReader(bytes.NewReader(data[:10]), iotest.ErrReader(errors.New("conexión interrumpida")))
+	if _, err := blobs.PutStream(ctx, failing, repository.UnknownSize, "image/png"); err == nil {
+		t.Error("PutStream() con lectura fallida debe devolver error")
+	}
+	if len(blobRepo.Blobs) != 1 || len(fileStorage.Files) != 1 {
+		t.Errorf("quedaron %d blobs y %d objetos, want 1 y 1", len(blobRepo.Blobs), len(fileStorage.Files))
+	}
+}
+
+// discardStorage descarta el contenido al subirlo para que el storage no
+// cuente en la memoria medida
+type discardStorage struct {
+	repository.FileStorage
+}
+
+func (s discardStorage) Upload(ctx context.Context, path string, reader io.Reader, size int64, contentType string) error {
+	if _, err := io.Copy(io.Discard, reader); err != nil {
+		return err
+	}
+	return s.FileStorage.Upload(ctx, path, strings.NewReader(""), 0, contentType)
+}
+
+// noiseJPEG genera un JPEG difícil de comprimir, cuyo archivo pesa varias
+// veces lo que ocupan sus píxeles decodificados
+func noiseJPEG(b *testing.B, width, height int) []byte {
+	b.Helper()
+	rng := rand.New(rand.NewSource(1))
+	img := stdimage.NewGray(stdimage.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(rng.Intn(256))
+	}
+	img.Set(0, 0, color.Gray{})
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 100}); err != nil {
+		b.Fatalf("no se pudo codificar el JPEG: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// peakRSS devuelve el máximo de memoria residente del proceso desde el
+// último resetPeakRSS. Solo funciona en Linux.
+func peakRSS() (int64, bool) {
+	status, err := os.ReadFile("/proc/self/status")
+	if err != nil {
+		return 0, false
+	}
+	for _, line := range strings.Split(string(status), "\n") {
+		if value, ok := strings.CutPrefix(line, "VmHWM:"); ok {
+			kb, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimSpace(value), " kB"), 10, 64)
+			return kb << 10, err == nil
+		}
+	}
+	return 0, false
+}
+
+// resetPeakRSS devuelve al sistema la memoria libre y reinicia el máximo
+func resetPeakRSS() bool {
+	debug.FreeOSMemory()
+	return os.WriteFile("/proc/self/clear_refs", []byte("5"), 0) == nil
+}
+
+// BenchmarkUpload compara la subida que carga el archivo entero en memoria,
+// como hacía el handler, con la que lo lee por partes. Reporta el pico de
+// memoria residente (peak-rss-MB) además de los bytes reservados por subida.
+//
+//	go test -run '^$' -bench Upload -benchmem ./internal/application/usecase/image
+func BenchmarkUpload(b *testing.B) {
+	data := noiseJPEG(b, 2000, 1500)
+	path := b.TempDir() + "/foto.jpg"
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		b.Fatal(err)
+	}
+	limits := image.DefaultImageLimits()
+
+	run := func(b *testing.B, input func(file *os.File) (image.UploadInput, error)) {
+		uploadUC := setupUpload(limits).withStorage(func(storage repository.FileStorage) repository.FileStorage {
+			return discardStorage{storage}
+		}).uploadUC
+		measured := resetPeakRSS()
+
+		b.SetBytes(int64(len(data)))
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			file, err := os.Open(path)
+			if err != nil {
+				b.Fatal(err)
+			}
+			in, err := input(file)
+			if err == nil {
+				_, err = uploadUC.Execute(context.Background(), in)
+			}
+			file.Close()
+			if err != nil {
+				b.Fatalf("Execute() error = %v", err)
+			}
+		}
+		b.StopTimer()
+
+		if peak, ok := peakRSS(); ok && measured {
+			b.ReportMetric(float64(peak)/(1<<20), "peak-rss-MB")
+		}
+	}
+
+	b.Run("buffered", func(b *testing.B) {
+		run(b, func(file *os.File) (image.UploadInput, error) {
+			data, err := io.ReadAll(file)
+			if err != nil {
+				return image.UploadInput{}, err
+			}
+			info, err := limits.Inspect(data)
+			if err != nil {
+				return image.UploadInput{}, err
+			}
+			return image.UploadInput{
+				FileName: "foto.jpg", UserName: "testuser", Data: data,
+				ContentType: info.ContentType, Format: info.Format, Width: info.Width, Height: info.Height,
+			}, nil
+		})
+	})
+
+	b.Run("streaming", func(b *testing.B) {
+		run(b, func(file *os.File) (image.UploadInput, error) {
+			info, err := file.Stat()
+			if err != nil {
+				return image.UploadInput{}, err
+			}
+			return image.UploadInput{
+				FileName: "foto.jpg", UserName: "testuser", Body: file, Size: info.Size(),
+				ContentType: "image/jpeg", Format: "jpeg",
+			}, nil
+		})
+	})
+}
@@ -0,0 +1,558 @@
+package image
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/disintegration/imaging"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+const (
+	defaultTextSize        = 32
+	defaultTextColor       = "#ffffff"
+	defaultTextStrokeColor = "#000000"
+	defaultTextAnchor      = "top-left"
+	defaultTextMargin      = 16
+	defaultTextPadding     = 8
+	maxTextLength          = 2000
+	maxTextSize            = 1000
+	maxTextStroke          = 50
+	maxTextMargin          = 10000
+)
+
+// textAnchors posición del bloque de texto y alineación que le corresponde
+// por defecto a cada línea
+var textAnchors = map[string]string{
+	"top-left":     "left",
+	"top":          "center",
+	"top-right":    "right",
+	"left":         "left",
+	"center":       "center",
+	"right":        "right",
+	"bottom-left":  "left",
+	"bottom":       "center",
+	"bottom-right": "right",
+}
+
+var textAligns = map[string]bool{"left": true, "center": true, "right": true}
+
+// parseHexColor interpreta colores "#rgb", "#rrggbb" o "#rrggbbaa"
+func parseHexColor(field, value string) (color.NRGBA, error) {
+	hex := strings.TrimPrefix(value, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if len(hex) != 8 || err != nil {
+		return color.NRGBA{}, &FieldError{Field: field, Message: "debe ser un color hexadecimal (#rgb, #rrggbb o #rrggbbaa)"}
+	}
+	return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
+
+func validateText(params OperationParams) error {
+	text, err := params.String("text", "")
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(text) == "" {
+		return &FieldError{Field: "text", Message: "es requerido"}
+	}
+	if !utf8.ValidString(text) {
+		return &FieldError{Field: "text", Message: "debe ser UTF-8 válido"}
+	}
+	if utf8.RuneCountInString(text) > maxTextLength {
+		return &FieldError{Field: "text", Message: fmt.Sprintf("no puede superar %d caracteres", maxTextLength)}
+	}
+
+	name, err := params.String("font", defaultFont)
+	if err != nil {
+		return err
+	}
+	fontID, err := params.Int("font_id", 0)
+	if err != nil {
+		return err
+	}
+	if fontID < 0 {
+		return &FieldError{Field: "font_id", Message: "no puede ser negativo"}
+	}
+	if fontID > 0 && params.Has("font") {
+		return &FieldError{Field: "font", Message: "no se puede combinar con font_id"}
+	}
+	if _, ok := embeddedFonts[name]; !ok {
+		return &FieldError{Field: "font", Message: "debe ser una de: " + strings.Join(EmbeddedFonts(), ", ")}
+	}
+
+	size, err := params.Float("size", defaultTextSize)
+	if err != nil {
+		return err
+	}
+	if size <= 0 || size > maxTextSize {
+		return &FieldError{Field: "size", Message: fmt.Sprintf("debe ser mayor que 0 y como máximo %d", maxTextSize)}
+	}
+
+	for field, fallback := range map[string]string{"color": defaultTextColor, "stroke_color": defaultTextStrokeColor, "background": ""} {
+		value, err := params.String(field, fallback)
+		if err != nil {
+			return err
+		}
+		if value == "" {
+			continue
+		}
+		if _, err := parseHexColor(field, value); err != nil {
+			return err
+		}
+	}
+
+	anchor, err := params.String("anchor", defaultTextAnchor)
+	if err != nil {
+		return err
+	}
+	if _, ok := textAnchors[anchor]; !ok {
+		return &FieldError{Field: "anchor", Message: "debe ser top-left, top, top-right, left, center, right, bottom-left, bottom o bottom-right"}
+	}
+	if params.Has("anchor") && (params.Has("x") || params.Has("y")) {
+		return &FieldError{Field: "anchor", Message: "no se puede combinar con x/y"}
+	}
+	for _, field := range []string{"x", "y"} {
+		if _, err := params.Int(field, 0); err != nil {
+			return err
+		}
+	}
+
+	align, err := params.String("align", "left")
+	if err != nil {
+		return err
+	}
+	if !textAligns[align] {
+		return &FieldError{Field: "align", Message: "debe ser left, center o right"}
+	}
+
+	limits := []struct {
+		field    string
+		fallback int
+		max      int
+	}{
+		{"margin", defaultTextMargin, maxTextMargin},
+		{"padding", defaultTextPadding, maxTextMargin},
+		{"stroke_width", 0, maxTextStroke},
+		{"max_width", 0, math.MaxInt32},
+	}
+	for _, l := range limits {
+		value, err := params.Int(l.field, l.fallback)
+		if err != nil {
+			return err
+		}
+		if value < 0 || value > l.max {
+			return &FieldError{Field: l.field, Message: fmt.Sprintf("debe estar entre 0 y %d", l.max)}
+		}
+	}
+
+	spacing, err := params.Float("line_spacing", 1)
+	if err != nil {
+		return err
+	}
+	if spacing < 0.5 || spacing > 3 {
+		return &FieldError{Field: "line_spacing", Message: "debe estar entre 0.5 y 3"}
+	}
+	return nil
+}
+
+// textStyle parámetros ya validados de la operación text
+type textStyle struct {
+	text        string
+	size        float64
+	fill        color.NRGBA
+	stroke      color.NRGBA
+	strokeWidth int
+	background  *color.NRGBA
+	padding     int
+	anchor      string
+	position    *image.Point
+	margin      int
+	align       string
+	maxWidth    int
+	lineSpacing float64
+}
+
+func textStyleFromParams(params OperationParams) textStyle {
+	s := textStyle{}
+	s.text, _ = params.String("text", "")
+	s.size, _ = params.Float("size", defaultTextSize)
+	fill, _ := params.String("color", defaultTextColor)
+	s.fill, _ = parseHexColor("color", fill)
+	stroke, _ := params.String("stroke_color", defaultTextStrokeColor)
+	s.stroke, _ = parseHexColor("stroke_color", stroke)
+	s.strokeWidth, _ = params.Int("stroke_width", 0)
+	if background, _ := params.String("background", ""); background != "" {
+		c, _ := parseHexColor("background", background)
+		s.background = &c
+	}
+	s.padding, _ = params.Int("padding", defaultTextPadding)
+	s.anchor, _ = params.String("anchor", defaultTextAnchor)
+	if params.Has("x") || params.Has("y") {
+		x, _ := params.Int("x", 0)
+		y, _ := params.Int("y", 0)
+		s.position = &image.Point{X: x, Y: y}
+	}
+	s.margin, _ = params.Int("margin", defaultTextMargin)
+	s.align, _ = params.String("align", textAnchors[s.anchor])
+	s.maxWidth, _ = params.Int("max_width", 0)
+	s.lineSpacing, _ = params.Float("line_spacing", 1)
+	return s
+}
+
+// applyText dibuja texto UTF-8 sobre la imagen con la fuente indicada, con
+// contorno, caja de fondo y ajuste de línea opcionales
+func (uc *TransformUseCase) applyText(ctx context.Context, img image.Image, params OperationParams) (image.Image, error) {
+	name, _ := params.String("font", defaultFont)
+	fontID, _ := params.Int("font_id", 0)
+	f, err := uc.loadFont(ctx, name, int64(fontID))
+	if err != nil {
+		return nil, err
+	}
+
+	style := textStyleFromParams(params)
+	face, err := newTextFace(f, style.size)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := face.wrap(style.text, style.maxWidth)
+
+	// Medidas del bloque de texto en píxeles
+	lineHeight := face.height * style.lineSpacing
+	widths := make([]float64, len(lines))
+	blockWidth := 0.0
+	for i, line := range lines {
+		widths[i] = face.measure(line)
+		blockWidth = math.Max(blockWidth, widths[i])
+	}
+	blockHeight := lineHeight*float64(len(lines)-1) + face.ascent + face.descent
+
+	// La caja incluye el contorno y, si hay fondo, el relleno
+	inset := style.strokeWidth
+	if style.background != nil {
+		inset += style.padding
+	}
+	boxSize := image.Pt(int(math.Ceil(blockWidth))+2*inset, int(math.Ceil(blockHeight))+2*inset)
+
+	dst := imaging.Clone(img)
+	bounds := dst.Bounds()
+
+	var origin image.Point
+	if style.position != nil {
+		origin = *style.position
+	} else {
+		origin = anchorPosition(style.anchor, bounds.Size(), boxSize, style.margin)
+	}
+	box := image.Rectangle{Min: origin, Max: origin.Add(boxSize)}
+
+	// Solo se rasteriza la parte visible de la caja, más el ancho del
+	// contorno para que los glifos que quedan fuera no corten su borde
+	region := box.Intersect(bounds.Inset(-style.strokeWidth))
+	if region.Empty() {
+		return dst, nil
+	}
+	offset := box.Min.Sub(region.Min)
+
+	raster := vector.NewRasterizer(region.Dx(), region.Dy())
+	for i, line := range lines {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		x := float64(offset.X + inset)
+		switch style.align {
+		case "center":
+			x += (blockWidth - widths[i]) / 2
+		case "right":
+			x += blockWidth - widths[i]
+		}
+		baseline := float64(offset.Y+inset) + face.ascent + lineHeight*float64(i)
+		face.draw(raster, line, x, baseline)
+	}
+
+	fill := image.NewAlpha(image.Rect(0, 0, region.Dx(), region.Dy()))
+	raster.Draw(fill, fill.Bounds(), image.Opaque, image.Point{})
+
+	if style.background != nil {
+		draw.Draw(dst, box, image.NewUniform(*style.background), image.Point{}, draw.Over)
+	}
+	if style.strokeWidth > 0 {
+		outline := dilateAlpha(fill, style.strokeWidth)
+		draw.DrawMask(dst, region, image.NewUniform(style.stroke), image.Point{}, outline, image.Point{}, draw.Over)
+	}
+	draw.DrawMask(dst, region, image.NewUniform(style.fill), image.Point{}, fill, image.Point{}, draw.Over)
+
+	return dst, nil
+}
+
+// anchorPosition calcula la esquina superior izquierda de una caja de tamaño
+// box ubicada en anchor dentro de un área de tamaño size
+func anchorPosition(anchor string, size, box image.Point, margin int) image.Point {
+	x := margin
+	switch anchor {
+	case "top", "center", "bottom":
+		x = (size.X - box.X) / 2
+	case "top-right", "right", "bottom-right":
+		x = size.X - box.X - margin
+	}
+
+	y := margin
+	switch anchor {
+	case "left", "center", "right":
+		y = (size.Y - box.Y) / 2
+	case "bottom-left", "bottom", "bottom-right":
+		y = size.Y - box.Y - margin
+	}
+	return image.Pt(x, y)
+}
+
+// textFace mide y dibuja texto con una fuente a un tamaño en píxeles
+type textFace struct {
+	font    *sfnt.Font
+	buf     sfnt.Buffer
+	ppem    fixed.Int26_6
+	ascent  float64
+	descent float64
+	height  float64
+}
+
+func newTextFace(f *sfnt.Font, size float64) (*textFace, error) {
+	face := &textFace{font: f, ppem: fixed.Int26_6(size * 64)}
+	metrics, err := f.Metrics(&face.buf, face.ppem, font.HintingNone)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudieron leer las métricas de la fuente: %w", err)
+	}
+	face.ascent = fixedToFloat(metrics.Ascent)
+	face.descent = fixedToFloat(metrics.Descent)
+	face.height = fixedToFloat(metrics.Height)
+	return face, nil
+}
+
+func fixedToFloat(v fixed.Int26_6) float64 {
+	return float64(v) / 64
+}
+
+// glyphs recorre los glifos de una línea con la posición horizontal de cada
+// uno, aplicando el kerning de la fuente. Los caracteres que la fuente no
+// tiene usan el glifo 0 (.notdef).
+func (f *textFace) glyphs(line string, visit func(idx sfnt.GlyphIndex, x fixed.Int26_6)) fixed.Int26_6 {
+	var x fixed.Int26_6
+	var prev sfnt.GlyphIndex
+	for i, r := range line {
+		idx, _ := f.font.GlyphIndex(&f.buf, r)
+		if i > 0 {
+			kern, _ := f.font.Kern(&f.buf, prev, idx, f.ppem, font.HintingNone)
+			x += kern
+		}
+		if visit != nil {
+			visit(idx, x)
+		}
+		advance, _ := f.font.GlyphAdvance(&f.buf, idx, f.ppem, font.HintingNone)
+		x += advance
+		prev = idx
+	}
+	return x
+}
+
+// measure devuelve el ancho de una línea en píxeles
+func (f *textFace) measure(line string) float64 {
+	return fixedToFloat(f.glyphs(line, nil))
+}
+
+// wrap separa el texto en líneas: respeta los saltos de línea y, si maxWidth
+// es mayor que cero, corta por palabras para no superar ese ancho. Una
+// palabra más ancha que maxWidth se corta entre caracteres.
+func (f *textFace) wrap(text string, maxWidth int) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		if maxWidth <= 0 {
+			lines = append(lines, paragraph)
+			continue
+		}
+
+		words := strings.Fields(paragraph)
+		if len(words) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+
+		line := ""
+		for _, word := range words {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if f.measure(candidate) <= float64(maxWidth) {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			line = word
+
+			// Cortar las palabras que no entran solas en una línea
+			for f.measure(line) > float64(maxWidth) {
+				head, tail := f.split(line, maxWidth)
+				lines = append(lines, head)
+				line = tail
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// split corta una palabra en el último carácter que entra en maxWidth; el
+// primer carácter siempre se conserva para avanzar aunque no entre
+func (f *textFace) split(word string, maxWidth int) (string, string) {
+	cut := 0
+	for i := range word {
+		if i > 0 && f.measure(word[:i]) > float64(maxWidth) {
+			break
+		}
+		cut = i
+	}
+	if cut == 0 {
+		_, size := utf8.DecodeRuneInString(word)
+		cut = size
+	}
+	return word[:cut], word[cut:]
+}
+
+// draw agrega los contornos de una línea al rasterizador con el origen de la
+// línea en (x, baseline)
+func (f *textFace) draw(raster *vector.Rasterizer, line string, x, baseline float64) {
+	var glyphBuf sfnt.Buffer
+	f.glyphs(line, func(idx sfnt.GlyphIndex, dx fixed.Int26_6) {
+		segments, err := f.font.LoadGlyph(&glyphBuf, idx, f.ppem, nil)
+		if err != nil {
+			// Glifos de color o inexistentes: se deja el espacio vacío
+			return
+		}
+
+		ox := float32(x + fixedToFloat(dx))
+		oy := float32(baseline)
+		point := func(p fixed.Point26_6) (float32, float32) {
+			return ox + float32(p.X)/64, oy + float32(p.Y)/64
+		}
+
+		started := false
+		for _, seg := range segments {
+			switch seg.Op {
+			case sfnt.SegmentOpMoveTo:
+				if started {
+					raster.ClosePath()
+				}
+				raster.MoveTo(point(seg.Args[0]))
+				started = true
+			case sfnt.SegmentOpLineTo:
+				raster.LineTo(point(seg.Args[0]))
+			case sfnt.SegmentOpQuadTo:
+				bx, by := point(seg.Args[0])
+				cx, cy := point(seg.Args[1])
+				raster.QuadTo(bx, by, cx, cy)
+			case sfnt.SegmentOpCubeTo:
+				bx, by := point(seg.Args[0])
+				cx, cy := point(seg.Args[1])
+				ex, ey := point(seg.Args[2])
+				raster.CubeTo(bx, by, cx, cy, ex, ey)
+			}
+		}
+		if started {
+			raster.ClosePath()
+		}
+	})
+}
+
+// dilateAlpha expande una máscara con un disco de radio r, lo que da el
+// contorno del texto. Cada fila se dilata horizontalmente con el algoritmo
+// de van Herk/Gil-Werman (costo constante por píxel) y luego se combinan las
+// filas vecinas con el semiancho del disco a esa distancia.
+func dilateAlpha(src *image.Alpha, r int) *image.Alpha {
+	bounds := src.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	dst := image.NewAlpha(bounds)
+
+	horizontal := map[int][]uint8{}
+	rowsFor := func(k int) []uint8 {
+		if rows, ok := horizontal[k]; ok {
+			return rows
+		}
+		rows := make([]uint8, w*h)
+		for y := 0; y < h; y++ {
+			row := src.Pix[y*src.Stride : y*src.Stride+w]
+			dilateRow(rows[y*w:(y+1)*w], row, k)
+		}
+		horizontal[k] = rows
+		return rows
+	}
+
+	for dy := -r; dy <= r; dy++ {
+		rows := rowsFor(int(math.Sqrt(float64(r*r - dy*dy))))
+		for y := 0; y < h; y++ {
+			sy := y + dy
+			if sy < 0 || sy >= h {
+				continue
+			}
+			out := dst.Pix[y*dst.Stride : y*dst.Stride+w]
+			for x, v := range rows[sy*w : (sy+1)*w] {
+				if v > out[x] {
+					out[x] = v
+				}
+			}
+		}
+	}
+	return dst
+}
+
+// dilateRow escribe en out el máximo de row en la ventana [x-k, x+k]
+func dilateRow(out, row []uint8, k int) {
+	if k == 0 {
+		copy(out, row)
+		return
+	}
+
+	// Se rellena con ceros a ambos lados para que toda ventana tenga 2k+1
+	size := 2*k + 1
+	n := len(row) + 2*k
+	padded := make([]uint8, n)
+	copy(padded[k:], row)
+
+	prefix := make([]uint8, n)
+	suffix := make([]uint8, n)
+	for i := 0; i < n; i++ {
+		prefix[i] = padded[i]
+		if i%size != 0 && prefix[i-1] > prefix[i] {
+			prefix[i] = prefix[i-1]
+		}
+	}
+	for i := n - 1; i >= 0; i-- {
+		suffix[i] = padded[i]
+		if (i+1)%size != 0 && i+1 < n && suffix[i+1] > suffix[i] {
+			suffix[i] = suffix[i+1]
+		}
+	}
+
+	for x := range out {
+		// La ventana en coordenadas con relleno es [x, x+2k]
+		out[x] = max(suffix[x], prefix[x+2*k])
+	}
+}
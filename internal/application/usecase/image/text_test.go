@@ -0,0 +1,273 @@
+package image_test
+
+import (
+	"context"
+	"errors"
+	stdimage "image"
+	"image/color"
+	"testing"
+
+	"github.com/RodrigoGonzalez78/internal/application/usecase/image"
+	"github.com/RodrigoGonzalez78/internal/domain/repository/mocks"
+	"golang.org/x/image/font/gofont/gobold"
+)
+
+// setupText crea el caso de uso con un repositorio de fuentes propio sobre
+// la imagen de 400x300 de setupTransform (su fondo siempre tiene B=128)
+func setupText(t *testing.T) (*image.TransformUseCase, *image.FontUseCase, *mocks.MockFileStorage) {
+	t.Helper()
+	_, imageRepo, fileStorage := setupTransform(t)
+	fontRepo := mocks.NewMockFontRepository()
+
+	useCase := image.NewTransformUseCase(imageRepo, fontRepo, fileStorage, nil, image.DefaultImageLimits())
+	return useCase, image.NewFontUseCase(fontRepo, fileStorage), fileStorage
+}
+
+func renderText(t *testing.T, useCase *image.TransformUseCase, params image.OperationParams) stdimage.Image {
+	t.Helper()
+	output, err := useCase.Execute(context.Background(), image.TransformInput{
+		ImageID:    1,
+		UserName:   "testuser",
+		Operations: []image.Operation{{Type: "text", Params: params}},
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	return decodeOutput(t, output)
+}
+
+// colorBounds devuelve el rectángulo que contiene todos los píxeles del color
+func colorBounds(img stdimage.Image, want color.NRGBA) stdimage.Rectangle {
+	var found stdimage.Rectangle
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if color.NRGBAModel.Convert(img.At(x, y)) == want {
+				found = found.Union(stdimage.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	return found
+}
+
+var (
+	textRed   = color.NRGBA{R: 255, A: 255}
+	textGreen = color.NRGBA{G: 255, A: 255}
+	textBlue  = color.NRGBA{B: 255, A: 255}
+)
+
+func TestTransformUseCase_TextPosition(t *testing.T) {
+	tests := []struct {
+		name   string
+		params image.OperationParams
+		check  func(r stdimage.Rectangle) bool
+	}{
+		{
+			name:   "arriba a la izquierda por defecto",
+			params: image.OperationParams{},
+			check: func(r stdimage.Rectangle) bool {
+				return r.Min.X >= 16 && r.Min.Y >= 16 && r.Max.X < 200 && r.Max.Y < 150
+			},
+		},
+		{
+			name:   "abajo a la derecha",
+			params: image.OperationParams{"anchor": "bottom-right"},
+			check: func(r stdimage.Rectangle) bool {
+				return r.Min.X > 200 && r.Min.Y > 150 && r.Max.X <= 384 && r.Max.Y <= 284
+			},
+		},
+		{
+			name:   "centrado arriba",
+			params: image.OperationParams{"anchor": "top"},
+			check: func(r stdimage.Rectangle) bool {
+				center := (r.Min.X + r.Max.X) / 2
+				return center > 190 && center < 210 && r.Max.Y < 150
+			},
+		},
+		{
+			name:   "posición absoluta",
+			params: image.OperationParams{"x": 100.0, "y": 200.0},
+			check: func(r stdimage.Rectangle) bool {
+				return r.Min.X >= 100 && r.Min.X < 110 && r.Min.Y >= 200 && r.Min.Y < 240
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCase, _, _ := setupText(t)
+
+			params := image.OperationParams{"text": "HOLA", "size": 40.0, "color": "#f00"}
+			for k, v := range tt.params {
+				params[k] = v
+			}
+
+			result := renderText(t, useCase, params)
+			if b := result.Bounds(); b.Dx() != 400 || b.Dy() != 300 {
+				t.Fatalf("dimensiones = %v, el texto no debe cambiar el tamaño", b.Size())
+			}
+
+			drawn := colorBounds(result, textRed)
+			if drawn.Empty() {
+				t.Fatal("no se dibujó el texto")
+			}
+			if !tt.check(drawn) {
+				t.Errorf("texto dibujado en %v, fuera de la posición esperada", drawn)
+			}
+		})
+	}
+}
+
+func TestTransformUseCase_TextWrap(t *testing.T) {
+	useCase, _, _ := setupText(t)
+	params := image.OperationParams{"text": "uno dos tres cuatro cinco seis", "size": 24.0, "color": "#ff0000"}
+
+	single := colorBounds(renderText(t, useCase, params), textRed)
+
+	params["max_width"] = 120.0
+	wrapped := colorBounds(renderText(t, useCase, params), textRed)
+
+	if wrapped.Dx() > 120 {
+		t.Errorf("ancho con max_width = %d, want <= 120", wrapped.Dx())
+	}
+	if wrapped.Dy() < 2*single.Dy() {
+		t.Errorf("alto con max_width = %d, sin ajuste = %d; se esperaban varias líneas", wrapped.Dy(), single.Dy())
+	}
+
+	// Una palabra más ancha que max_width se corta entre caracteres
+	long := colorBounds(renderText(t, useCase, image.OperationParams{
+		"text": "supercalifragilístico", "size": 24.0, "color": "#ff0000", "max_width": 80.0,
+	}), textRed)
+	if long.Dx() > 80 {
+		t.Errorf("palabra larga con max_width = %d de ancho, want <= 80", long.Dx())
+	}
+}
+
+func TestTransformUseCase_TextStrokeAndBackground(t *testing.T) {
+	useCase, _, _ := setupText(t)
+
+	result := renderText(t, useCase, image.OperationParams{
+		"text":         "¡Año 2024!",
+		"size":         40.0,
+		"color":        "#ff0000",
+		"stroke_width": 3.0,
+		"stroke_color": "#00ff00",
+		"background":   "#0000ff",
+		"padding":      10.0,
+		"margin":       0.0,
+	})
+
+	fill := colorBounds(result, textRed)
+	stroke := colorBounds(result, textGreen)
+	if fill.Empty() || stroke.Empty() {
+		t.Fatalf("relleno en %v y contorno en %v, se esperaban ambos", fill, stroke)
+	}
+	if !fill.In(stroke.Inset(-1)) || stroke.Dx() < fill.Dx()+4 {
+		t.Errorf("el contorno %v debe rodear al relleno %v", stroke, fill)
+	}
+
+	// La caja de fondo empieza en la esquina (margin 0) y rodea al contorno
+	box := colorBounds(result, textBlue)
+	if box.Min != (stdimage.Point{}) {
+		t.Errorf("caja de fondo en %v, want desde (0,0)", box)
+	}
+	if !stroke.In(box) || stroke.Min.X-box.Min.X < 10 {
+		t.Errorf("la caja %v debe rodear al contorno %v con el relleno indicado", box, stroke)
+	}
+}
+
+func TestTransformUseCase_TextErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    image.OperationParams
+		wantField string
+	}{
+		{name: "sin texto", params: image.OperationParams{}, wantField: "text"},
+		{name: "texto vacío", params: image.OperationParams{"text": "   "}, wantField: "text"},
+		{name: "fuente desconocida", params: image.OperationParams{"text": "a", "font": "arial"}, wantField: "font"},
+		{name: "fuente y font_id", params: image.OperationParams{"text": "a", "font": "gobold", "font_id": 1.0}, wantField: "font"},
+		{name: "tamaño en cero", params: image.OperationParams{"text": "a", "size": 0.0}, wantField: "size"},
+		{name: "color inválido", params: image.OperationParams{"text": "a", "color": "rojo"}, wantField: "color"},
+		{name: "fondo inválido", params: image.OperationParams{"text": "a", "background": "#12345"}, wantField: "background"},
+		{name: "anclaje inválido", params: image.OperationParams{"text": "a", "anchor": "arriba"}, wantField: "anchor"},
+		{name: "anclaje y posición", params: image.OperationParams{"text": "a", "anchor": "top", "x": 10.0}, wantField: "anchor"},
+		{name: "alineación inválida", params: image.OperationParams{"text": "a", "align": "justify"}, wantField: "align"},
+		{name: "contorno excesivo", params: image.OperationParams{"text": "a", "stroke_width": 51.0}, wantField: "stroke_width"},
+		{name: "ancho negativo", params: image.OperationParams{"text": "a", "max_width": -1.0}, wantField: "max_width"},
+		{name: "interlineado fuera de rango", params: image.OperationParams{"text": "a", "line_spacing": 4.0}, wantField: "line_spacing"},
+		{name: "fuente inexistente", params: image.OperationParams{"text": "a", "font_id": 99.0}, wantField: "font_id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCase, _, _ := setupText(t)
+
+			_, err := useCase.Execute(context.Background(), image.TransformInput{
+				ImageID:    1,
+				UserName:   "testuser",
+				Operations: []image.Operation{{Type: "text", Params: tt.params}},
+			})
+
+			var stepErr *image.StepError
+			var fieldErr *image.FieldError
+			if !errors.As(err, &stepErr) || !errors.As(err, &fieldErr) {
+				t.Fatalf("Execute() error = %v, want StepError con FieldError", err)
+			}
+			if fieldErr.Field != tt.wantField {
+				t.Errorf("FieldError.Field = %v, want %v", fieldErr.Field, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestFontUseCase_UserFonts(t *testing.T) {
+	useCase, fontUC, fileStorage := setupText(t)
+	ctx := context.Background()
+
+	if _, err := fontUC.Upload(ctx, image.FontUploadInput{UserName: "testuser", FileName: "x.ttf", Data: []byte("no es una fuente")}); !errors.Is(err, image.ErrInvalidFont) {
+		t.Errorf("Upload() con datos inválidos error = %v, want ErrInvalidFont", err)
+	}
+
+	font, err := fontUC.Upload(ctx, image.FontUploadInput{UserName: "testuser", FileName: "bold.ttf", Data: gobold.TTF})
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if font.Name != "Go" || font.Family != "Go" {
+		t.Errorf("Upload() = %+v, se esperaba el nombre de la familia", font)
+	}
+	if _, ok := fileStorage.Files["testuser/fonts/bold.ttf"]; !ok {
+		t.Error("la fuente debe guardarse en el storage del usuario")
+	}
+	if !image.IsFontPath("testuser/fonts/bold.ttf") || image.IsFontPath("testuser/test.png") {
+		t.Error("IsFontPath() no distingue las fuentes de las imágenes")
+	}
+
+	params := image.OperationParams{"text": "HOLA", "size": 40.0, "color": "#ff0000", "font_id": float64(font.ID)}
+	if drawn := colorBounds(renderText(t, useCase, params), textRed); drawn.Empty() {
+		t.Error("no se dibujó el texto con la fuente subida")
+	}
+
+	// La fuente solo puede usarse sobre imágenes de su dueño
+	fileStorage.Files["otro/foto.png"] = encodeTestPNG(t, 100, 100)
+	if _, err := useCase.ExecutePath(ctx, image.TransformPathInput{
+		ObjectPath: "otro/foto.png",
+		Operations: []image.Operation{{Type: "text", Params: params}},
+	}); err == nil {
+		t.Error("ExecutePath() con fuente ajena se esperaba error")
+	}
+
+	fonts, err := fontUC.List("testuser")
+	if err != nil || len(fonts) != 1 {
+		t.Fatalf("List() = %v, %v; want una fuente", fonts, err)
+	}
+
+	if err := fontUC.Delete(ctx, "otro", font.ID); err == nil {
+		t.Error("Delete() de otro usuario se esperaba error")
+	}
+	if err := fontUC.Delete(ctx, "testuser", font.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, ok := fileStorage.Files["testuser/fonts/bold.ttf"]; ok {
+		t.Error("Delete() debe borrar la fuente del storage")
+	}
+}
@@ -1,37 +1,79 @@
 package image
 
 import (
+	"bytes"
 	"context"
 	"errors"
-	"image"
-	"image/color"
 	"io"
+	"strings"
 
 	"github.com/RodrigoGonzalez78/internal/domain/repository"
-	"github.com/disintegration/imaging"
 )
 
 // TransformUseCase maneja el caso de uso de transformación de imágenes
 type TransformUseCase struct {
 	imageRepo   repository.ImageRepository
+	fontRepo    repository.FontRepository
 	fileStorage repository.FileStorage
+	cache       *DerivativeCache
+	registry    *OperationRegistry
+	limits      ImageLimits
 }
 
-// NewTransformUseCase crea una nueva instancia de TransformUseCase
+// NewTransformUseCase crea una nueva instancia de TransformUseCase. Si cache
+// es nil cada petición se procesa desde el original. Los límites se aplican
+// tanto a la imagen de origen como a las dimensiones pedidas en el pipeline.
 func NewTransformUseCase(
 	imageRepo repository.ImageRepository,
+	fontRepo repository.FontRepository,
 	fileStorage repository.FileStorage,
+	cache *DerivativeCache,
+	limits ImageLimits,
 ) *TransformUseCase {
-	return &TransformUseCase{
+	uc := &TransformUseCase{
 		imageRepo:   imageRepo,
+		fontRepo:    fontRepo,
 		fileStorage: fileStorage,
+		cache:       cache,
+		registry:    NewOperationRegistry(),
+		limits:      limits,
 	}
+
+	// El tamaño final de resize solo se conoce al aplicarlo
+	uc.registry.Register("resize", validateResize, resizeApplier(limits))
+
+	// Operaciones que necesitan leer otras imágenes o fuentes del storage
+	uc.registry.Register("watermark", validateWatermark, uc.applyWatermark)
+	uc.registry.Register("text", validateText, uc.applyText)
+
+	return uc
+}
+
+// ValidateOperations valida un pipeline con el registro del caso de uso
+func (uc *TransformUseCase) ValidateOperations(ops []Operation) error {
+	if err := uc.registry.Validate(ops); err != nil {
+		return err
+	}
+	return uc.limits.checkOperations(ops)
+}
+
+// ValidateFormat valida el formato de salida y sus opciones de codificación
+func (uc *TransformUseCase) ValidateFormat(format string, opts EncodeOptions) error {
+	_, err := resolveEncoding(format, opts)
+	return err
 }
 
-// ResizeParams parámetros de redimensionado
+// ResizeParams parámetros de redimensionado. Basta con Width o Height para
+// escalar conservando la proporción; Mode admite exact, fit, fill (cover) y
+// contain (pad).
 type ResizeParams struct {
-	Width  int
-	Height int
+	Width      int
+	Height     int
+	Mode       string
+	Gravity    string
+	Background string
+	Filter     string
+	NoUpscale  bool
 }
 
 // CropParams parámetros de recorte
@@ -48,26 +90,50 @@ type FilterParams struct {
 	Sepia     bool
 }
 
-// TransformInput representa los datos de entrada
+// TransformInput representa los datos de entrada. Si Operations está vacío
+// se usan los campos Resize, Crop, Rotate y Filters (formato anterior).
 type TransformInput struct {
-	ImageID  int64
-	UserName string
-	Resize   ResizeParams
-	Crop     CropParams
-	Rotate   float64
-	Format   string
-	Filters  FilterParams
+	ImageID    int64
+	UserName   string
+	Operations []Operation
+	Resize     ResizeParams
+	Crop       CropParams
+	Rotate     float64
+	Format     string
+	Encoding   EncodeOptions
+	Filters    FilterParams
 }
 
-// TransformOutput representa los datos de salida
+// Pipeline devuelve los pasos a ejecutar para la entrada
+func (in TransformInput) Pipeline() []Operation {
+	if len(in.Operations) > 0 {
+		return in.Operations
+	}
+	return LegacyOperations(in.Resize, in.Crop, in.Rotate, in.Filters)
+}
+
+// TransformOutput representa los datos de salida ya codificados
 type TransformOutput struct {
-	Image       image.Image
-	Format      imaging.Format
+	Data        []byte
+	Format      string
+	Extension   string
 	ContentType string
+	Cached      bool
 }
 
 // Execute ejecuta el caso de uso
 func (uc *TransformUseCase) Execute(ctx context.Context, input TransformInput) (*TransformOutput, error) {
+	ops := input.Pipeline()
+
+	// Validar el pipeline y el formato antes de tocar el storage
+	if err := uc.ValidateOperations(ops); err != nil {
+		return nil, err
+	}
+	enc, err := resolveEncoding(input.Format, input.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
 	// Obtener metadata de la imagen
 	imageData, err := uc.imageRepo.FindByID(input.ImageID)
 	if err != nil {
@@ -79,73 +145,130 @@ func (uc *TransformUseCase) Execute(ctx context.Context, input TransformInput) (
 		return nil, errors.New("no tienes permiso para esta imagen")
 	}
 
-	// Obtener imagen del storage
-	reader, err := uc.fileStorage.Get(ctx, imageData.Path)
+	return uc.render(ctx, imageData.UserName, imageData.Path, ops, enc)
+}
+
+// TransformPathInput representa la entrada para transformar un objeto por su
+// ruta en el storage (usado por las URLs firmadas)
+type TransformPathInput struct {
+	ObjectPath string
+	Operations []Operation
+	Format     string
+	Encoding   EncodeOptions
+}
+
+// ExecutePath transforma un objeto por su ruta; la autorización la resuelve
+// quien llama (por ejemplo verificando la firma de la URL)
+func (uc *TransformUseCase) ExecutePath(ctx context.Context, input TransformPathInput) (*TransformOutput, error) {
+	if err := uc.ValidateOperations(input.Operations); err != nil {
+		return nil, err
+	}
+	enc, err := resolveEncoding(input.Format, input.Encoding)
 	if err != nil {
-		return nil, errors.New("no se pudo leer la imagen desde el storage")
+		return nil, err
 	}
-	defer reader.Close()
 
-	// Decodificar imagen
-	srcImage, _, err := image.Decode(reader)
+	return uc.render(ctx, pathOwner(input.ObjectPath), uc.resolveObject(input.ObjectPath), input.Operations, enc)
+}
+
+// resolveObject traduce la ruta pública {usuario}/{nombre} de una imagen al
+// objeto del storage que guarda su contenido. Las demás rutas (versiones,
+// resultados de trabajos e imágenes anteriores a los blobs) no cambian.
+func (uc *TransformUseCase) resolveObject(objectPath string) string {
+	owner, name, ok := strings.Cut(objectPath, "/")
+	if !ok || strings.Contains(name, "/") {
+		return objectPath
+	}
+	image, err := uc.imageRepo.FindByName(owner, name)
 	if err != nil {
-		return nil, errors.New("error al decodificar la imagen")
+		return objectPath
 	}
+	return image.Path
+}
 
-	dstImage := srcImage
+// render resuelve un pipeline sobre un objeto del storage, usando la caché
+// de derivados si está configurada. owner es el usuario dueño del objeto.
+func (uc *TransformUseCase) render(ctx context.Context, owner, objectPath string, ops []Operation, enc *outputEncoding) (*TransformOutput, error) {
+	output := &TransformOutput{
+		Format:      enc.Name,
+		Extension:   enc.Extension,
+		ContentType: enc.ContentType,
+	}
 
-	// Aplicar transformaciones
-	if input.Resize.Width > 0 && input.Resize.Height > 0 {
-		dstImage = imaging.Resize(dstImage, input.Resize.Width, input.Resize.Height, imaging.Lanczos)
+	process := func(ctx context.Context) ([]byte, error) {
+		return uc.process(withOwner(ctx, owner), objectPath, ops, enc)
+	}
+
+	if uc.cache == nil {
+		data, err := process(ctx)
+		if err != nil {
+			return nil, err
+		}
+		output.Data = data
+		return output, nil
+	}
+
+	// El ETag del original forma parte de la clave: si la imagen se reemplaza
+	// o se elimina, los derivados anteriores dejan de ser alcanzables
+	source, err := uc.fileStorage.Stat(ctx, objectPath)
+	if err != nil {
+		return nil, errors.New("no se pudo leer la imagen desde el storage")
+	}
+
+	key, err := DerivativeKey(source, owner, ops, enc.cacheFormat())
+	if err != nil {
+		return nil, err
 	}
 
-	if input.Crop.Width > 0 && input.Crop.Height > 0 {
-		cropRect := image.Rect(
-			input.Crop.X,
-			input.Crop.Y,
-			input.Crop.X+input.Crop.Width,
-			input.Crop.Y+input.Crop.Height,
-		)
-		dstImage = imaging.Crop(dstImage, cropRect)
+	data, cached, err := uc.cache.GetOrCreate(ctx, objectPath, key, enc.ContentType, process)
+	if err != nil {
+		return nil, err
 	}
 
-	if input.Rotate != 0 {
-		dstImage = imaging.Rotate(dstImage, input.Rotate, color.Transparent)
+	output.Data = data
+	output.Cached = cached
+	return output, nil
+}
+
+// process descarga, decodifica, aplica el pipeline y codifica el resultado
+func (uc *TransformUseCase) process(ctx context.Context, objectPath string, ops []Operation, enc *outputEncoding) ([]byte, error) {
+	// Obtener imagen del storage
+	reader, err := uc.fileStorage.Get(ctx, objectPath)
+	if err != nil {
+		return nil, errors.New("no se pudo leer la imagen desde el storage")
 	}
+	defer reader.Close()
 
-	if input.Filters.Grayscale {
-		dstImage = imaging.Grayscale(dstImage)
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, errors.New("no se pudo leer la imagen desde el storage")
 	}
 
-	if input.Filters.Sepia {
-		dstImage = imaging.AdjustSaturation(dstImage, -100)
-		dstImage = imaging.AdjustContrast(dstImage, 10)
+	// Verificar las dimensiones declaradas antes de reservar memoria para
+	// los píxeles
+	srcImage, err := uc.limits.decode(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.limits.checkPipeline(ops, srcImage.Bounds().Size()); err != nil {
+		return nil, err
 	}
 
-	// Determinar formato de salida
-	var imgFormat imaging.Format
-	var contentType string
+	// Aplicar transformaciones en el orden solicitado
+	dstImage, err := uc.registry.Run(ctx, srcImage, ops)
+	if err != nil {
+		return nil, err
+	}
 
-	switch input.Format {
-	case "jpg", "jpeg":
-		imgFormat = imaging.JPEG
-		contentType = "image/jpeg"
-	case "gif":
-		imgFormat = imaging.GIF
-		contentType = "image/gif"
-	default:
-		imgFormat = imaging.PNG
-		contentType = "image/png"
+	var buf bytes.Buffer
+	if err := enc.encode(&buf, dstImage); err != nil {
+		return nil, errors.New("error al codificar la imagen")
 	}
 
-	return &TransformOutput{
-		Image:       dstImage,
-		Format:      imgFormat,
-		ContentType: contentType,
-	}, nil
+	return buf.Bytes(), nil
 }
 
-// ServeImage obtiene una imagen del storage para servirla
+// ServeImage obtiene una imagen del storage por su ruta pública para servirla
 func (uc *TransformUseCase) ServeImage(ctx context.Context, objectPath string) (io.ReadCloser, error) {
-	return uc.fileStorage.Get(ctx, objectPath)
+	return uc.fileStorage.Get(ctx, uc.resolveObject(objectPath))
 }
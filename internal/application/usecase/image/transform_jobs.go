@@ -0,0 +1,449 @@
+package image
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/RodrigoGonzalez78/internal/domain/entity"
+	"github.com/RodrigoGonzalez78/internal/domain/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrJobNotFound = errors.New("trabajo no encontrado")
+	ErrJobFinished = errors.New("el trabajo ya finalizó")
+)
+
+// TransformJobUseCase encola transformaciones y las ejecuta en segundo plano
+// con un pool acotado de workers. El estado vive en la base de datos, así que
+// los trabajos pendientes sobreviven a un reinicio. Los trabajos terminados
+// se borran con su resultado al cumplirse la retención.
+type TransformJobUseCase struct {
+	jobRepo     repository.TransformJobRepository
+	imageRepo   repository.ImageRepository
+	fileStorage repository.FileStorage
+	transformUC *TransformUseCase
+	baseURL     string
+	port        string
+	retention   time.Duration
+
+	queue chan int64
+
+	mu       sync.Mutex
+	enqueued map[int64]bool
+	running  map[int64]context.CancelFunc
+}
+
+// NewTransformJobUseCase crea una nueva instancia de TransformJobUseCase.
+// queueSize acota los trabajos en memoria; el resto espera en la base de
+// datos hasta el siguiente escaneo. retention en cero conserva los trabajos
+// terminados indefinidamente.
+func NewTransformJobUseCase(
+	jobRepo repository.TransformJobRepository,
+	imageRepo repository.ImageRepository,
+	fileStorage repository.FileStorage,
+	transformUC *TransformUseCase,
+	baseURL, port string,
+	queueSize int,
+	retention time.Duration,
+) *TransformJobUseCase {
+	if queueSize <= 0 {
+		queueSize = 1
+	}
+
+	return &TransformJobUseCase{
+		jobRepo:     jobRepo,
+		imageRepo:   imageRepo,
+		fileStorage: fileStorage,
+		transformUC: transformUC,
+		baseURL:     baseURL,
+		port:        port,
+		retention:   retention,
+		queue:       make(chan int64, queueSize),
+		enqueued:    make(map[int64]bool),
+		running:     make(map[int64]context.CancelFunc),
+	}
+}
+
+// JobOutput representa el estado de un trabajo
+type JobOutput struct {
+	ID         int64
+	ImageID    int64
+	Status     entity.JobStatus
+	ResultURL  string
+	Error      string
+	CreatedAt  time.Time
+	StartedAt  *time.Time
+	FinishedAt *time.Time
+}
+
+// Submit valida la transformación, guarda el trabajo y lo encola
+func (uc *TransformJobUseCase) Submit(ctx context.Context, input TransformInput) (*JobOutput, error) {
+	ops := input.Pipeline()
+
+	if err := uc.transformUC.ValidateOperations(ops); err != nil {
+		return nil, err
+	}
+	if err := uc.transformUC.ValidateFormat(input.Format, input.Encoding); err != nil {
+		return nil, err
+	}
+
+	imageData, err := uc.imageRepo.FindByID(input.ImageID)
+	if err != nil {
+		return nil, errors.New("imagen no encontrada")
+	}
+
+	if imageData.UserName != input.UserName {
+		return nil, errors.New("no tienes permiso para esta imagen")
+	}
+
+	encoded, err := json.Marshal(ops)
+	if err != nil {
+		return nil, fmt.Errorf("error al serializar las operaciones: %w", err)
+	}
+
+	var encoding string
+	if !input.Encoding.Empty() {
+		options, err := json.Marshal(input.Encoding)
+		if err != nil {
+			return nil, fmt.Errorf("error al serializar las opciones de formato: %w", err)
+		}
+		encoding = string(options)
+	}
+
+	job := entity.NewTransformJob(input.UserName, input.ImageID, string(encoded), input.Format, encoding)
+	if err := uc.jobRepo.Create(job); err != nil {
+		return nil, fmt.Errorf("error al guardar el trabajo: %w", err)
+	}
+
+	uc.enqueue(job.ID)
+
+	return uc.toOutput(job), nil
+}
+
+// Get devuelve el estado de un trabajo del usuario
+func (uc *TransformJobUseCase) Get(id int64, userName string) (*JobOutput, error) {
+	job, err := uc.findOwned(id, userName)
+	if err != nil {
+		return nil, err
+	}
+	return uc.toOutput(job), nil
+}
+
+// Cancel cancela un trabajo en cola o en ejecución
+func (uc *TransformJobUseCase) Cancel(id int64, userName string) (*JobOutput, error) {
+	if _, err := uc.findOwned(id, userName); err != nil {
+		return nil, err
+	}
+
+	canceled, err := uc.jobRepo.Cancel(id)
+	if err != nil {
+		return nil, err
+	}
+	if !canceled {
+		return nil, ErrJobFinished
+	}
+
+	// Si un worker lo está ejecutando, interrumpirlo entre pasos del pipeline
+	uc.mu.Lock()
+	if cancel, ok := uc.running[id]; ok {
+		cancel()
+	}
+	uc.mu.Unlock()
+
+	job, err := uc.jobRepo.FindByID(id)
+	if err != nil {
+		return nil, ErrJobNotFound
+	}
+	return uc.toOutput(job), nil
+}
+
+// Start recupera los trabajos interrumpidos, lanza los workers y revisa la
+// base de datos cada pollInterval en busca de trabajos en cola. Bloquea hasta
+// que se cancele el contexto.
+func (uc *TransformJobUseCase) Start(ctx context.Context, workers int, pollInterval time.Duration) {
+	if workers <= 0 {
+		return
+	}
+
+	if requeued, err := uc.jobRepo.RequeueRunning(); err != nil {
+		log.Printf("  Error al recuperar trabajos interrumpidos: %v", err)
+	} else if requeued > 0 {
+		log.Printf(" Trabajos interrumpidos vueltos a encolar: %d", requeued)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			uc.worker(ctx)
+		}()
+	}
+
+	uc.scan()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case <-ticker.C:
+			uc.scan()
+		}
+	}
+}
+
+// ExpireFinished borra hasta limit trabajos terminados hace más que la
+// retención, con sus resultados, y devuelve cuántos se borraron
+func (uc *TransformJobUseCase) ExpireFinished(ctx context.Context, limit int) (int, error) {
+	if uc.retention <= 0 {
+		return 0, nil
+	}
+
+	jobs, err := uc.jobRepo.FindFinishedBefore(time.Now().Add(-uc.retention), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for i := range jobs {
+		if err := deleteJob(ctx, uc.jobRepo, uc.fileStorage, &jobs[i]); err != nil {
+			log.Printf("  No se pudo borrar el trabajo %d: %v", jobs[i].ID, err)
+			continue
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+// StartJanitor borra los trabajos vencidos cada interval hasta que se
+// cancele ctx
+func (uc *TransformJobUseCase) StartJanitor(ctx context.Context, interval time.Duration) {
+	if interval <= 0 || uc.retention <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expired, err := uc.ExpireFinished(ctx, 100)
+			if err != nil {
+				log.Printf("  Error al borrar trabajos vencidos: %v", err)
+				continue
+			}
+			if expired > 0 {
+				log.Printf(" Trabajos vencidos borrados: %d", expired)
+			}
+		}
+	}
+}
+
+// deleteJob borra el resultado de un trabajo y después su fila, así un fallo
+// del storage deja la fila para reintentarlo. Si el trabajo sigue
+// ejecutándose, Finish ya no encuentra la fila y el worker descarta el
+// resultado que subió.
+func deleteJob(ctx context.Context, jobRepo repository.TransformJobRepository, fileStorage repository.FileStorage, job *entity.TransformJob) error {
+	if job.ResultPath != "" {
+		if err := fileStorage.Delete(ctx, job.ResultPath); err != nil {
+			return fmt.Errorf("error al eliminar el resultado: %w", err)
+		}
+	}
+	if err := jobRepo.Delete(job.ID); err != nil {
+		return fmt.Errorf("error al eliminar el trabajo: %w", err)
+	}
+	return nil
+}
+
+// scan encola los trabajos pendientes que no estén ya en memoria
+func (uc *TransformJobUseCase) scan() {
+	jobs, err := uc.jobRepo.FindQueued(cap(uc.queue))
+	if err != nil {
+		log.Printf("  Error al buscar trabajos en cola: %v", err)
+		return
+	}
+
+	for _, job := range jobs {
+		if !uc.enqueue(job.ID) {
+			return
+		}
+	}
+}
+
+// enqueue agrega el trabajo a la cola en memoria sin bloquear. Si la cola
+// está llena el trabajo queda en la base de datos para el próximo escaneo.
+func (uc *TransformJobUseCase) enqueue(id int64) bool {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	if uc.enqueued[id] {
+		return true
+	}
+
+	select {
+	case uc.queue <- id:
+		uc.enqueued[id] = true
+		return true
+	default:
+		return false
+	}
+}
+
+func (uc *TransformJobUseCase) worker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-uc.queue:
+			uc.mu.Lock()
+			delete(uc.enqueued, id)
+			uc.mu.Unlock()
+
+			uc.run(ctx, id)
+		}
+	}
+}
+
+// run ejecuta un trabajo. MarkRunning es atómico, así que un trabajo
+// encolado dos veces solo se procesa una.
+func (uc *TransformJobUseCase) run(ctx context.Context, id int64) {
+	started, err := uc.jobRepo.MarkRunning(id)
+	if err != nil {
+		log.Printf("  Error al iniciar el trabajo %d: %v", id, err)
+		return
+	}
+	if !started {
+		return
+	}
+
+	job, err := uc.jobRepo.FindByID(id)
+	if err != nil {
+		log.Printf("  Error al leer el trabajo %d: %v", id, err)
+		return
+	}
+
+	jobCtx, cancel := context.WithCancel(ctx)
+	uc.mu.Lock()
+	uc.running[id] = cancel
+	uc.mu.Unlock()
+
+	defer func() {
+		uc.mu.Lock()
+		delete(uc.running, id)
+		uc.mu.Unlock()
+		cancel()
+	}()
+
+	resultPath, err := uc.process(jobCtx, job)
+
+	// El servidor se está apagando: el trabajo queda en running y se vuelve a
+	// encolar en el próximo arranque
+	if ctx.Err() != nil {
+		if resultPath != "" {
+			uc.fileStorage.Delete(context.WithoutCancel(ctx), resultPath)
+		}
+		return
+	}
+
+	now := time.Now()
+	job.FinishedAt = &now
+	if err != nil {
+		job.Status = entity.JobFailed
+		job.Error = err.Error()
+	} else {
+		job.Status = entity.JobSucceeded
+		job.ResultPath = resultPath
+	}
+
+	finished, finishErr := uc.jobRepo.Finish(job)
+	if finishErr != nil {
+		log.Printf("  Error al guardar el resultado del trabajo %d: %v", id, finishErr)
+		return
+	}
+
+	// Cancelado mientras corría: el resultado no se publica
+	if !finished && resultPath != "" {
+		if err := uc.fileStorage.Delete(context.WithoutCancel(ctx), resultPath); err != nil {
+			log.Printf("  No se pudo eliminar el resultado del trabajo cancelado %d: %v", id, err)
+		}
+	}
+}
+
+// process ejecuta la transformación y guarda el resultado como un objeto nuevo
+func (uc *TransformJobUseCase) process(ctx context.Context, job *entity.TransformJob) (string, error) {
+	var ops []Operation
+	if err := json.Unmarshal([]byte(job.Operations), &ops); err != nil {
+		return "", fmt.Errorf("operaciones inválidas: %w", err)
+	}
+
+	var encoding EncodeOptions
+	if job.Encoding != "" {
+		if err := json.Unmarshal([]byte(job.Encoding), &encoding); err != nil {
+			return "", fmt.Errorf("opciones de formato inválidas: %w", err)
+		}
+	}
+
+	output, err := uc.transformUC.Execute(ctx, TransformInput{
+		ImageID:    job.ImageID,
+		UserName:   job.UserName,
+		Operations: ops,
+		Format:     job.Format,
+		Encoding:   encoding,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	// El resultado se sirve por la ruta pública, así que su nombre es
+	// aleatorio y no el ID del trabajo
+	resultPath := fmt.Sprintf("%s/jobs/%s.%s", job.UserName, uuid.New().String(), output.Extension)
+	if err := uc.fileStorage.Upload(ctx, resultPath, bytes.NewReader(output.Data), int64(len(output.Data)), output.ContentType); err != nil {
+		return "", fmt.Errorf("error al guardar el resultado: %w", err)
+	}
+
+	return resultPath, nil
+}
+
+func (uc *TransformJobUseCase) findOwned(id int64, userName string) (*entity.TransformJob, error) {
+	job, err := uc.jobRepo.FindByID(id)
+	if err != nil || job.UserName != userName {
+		return nil, ErrJobNotFound
+	}
+	return job, nil
+}
+
+func (uc *TransformJobUseCase) toOutput(job *entity.TransformJob) *JobOutput {
+	output := &JobOutput{
+		ID:         job.ID,
+		ImageID:    job.ImageID,
+		Status:     job.Status,
+		Error:      job.Error,
+		CreatedAt:  job.CreatedAt,
+		StartedAt:  job.StartedAt,
+		FinishedAt: job.FinishedAt,
+	}
+
+	if job.Status == entity.JobSucceeded && job.ResultPath != "" {
+		output.ResultURL = fmt.Sprintf("%s:%s/images/%s", uc.baseURL, uc.port, job.ResultPath)
+	}
+
+	return output
+}
@@ -0,0 +1,347 @@
+package image_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	stdimage "image"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RodrigoGonzalez78/internal/application/usecase/image"
+	"github.com/RodrigoGonzalez78/internal/domain/entity"
+	"github.com/RodrigoGonzalez78/internal/domain/repository/mocks"
+)
+
+func setupJobs(t *testing.T) (*image.TransformJobUseCase, *mocks.MockTransformJobRepository, *mocks.MockFileStorage) {
+	t.Helper()
+	transformUC, imageRepo, fileStorage := setupTransform(t)
+	jobRepo := mocks.NewMockTransformJobRepository()
+	useCase := image.NewTransformJobUseCase(jobRepo, imageRepo, fileStorage, transformUC, "http://localhost", "8080", 10, time.Hour)
+	return useCase, jobRepo, fileStorage
+}
+
+// startJobs lanza los workers y los detiene al terminar el test
+func startJobs(t *testing.T, useCase *image.TransformJobUseCase) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		useCase.Start(ctx, 2, 10*time.Millisecond)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+// waitForJob espera a que el trabajo llegue a un estado final
+func waitForJob(t *testing.T, useCase *image.TransformJobUseCase, id int64) *image.JobOutput {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		output, err := useCase.Get(id, "testuser")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if output.Status.Finished() {
+			return output
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("el trabajo %d no terminó a tiempo", id)
+	return nil
+}
+
+var jobResizeInput = image.TransformInput{
+	ImageID:  1,
+	UserName: "testuser",
+	Operations: []image.Operation{
+		{Type: "resize", Params: image.OperationParams{"width": 40.0, "height": 30.0}},
+	},
+}
+
+var jobResultPattern = regexp.MustCompile(`^http://localhost:8080/images/testuser/jobs/[0-9a-f-]{36}\.png$`)
+
+func TestTransformJobUseCase_Succeeds(t *testing.T) {
+	useCase, _, fileStorage := setupJobs(t)
+
+	submitted, err := useCase.Submit(context.Background(), jobResizeInput)
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	if submitted.Status != entity.JobQueued {
+		t.Errorf("Status = %v, want queued", submitted.Status)
+	}
+
+	startJobs(t, useCase)
+	output := waitForJob(t, useCase, submitted.ID)
+
+	if output.Status != entity.JobSucceeded {
+		t.Fatalf("Status = %v (%s), want succeeded", output.Status, output.Error)
+	}
+	// El nombre del resultado es aleatorio: no se deduce del ID del trabajo
+	if !jobResultPattern.MatchString(output.ResultURL) || strings.Contains(output.ResultURL, fmt.Sprintf("/jobs/%d.", submitted.ID)) {
+		t.Errorf("ResultURL = %v", output.ResultURL)
+	}
+
+	resultPath := strings.TrimPrefix(output.ResultURL, "http://localhost:8080/images/")
+	data, exists := fileStorage.Files[resultPath]
+	if !exists {
+		t.Fatalf("el resultado %s no se guardó en el storage", resultPath)
+	}
+	result := decodeOutput(t, &image.TransformOutput{Data: data})
+	if result.Bounds().Dx() != 40 || result.Bounds().Dy() != 30 {
+		t.Errorf("dimensiones = %v, want 40x30", result.Bounds().Size())
+	}
+}
+
+func TestTransformJobUseCase_Submit(t *testing.T) {
+	tests := []struct {
+		name  string
+		input image.TransformInput
+	}{
+		{
+			name: "operación inválida",
+			input: image.TransformInput{
+				ImageID:    1,
+				UserName:   "testuser",
+				Operations: []image.Operation{{Type: "desconocida"}},
+			},
+		},
+		{
+			name:  "imagen de otro usuario",
+			input: image.TransformInput{ImageID: 1, UserName: "otro", Operations: jobResizeInput.Operations},
+		},
+		{
+			name:  "imagen inexistente",
+			input: image.TransformInput{ImageID: 99, UserName: "testuser", Operations: jobResizeInput.Operations},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCase, jobRepo, _ := setupJobs(t)
+
+			if _, err := useCase.Submit(context.Background(), tt.input); err == nil {
+				t.Error("Submit() se esperaba error")
+			}
+			if len(jobRepo.Jobs) != 0 {
+				t.Error("no debe guardarse un trabajo inválido")
+			}
+		})
+	}
+}
+
+func TestTransformJobUseCase_Failed(t *testing.T) {
+	useCase, _, fileStorage := setupJobs(t)
+
+	submitted, err := useCase.Submit(context.Background(), jobResizeInput)
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+
+	// El original desaparece antes de que el trabajo se ejecute
+	delete(fileStorage.Files, "testuser/test.png")
+
+	startJobs(t, useCase)
+	output := waitForJob(t, useCase, submitted.ID)
+
+	if output.Status != entity.JobFailed {
+		t.Errorf("Status = %v, want failed", output.Status)
+	}
+	if output.Error == "" {
+		t.Error("un trabajo fallido debe informar el error")
+	}
+	if output.ResultURL != "" {
+		t.Error("un trabajo fallido no debe tener URL de resultado")
+	}
+}
+
+func TestTransformJobUseCase_Cancel(t *testing.T) {
+	useCase, _, fileStorage := setupJobs(t)
+
+	submitted, err := useCase.Submit(context.Background(), jobResizeInput)
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+
+	if _, err := useCase.Cancel(submitted.ID, "otro"); !errors.Is(err, image.ErrJobNotFound) {
+		t.Errorf("Cancel() de otro usuario error = %v, want ErrJobNotFound", err)
+	}
+
+	canceled, err := useCase.Cancel(submitted.ID, "testuser")
+	if err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+	if canceled.Status != entity.JobCanceled {
+		t.Errorf("Status = %v, want canceled", canceled.Status)
+	}
+
+	if _, err := useCase.Cancel(submitted.ID, "testuser"); !errors.Is(err, image.ErrJobFinished) {
+		t.Errorf("Cancel() repetido error = %v, want ErrJobFinished", err)
+	}
+
+	// Los workers descartan el trabajo cancelado aunque siga en la cola
+	startJobs(t, useCase)
+	time.Sleep(50 * time.Millisecond)
+
+	output, err := useCase.Get(submitted.ID, "testuser")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if output.Status != entity.JobCanceled {
+		t.Errorf("Status = %v, want canceled", output.Status)
+	}
+	if len(fileStorage.Files) != 1 {
+		t.Error("un trabajo cancelado no debe generar resultado")
+	}
+}
+
+func TestTransformJobUseCase_ResumesAfterRestart(t *testing.T) {
+	useCase, jobRepo, _ := setupJobs(t)
+
+	// Un trabajo que quedó en running cuando se detuvo el servidor
+	ops, _ := json.Marshal(jobResizeInput.Operations)
+	job := entity.NewTransformJob("testuser", 1, string(ops), "", "")
+	if err := jobRepo.Create(job); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := jobRepo.MarkRunning(job.ID); err != nil {
+		t.Fatalf("MarkRunning() error = %v", err)
+	}
+
+	startJobs(t, useCase)
+	output := waitForJob(t, useCase, job.ID)
+
+	if output.Status != entity.JobSucceeded {
+		t.Errorf("Status = %v (%s), want succeeded", output.Status, output.Error)
+	}
+}
+
+func TestTransformJobUseCase_GetOtherUser(t *testing.T) {
+	useCase, _, _ := setupJobs(t)
+
+	submitted, err := useCase.Submit(context.Background(), jobResizeInput)
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+
+	if _, err := useCase.Get(submitted.ID, "otro"); !errors.Is(err, image.ErrJobNotFound) {
+		t.Errorf("Get() error = %v, want ErrJobNotFound", err)
+	}
+}
+
+func TestTransformJobUseCase_KeepsEncodeOptions(t *testing.T) {
+	useCase, jobRepo, fileStorage := setupJobs(t)
+
+	input := jobResizeInput
+	input.Format = "gif"
+	input.Encoding = image.EncodeOptions{PaletteSize: 8}
+
+	submitted, err := useCase.Submit(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	if jobRepo.Jobs[submitted.ID].Encoding == "" {
+		t.Error("las opciones de formato deben guardarse con el trabajo")
+	}
+
+	startJobs(t, useCase)
+	output := waitForJob(t, useCase, submitted.ID)
+	if output.Status != entity.JobSucceeded {
+		t.Fatalf("Status = %v (%s), want succeeded", output.Status, output.Error)
+	}
+	if !strings.HasSuffix(output.ResultURL, ".gif") {
+		t.Errorf("ResultURL = %v, want extensión .gif", output.ResultURL)
+	}
+
+	resultPath := strings.TrimPrefix(output.ResultURL, "http://localhost:8080/images/")
+	result := decodeOutput(t, &image.TransformOutput{Data: fileStorage.Files[resultPath]})
+	if paletted, ok := result.(*stdimage.Paletted); !ok || len(paletted.Palette) > 8 {
+		t.Errorf("resultado = %T, want gif con a lo sumo 8 colores", result)
+	}
+}
+
+// runJobs ejecuta count trabajos hasta que terminan y devuelve sus IDs
+func runJobs(t *testing.T, useCase *image.TransformJobUseCase, count int) []int64 {
+	t.Helper()
+	var ids []int64
+	for i := 0; i < count; i++ {
+		submitted, err := useCase.Submit(context.Background(), jobResizeInput)
+		if err != nil {
+			t.Fatalf("Submit() error = %v", err)
+		}
+		ids = append(ids, submitted.ID)
+	}
+
+	startJobs(t, useCase)
+	for _, id := range ids {
+		if output := waitForJob(t, useCase, id); output.Status != entity.JobSucceeded {
+			t.Fatalf("Status = %v (%s), want succeeded", output.Status, output.Error)
+		}
+	}
+	return ids
+}
+
+func TestTransformJobUseCase_ExpireFinished(t *testing.T) {
+	useCase, jobRepo, fileStorage := setupJobs(t)
+	ids := runJobs(t, useCase, 2)
+
+	old := jobRepo.Jobs[ids[0]]
+	finishedAt := time.Now().Add(-2 * time.Hour)
+	old.FinishedAt = &finishedAt
+	oldResult, keptResult := old.ResultPath, jobRepo.Jobs[ids[1]].ResultPath
+
+	expired, err := useCase.ExpireFinished(context.Background(), 10)
+	if err != nil || expired != 1 {
+		t.Fatalf("ExpireFinished() = %d, %v; want 1", expired, err)
+	}
+	if _, err := useCase.Get(ids[0], "testuser"); !errors.Is(err, image.ErrJobNotFound) {
+		t.Errorf("Get() del trabajo vencido error = %v, want ErrJobNotFound", err)
+	}
+	if _, exists := fileStorage.Files[oldResult]; exists {
+		t.Error("el resultado del trabajo vencido sigue en el storage")
+	}
+	if _, exists := fileStorage.Files[keptResult]; !exists {
+		t.Error("se borró el resultado de un trabajo sin vencer")
+	}
+
+	// Si falla el storage la fila queda para el próximo intento
+	fileStorage.DeleteError = errors.New("storage caído")
+	finishedAt = time.Now().Add(-2 * time.Hour)
+	jobRepo.Jobs[ids[1]].FinishedAt = &finishedAt
+	if expired, _ := useCase.ExpireFinished(context.Background(), 10); expired != 0 {
+		t.Errorf("ExpireFinished() con el storage caído = %d, want 0", expired)
+	}
+	if _, exists := jobRepo.Jobs[ids[1]]; !exists {
+		t.Error("se borró el trabajo sin poder borrar su resultado")
+	}
+}
+
+func TestDeleteUseCase_RemovesJobs(t *testing.T) {
+	transformUC, imageRepo, fileStorage := setupTransform(t)
+	jobRepo := mocks.NewMockTransformJobRepository()
+	jobUC := image.NewTransformJobUseCase(jobRepo, imageRepo, fileStorage, transformUC, "http://localhost", "8080", 10, 0)
+	deleteUC := image.NewDeleteUseCase(imageRepo, jobRepo, fileStorage, image.NewBlobStore(mocks.NewMockBlobRepository(), fileStorage), nil)
+
+	ids := runJobs(t, jobUC, 2)
+	results := []string{jobRepo.Jobs[ids[0]].ResultPath, jobRepo.Jobs[ids[1]].ResultPath}
+
+	output, err := deleteUC.Execute(context.Background(), image.DeleteInput{ImageID: 1, UserName: "testuser"})
+	if err != nil || output.Pending {
+		t.Fatalf("Execute() = %+v, %v", output, err)
+	}
+	if len(jobRepo.Jobs) != 0 {
+		t.Errorf("trabajos restantes = %d, want 0", len(jobRepo.Jobs))
+	}
+	for _, path := range results {
+		if _, exists := fileStorage.Files[path]; exists {
+			t.Errorf("el resultado %s sigue en el storage", path)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package image_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	stdimage "image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/RodrigoGonzalez78/internal/application/usecase/image"
+	"github.com/RodrigoGonzalez78/internal/domain/entity"
+	"github.com/RodrigoGonzalez78/internal/domain/repository/mocks"
+)
+
+// encodeTestPNG genera un PNG sólido de las dimensiones indicadas
+func encodeTestPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := stdimage.NewNRGBA(stdimage.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("no se pudo codificar el PNG de prueba: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func setupTransform(t *testing.T) (*image.TransformUseCase, *mocks.MockImageRepository, *mocks.MockFileStorage) {
+	t.Helper()
+	imageRepo := mocks.NewMockImageRepository()
+	fileStorage := mocks.NewMockFileStorage()
+
+	imageRepo.Images[1] = &entity.Image{
+		ID:       1,
+		Name:     "test.png",
+		UserName: "testuser",
+		Path:     "testuser/test.png",
+		Format:   "png",
+		Width:    400,
+		Height:   300,
+	}
+	fileStorage.Files["testuser/test.png"] = encodeTestPNG(t, 400, 300)
+
+	return image.NewTransformUseCase(imageRepo, mocks.NewMockFontRepository(), fileStorage, nil, image.DefaultImageLimits()), imageRepo, fileStorage
+}
+
+// decodeOutput decodifica la imagen resultante de una transformación
+func decodeOutput(t *testing.T, output *image.TransformOutput) stdimage.Image {
+	t.Helper()
+	img, _, err := stdimage.Decode(bytes.NewReader(output.Data))
+	if err != nil {
+		t.Fatalf("no se pudo decodificar la salida: %v", err)
+	}
+	return img
+}
+
+func TestTransformUseCase_Pipeline(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      image.TransformInput
+		wantWidth  int
+		wantHeight int
+		wantStep   int
+		wantErr    bool
+	}{
+		{
+			name: "recortar y luego redimensionar",
+			input: image.TransformInput{
+				Operations: []image.Operation{
+					{Type: "crop", Params: image.OperationParams{"x": 10.0, "y": 10.0, "width": 100.0, "height": 50.0}},
+					{Type: "resize", Params: image.OperationParams{"width": 200.0, "height": 200.0}},
+				},
+			},
+			wantWidth:  200,
+			wantHeight: 200,
+		},
+		{
+			name: "redimensionar y luego recortar",
+			input: image.TransformInput{
+				Operations: []image.Operation{
+					{Type: "resize", Params: image.OperationParams{"width": 200.0, "height": 200.0}},
+					{Type: "crop", Params: image.OperationParams{"x": 0.0, "y": 0.0, "width": 100.0, "height": 50.0}},
+				},
+			},
+			wantWidth:  100,
+			wantHeight: 50,
+		},
+		{
+			name: "formato anterior",
+			input: image.TransformInput{
+				Resize: image.ResizeParams{Width: 80, Height: 60},
+				Rotate: 90,
+			},
+			wantWidth:  60,
+			wantHeight: 80,
+		},
+		{
+			name: "tipo desconocido",
+			input: image.TransformInput{
+				Operations: []image.Operation{
+					{Type: "grayscale"},
+					{Type: "explode"},
+				},
+			},
+			wantErr:  true,
+			wantStep: 1,
+		},
+		{
+			name: "parámetro inválido",
+			input: image.TransformInput{
+				Operations: []image.Operation{
+					{Type: "resize", Params: image.OperationParams{"width": "grande", "height": 10.0}},
+				},
+			},
+			wantErr:  true,
+			wantStep: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCase, _, fileStorage := setupTransform(t)
+			tt.input.ImageID = 1
+			tt.input.UserName = "testuser"
+
+			output, err := useCase.Execute(context.Background(), tt.input)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				var stepErr *image.StepError
+				if !errors.As(err, &stepErr) {
+					t.Fatalf("Execute() error = %T, want *image.StepError", err)
+				}
+				if stepErr.Step != tt.wantStep {
+					t.Errorf("StepError.Step = %d, want %d", stepErr.Step, tt.wantStep)
+				}
+				if fileStorage.GetCalled {
+					t.Error("no debe leerse el storage si el pipeline es inválido")
+				}
+				return
+			}
+
+			bounds := decodeOutput(t, output).Bounds()
+			if bounds.Dx() != tt.wantWidth || bounds.Dy() != tt.wantHeight {
+				t.Errorf("dimensiones = %dx%d, want %dx%d", bounds.Dx(), bounds.Dy(), tt.wantWidth, tt.wantHeight)
+			}
+		})
+	}
+}
+
+func TestLegacyOperations(t *testing.T) {
+	ops := image.LegacyOperations(
+		image.ResizeParams{Width: 100, Height: 100},
+		image.CropParams{Width: 10, Height: 10},
+		45,
+		image.FilterParams{Grayscale: true, Sepia: true},
+	)
+
+	want := []string{"resize", "crop", "rotate", "grayscale", "sepia"}
+	if len(ops) != len(want) {
+		t.Fatalf("len(ops) = %d, want %d", len(ops), len(want))
+	}
+	for i, op := range ops {
+		if op.Type != want[i] {
+			t.Errorf("ops[%d].Type = %s, want %s", i, op.Type, want[i])
+		}
+	}
+
+	if ops := image.LegacyOperations(image.ResizeParams{}, image.CropParams{}, 0, image.FilterParams{}); len(ops) != 0 {
+		t.Errorf("sin parámetros se esperaban 0 operaciones, got %d", len(ops))
+	}
+}
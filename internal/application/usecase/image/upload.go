@@ -1,98 +1,225 @@
 package image
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"log"
 
 	"github.com/RodrigoGonzalez78/internal/domain/entity"
 	"github.com/RodrigoGonzalez78/internal/domain/repository"
+	"github.com/RodrigoGonzalez78/internal/pkg/exif"
 )
 
 // UploadUseCase maneja el caso de uso de subida de imágenes
 type UploadUseCase struct {
-	imageRepo   repository.ImageRepository
-	fileStorage repository.FileStorage
-	baseURL     string
-	port        string
+	imageRepo    repository.ImageRepository
+	metadataRepo repository.ImageMetadataRepository
+	blobs        *BlobStore
+	renditions   *RenditionUseCase
+	limits       ImageLimits
+	baseURL      string
+	port         string
 }
 
 // NewUploadUseCase crea una nueva instancia de UploadUseCase
 func NewUploadUseCase(
 	imageRepo repository.ImageRepository,
-	fileStorage repository.FileStorage,
+	metadataRepo repository.ImageMetadataRepository,
+	blobs *BlobStore,
+	renditions *RenditionUseCase,
+	limits ImageLimits,
 	baseURL, port string,
 ) *UploadUseCase {
 	return &UploadUseCase{
-		imageRepo:   imageRepo,
-		fileStorage: fileStorage,
-		baseURL:     baseURL,
-		port:        port,
+		imageRepo:    imageRepo,
+		metadataRepo: metadataRepo,
+		blobs:        blobs,
+		renditions:   renditions,
+		limits:       limits,
+		baseURL:      baseURL,
+		port:         port,
 	}
 }
 
 // UploadInput representa los datos de entrada para la subida
 type UploadInput struct {
-	FileName    string
-	UserName    string
+	FileName string
+	UserName string
+	// Body es el contenido, que se lee una sola vez mientras se sube, y Size
+	// su largo o repository.UnknownSize. Si Body es nil se usa Data.
+	Body        io.Reader
+	Size        int64
 	Data        []byte
 	ContentType string
 	Format      string
-	Width       int
-	Height      int
+	// Width y Height en cero se toman de la cabecera de la imagen
+	Width  int
+	Height int
+	// MetadataPolicy indica qué metadatos se quitan antes de guardar; vacía
+	// los conserva
+	MetadataPolicy MetadataPolicy
+	// ParentID y Operations registran el origen de una imagen guardada a
+	// partir de una transformación
+	ParentID   *int64
+	Operations string
+	// OnDuplicate indica qué hacer si el usuario ya tiene una imagen a
+	// distancia de Hamming menor o igual que DuplicateThreshold; vacía
+	// equivale a allow
+	OnDuplicate        DuplicatePolicy
+	DuplicateThreshold int
 }
 
 // UploadOutput representa los datos de salida de la subida
 type UploadOutput struct {
-	URL    string
-	Name   string
-	Size   int64
-	Format string
-	Width  int
-	Height int
+	ID       int64
+	ParentID *int64
+	URL      string
+	Name     string
+	Size     int64
+	Format   string
+	Width    int
+	Height   int
+	// Duplicate indica que no se guardó nada y que la imagen devuelta es la
+	// que ya existía
+	Duplicate bool
 }
 
-// Execute ejecuta el caso de uso de subida de imagen
+// Execute ejecuta el caso de uso de subida de imagen. El contenido va al
+// storage a medida que se lee; las dimensiones, el hash perceptual y el
+// checksum se calculan sobre la misma lectura.
 func (uc *UploadUseCase) Execute(ctx context.Context, input UploadInput) (*UploadOutput, error) {
-	if len(input.Data) == 0 {
-		return nil, errors.New("no se proporcionó datos de imagen")
+	body, size := input.Body, input.Size
+	if body == nil {
+		if len(input.Data) == 0 {
+			return nil, errors.New("no se proporcionó datos de imagen")
+		}
+		body, size = bytes.NewReader(input.Data), int64(len(input.Data))
+	}
+	if err := uc.limits.CheckSize(size); err != nil {
+		return nil, err
+	}
+
+	// Quitar los metadatos antes de que el original llegue al storage, donde
+	// se sirve tal cual
+	if mode, ok := stripMode(input.MetadataPolicy); ok {
+		stripped, pw := io.Pipe()
+		go func(source io.Reader) {
+			err := exif.StripTo(pw, source, mode)
+			if errors.Is(err, exif.ErrInvalid) {
+				err = fmt.Errorf("%w (%v)", ErrUnreadableMetadata, err)
+			}
+			pw.CloseWithError(err)
+		}(body)
+		// Si la subida se corta antes, liberar al que escribe
+		defer stripped.Close()
+		body, size = stripped, repository.UnknownSize
 	}
 
-	// Construir path del objeto
-	objectPath := fmt.Sprintf("%s/%s", input.UserName, input.FileName)
+	// El contenido se guarda bajo su SHA-256: si ya estaba en el storage solo
+	// se suma una referencia. El nombre sigue formando la URL pública.
+	requireConfig := input.Width == 0 || input.Height == 0
+	analysis := startAnalysis(uc.limits, requireConfig)
+	blob, err := uc.blobs.PutStream(ctx, analysis.Tee(body), size, input.ContentType)
+	result := analysis.Wait(err)
 
-	// Subir a storage
-	err := uc.fileStorage.Upload(ctx, objectPath, input.Data, input.ContentType)
-	if err != nil {
-		return nil, fmt.Errorf("error al subir imagen: %w", err)
+	var limitErr *LimitError
+	invalid := result.Err != nil && (requireConfig || errors.As(result.Err, &limitErr))
+	if err != nil && (!invalid || errors.Is(err, ErrUnreadableMetadata)) {
+		return nil, err
+	}
+	if invalid {
+		if err == nil {
+			uc.discard(ctx, blob)
+		}
+		return nil, result.Err
+	}
+
+	// El hash se calcula sobre los píxeles ya orientados, así que no depende
+	// de los metadatos
+	if result.Err != nil || result.HashErr != nil {
+		log.Printf("  No se pudo calcular el hash perceptual de %s: %v", input.FileName, errors.Join(result.Err, result.HashErr))
+	}
+	width, height := input.Width, input.Height
+	if requireConfig {
+		width, height = result.Width, result.Height
+	}
+
+	if result.Hash != "" && input.OnDuplicate != "" && input.OnDuplicate != DuplicateAllow {
+		matches, err := findSimilar(uc.imageRepo, input.UserName, result.Hash, input.DuplicateThreshold)
+		if err != nil {
+			uc.discard(ctx, blob)
+			return nil, fmt.Errorf("error al buscar duplicados: %w", err)
+		}
+		if len(matches) > 0 {
+			// El contenido ya se subió mientras se calculaba el hash
+			uc.discard(ctx, blob)
+			existing := uc.toOutput(&matches[0].image)
+			if input.OnDuplicate == DuplicateReject {
+				return nil, &DuplicateError{Existing: existing, Distance: matches[0].distance}
+			}
+			existing.Duplicate = true
+			return existing, nil
+		}
 	}
 
 	// Crear entidad de imagen
 	image := entity.NewImage(
 		input.FileName,
 		input.UserName,
-		objectPath,
+		blob.Path,
 		input.Format,
-		int64(len(input.Data)),
-		input.Width,
-		input.Height,
+		blob.Size,
+		width,
+		height,
 	)
+	image.ParentID = input.ParentID
+	image.Operations = input.Operations
+	image.PerceptualHash = result.Hash
 
 	// Guardar en base de datos
 	err = uc.imageRepo.Create(image)
 	if err != nil {
+		// Evitar dejar un objeto huérfano en el storage
+		uc.discard(ctx, blob)
 		return nil, fmt.Errorf("error al guardar imagen en BD: %w", err)
 	}
 
-	// Construir URL de la imagen
-	url := fmt.Sprintf("%s:%s/images/%s/%s", uc.baseURL, uc.port, input.UserName, input.FileName)
+	// Los metadatos EXIF son informativos: si no se pueden guardar la
+	// imagen igual queda subida. Están al comienzo del archivo.
+	if metadata := extractMetadata(result.Head); metadata != nil {
+		metadata.ImageID = image.ID
+		if err := uc.metadataRepo.Save(metadata); err != nil {
+			log.Printf("  No se pudieron guardar los metadatos de la imagen %d: %v", image.ID, err)
+		}
+	}
+
+	// Las versiones para srcset se generan sin demorar la respuesta
+	if uc.renditions != nil {
+		uc.renditions.Enqueue(image.ID)
+	}
 
+	return uc.toOutput(image), nil
+}
+
+// discard libera el blob de una subida que no llegó a registrarse
+func (uc *UploadUseCase) discard(ctx context.Context, blob *entity.Blob) {
+	if _, err := uc.blobs.Release(ctx, blob.Path); err != nil {
+		log.Printf("  No se pudo liberar el blob %s: %v", blob.Path, err)
+	}
+}
+
+func (uc *UploadUseCase) toOutput(image *entity.Image) *UploadOutput {
 	return &UploadOutput{
-		URL:    url,
-		Name:   input.FileName,
-		Size:   int64(len(input.Data)),
-		Format: input.Format,
-		Width:  input.Width,
-		Height: input.Height,
-	}, nil
+		ID:       image.ID,
+		ParentID: image.ParentID,
+		URL:      fmt.Sprintf("%s:%s/images/%s/%s", uc.baseURL, uc.port, image.UserName, image.Name),
+		Name:     image.Name,
+		Size:     image.Size,
+		Format:   image.Format,
+		Width:    image.Width,
+		Height:   image.Height,
+	}
 }
@@ -0,0 +1,42 @@
+package image_test
+
+import (
+	"github.com/RodrigoGonzalez78/internal/application/usecase/image"
+	"github.com/RodrigoGonzalez78/internal/domain/repository"
+	"github.com/RodrigoGonzalez78/internal/domain/repository/mocks"
+)
+
+// uploadFixture arma UploadUseCase sobre mocks. Las pruebas de cada caso de
+// uso lo incluyen y le agregan lo que necesitan.
+type uploadFixture struct {
+	blobRepo     *mocks.MockBlobRepository
+	imageRepo    *mocks.MockImageRepository
+	metadataRepo *mocks.MockImageMetadataRepository
+	fileStorage  *mocks.MockFileStorage
+	// storage es el que reciben los casos de uso: fileStorage, o lo que
+	// haya puesto withStorage encima
+	storage  repository.FileStorage
+	blobs    *image.BlobStore
+	uploadUC *image.UploadUseCase
+	limits   image.ImageLimits
+}
+
+func setupUpload(limits image.ImageLimits) *uploadFixture {
+	f := &uploadFixture{
+		blobRepo:     mocks.NewMockBlobRepository(),
+		imageRepo:    mocks.NewMockImageRepository(),
+		metadataRepo: mocks.NewMockImageMetadataRepository(),
+		fileStorage:  mocks.NewMockFileStorage(),
+		limits:       limits,
+	}
+	return f.withStorage(func(storage repository.FileStorage) repository.FileStorage { return storage })
+}
+
+// withStorage vuelve a armar los casos de uso con el storage envuelto por
+// wrap, por ejemplo con la verificación de checksums
+func (f *uploadFixture) withStorage(wrap func(repository.FileStorage) repository.FileStorage) *uploadFixture {
+	f.storage = wrap(f.fileStorage)
+	f.blobs = image.NewBlobStore(f.blobRepo, f.storage)
+	f.uploadUC = image.NewUploadUseCase(f.imageRepo, f.metadataRepo, f.blobs, nil, f.limits, "http://localhost", "8080")
+	return f
+}
@@ -0,0 +1,109 @@
+package image
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// urlOperation describe cómo se mapean los argumentos posicionales de un
+// segmento de URL ("rs:800:600") a los parámetros de una operación
+type urlOperation struct {
+	Type string
+	Args []string
+}
+
+var urlOperations = map[string]urlOperation{
+	"rs":     {Type: "resize", Args: []string{"width", "height", "mode"}},
+	"resize": {Type: "resize", Args: []string{"width", "height", "mode"}},
+	"c":      {Type: "crop", Args: []string{"width", "height", "x", "y"}},
+	"crop":   {Type: "crop", Args: []string{"width", "height", "x", "y"}},
+	"rot":    {Type: "rotate", Args: []string{"angle"}},
+	"rotate": {Type: "rotate", Args: []string{"angle"}},
+	"gs":     {Type: "grayscale"},
+}
+
+// ParseURLOperations convierte los segmentos de una URL de transformación en
+// un pipeline y un formato de salida. Cada segmento tiene la forma
+// "nombre:arg1:arg2"; los alias conocidos usan argumentos posicionales y el
+// resto de operaciones acepta "nombre:clave=valor:clave=valor". El segmento
+// especial "f:<formato>" selecciona el formato de salida.
+func ParseURLOperations(segments []string) ([]Operation, string, error) {
+	var ops []Operation
+	var format string
+
+	for i, segment := range segments {
+		if segment == "" {
+			continue
+		}
+
+		parts := strings.Split(segment, ":")
+		name, args := parts[0], parts[1:]
+
+		if name == "f" || name == "format" {
+			if len(args) != 1 || args[0] == "" {
+				return nil, "", fmt.Errorf("segmento %d: formato inválido", i)
+			}
+			format = args[0]
+			continue
+		}
+
+		op := Operation{Type: name, Params: OperationParams{}}
+
+		if alias, ok := urlOperations[name]; ok {
+			op.Type = alias.Type
+			if len(args) > len(alias.Args) {
+				return nil, "", fmt.Errorf("segmento %d (%s): se esperaban como máximo %d argumentos", i, name, len(alias.Args))
+			}
+			for j, arg := range args {
+				if arg == "" {
+					continue
+				}
+				op.Params[alias.Args[j]] = parseURLValue(arg)
+			}
+			ops = append(ops, op)
+			continue
+		}
+
+		for _, arg := range args {
+			key, value, found := strings.Cut(arg, "=")
+			if !found || key == "" {
+				return nil, "", fmt.Errorf("segmento %d (%s): se esperaba clave=valor en %q", i, name, arg)
+			}
+			op.Params[key] = parseURLValue(value)
+		}
+		ops = append(ops, op)
+	}
+
+	return ops, format, nil
+}
+
+// SplitTransformPath separa una ruta "ops.../usuario/archivo" en sus partes
+func SplitTransformPath(rest string) ([]string, string, string, error) {
+	segments := strings.Split(strings.Trim(rest, "/"), "/")
+	if len(segments) < 2 {
+		return nil, "", "", errors.New("la ruta debe terminar en /usuario/archivo")
+	}
+
+	userName := segments[len(segments)-2]
+	fileName := segments[len(segments)-1]
+	if userName == "" || fileName == "" || strings.Contains(userName, "..") || strings.Contains(fileName, "..") {
+		return nil, "", "", errors.New("ruta de imagen inválida")
+	}
+
+	return segments[:len(segments)-2], userName, fileName, nil
+}
+
+func parseURLValue(value string) interface{} {
+	if number, err := strconv.ParseFloat(value, 64); err == nil {
+		return number
+	}
+	switch value {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	return value
+}
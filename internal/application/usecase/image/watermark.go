@@ -0,0 +1,179 @@
+package image
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"strings"
+
+	"github.com/disintegration/imaging"
+)
+
+const (
+	defaultWatermarkAnchor  = "bottom-right"
+	defaultWatermarkOpacity = 0.5
+	defaultWatermarkScale   = 0.25
+	defaultWatermarkMargin  = 16
+	maxWatermarkMargin      = 10000
+)
+
+// watermarkAnchors son las posiciones admitidas; "tile" repite la marca de
+// agua sobre toda la imagen
+var watermarkAnchors = map[string]bool{
+	"top-left":     true,
+	"top-right":    true,
+	"bottom-left":  true,
+	"bottom-right": true,
+	"center":       true,
+	"tile":         true,
+}
+
+type ownerKey struct{}
+
+// withOwner indica a las operaciones a qué usuario pertenece la imagen que
+// se procesa, para que solo puedan usar recursos de ese mismo usuario
+func withOwner(ctx context.Context, owner string) context.Context {
+	return context.WithValue(ctx, ownerKey{}, owner)
+}
+
+func ownerFromContext(ctx context.Context) string {
+	owner, _ := ctx.Value(ownerKey{}).(string)
+	return owner
+}
+
+// pathOwner devuelve el usuario dueño de un objeto "usuario/archivo"
+func pathOwner(objectPath string) string {
+	owner, _, _ := strings.Cut(objectPath, "/")
+	return owner
+}
+
+func validateWatermark(params OperationParams) error {
+	imageID, err := params.Int("image_id", 0)
+	if err != nil {
+		return err
+	}
+	if imageID <= 0 {
+		return &FieldError{Field: "image_id", Message: "es requerido"}
+	}
+
+	anchor, err := params.String("anchor", defaultWatermarkAnchor)
+	if err != nil {
+		return err
+	}
+	if !watermarkAnchors[anchor] {
+		return &FieldError{Field: "anchor", Message: "debe ser top-left, top-right, bottom-left, bottom-right, center o tile"}
+	}
+
+	opacity, err := params.Float("opacity", defaultWatermarkOpacity)
+	if err != nil {
+		return err
+	}
+	if opacity <= 0 || opacity > 1 {
+		return &FieldError{Field: "opacity", Message: "debe ser mayor que 0 y como máximo 1"}
+	}
+
+	scale, err := params.Float("scale", defaultWatermarkScale)
+	if err != nil {
+		return err
+	}
+	if scale <= 0 || scale > 1 {
+		return &FieldError{Field: "scale", Message: "debe ser mayor que 0 y como máximo 1"}
+	}
+
+	margin, err := params.Int("margin", defaultWatermarkMargin)
+	if err != nil {
+		return err
+	}
+	if margin < 0 || margin > maxWatermarkMargin {
+		return &FieldError{Field: "margin", Message: fmt.Sprintf("debe estar entre 0 y %d", maxWatermarkMargin)}
+	}
+	return nil
+}
+
+// applyWatermark superpone una imagen PNG del mismo usuario. El ancho de la
+// marca de agua es una fracción (scale) del ancho de la imagen destino.
+func (uc *TransformUseCase) applyWatermark(ctx context.Context, img image.Image, params OperationParams) (image.Image, error) {
+	imageID, _ := params.Int("image_id", 0)
+	anchor, _ := params.String("anchor", defaultWatermarkAnchor)
+	opacity, _ := params.Float("opacity", defaultWatermarkOpacity)
+	scale, _ := params.Float("scale", defaultWatermarkScale)
+	margin, _ := params.Int("margin", defaultWatermarkMargin)
+
+	mark, err := uc.loadWatermark(ctx, int64(imageID), ownerFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	dst := imaging.Clone(img)
+	bounds := dst.Bounds()
+
+	width := max(1, int(float64(bounds.Dx())*scale))
+	mark = imaging.Resize(mark, width, 0, imaging.Lanczos)
+	markSize := mark.Bounds().Size()
+
+	mask := image.NewUniform(color.Alpha{A: uint8(opacity*255 + 0.5)})
+	overlay := func(at image.Point) {
+		rect := image.Rectangle{Min: at, Max: at.Add(markSize)}
+		draw.DrawMask(dst, rect, mark, mark.Bounds().Min, mask, image.Point{}, draw.Over)
+	}
+
+	if anchor == "tile" {
+		stepX, stepY := markSize.X+margin, markSize.Y+margin
+		for y := margin; y < bounds.Dy(); y += stepY {
+			for x := margin; x < bounds.Dx(); x += stepX {
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+				overlay(image.Pt(x, y))
+			}
+		}
+		return dst, nil
+	}
+
+	overlay(watermarkPosition(anchor, bounds.Size(), markSize, margin))
+	return dst, nil
+}
+
+// watermarkPosition calcula la esquina superior izquierda de la marca de agua
+func watermarkPosition(anchor string, size, mark image.Point, margin int) image.Point {
+	switch anchor {
+	case "top-left":
+		return image.Pt(margin, margin)
+	case "top-right":
+		return image.Pt(size.X-mark.X-margin, margin)
+	case "bottom-left":
+		return image.Pt(margin, size.Y-mark.Y-margin)
+	case "center":
+		return image.Pt((size.X-mark.X)/2, (size.Y-mark.Y)/2)
+	default:
+		return image.Pt(size.X-mark.X-margin, size.Y-mark.Y-margin)
+	}
+}
+
+// loadWatermark obtiene la imagen de la marca de agua, que debe ser un PNG
+// del mismo usuario que la imagen procesada
+func (uc *TransformUseCase) loadWatermark(ctx context.Context, imageID int64, owner string) (image.Image, error) {
+	mark, err := uc.imageRepo.FindByID(imageID)
+	if err != nil || mark.UserName != owner {
+		return nil, &FieldError{Field: "image_id", Message: "la marca de agua no existe"}
+	}
+	if mark.Format != "png" {
+		return nil, &FieldError{Field: "image_id", Message: "la marca de agua debe ser una imagen png"}
+	}
+
+	reader, err := uc.fileStorage.Get(ctx, mark.Path)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo leer la marca de agua: %w", err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo leer la marca de agua: %w", err)
+	}
+
+	return uc.limits.decode(data)
+}
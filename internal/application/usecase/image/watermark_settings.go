@@ -0,0 +1,203 @@
+package image
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/RodrigoGonzalez78/internal/domain/repository"
+)
+
+// ErrNoDefaultWatermark indica que el usuario no configuró una marca de agua
+var ErrNoDefaultWatermark = errors.New("no hay una marca de agua por defecto configurada")
+
+// WatermarkSettings parámetros de la marca de agua por defecto de un usuario
+type WatermarkSettings struct {
+	ImageID int64   `json:"image_id"`
+	Anchor  string  `json:"anchor"`
+	Opacity float64 `json:"opacity"`
+	Scale   float64 `json:"scale"`
+	Margin  *int    `json:"margin"`
+}
+
+// withDefaults completa los campos sin valor con los valores por defecto
+func (s WatermarkSettings) withDefaults() WatermarkSettings {
+	if s.Anchor == "" {
+		s.Anchor = defaultWatermarkAnchor
+	}
+	if s.Opacity == 0 {
+		s.Opacity = defaultWatermarkOpacity
+	}
+	if s.Scale == 0 {
+		s.Scale = defaultWatermarkScale
+	}
+	if s.Margin == nil {
+		margin := defaultWatermarkMargin
+		s.Margin = &margin
+	}
+	return s
+}
+
+// Operation convierte la configuración en un paso del pipeline
+func (s WatermarkSettings) Operation() Operation {
+	s = s.withDefaults()
+	return Operation{
+		Type: "watermark",
+		Params: OperationParams{
+			"image_id": float64(s.ImageID),
+			"anchor":   s.Anchor,
+			"opacity":  s.Opacity,
+			"scale":    s.Scale,
+			"margin":   float64(*s.Margin),
+		},
+	}
+}
+
+// watermarkCacheTTL es cuánto se reutiliza la marca de agua por defecto de un
+// usuario antes de volver a leerla; los cambios hechos en esta instancia se
+// ven enseguida
+const watermarkCacheTTL = time.Minute
+
+// cachedWatermark es la marca de agua resuelta de un usuario: op es nil si
+// no tiene una o si su imagen ya no existe
+type cachedWatermark struct {
+	op        *Operation
+	markPath  string
+	expiresAt time.Time
+}
+
+// WatermarkUseCase administra la marca de agua por defecto de cada usuario y
+// la agrega a los derivados que se sirven públicamente
+type WatermarkUseCase struct {
+	userRepo  repository.UserRepository
+	imageRepo repository.ImageRepository
+
+	mu    sync.Mutex
+	cache map[string]cachedWatermark
+}
+
+// NewWatermarkUseCase crea una nueva instancia de WatermarkUseCase
+func NewWatermarkUseCase(userRepo repository.UserRepository, imageRepo repository.ImageRepository) *WatermarkUseCase {
+	return &WatermarkUseCase{
+		userRepo:  userRepo,
+		imageRepo: imageRepo,
+		cache:     make(map[string]cachedWatermark),
+	}
+}
+
+// SetDefault valida y guarda la marca de agua por defecto del usuario. La
+// imagen debe ser un PNG propio.
+func (uc *WatermarkUseCase) SetDefault(userName string, settings WatermarkSettings) (*WatermarkSettings, error) {
+	settings = settings.withDefaults()
+
+	op := settings.Operation()
+	if err := validateWatermark(op.Params); err != nil {
+		return nil, err
+	}
+
+	mark, err := uc.imageRepo.FindByID(settings.ImageID)
+	if err != nil || mark.UserName != userName {
+		return nil, &FieldError{Field: "image_id", Message: "la imagen no existe"}
+	}
+	if mark.Format != "png" {
+		return nil, &FieldError{Field: "image_id", Message: "la marca de agua debe ser una imagen png"}
+	}
+
+	encoded, err := json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.userRepo.UpdateDefaultWatermark(userName, string(encoded)); err != nil {
+		return nil, fmt.Errorf("error al guardar la marca de agua: %w", err)
+	}
+	uc.forget(userName)
+
+	return &settings, nil
+}
+
+// GetDefault devuelve la marca de agua por defecto del usuario
+func (uc *WatermarkUseCase) GetDefault(userName string) (*WatermarkSettings, error) {
+	user, err := uc.userRepo.FindByUserName(userName)
+	if err != nil {
+		return nil, fmt.Errorf("error al buscar el usuario: %w", err)
+	}
+	if user.DefaultWatermark == "" {
+		return nil, ErrNoDefaultWatermark
+	}
+
+	var settings WatermarkSettings
+	if err := json.Unmarshal([]byte(user.DefaultWatermark), &settings); err != nil {
+		return nil, fmt.Errorf("marca de agua guardada inválida: %w", err)
+	}
+	return &settings, nil
+}
+
+// ClearDefault elimina la marca de agua por defecto del usuario
+func (uc *WatermarkUseCase) ClearDefault(userName string) error {
+	if err := uc.userRepo.UpdateDefaultWatermark(userName, ""); err != nil {
+		return fmt.Errorf("error al eliminar la marca de agua: %w", err)
+	}
+	uc.forget(userName)
+	return nil
+}
+
+// PublicPipeline agrega la marca de agua por defecto del dueño del objeto a
+// las operaciones de un derivado público. Se aplica a todo lo que cuelga de
+// la carpeta del usuario, incluidas las versiones y los resultados de
+// trabajos; solo la propia imagen de la marca de agua queda sin cambios.
+func (uc *WatermarkUseCase) PublicPipeline(objectPath string, ops []Operation) []Operation {
+	owner, name, _ := strings.Cut(objectPath, "/")
+	if owner == "" || name == "" {
+		return ops
+	}
+
+	mark := uc.resolve(owner)
+	// objectPath es la ruta pública, no el blob, que puede ser compartido
+	if mark.op == nil || mark.markPath == objectPath {
+		return ops
+	}
+
+	pipeline := make([]Operation, 0, len(ops)+1)
+	pipeline = append(pipeline, ops...)
+	return append(pipeline, *mark.op)
+}
+
+// resolve devuelve la marca de agua del usuario, leyéndola de la base solo
+// cuando no está en caché o venció
+func (uc *WatermarkUseCase) resolve(userName string) cachedWatermark {
+	uc.mu.Lock()
+	cached, ok := uc.cache[userName]
+	uc.mu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached
+	}
+
+	cached = cachedWatermark{expiresAt: time.Now().Add(watermarkCacheTTL)}
+	settings, err := uc.GetDefault(userName)
+	switch {
+	case err == nil:
+		if mark, err := uc.imageRepo.FindByID(settings.ImageID); err == nil {
+			op := settings.Operation()
+			cached.op = &op
+			cached.markPath = mark.UserName + "/" + mark.Name
+		}
+	case !errors.Is(err, ErrNoDefaultWatermark):
+		// El error también queda en caché para no repetirlo en cada petición
+		log.Printf("  No se pudo obtener la marca de agua de %s: %v", userName, err)
+	}
+
+	uc.mu.Lock()
+	uc.cache[userName] = cached
+	uc.mu.Unlock()
+	return cached
+}
+
+func (uc *WatermarkUseCase) forget(userName string) {
+	uc.mu.Lock()
+	delete(uc.cache, userName)
+	uc.mu.Unlock()
+}
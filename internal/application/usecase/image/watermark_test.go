@@ -0,0 +1,271 @@
+package image_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	stdimage "image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/RodrigoGonzalez78/internal/application/usecase/image"
+	"github.com/RodrigoGonzalez78/internal/domain/entity"
+	"github.com/RodrigoGonzalez78/internal/domain/repository/mocks"
+)
+
+var markColor = color.NRGBA{R: 255, A: 255}
+
+// setupWatermark agrega una marca de agua PNG roja de 40x20 (ID 2), una de
+// otro usuario (ID 3) y un JPEG propio (ID 4)
+func setupWatermark(t *testing.T) (*image.TransformUseCase, *mocks.MockImageRepository, *mocks.MockFileStorage) {
+	t.Helper()
+	useCase, imageRepo, fileStorage := setupTransform(t)
+
+	mark := stdimage.NewNRGBA(stdimage.Rect(0, 0, 40, 20))
+	for i := 0; i < len(mark.Pix); i += 4 {
+		copy(mark.Pix[i:], []uint8{markColor.R, markColor.G, markColor.B, markColor.A})
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, mark); err != nil {
+		t.Fatalf("no se pudo codificar la marca de agua: %v", err)
+	}
+
+	imageRepo.Images[2] = &entity.Image{ID: 2, Name: "mark.png", UserName: "testuser", Path: "testuser/mark.png", Format: "png"}
+	imageRepo.Images[3] = &entity.Image{ID: 3, Name: "mark.png", UserName: "otro", Path: "otro/mark.png", Format: "png"}
+	imageRepo.Images[4] = &entity.Image{ID: 4, Name: "foto.jpg", UserName: "testuser", Path: "testuser/foto.jpg", Format: "jpeg"}
+	fileStorage.Files["testuser/mark.png"] = buf.Bytes()
+	fileStorage.Files["otro/mark.png"] = buf.Bytes()
+	fileStorage.Files["testuser/foto.jpg"] = encodeTestJPEG(t, 10, 10)
+
+	return useCase, imageRepo, fileStorage
+}
+
+func watermarkOp(params image.OperationParams) []image.Operation {
+	return []image.Operation{{Type: "watermark", Params: params}}
+}
+
+func isMarkColor(c color.Color) bool {
+	return color.NRGBAModel.Convert(c) == markColor
+}
+
+func TestTransformUseCase_Watermark(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   image.OperationParams
+		marked   []stdimage.Point
+		unmarked []stdimage.Point
+	}{
+		{
+			// La imagen es de 400x300: scale 0.1 da una marca de 40x20
+			name:     "esquina inferior derecha por defecto",
+			params:   image.OperationParams{"image_id": 2.0, "opacity": 1.0, "scale": 0.1},
+			marked:   []stdimage.Point{{X: 400 - 16 - 1, Y: 300 - 16 - 1}, {X: 400 - 16 - 40, Y: 300 - 16 - 20}},
+			unmarked: []stdimage.Point{{X: 0, Y: 0}, {X: 399, Y: 299}, {X: 400 - 16 - 41, Y: 270}},
+		},
+		{
+			name:     "esquina superior izquierda sin margen",
+			params:   image.OperationParams{"image_id": 2.0, "opacity": 1.0, "scale": 0.1, "anchor": "top-left", "margin": 0.0},
+			marked:   []stdimage.Point{{X: 0, Y: 0}, {X: 39, Y: 19}},
+			unmarked: []stdimage.Point{{X: 40, Y: 0}, {X: 0, Y: 20}},
+		},
+		{
+			name:     "centro",
+			params:   image.OperationParams{"image_id": 2.0, "opacity": 1.0, "scale": 0.1, "anchor": "center"},
+			marked:   []stdimage.Point{{X: 200, Y: 150}},
+			unmarked: []stdimage.Point{{X: 0, Y: 0}, {X: 200, Y: 100}},
+		},
+		{
+			name:     "mosaico",
+			params:   image.OperationParams{"image_id": 2.0, "opacity": 1.0, "scale": 0.1, "anchor": "tile", "margin": 10.0},
+			marked:   []stdimage.Point{{X: 10, Y: 10}, {X: 60, Y: 40}, {X: 360, Y: 280}},
+			unmarked: []stdimage.Point{{X: 5, Y: 5}, {X: 55, Y: 35}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCase, _, _ := setupWatermark(t)
+
+			output, err := useCase.Execute(context.Background(), image.TransformInput{
+				ImageID:    1,
+				UserName:   "testuser",
+				Operations: watermarkOp(tt.params),
+			})
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			result := decodeOutput(t, output)
+			if b := result.Bounds(); b.Dx() != 400 || b.Dy() != 300 {
+				t.Fatalf("dimensiones = %v, la marca de agua no debe cambiar el tamaño", b.Size())
+			}
+			for _, p := range tt.marked {
+				if !isMarkColor(result.At(p.X, p.Y)) {
+					t.Errorf("píxel %v = %v, se esperaba la marca de agua", p, result.At(p.X, p.Y))
+				}
+			}
+			for _, p := range tt.unmarked {
+				if isMarkColor(result.At(p.X, p.Y)) {
+					t.Errorf("píxel %v no debería tener la marca de agua", p)
+				}
+			}
+		})
+	}
+}
+
+func TestTransformUseCase_WatermarkOpacity(t *testing.T) {
+	useCase, _, _ := setupWatermark(t)
+
+	output, err := useCase.Execute(context.Background(), image.TransformInput{
+		ImageID:    1,
+		UserName:   "testuser",
+		Operations: watermarkOp(image.OperationParams{"image_id": 2.0, "opacity": 0.5, "anchor": "top-left", "margin": 0.0}),
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	// El fondo en (0,0) es R=0, G=0, B=128: con opacidad 0.5 queda a mitad de camino
+	c := color.NRGBAModel.Convert(decodeOutput(t, output).At(0, 0)).(color.NRGBA)
+	if c.R < 120 || c.R > 135 || c.B < 56 || c.B > 72 {
+		t.Errorf("píxel mezclado = %v, se esperaba R≈128 y B≈64", c)
+	}
+}
+
+func TestTransformUseCase_WatermarkErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    image.OperationParams
+		wantField string
+	}{
+		{name: "sin imagen", params: image.OperationParams{}, wantField: "image_id"},
+		{name: "anclaje inválido", params: image.OperationParams{"image_id": 2.0, "anchor": "arriba"}, wantField: "anchor"},
+		{name: "opacidad fuera de rango", params: image.OperationParams{"image_id": 2.0, "opacity": 1.5}, wantField: "opacity"},
+		{name: "escala en cero", params: image.OperationParams{"image_id": 2.0, "scale": 0.0}, wantField: "scale"},
+		{name: "margen negativo", params: image.OperationParams{"image_id": 2.0, "margin": -1.0}, wantField: "margin"},
+		{name: "imagen de otro usuario", params: image.OperationParams{"image_id": 3.0}, wantField: "image_id"},
+		{name: "imagen inexistente", params: image.OperationParams{"image_id": 99.0}, wantField: "image_id"},
+		{name: "imagen que no es png", params: image.OperationParams{"image_id": 4.0}, wantField: "image_id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCase, _, _ := setupWatermark(t)
+
+			_, err := useCase.Execute(context.Background(), image.TransformInput{
+				ImageID:    1,
+				UserName:   "testuser",
+				Operations: watermarkOp(tt.params),
+			})
+
+			var stepErr *image.StepError
+			var fieldErr *image.FieldError
+			if !errors.As(err, &stepErr) || !errors.As(err, &fieldErr) {
+				t.Fatalf("Execute() error = %v, want StepError con FieldError", err)
+			}
+			if fieldErr.Field != tt.wantField {
+				t.Errorf("FieldError.Field = %v, want %v", fieldErr.Field, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestTransformUseCase_WatermarkOwnerFromPath(t *testing.T) {
+	useCase, _, fileStorage := setupWatermark(t)
+	fileStorage.Files["otro/foto.png"] = encodeTestPNG(t, 100, 100)
+
+	// Por URL firmada el dueño sale de la ruta: "otro" puede usar su marca
+	// de agua pero no la de testuser
+	if _, err := useCase.ExecutePath(context.Background(), image.TransformPathInput{
+		ObjectPath: "otro/foto.png",
+		Operations: watermarkOp(image.OperationParams{"image_id": 3.0}),
+	}); err != nil {
+		t.Errorf("ExecutePath() con marca propia error = %v", err)
+	}
+
+	if _, err := useCase.ExecutePath(context.Background(), image.TransformPathInput{
+		ObjectPath: "otro/foto.png",
+		Operations: watermarkOp(image.OperationParams{"image_id": 2.0}),
+	}); err == nil {
+		t.Error("ExecutePath() con marca ajena se esperaba error")
+	}
+}
+
+func TestWatermarkUseCase_Default(t *testing.T) {
+	_, imageRepo, _ := setupWatermark(t)
+	userRepo := mocks.NewMockUserRepository()
+	userRepo.Users["testuser"] = entity.NewUser("testuser", "hash")
+	useCase := image.NewWatermarkUseCase(userRepo, imageRepo)
+
+	if _, err := useCase.GetDefault("testuser"); !errors.Is(err, image.ErrNoDefaultWatermark) {
+		t.Errorf("GetDefault() error = %v, want ErrNoDefaultWatermark", err)
+	}
+	if ops := useCase.PublicPipeline("testuser/test.png", nil); len(ops) != 0 {
+		t.Errorf("PublicPipeline() sin marca = %v, want vacío", ops)
+	}
+
+	for _, id := range []int64{3, 4, 99} {
+		if _, err := useCase.SetDefault("testuser", image.WatermarkSettings{ImageID: id}); err == nil {
+			t.Errorf("SetDefault(%d) se esperaba error", id)
+		}
+	}
+
+	settings, err := useCase.SetDefault("testuser", image.WatermarkSettings{ImageID: 2, Anchor: "tile"})
+	if err != nil {
+		t.Fatalf("SetDefault() error = %v", err)
+	}
+	if settings.Opacity != 0.5 || settings.Scale != 0.25 || settings.Margin == nil || *settings.Margin != 16 {
+		t.Errorf("SetDefault() = %+v, se esperaban los valores por defecto", settings)
+	}
+
+	stored, err := useCase.GetDefault("testuser")
+	if err != nil {
+		t.Fatalf("GetDefault() error = %v", err)
+	}
+	if stored.ImageID != 2 || stored.Anchor != "tile" {
+		t.Errorf("GetDefault() = %+v", stored)
+	}
+
+	resize := []image.Operation{{Type: "resize", Params: image.OperationParams{"width": 10.0, "height": 10.0}}}
+	ops := useCase.PublicPipeline("testuser/test.png", resize)
+	if len(ops) != 2 || ops[0].Type != "resize" || ops[1].Type != "watermark" {
+		t.Errorf("PublicPipeline() = %v, want resize y luego watermark", ops)
+	}
+	if len(resize) != 1 {
+		t.Error("PublicPipeline() no debe modificar las operaciones recibidas")
+	}
+
+	if ops := useCase.PublicPipeline("testuser/mark.png", nil); len(ops) != 0 {
+		t.Error("la marca de agua no debe aplicarse sobre sí misma")
+	}
+	if ops := useCase.PublicPipeline("otro/mark.png", nil); len(ops) != 0 {
+		t.Error("la marca de agua de un usuario no debe aplicarse a otro")
+	}
+	// Las versiones y los resultados de trabajos también son públicos
+	for _, objectPath := range []string{"testuser/renditions/1/3f0c2a9e-8d4b-4c1e-9a7f-2b6d5e8c1a40/640.webp", "testuser/jobs/3f0c2a9e-8d4b-4c1e-9a7f-2b6d5e8c1a40.png"} {
+		if ops := useCase.PublicPipeline(objectPath, nil); len(ops) != 1 {
+			t.Errorf("PublicPipeline(%s) = %v, want la marca", objectPath, ops)
+		}
+	}
+	if ops := useCase.PublicPipeline("cache/ab/cdef.png", nil); len(ops) != 0 {
+		t.Errorf("PublicPipeline() fuera de la carpeta de un usuario = %v, want vacío", ops)
+	}
+
+	// La configuración se lee una vez y se reutiliza; los cambios hechos con
+	// el caso de uso la invalidan enseguida
+	userRepo.Users["testuser"].DefaultWatermark = ""
+	if ops := useCase.PublicPipeline("testuser/test.png", nil); len(ops) != 1 {
+		t.Errorf("PublicPipeline() = %v, want la marca en caché", ops)
+	}
+
+	if err := useCase.ClearDefault("testuser"); err != nil {
+		t.Fatalf("ClearDefault() error = %v", err)
+	}
+	if _, err := useCase.GetDefault("testuser"); !errors.Is(err, image.ErrNoDefaultWatermark) {
+		t.Errorf("GetDefault() tras ClearDefault error = %v", err)
+	}
+	if ops := useCase.PublicPipeline("testuser/test.png", nil); len(ops) != 0 {
+		t.Errorf("PublicPipeline() tras ClearDefault = %v, want vacío", ops)
+	}
+}
@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+// Blob contenido de una imagen guardado una sola vez en el storage bajo su
+// SHA-256. RefCount es la cantidad de imágenes que lo usan.
+type Blob struct {
+	Checksum    string
+	Path        string
+	Size        int64
+	ContentType string
+	RefCount    int64
+	CreatedAt   time.Time
+}
+
+func NewBlob(checksum, path, contentType string, size int64) *Blob {
+	return &Blob{
+		Checksum:    checksum,
+		Path:        path,
+		Size:        size,
+		ContentType: contentType,
+		RefCount:    1,
+		CreatedAt:   time.Now(),
+	}
+}
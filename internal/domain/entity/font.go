@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+// Font fuente TrueType/OpenType subida por un usuario para la operación text
+type Font struct {
+	ID        int64
+	UserName  string
+	Name      string
+	Family    string
+	Path      string
+	Size      int64
+	CreatedAt time.Time
+}
+
+func NewFont(userName, name, family, path string, size int64) *Font {
+	return &Font{
+		UserName:  userName,
+		Name:      name,
+		Family:    family,
+		Path:      path,
+		Size:      size,
+		CreatedAt: time.Now(),
+	}
+}
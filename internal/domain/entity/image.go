@@ -3,15 +3,24 @@ package entity
 import "time"
 
 type Image struct {
-	ID        int64
-	Name      string
-	UserName  string
-	Path      string
-	Size      int64
-	Format    string
-	Width     int
-	Height    int
-	CreatedAt time.Time
+	ID              int64
+	Name            string
+	UserName        string
+	Path            string
+	Size            int64
+	Format          string
+	Width           int
+	Height          int
+	CreatedAt       time.Time
+	PendingDeleteAt *time.Time
+	// ParentID es la imagen de la que se obtuvo esta al guardar una
+	// transformación; nil para las imágenes subidas
+	ParentID *int64
+	// Operations es el pipeline aplicado a la imagen padre, serializado en JSON
+	Operations string
+	// PerceptualHash es el dHash de la imagen en hexadecimal; vacío si todavía
+	// no se calculó
+	PerceptualHash string
 }
 
 func NewImage(name, userName, path, format string, size int64, width, height int) *Image {
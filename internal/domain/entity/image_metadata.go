@@ -0,0 +1,23 @@
+package entity
+
+import "time"
+
+// ImageMetadata datos EXIF extraídos de una imagen al subirla. Los campos que
+// el archivo no trae quedan en su valor cero (o nil).
+type ImageMetadata struct {
+	ImageID      int64
+	Orientation  int
+	CameraMake   string
+	CameraModel  string
+	LensMake     string
+	LensModel    string
+	Software     string
+	CapturedAt   *time.Time
+	Latitude     *float64
+	Longitude    *float64
+	Altitude     *float64
+	ExposureTime float64 // segundos
+	FNumber      float64
+	ISO          int
+	FocalLength  float64 // milímetros
+}
@@ -0,0 +1,28 @@
+package entity
+
+import "time"
+
+// Preset pipeline de transformación con nombre guardado por un usuario
+type Preset struct {
+	ID         int64
+	UserName   string
+	Name       string
+	Operations string // pipeline serializado en JSON
+	Format     string
+	Encoding   string // opciones de codificación serializadas en JSON
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func NewPreset(userName, name, operations, format, encoding string) *Preset {
+	now := time.Now()
+	return &Preset{
+		UserName:   userName,
+		Name:       name,
+		Operations: operations,
+		Format:     format,
+		Encoding:   encoding,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
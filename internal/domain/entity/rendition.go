@@ -0,0 +1,28 @@
+package entity
+
+import "time"
+
+// Rendition versión redimensionada de una imagen generada al subirla para
+// armar atributos srcset
+type Rendition struct {
+	ID        int64
+	ImageID   int64
+	Width     int
+	Height    int
+	Format    string
+	Path      string
+	Size      int64
+	CreatedAt time.Time
+}
+
+func NewRendition(imageID int64, width, height int, format, path string, size int64) *Rendition {
+	return &Rendition{
+		ImageID:   imageID,
+		Width:     width,
+		Height:    height,
+		Format:    format,
+		Path:      path,
+		Size:      size,
+		CreatedAt: time.Now(),
+	}
+}
@@ -0,0 +1,38 @@
+package entity
+
+import "time"
+
+// ResumableUpload subida que llega en varias peticiones. Lo recibido se
+// guarda en partes en el storage hasta completar Length bytes; Parts tiene el
+// offset donde empieza cada una.
+type ResumableUpload struct {
+	ID       string
+	UserName string
+	Length   int64
+	Offset   int64
+	Parts    []int64
+	// Metadata es el Upload-Metadata del cliente tal como llegó
+	Metadata           string
+	MetadataPolicy     string
+	OnDuplicate        string
+	DuplicateThreshold int
+	// ImageID es la imagen creada al completarse la subida
+	ImageID   *int64
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+func NewResumableUpload(id, userName string, length int64, expiresAt time.Time) *ResumableUpload {
+	return &ResumableUpload{
+		ID:        id,
+		UserName:  userName,
+		Length:    length,
+		CreatedAt: time.Now(),
+		ExpiresAt: expiresAt,
+	}
+}
+
+// Completed indica si ya se recibió todo el contenido
+func (u *ResumableUpload) Completed() bool {
+	return u.Offset == u.Length
+}
@@ -0,0 +1,29 @@
+package entity
+
+import "time"
+
+type RefreshToken struct {
+	ID        int64
+	TokenHash string
+	UserName  string
+	FamilyID  string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+}
+
+func NewRefreshToken(tokenHash, userName, familyID string, expiresAt time.Time) *RefreshToken {
+	return &RefreshToken{
+		TokenHash: tokenHash,
+		UserName:  userName,
+		FamilyID:  familyID,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}
+}
+
+type RevokedToken struct {
+	JTI       string
+	ExpiresAt time.Time
+}
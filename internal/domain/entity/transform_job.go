@@ -0,0 +1,45 @@
+package entity
+
+import "time"
+
+type JobStatus string
+
+const (
+	JobQueued    JobStatus = "queued"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+	JobCanceled  JobStatus = "canceled"
+)
+
+// Finished indica si el trabajo ya no va a cambiar de estado
+func (s JobStatus) Finished() bool {
+	return s == JobSucceeded || s == JobFailed || s == JobCanceled
+}
+
+type TransformJob struct {
+	ID         int64
+	UserName   string
+	ImageID    int64
+	Operations string // pipeline serializado en JSON
+	Format     string
+	Encoding   string // opciones de codificación serializadas en JSON
+	Status     JobStatus
+	ResultPath string
+	Error      string
+	CreatedAt  time.Time
+	StartedAt  *time.Time
+	FinishedAt *time.Time
+}
+
+func NewTransformJob(userName string, imageID int64, operations, format, encoding string) *TransformJob {
+	return &TransformJob{
+		UserName:   userName,
+		ImageID:    imageID,
+		Operations: operations,
+		Format:     format,
+		Encoding:   encoding,
+		Status:     JobQueued,
+		CreatedAt:  time.Now(),
+	}
+}
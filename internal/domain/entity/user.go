@@ -3,6 +3,12 @@ package entity
 type User struct {
 	UserName string
 	Password string
+	// DefaultWatermark guarda en JSON la marca de agua que se aplica a las
+	// imágenes públicas del usuario; vacío si no tiene
+	DefaultWatermark string
+	// MetadataPolicy indica qué metadatos se quitan de las imágenes que sube
+	// el usuario (keep, strip_gps o strip_all); vacío equivale a keep
+	MetadataPolicy string
 }
 
 func NewUser(userName, password string) *User {
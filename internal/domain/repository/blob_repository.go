@@ -0,0 +1,16 @@
+package repository
+
+import "github.com/RodrigoGonzalez78/internal/domain/entity"
+
+// BlobRepository lleva la cuenta de referencias de los blobs del storage
+type BlobRepository interface {
+	// Acquire suma una referencia al blob, creándolo con una referencia si
+	// no existía. Indica si se creó.
+	Acquire(blob *entity.Blob) (bool, error)
+
+	// Release resta una referencia y devuelve las que quedan; al llegar a
+	// cero se elimina el registro
+	Release(checksum string) (int64, error)
+
+	FindByChecksum(checksum string) (*entity.Blob, error)
+}
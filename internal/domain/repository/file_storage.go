@@ -2,11 +2,39 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"io"
+	"time"
 )
 
+// ErrObjectNotFound indica que el objeto no existe en el storage
+var ErrObjectNotFound = errors.New("objeto no encontrado")
+
+// UnknownSize se pasa a Upload cuando no se conoce el largo del contenido
+const UnknownSize int64 = -1
+
+// ObjectInfo metadata de un objeto almacenado
+type ObjectInfo struct {
+	Path         string
+	Size         int64
+	ETag         string
+	ContentType  string
+	LastModified time.Time
+}
+
 type FileStorage interface {
-	Upload(ctx context.Context, path string, data []byte, contentType string) error
+	// Upload guarda el contenido leído de reader. size es la cantidad de bytes
+	// o UnknownSize; si reader falla a mitad de camino no queda objeto en path
+	Upload(ctx context.Context, path string, reader io.Reader, size int64, contentType string) error
 
 	Get(ctx context.Context, path string) (io.ReadCloser, error)
+
+	Stat(ctx context.Context, path string) (*ObjectInfo, error)
+
+	// Move renombra un objeto reemplazando el destino si ya existe
+	Move(ctx context.Context, from, to string) error
+
+	Delete(ctx context.Context, path string) error
+
+	DeletePrefix(ctx context.Context, prefix string) error
 }
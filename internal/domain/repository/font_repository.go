@@ -0,0 +1,13 @@
+package repository
+
+import "github.com/RodrigoGonzalez78/internal/domain/entity"
+
+type FontRepository interface {
+	Create(font *entity.Font) error
+
+	FindByID(id int64) (*entity.Font, error)
+
+	FindByUser(userName string) ([]entity.Font, error)
+
+	Delete(id int64) error
+}
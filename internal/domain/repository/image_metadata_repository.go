@@ -0,0 +1,13 @@
+package repository
+
+import "github.com/RodrigoGonzalez78/internal/domain/entity"
+
+// ImageMetadataRepository guarda los metadatos EXIF de cada imagen
+type ImageMetadataRepository interface {
+	// Save crea o reemplaza los metadatos de la imagen
+	Save(metadata *entity.ImageMetadata) error
+
+	FindByImageID(imageID int64) (*entity.ImageMetadata, error)
+
+	Delete(imageID int64) error
+}
@@ -8,4 +8,31 @@ type ImageRepository interface {
 	FindByID(id int64) (*entity.Image, error)
 
 	FindByUser(userName string, page, limit int) ([]entity.Image, int64, error)
+
+	// FindByName busca una imagen del usuario por el nombre de su URL pública
+	FindByName(userName, name string) (*entity.Image, error)
+
+	// FindChildren devuelve las imágenes guardadas a partir de parentID,
+	// ordenadas por ID
+	FindChildren(parentID int64) ([]entity.Image, error)
+
+	// FindHashedByUser devuelve las imágenes del usuario que tienen hash
+	// perceptual, ordenadas por ID
+	FindHashedByUser(userName string) ([]entity.Image, error)
+
+	// FindUnhashed devuelve hasta limit imágenes sin hash perceptual con ID
+	// mayor que afterID, ordenadas por ID
+	FindUnhashed(afterID int64, limit int) ([]entity.Image, error)
+
+	UpdatePerceptualHash(id int64, hash string) error
+
+	// UpdateObject apunta la imagen a otro objeto del storage cuando se
+	// reescribe su contenido
+	UpdateObject(id int64, path string, size int64) error
+
+	MarkPendingDelete(id int64) error
+
+	FindPendingDelete(limit int) ([]entity.Image, error)
+
+	Delete(id int64) error
 }
@@ -0,0 +1,70 @@
+package mocks
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/RodrigoGonzalez78/internal/domain/entity"
+)
+
+// MockBlobRepository es un mock del repositorio de blobs para testing
+type MockBlobRepository struct {
+	mu           sync.Mutex
+	Blobs        map[string]*entity.Blob
+	AcquireError error
+}
+
+// NewMockBlobRepository crea un nuevo mock de BlobRepository
+func NewMockBlobRepository() *MockBlobRepository {
+	return &MockBlobRepository{
+		Blobs: make(map[string]*entity.Blob),
+	}
+}
+
+// Acquire simula sumar una referencia a un blob
+func (m *MockBlobRepository) Acquire(blob *entity.Blob) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.AcquireError != nil {
+		return false, m.AcquireError
+	}
+	if existing, exists := m.Blobs[blob.Checksum]; exists {
+		existing.RefCount++
+		return false, nil
+	}
+	stored := *blob
+	stored.RefCount = 1
+	m.Blobs[blob.Checksum] = &stored
+	return true, nil
+}
+
+// Release simula restar una referencia a un blob
+func (m *MockBlobRepository) Release(checksum string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	blob, exists := m.Blobs[checksum]
+	if !exists {
+		return 0, errors.New("blob no encontrado")
+	}
+	blob.RefCount--
+	if blob.RefCount <= 0 {
+		delete(m.Blobs, checksum)
+		return 0, nil
+	}
+	return blob.RefCount, nil
+}
+
+// FindByChecksum simula buscar un blob
+func (m *MockBlobRepository) FindByChecksum(checksum string) (*entity.Blob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	blob, exists := m.Blobs[checksum]
+	if !exists {
+		return nil, errors.New("blob no encontrado")
+	}
+	copied := *blob
+	return &copied, nil
+}
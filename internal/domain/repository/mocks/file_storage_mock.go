@@ -2,17 +2,28 @@ package mocks
 
 import (
 	"context"
+	"crypto/md5"
+	"encoding/hex"
 	"io"
 	"strings"
+	"sync"
+
+	"github.com/RodrigoGonzalez78/internal/domain/repository"
 )
 
 // MockFileStorage es un mock del storage de archivos para testing
 type MockFileStorage struct {
+	mu           sync.Mutex
 	Files        map[string][]byte
 	UploadError  error
 	GetError     error
+	StatError    error
+	DeleteError  error
 	UploadCalled bool
 	GetCalled    bool
+	StatCalled   bool
+	DeleteCalled bool
+	GetCount     int
 }
 
 // NewMockFileStorage crea un nuevo mock de FileStorage
@@ -23,24 +34,105 @@ func NewMockFileStorage() *MockFileStorage {
 }
 
 // Upload simula subir un archivo
-func (m *MockFileStorage) Upload(ctx context.Context, path string, data []byte, contentType string) error {
+func (m *MockFileStorage) Upload(ctx context.Context, path string, reader io.Reader, size int64, contentType string) error {
+	// Consumir el reader aun si falla, como haría un backend real
+	data, readErr := io.ReadAll(reader)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	m.UploadCalled = true
 	if m.UploadError != nil {
 		return m.UploadError
 	}
+	if readErr != nil {
+		return readErr
+	}
+	// Un backend real aborta la subida si se cancela el contexto
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	m.Files[path] = data
 	return nil
 }
 
 // Get simula obtener un archivo
 func (m *MockFileStorage) Get(ctx context.Context, path string) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	m.GetCalled = true
+	m.GetCount++
 	if m.GetError != nil {
 		return nil, m.GetError
 	}
 	data, exists := m.Files[path]
 	if !exists {
-		return nil, m.GetError
+		return nil, repository.ErrObjectNotFound
 	}
 	return io.NopCloser(strings.NewReader(string(data))), nil
 }
+
+// Stat simula obtener la metadata de un archivo
+func (m *MockFileStorage) Stat(ctx context.Context, path string) (*repository.ObjectInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.StatCalled = true
+	if m.StatError != nil {
+		return nil, m.StatError
+	}
+	data, exists := m.Files[path]
+	if !exists {
+		return nil, repository.ErrObjectNotFound
+	}
+	sum := md5.Sum(data)
+	return &repository.ObjectInfo{
+		Path: path,
+		Size: int64(len(data)),
+		ETag: hex.EncodeToString(sum[:]),
+	}, nil
+}
+
+// Move simula renombrar un archivo
+func (m *MockFileStorage) Move(ctx context.Context, from, to string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	data, exists := m.Files[from]
+	if !exists {
+		return repository.ErrObjectNotFound
+	}
+	m.Files[to] = data
+	delete(m.Files, from)
+	return nil
+}
+
+// Delete simula eliminar un archivo
+func (m *MockFileStorage) Delete(ctx context.Context, path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.DeleteCalled = true
+	if m.DeleteError != nil {
+		return m.DeleteError
+	}
+	delete(m.Files, path)
+	return nil
+}
+
+// DeletePrefix simula eliminar todos los archivos bajo un prefijo
+func (m *MockFileStorage) DeletePrefix(ctx context.Context, prefix string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.DeleteError != nil {
+		return m.DeleteError
+	}
+	for path := range m.Files {
+		if strings.HasPrefix(path, prefix) {
+			delete(m.Files, path)
+		}
+	}
+	return nil
+}
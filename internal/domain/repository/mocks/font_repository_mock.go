@@ -0,0 +1,79 @@
+package mocks
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/RodrigoGonzalez78/internal/domain/entity"
+)
+
+// MockFontRepository es un mock del repositorio de fuentes para testing
+type MockFontRepository struct {
+	mu          sync.Mutex
+	Fonts       map[int64]*entity.Font
+	NextID      int64
+	CreateError error
+	DeleteError error
+}
+
+// NewMockFontRepository crea un nuevo mock de FontRepository
+func NewMockFontRepository() *MockFontRepository {
+	return &MockFontRepository{
+		Fonts:  make(map[int64]*entity.Font),
+		NextID: 1,
+	}
+}
+
+// Create simula la creación de una fuente
+func (m *MockFontRepository) Create(font *entity.Font) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.CreateError != nil {
+		return m.CreateError
+	}
+	font.ID = m.NextID
+	m.Fonts[m.NextID] = font
+	m.NextID++
+	return nil
+}
+
+// FindByID simula buscar una fuente por ID
+func (m *MockFontRepository) FindByID(id int64) (*entity.Font, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	font, exists := m.Fonts[id]
+	if !exists {
+		return nil, errors.New("fuente no encontrada")
+	}
+	return font, nil
+}
+
+// FindByUser simula obtener las fuentes de un usuario ordenadas por ID
+func (m *MockFontRepository) FindByUser(userName string) ([]entity.Font, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var result []entity.Font
+	for _, font := range m.Fonts {
+		if font.UserName == userName {
+			result = append(result, *font)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+// Delete simula eliminar una fuente
+func (m *MockFontRepository) Delete(id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.DeleteError != nil {
+		return m.DeleteError
+	}
+	delete(m.Fonts, id)
+	return nil
+}
@@ -0,0 +1,55 @@
+package mocks
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/RodrigoGonzalez78/internal/domain/entity"
+)
+
+// MockImageMetadataRepository es un mock del repositorio de metadatos para testing
+type MockImageMetadataRepository struct {
+	mu        sync.Mutex
+	Metadata  map[int64]*entity.ImageMetadata
+	SaveError error
+}
+
+// NewMockImageMetadataRepository crea un nuevo mock de ImageMetadataRepository
+func NewMockImageMetadataRepository() *MockImageMetadataRepository {
+	return &MockImageMetadataRepository{
+		Metadata: make(map[int64]*entity.ImageMetadata),
+	}
+}
+
+// Save simula guardar los metadatos de una imagen
+func (m *MockImageMetadataRepository) Save(metadata *entity.ImageMetadata) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.SaveError != nil {
+		return m.SaveError
+	}
+	m.Metadata[metadata.ImageID] = metadata
+	return nil
+}
+
+// FindByImageID simula buscar los metadatos de una imagen
+func (m *MockImageMetadataRepository) FindByImageID(imageID int64) (*entity.ImageMetadata, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	metadata, exists := m.Metadata[imageID]
+	if !exists {
+		return nil, errors.New("metadatos no encontrados")
+	}
+	return metadata, nil
+}
+
+// Delete simula borrar los metadatos de una imagen
+func (m *MockImageMetadataRepository) Delete(imageID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.Metadata, imageID)
+	return nil
+}
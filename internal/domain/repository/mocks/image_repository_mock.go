@@ -2,20 +2,27 @@ package mocks
 
 import (
 	"errors"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/RodrigoGonzalez78/internal/domain/entity"
 )
 
 // MockImageRepository es un mock del repositorio de imágenes para testing
 type MockImageRepository struct {
+	mu               sync.Mutex
 	Images           map[int64]*entity.Image
 	NextID           int64
 	CreateError      error
 	FindByIDError    error
 	FindByUserError  error
+	MarkError        error
+	DeleteError      error
 	CreateCalled     bool
 	FindByIDCalled   bool
 	FindByUserCalled bool
+	DeleteCalled     bool
 }
 
 // NewMockImageRepository crea un nuevo mock de ImageRepository
@@ -28,6 +35,9 @@ func NewMockImageRepository() *MockImageRepository {
 
 // Create simula la creación de una imagen
 func (m *MockImageRepository) Create(image *entity.Image) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	m.CreateCalled = true
 	if m.CreateError != nil {
 		return m.CreateError
@@ -40,12 +50,15 @@ func (m *MockImageRepository) Create(image *entity.Image) error {
 
 // FindByID simula buscar una imagen por ID
 func (m *MockImageRepository) FindByID(id int64) (*entity.Image, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	m.FindByIDCalled = true
 	if m.FindByIDError != nil {
 		return nil, m.FindByIDError
 	}
 	image, exists := m.Images[id]
-	if !exists {
+	if !exists || image.PendingDeleteAt != nil {
 		return nil, errors.New("imagen no encontrada")
 	}
 	return image, nil
@@ -53,6 +66,9 @@ func (m *MockImageRepository) FindByID(id int64) (*entity.Image, error) {
 
 // FindByUser simula obtener imágenes de un usuario
 func (m *MockImageRepository) FindByUser(userName string, page, limit int) ([]entity.Image, int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	m.FindByUserCalled = true
 	if m.FindByUserError != nil {
 		return nil, 0, m.FindByUserError
@@ -60,11 +76,13 @@ func (m *MockImageRepository) FindByUser(userName string, page, limit int) ([]en
 
 	var result []entity.Image
 	for _, img := range m.Images {
-		if img.UserName == userName {
+		if img.UserName == userName && img.PendingDeleteAt == nil {
 			result = append(result, *img)
 		}
 	}
 
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+
 	// Simular paginación simple
 	start := (page - 1) * limit
 	end := start + limit
@@ -77,3 +95,144 @@ func (m *MockImageRepository) FindByUser(userName string, page, limit int) ([]en
 
 	return result[start:end], int64(len(result)), nil
 }
+
+// FindByName simula buscar una imagen del usuario por nombre
+func (m *MockImageRepository) FindByName(userName, name string) (*entity.Image, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var found *entity.Image
+	for _, img := range m.Images {
+		if img.UserName == userName && img.Name == name && img.PendingDeleteAt == nil {
+			if found == nil || img.ID < found.ID {
+				found = img
+			}
+		}
+	}
+	if found == nil {
+		return nil, errors.New("imagen no encontrada")
+	}
+	return found, nil
+}
+
+// FindChildren simula obtener las imágenes derivadas de otra
+func (m *MockImageRepository) FindChildren(parentID int64) ([]entity.Image, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := []entity.Image{}
+	for _, img := range m.Images {
+		if img.ParentID != nil && *img.ParentID == parentID && img.PendingDeleteAt == nil {
+			result = append(result, *img)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+// FindHashedByUser simula obtener las imágenes del usuario con hash perceptual
+func (m *MockImageRepository) FindHashedByUser(userName string) ([]entity.Image, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := []entity.Image{}
+	for _, img := range m.Images {
+		if img.UserName == userName && img.PerceptualHash != "" && img.PendingDeleteAt == nil {
+			result = append(result, *img)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+// FindUnhashed simula obtener las imágenes sin hash perceptual
+func (m *MockImageRepository) FindUnhashed(afterID int64, limit int) ([]entity.Image, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := []entity.Image{}
+	for _, img := range m.Images {
+		if img.ID > afterID && img.PerceptualHash == "" && img.PendingDeleteAt == nil {
+			result = append(result, *img)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// UpdatePerceptualHash simula guardar el hash perceptual de una imagen
+func (m *MockImageRepository) UpdatePerceptualHash(id int64, hash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	image, exists := m.Images[id]
+	if !exists {
+		return errors.New("imagen no encontrada")
+	}
+	image.PerceptualHash = hash
+	return nil
+}
+
+// UpdateObject simula apuntar una imagen a otro objeto
+func (m *MockImageRepository) UpdateObject(id int64, path string, size int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	image, exists := m.Images[id]
+	if !exists {
+		return errors.New("imagen no encontrada")
+	}
+	image.Path = path
+	image.Size = size
+	return nil
+}
+
+// MarkPendingDelete simula marcar una imagen como pendiente de eliminación
+func (m *MockImageRepository) MarkPendingDelete(id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.MarkError != nil {
+		return m.MarkError
+	}
+	image, exists := m.Images[id]
+	if !exists {
+		return errors.New("imagen no encontrada")
+	}
+	now := time.Now()
+	image.PendingDeleteAt = &now
+	return nil
+}
+
+// FindPendingDelete simula obtener las imágenes pendientes de eliminación
+func (m *MockImageRepository) FindPendingDelete(limit int) ([]entity.Image, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var result []entity.Image
+	for _, img := range m.Images {
+		if img.PendingDeleteAt != nil && len(result) < limit {
+			result = append(result, *img)
+		}
+	}
+	return result, nil
+}
+
+// Delete simula eliminar una imagen
+func (m *MockImageRepository) Delete(id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.DeleteCalled = true
+	if m.DeleteError != nil {
+		return m.DeleteError
+	}
+	delete(m.Images, id)
+	return nil
+}
@@ -0,0 +1,113 @@
+package mocks
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/RodrigoGonzalez78/internal/domain/entity"
+)
+
+// MockPresetRepository es un mock del repositorio de presets para testing
+type MockPresetRepository struct {
+	mu          sync.Mutex
+	Presets     map[int64]*entity.Preset
+	NextID      int64
+	CreateError error
+}
+
+// NewMockPresetRepository crea un nuevo mock de PresetRepository
+func NewMockPresetRepository() *MockPresetRepository {
+	return &MockPresetRepository{
+		Presets: make(map[int64]*entity.Preset),
+		NextID:  1,
+	}
+}
+
+// Create simula la creación de un preset; el nombre es único por usuario
+func (m *MockPresetRepository) Create(preset *entity.Preset) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.CreateError != nil {
+		return m.CreateError
+	}
+	for _, p := range m.Presets {
+		if p.UserName == preset.UserName && p.Name == preset.Name {
+			return errors.New("UNIQUE constraint failed: presets.user_name, presets.name")
+		}
+	}
+	preset.ID = m.NextID
+	m.Presets[m.NextID] = preset
+	m.NextID++
+	return nil
+}
+
+// FindByID simula buscar un preset por ID
+func (m *MockPresetRepository) FindByID(id int64) (*entity.Preset, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	preset, exists := m.Presets[id]
+	if !exists {
+		return nil, errors.New("preset no encontrado")
+	}
+	copied := *preset
+	return &copied, nil
+}
+
+// FindByName simula buscar un preset por usuario y nombre
+func (m *MockPresetRepository) FindByName(userName, name string) (*entity.Preset, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, preset := range m.Presets {
+		if preset.UserName == userName && preset.Name == name {
+			copied := *preset
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("preset no encontrado")
+}
+
+// FindByUser simula obtener los presets de un usuario ordenados por nombre
+func (m *MockPresetRepository) FindByUser(userName string) ([]entity.Preset, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var result []entity.Preset
+	for _, preset := range m.Presets {
+		if preset.UserName == userName {
+			result = append(result, *preset)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// Update simula reemplazar un preset
+func (m *MockPresetRepository) Update(preset *entity.Preset) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.Presets[preset.ID]; !exists {
+		return errors.New("preset no encontrado")
+	}
+	for id, p := range m.Presets {
+		if id != preset.ID && p.UserName == preset.UserName && p.Name == preset.Name {
+			return errors.New("UNIQUE constraint failed: presets.user_name, presets.name")
+		}
+	}
+	copied := *preset
+	m.Presets[preset.ID] = &copied
+	return nil
+}
+
+// Delete simula eliminar un preset
+func (m *MockPresetRepository) Delete(id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.Presets, id)
+	return nil
+}
@@ -0,0 +1,58 @@
+package mocks
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/RodrigoGonzalez78/internal/domain/entity"
+)
+
+// MockRenditionRepository es un mock del repositorio de versiones para testing
+type MockRenditionRepository struct {
+	mu           sync.Mutex
+	Renditions   map[int64][]entity.Rendition
+	ReplaceError error
+	// Images, si no es nil, hace que ReplaceForImage solo guarde versiones
+	// de imágenes que sigue encontrando
+	Images *MockImageRepository
+}
+
+// NewMockRenditionRepository crea un nuevo mock de RenditionRepository
+func NewMockRenditionRepository() *MockRenditionRepository {
+	return &MockRenditionRepository{
+		Renditions: make(map[int64][]entity.Rendition),
+	}
+}
+
+// ReplaceForImage simula reemplazar las versiones de una imagen
+func (m *MockRenditionRepository) ReplaceForImage(imageID int64, renditions []entity.Rendition) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.ReplaceError != nil {
+		return false, m.ReplaceError
+	}
+	if m.Images != nil {
+		if _, err := m.Images.FindByID(imageID); err != nil {
+			delete(m.Renditions, imageID)
+			return false, nil
+		}
+	}
+	m.Renditions[imageID] = append([]entity.Rendition(nil), renditions...)
+	return true, nil
+}
+
+// FindByImageID simula obtener las versiones ordenadas por ancho y formato
+func (m *MockRenditionRepository) FindByImageID(imageID int64) ([]entity.Rendition, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := append([]entity.Rendition(nil), m.Renditions[imageID]...)
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Width != result[j].Width {
+			return result[i].Width < result[j].Width
+		}
+		return result[i].Format < result[j].Format
+	})
+	return result, nil
+}
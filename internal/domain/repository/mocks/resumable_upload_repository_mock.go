@@ -0,0 +1,117 @@
+package mocks
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/RodrigoGonzalez78/internal/domain/entity"
+)
+
+// MockResumableUploadRepository es un mock del repositorio de subidas
+// reanudables para testing. Guarda y devuelve copias, como una base de datos.
+type MockResumableUploadRepository struct {
+	mu            sync.Mutex
+	Uploads       map[string]*entity.ResumableUpload
+	AdvanceError  error
+	CompleteError error
+}
+
+// NewMockResumableUploadRepository crea un nuevo mock de ResumableUploadRepository
+func NewMockResumableUploadRepository() *MockResumableUploadRepository {
+	return &MockResumableUploadRepository{
+		Uploads: make(map[string]*entity.ResumableUpload),
+	}
+}
+
+// Create simula guardar una subida
+func (m *MockResumableUploadRepository) Create(upload *entity.ResumableUpload) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.Uploads[upload.ID]; exists {
+		return errors.New("la subida ya existe")
+	}
+	m.Uploads[upload.ID] = copyUpload(upload)
+	return nil
+}
+
+// FindByID simula buscar una subida
+func (m *MockResumableUploadRepository) FindByID(id string) (*entity.ResumableUpload, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	upload, exists := m.Uploads[id]
+	if !exists {
+		return nil, errors.New("subida no encontrada")
+	}
+	return copyUpload(upload), nil
+}
+
+// Advance simula guardar el avance condicionado al offset anterior
+func (m *MockResumableUploadRepository) Advance(upload *entity.ResumableUpload, from int64) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.AdvanceError != nil {
+		return false, m.AdvanceError
+	}
+	stored, exists := m.Uploads[upload.ID]
+	if !exists || stored.Offset != from {
+		return false, nil
+	}
+	stored.Offset = upload.Offset
+	stored.Parts = append([]int64(nil), upload.Parts...)
+	stored.ExpiresAt = upload.ExpiresAt
+	return true, nil
+}
+
+// Complete simula registrar la imagen creada
+func (m *MockResumableUploadRepository) Complete(id string, imageID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.CompleteError != nil {
+		return m.CompleteError
+	}
+	stored, exists := m.Uploads[id]
+	if !exists {
+		return errors.New("subida no encontrada")
+	}
+	stored.ImageID = &imageID
+	return nil
+}
+
+// Delete simula eliminar una subida
+func (m *MockResumableUploadRepository) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.Uploads, id)
+	return nil
+}
+
+// FindExpired simula buscar las subidas vencidas
+func (m *MockResumableUploadRepository) FindExpired(before time.Time, limit int) ([]entity.ResumableUpload, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var expired []entity.ResumableUpload
+	for _, upload := range m.Uploads {
+		if upload.ExpiresAt.Before(before) {
+			expired = append(expired, *copyUpload(upload))
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool { return expired[i].ExpiresAt.Before(expired[j].ExpiresAt) })
+	if len(expired) > limit {
+		expired = expired[:limit]
+	}
+	return expired, nil
+}
+
+func copyUpload(upload *entity.ResumableUpload) *entity.ResumableUpload {
+	stored := *upload
+	stored.Parts = append([]int64(nil), upload.Parts...)
+	return &stored
+}
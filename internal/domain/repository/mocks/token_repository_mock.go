@@ -0,0 +1,117 @@
+package mocks
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/RodrigoGonzalez78/internal/domain/entity"
+)
+
+// MockRefreshTokenRepository es un mock del repositorio de refresh tokens para testing
+type MockRefreshTokenRepository struct {
+	mu                 sync.Mutex
+	Tokens             map[string]*entity.RefreshToken
+	NextID             int64
+	CreateError        error
+	RevokeFamilyCalled bool
+}
+
+// NewMockRefreshTokenRepository crea un nuevo mock de RefreshTokenRepository
+func NewMockRefreshTokenRepository() *MockRefreshTokenRepository {
+	return &MockRefreshTokenRepository{
+		Tokens: make(map[string]*entity.RefreshToken),
+		NextID: 1,
+	}
+}
+
+// Create simula guardar un refresh token
+func (m *MockRefreshTokenRepository) Create(token *entity.RefreshToken) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.CreateError != nil {
+		return m.CreateError
+	}
+	token.ID = m.NextID
+	m.NextID++
+	m.Tokens[token.TokenHash] = token
+	return nil
+}
+
+// FindByHash simula buscar un refresh token por su hash
+func (m *MockRefreshTokenRepository) FindByHash(tokenHash string) (*entity.RefreshToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	token, exists := m.Tokens[tokenHash]
+	if !exists {
+		return nil, errors.New("refresh token no encontrado")
+	}
+	copied := *token
+	return &copied, nil
+}
+
+// MarkUsed simula marcar un refresh token como rotado
+func (m *MockRefreshTokenRepository) MarkUsed(id int64) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, token := range m.Tokens {
+		if token.ID == id {
+			if token.UsedAt != nil {
+				return false, nil
+			}
+			now := time.Now()
+			token.UsedAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// RevokeFamily simula revocar todos los tokens de una familia
+func (m *MockRefreshTokenRepository) RevokeFamily(familyID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.RevokeFamilyCalled = true
+	now := time.Now()
+	for _, token := range m.Tokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+// MockRevokedTokenRepository es un mock de la lista de revocación para testing
+type MockRevokedTokenRepository struct {
+	mu      sync.Mutex
+	Revoked map[string]time.Time
+}
+
+// NewMockRevokedTokenRepository crea un nuevo mock de RevokedTokenRepository
+func NewMockRevokedTokenRepository() *MockRevokedTokenRepository {
+	return &MockRevokedTokenRepository{
+		Revoked: make(map[string]time.Time),
+	}
+}
+
+// Revoke simula agregar un jti a la lista de revocación
+func (m *MockRevokedTokenRepository) Revoke(jti string, expiresAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.Revoked[jti] = expiresAt
+	return nil
+}
+
+// IsRevoked simula consultar la lista de revocación
+func (m *MockRevokedTokenRepository) IsRevoked(jti string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, exists := m.Revoked[jti]
+	return exists, nil
+}
@@ -0,0 +1,180 @@
+package mocks
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/RodrigoGonzalez78/internal/domain/entity"
+)
+
+// MockTransformJobRepository es un mock del repositorio de trabajos para testing
+type MockTransformJobRepository struct {
+	mu          sync.Mutex
+	Jobs        map[int64]*entity.TransformJob
+	NextID      int64
+	CreateError error
+	DeleteError error
+}
+
+// NewMockTransformJobRepository crea un nuevo mock de TransformJobRepository
+func NewMockTransformJobRepository() *MockTransformJobRepository {
+	return &MockTransformJobRepository{
+		Jobs:   make(map[int64]*entity.TransformJob),
+		NextID: 1,
+	}
+}
+
+// Create simula guardar un trabajo
+func (m *MockTransformJobRepository) Create(job *entity.TransformJob) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.CreateError != nil {
+		return m.CreateError
+	}
+	job.ID = m.NextID
+	m.NextID++
+	copied := *job
+	m.Jobs[job.ID] = &copied
+	return nil
+}
+
+// FindByID simula buscar un trabajo por ID
+func (m *MockTransformJobRepository) FindByID(id int64) (*entity.TransformJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, exists := m.Jobs[id]
+	if !exists {
+		return nil, errors.New("trabajo no encontrado")
+	}
+	copied := *job
+	return &copied, nil
+}
+
+// FindQueued simula buscar los trabajos en cola por orden de creación
+func (m *MockTransformJobRepository) FindQueued(limit int) ([]entity.TransformJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var result []entity.TransformJob
+	for _, job := range m.Jobs {
+		if job.Status == entity.JobQueued {
+			result = append(result, *job)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// MarkRunning simula el paso de queued a running
+func (m *MockTransformJobRepository) MarkRunning(id int64) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, exists := m.Jobs[id]
+	if !exists || job.Status != entity.JobQueued {
+		return false, nil
+	}
+	now := time.Now()
+	job.Status = entity.JobRunning
+	job.StartedAt = &now
+	return true, nil
+}
+
+// Finish simula guardar el resultado de un trabajo en running
+func (m *MockTransformJobRepository) Finish(result *entity.TransformJob) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, exists := m.Jobs[result.ID]
+	if !exists || job.Status != entity.JobRunning {
+		return false, nil
+	}
+	job.Status = result.Status
+	job.ResultPath = result.ResultPath
+	job.Error = result.Error
+	job.FinishedAt = result.FinishedAt
+	return true, nil
+}
+
+// Cancel simula cancelar un trabajo no finalizado
+func (m *MockTransformJobRepository) Cancel(id int64) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, exists := m.Jobs[id]
+	if !exists || job.Status.Finished() {
+		return false, nil
+	}
+	now := time.Now()
+	job.Status = entity.JobCanceled
+	job.FinishedAt = &now
+	return true, nil
+}
+
+// RequeueRunning simula volver a encolar los trabajos interrumpidos
+func (m *MockTransformJobRepository) RequeueRunning() (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var count int64
+	for _, job := range m.Jobs {
+		if job.Status == entity.JobRunning {
+			job.Status = entity.JobQueued
+			job.StartedAt = nil
+			count++
+		}
+	}
+	return count, nil
+}
+
+// FindByImage simula buscar los trabajos de una imagen
+func (m *MockTransformJobRepository) FindByImage(imageID int64) ([]entity.TransformJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var result []entity.TransformJob
+	for _, job := range m.Jobs {
+		if job.ImageID == imageID {
+			result = append(result, *job)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+// FindFinishedBefore simula buscar los trabajos terminados antes de before
+func (m *MockTransformJobRepository) FindFinishedBefore(before time.Time, limit int) ([]entity.TransformJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var result []entity.TransformJob
+	for _, job := range m.Jobs {
+		if job.FinishedAt != nil && job.FinishedAt.Before(before) {
+			result = append(result, *job)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].FinishedAt.Before(*result[j].FinishedAt) })
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// Delete simula borrar un trabajo
+func (m *MockTransformJobRepository) Delete(id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.DeleteError != nil {
+		return m.DeleteError
+	}
+	delete(m.Jobs, id)
+	return nil
+}
@@ -15,6 +15,7 @@ type MockUserRepository struct {
 	CreateCalled bool
 	FindCalled   bool
 	ExistsCalled bool
+	UpdateError  error
 }
 
 // NewMockUserRepository crea un nuevo mock de UserRepository
@@ -56,3 +57,29 @@ func (m *MockUserRepository) ExistsByUserName(userName string) (bool, error) {
 	_, exists := m.Users[userName]
 	return exists, nil
 }
+
+// UpdateDefaultWatermark simula guardar la marca de agua por defecto
+func (m *MockUserRepository) UpdateDefaultWatermark(userName, watermark string) error {
+	if m.UpdateError != nil {
+		return m.UpdateError
+	}
+	user, exists := m.Users[userName]
+	if !exists {
+		return errors.New("usuario no encontrado")
+	}
+	user.DefaultWatermark = watermark
+	return nil
+}
+
+// UpdateMetadataPolicy simula guardar la política de metadatos
+func (m *MockUserRepository) UpdateMetadataPolicy(userName, policy string) error {
+	if m.UpdateError != nil {
+		return m.UpdateError
+	}
+	user, exists := m.Users[userName]
+	if !exists {
+		return errors.New("usuario no encontrado")
+	}
+	user.MetadataPolicy = policy
+	return nil
+}
@@ -0,0 +1,19 @@
+package repository
+
+import "github.com/RodrigoGonzalez78/internal/domain/entity"
+
+// PresetRepository guarda los presets de transformación. El nombre es único
+// por usuario.
+type PresetRepository interface {
+	Create(preset *entity.Preset) error
+
+	FindByID(id int64) (*entity.Preset, error)
+
+	FindByName(userName, name string) (*entity.Preset, error)
+
+	FindByUser(userName string) ([]entity.Preset, error)
+
+	Update(preset *entity.Preset) error
+
+	Delete(id int64) error
+}
@@ -0,0 +1,14 @@
+package repository
+
+import "github.com/RodrigoGonzalez78/internal/domain/entity"
+
+// RenditionRepository registra las versiones redimensionadas de cada imagen
+type RenditionRepository interface {
+	// ReplaceForImage reemplaza todas las versiones de la imagen en una sola
+	// transacción. Devuelve false sin guardar nada si la imagen ya no existe
+	// o está pendiente de eliminación.
+	ReplaceForImage(imageID int64, renditions []entity.Rendition) (bool, error)
+
+	// FindByImageID devuelve las versiones ordenadas por ancho y formato
+	FindByImageID(imageID int64) ([]entity.Rendition, error)
+}
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/RodrigoGonzalez78/internal/domain/entity"
+)
+
+// ResumableUploadRepository persiste el avance de las subidas reanudables.
+// El avance es condicional para que dos peticiones sobre la misma subida no
+// se pisen.
+type ResumableUploadRepository interface {
+	Create(upload *entity.ResumableUpload) error
+
+	FindByID(id string) (*entity.ResumableUpload, error)
+
+	// Advance guarda Offset, Parts y ExpiresAt solo si el offset guardado
+	// sigue siendo from. Devuelve false si otra petición lo cambió.
+	Advance(upload *entity.ResumableUpload, from int64) (bool, error)
+
+	// Complete registra la imagen creada con el contenido de la subida
+	Complete(id string, imageID int64) error
+
+	Delete(id string) error
+
+	// FindExpired devuelve subidas cuyo vencimiento es anterior a before
+	FindExpired(before time.Time, limit int) ([]entity.ResumableUpload, error)
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/RodrigoGonzalez78/internal/domain/entity"
+)
+
+type RefreshTokenRepository interface {
+	Create(token *entity.RefreshToken) error
+
+	FindByHash(tokenHash string) (*entity.RefreshToken, error)
+
+	// MarkUsed marca el token como rotado. Devuelve false si ya estaba usado,
+	// lo que indica un intento de reutilización.
+	MarkUsed(id int64) (bool, error)
+
+	RevokeFamily(familyID string) error
+}
+
+type RevokedTokenRepository interface {
+	Revoke(jti string, expiresAt time.Time) error
+
+	IsRevoked(jti string) (bool, error)
+}
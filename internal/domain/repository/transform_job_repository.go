@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/RodrigoGonzalez78/internal/domain/entity"
+)
+
+// TransformJobRepository persiste los trabajos de transformación. Los cambios
+// de estado son condicionales para que un worker y una cancelación
+// concurrentes no se pisen.
+type TransformJobRepository interface {
+	Create(job *entity.TransformJob) error
+
+	FindByID(id int64) (*entity.TransformJob, error)
+
+	FindQueued(limit int) ([]entity.TransformJob, error)
+
+	// MarkRunning pasa el trabajo de queued a running. Devuelve false si ya
+	// no estaba en cola (otro worker lo tomó o fue cancelado).
+	MarkRunning(id int64) (bool, error)
+
+	// Finish guarda el resultado de un trabajo en running. Devuelve false si
+	// el trabajo fue cancelado mientras se ejecutaba.
+	Finish(job *entity.TransformJob) (bool, error)
+
+	// Cancel cancela un trabajo que todavía no terminó
+	Cancel(id int64) (bool, error)
+
+	// RequeueRunning vuelve a poner en cola los trabajos que quedaron en
+	// running, por ejemplo tras un reinicio
+	RequeueRunning() (int64, error)
+
+	// FindByImage devuelve todos los trabajos de una imagen
+	FindByImage(imageID int64) ([]entity.TransformJob, error)
+
+	// FindFinishedBefore devuelve trabajos que terminaron antes de before
+	FindFinishedBefore(before time.Time, limit int) ([]entity.TransformJob, error)
+
+	Delete(id int64) error
+}
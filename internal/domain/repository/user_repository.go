@@ -8,4 +8,8 @@ type UserRepository interface {
 	FindByUserName(userName string) (*entity.User, error)
 
 	ExistsByUserName(userName string) (bool, error)
+
+	UpdateDefaultWatermark(userName, watermark string) error
+
+	UpdateMetadataPolicy(userName, policy string) error
 }
@@ -2,6 +2,7 @@ package service_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/RodrigoGonzalez78/internal/domain/service"
 )
@@ -170,3 +171,104 @@ func TestTokenService_ValidateInvalidToken(t *testing.T) {
 		})
 	}
 }
+
+func TestTokenService_UniqueID(t *testing.T) {
+	tokenService := service.NewTokenService("mi-secreto-super-seguro", 15*time.Minute)
+
+	first, _ := tokenService.Generate("testuser")
+	second, _ := tokenService.Generate("testuser")
+
+	firstClaims, err := tokenService.Validate("Bearer " + first)
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	secondClaims, err := tokenService.Validate("Bearer " + second)
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	if firstClaims.ID == "" {
+		t.Error("el token debe incluir un jti")
+	}
+	if firstClaims.ID == secondClaims.ID {
+		t.Error("cada token debe tener un jti distinto")
+	}
+}
+
+func TestTokenService_GenerateRefreshToken(t *testing.T) {
+	tokenService := service.NewTokenService("mi-secreto-super-seguro", 15*time.Minute)
+
+	token, hash, err := tokenService.GenerateRefreshToken()
+	if err != nil {
+		t.Fatalf("GenerateRefreshToken() error = %v", err)
+	}
+	if token == hash {
+		t.Error("el hash no debe ser igual al token")
+	}
+	if tokenService.HashRefreshToken(token) != hash {
+		t.Error("HashRefreshToken() no coincide con el hash generado")
+	}
+
+	other, _, _ := tokenService.GenerateRefreshToken()
+	if other == token {
+		t.Error("cada refresh token debe ser distinto")
+	}
+}
+
+func TestURLSigner_SignAndVerify(t *testing.T) {
+	signer := service.NewURLSigner("secreto-de-urls")
+	path := "/rs:800:600/gs/testuser/imagen.jpg"
+	signature := signer.Sign(path)
+
+	tests := []struct {
+		name      string
+		signer    *service.URLSigner
+		signature string
+		path      string
+		want      bool
+	}{
+		{
+			name:      "firma válida",
+			signer:    signer,
+			signature: signature,
+			path:      path,
+			want:      true,
+		},
+		{
+			name:      "ruta modificada",
+			signer:    signer,
+			signature: signature,
+			path:      "/rs:1600:1200/gs/testuser/imagen.jpg",
+			want:      false,
+		},
+		{
+			name:      "otro secreto",
+			signer:    service.NewURLSigner("otro-secreto"),
+			signature: signature,
+			path:      path,
+			want:      false,
+		},
+		{
+			name:      "firma mal codificada",
+			signer:    signer,
+			signature: "%%%",
+			path:      path,
+			want:      false,
+		},
+		{
+			name:      "sin secreto configurado",
+			signer:    service.NewURLSigner(""),
+			signature: service.NewURLSigner("").Sign(path),
+			path:      path,
+			want:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.signer.Verify(tt.signature, tt.path); got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
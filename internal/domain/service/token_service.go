@@ -1,6 +1,10 @@
 package service
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"strings"
 	"time"
@@ -25,10 +29,22 @@ func NewTokenService(secret string, expiry time.Duration) *TokenService {
 	}
 }
 
+// Expiry devuelve la duración de los access tokens
+func (s *TokenService) Expiry() time.Duration {
+	return s.expiry
+}
+
+// Generate emite un access token con un jti único, que permite revocarlo
 func (s *TokenService) Generate(userName string) (string, error) {
+	jti, err := NewRandomID()
+	if err != nil {
+		return "", err
+	}
+
 	claims := TokenClaims{
 		UserName: userName,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.expiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
@@ -48,7 +64,7 @@ func (s *TokenService) Validate(tokenStr string) (*TokenClaims, error) {
 	var claims TokenClaims
 	token, err := jwt.ParseWithClaims(tokenStr, &claims, func(t *jwt.Token) (interface{}, error) {
 		return s.secretKey, nil
-	})
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 
 	if err != nil {
 		return nil, err
@@ -60,3 +76,30 @@ func (s *TokenService) Validate(tokenStr string) (*TokenClaims, error) {
 
 	return &claims, nil
 }
+
+// GenerateRefreshToken devuelve un refresh token opaco y su hash. Solo el hash
+// se guarda en la base de datos.
+func (s *TokenService) GenerateRefreshToken() (token string, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	return token, s.HashRefreshToken(token), nil
+}
+
+// HashRefreshToken calcula el hash con el que se guarda un refresh token
+func (s *TokenService) HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewRandomID genera un identificador aleatorio de 128 bits en hexadecimal
+func NewRandomID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
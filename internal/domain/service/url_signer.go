@@ -0,0 +1,44 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// URLSigner firma y verifica rutas de transformación con HMAC-SHA256
+type URLSigner struct {
+	secretKey []byte
+}
+
+func NewURLSigner(secret string) *URLSigner {
+	return &URLSigner{secretKey: []byte(secret)}
+}
+
+// Enabled indica si hay un secreto configurado
+func (s *URLSigner) Enabled() bool {
+	return len(s.secretKey) > 0
+}
+
+// Sign devuelve la firma en base64 URL-safe (sin padding) de la ruta
+func (s *URLSigner) Sign(path string) string {
+	mac := hmac.New(sha256.New, s.secretKey)
+	mac.Write([]byte(path))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Verify comprueba en tiempo constante que la firma corresponde a la ruta
+func (s *URLSigner) Verify(signature, path string) bool {
+	if !s.Enabled() {
+		return false
+	}
+
+	expected, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, s.secretKey)
+	mac.Write([]byte(path))
+	return hmac.Equal(expected, mac.Sum(nil))
+}
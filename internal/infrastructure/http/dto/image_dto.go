@@ -1,30 +1,119 @@
 package dto
 
+import "time"
+
 type ErrorResponse struct {
 	Message string `json:"message" example:"Descripción del error"`
 }
 
+// UploadErrorResponse describe por qué se rechazó un archivo (413 o 415)
+type UploadErrorResponse struct {
+	Error   string `json:"error" example:"image_too_large" enums:"payload_too_large,image_too_large,unsupported_media_type"`
+	Message string `json:"message" example:"la imagen supera el límite de pixels: 100000000 (máximo 50000000)"`
+	Limit   string `json:"limit,omitempty" example:"pixels" enums:"bytes,width,height,pixels"`
+	Actual  int64  `json:"actual,omitempty" example:"100000000"`
+	Max     int64  `json:"max,omitempty" example:"50000000"`
+}
+
 type UploadedImageDetail struct {
-	URL    string `json:"url" example:"http://localhost:8080/images/username/imagen123.jpg"`
-	Name   string `json:"name" example:"imagen123.jpg"`
-	Size   int64  `json:"size" example:"204800"`
-	Format string `json:"format" example:"jpeg"`
-	Width  int    `json:"width" example:"1920"`
-	Height int    `json:"height" example:"1080"`
+	ID       int64  `json:"id" example:"12"`
+	ParentID *int64 `json:"parent_id,omitempty" example:"7"`
+	URL      string `json:"url" example:"http://localhost:8080/images/username/imagen123.jpg"`
+	Name     string `json:"name" example:"imagen123.jpg"`
+	Size     int64  `json:"size" example:"204800"`
+	Format   string `json:"format" example:"jpeg"`
+	Width    int    `json:"width" example:"1920"`
+	Height   int    `json:"height" example:"1080"`
 }
 
 type UploadResponse struct {
 	Message string              `json:"message" example:"Imagen subida exitosamente"`
 	Image   UploadedImageDetail `json:"image"`
+	// Duplicate indica que no se guardó nada: image es la imagen que ya existía
+	Duplicate bool `json:"duplicate,omitempty"`
+}
+
+// DuplicateResponse se devuelve con 409 cuando la subida pide rechazar
+// duplicados y el usuario ya tiene una imagen igual o casi igual
+type DuplicateResponse struct {
+	Error    string              `json:"error" example:"duplicate"`
+	Message  string              `json:"message" example:"la imagen ya existe: imagen123.jpg (distancia 2)"`
+	Distance int                 `json:"distance" example:"2"`
+	Image    UploadedImageDetail `json:"image"`
+}
+
+// BatchUploadResponse es el resultado de una subida por lotes. La petición
+// responde 200 aunque fallen archivos: el estado de cada uno va en results.
+type BatchUploadResponse struct {
+	Succeeded int                 `json:"succeeded" example:"2"`
+	Failed    int                 `json:"failed" example:"1"`
+	Results   []BatchUploadResult `json:"results"`
+}
+
+// BatchUploadResult es el resultado de un archivo del lote, en el orden en
+// que se enviaron
+type BatchUploadResult struct {
+	Index    int    `json:"index" example:"0"`
+	FileName string `json:"file_name" example:"vacaciones.jpg"`
+	// Status es el estado que habría tenido el archivo en POST /upload
+	Status int `json:"status" example:"201"`
+	// Image es la imagen subida o, si era un duplicado, la que ya existía
+	Image     *UploadedImageDetail `json:"image,omitempty"`
+	Duplicate bool                 `json:"duplicate,omitempty"`
+	Error     *BatchUploadError    `json:"error,omitempty"`
+}
+
+// BatchUploadError explica por qué se rechazó un archivo del lote
+type BatchUploadError struct {
+	Error   string `json:"error" example:"image_too_large" enums:"payload_too_large,image_too_large,unsupported_media_type,invalid_image,unreadable_metadata,duplicate,upload_failed"`
+	Message string `json:"message" example:"la imagen supera el límite de pixels: 100000000 (máximo 50000000)"`
+	Limit   string `json:"limit,omitempty" example:"pixels" enums:"bytes,width,height,pixels"`
+	Actual  int64  `json:"actual,omitempty" example:"100000000"`
+	Max     int64  `json:"max,omitempty" example:"50000000"`
+	// Distance es la distancia al duplicado; solo con error "duplicate"
+	Distance *int `json:"distance,omitempty" example:"2"`
 }
 
 type ImageDetailResponse struct {
-	URL    string `json:"url" example:"http://localhost:8080/images/rodrick/imagen123.jpg"`
-	Name   string `json:"name" example:"imagen123.jpg"`
-	Size   int64  `json:"size" example:"204800"`
-	Format string `json:"format" example:"jpeg"`
-	Width  int    `json:"width" example:"1920"`
-	Height int    `json:"height" example:"1080"`
+	URL        string              `json:"url" example:"http://localhost:8080/images/rodrick/imagen123.jpg"`
+	Name       string              `json:"name" example:"imagen123.jpg"`
+	Size       int64               `json:"size" example:"204800"`
+	Format     string              `json:"format" example:"jpeg"`
+	Width      int                 `json:"width" example:"1920"`
+	Height     int                 `json:"height" example:"1080"`
+	ParentID   *int64              `json:"parent_id,omitempty" example:"7"`
+	Renditions []RenditionResponse `json:"renditions"`
+}
+
+// RenditionResponse es una versión redimensionada para armar un srcset
+type RenditionResponse struct {
+	Width  int    `json:"width" example:"640"`
+	Height int    `json:"height" example:"360"`
+	Format string `json:"format" example:"webp"`
+	URL    string `json:"url" example:"http://localhost:8080/images/rodrick/renditions/12/9b2e4c1a-7d3f-4e8b-a6c5-2f1d0e9c8b7a/640.webp"`
+	Size   int64  `json:"size" example:"28410"`
+}
+
+type ImageMetadataGPS struct {
+	Latitude  float64  `json:"latitude" example:"-34.6037"`
+	Longitude float64  `json:"longitude" example:"-58.3816"`
+	Altitude  *float64 `json:"altitude,omitempty" example:"25"`
+}
+
+type ImageMetadataResponse struct {
+	ImageID      int64             `json:"image_id" example:"7"`
+	Orientation  int               `json:"orientation" example:"6"`
+	CameraMake   string            `json:"camera_make,omitempty" example:"Apple"`
+	CameraModel  string            `json:"camera_model,omitempty" example:"iPhone 13"`
+	LensMake     string            `json:"lens_make,omitempty" example:"Apple"`
+	LensModel    string            `json:"lens_model,omitempty" example:"iPhone 13 back dual wide camera"`
+	Software     string            `json:"software,omitempty" example:"17.2"`
+	CapturedAt   *time.Time        `json:"captured_at,omitempty"`
+	GPS          *ImageMetadataGPS `json:"gps,omitempty"`
+	ExposureTime string            `json:"exposure_time,omitempty" example:"1/125"`
+	FNumber      float64           `json:"f_number,omitempty" example:"1.6"`
+	ISO          int               `json:"iso,omitempty" example:"50"`
+	FocalLength  float64           `json:"focal_length,omitempty" example:"5.1"`
 }
 
 type ImageItem struct {
@@ -37,6 +126,17 @@ type ImageItem struct {
 	Height int    `json:"height"`
 }
 
+type SimilarImageItem struct {
+	ImageItem
+	Distance int `json:"distance" example:"3"` // bits distintos entre los hashes, de 0 a 64
+}
+
+type SimilarImagesResponse struct {
+	ImageID   int64              `json:"image_id" example:"12"`
+	Threshold int                `json:"threshold" example:"10"`
+	Images    []SimilarImageItem `json:"images"`
+}
+
 type PaginatedImagesResponse struct {
 	Page   int         `json:"page"`
 	Limit  int         `json:"limit"`
@@ -44,11 +144,29 @@ type PaginatedImagesResponse struct {
 	Images []ImageItem `json:"images"`
 }
 
+type TransformationOperation struct {
+	Type   string                 `json:"type" example:"resize"`
+	Params map[string]interface{} `json:"params"`
+}
+
+// TransformationRequest acepta un pipeline ordenado en "operations". El
+// objeto "transformations" se mantiene como formato anterior y solo se usa
+// cuando "operations" está vacío.
 type TransformationRequest struct {
+	Operations      []TransformationOperation `json:"operations"`
+	Format          string                    `json:"format" example:"png" enums:"png,jpeg,gif,tiff,bmp,webp"`
+	Quality         int                       `json:"quality,omitempty" example:"85"`                       // solo jpeg, 1 a 100
+	Compression     string                    `json:"compression,omitempty" enums:"default,none,fast,best"` // solo png
+	PaletteSize     int                       `json:"palette_size,omitempty" example:"64"`                  // solo gif, 1 a 256
 	Transformations struct {
 		Resize struct {
-			Width  int `json:"width"`
-			Height int `json:"height"`
+			Width      int    `json:"width"`
+			Height     int    `json:"height"`
+			Mode       string `json:"mode,omitempty" enums:"exact,fit,fill,cover,contain,pad"`
+			Gravity    string `json:"gravity,omitempty" example:"center"`
+			Background string `json:"background,omitempty" example:"#ffffff"`
+			Filter     string `json:"filter,omitempty" enums:"lanczos,catmullrom,box,nearest"`
+			NoUpscale  bool   `json:"no_upscale,omitempty"`
 		} `json:"resize"`
 		Crop struct {
 			Width  int `json:"width"`
@@ -64,3 +182,152 @@ type TransformationRequest struct {
 		} `json:"filters"`
 	} `json:"transformations"`
 }
+
+type SignedURLRequest struct {
+	Operations string `json:"operations" example:"rs:800:600/gs"`
+	Format     string `json:"format" example:"jpeg"`
+}
+
+type SignedURLResponse struct {
+	URL string `json:"url" example:"http://localhost:8080/t/Xk3.../rs:800:600/gs/f:jpeg/rodrick/imagen123.jpg"`
+}
+
+type JobResponse struct {
+	ID         int64      `json:"id" example:"42"`
+	ImageID    int64      `json:"image_id" example:"7"`
+	Status     string     `json:"status" example:"queued" enums:"queued,running,succeeded,failed,canceled"`
+	ResultURL  string     `json:"result_url,omitempty" example:"http://localhost:8080/images/rodrick/jobs/5f0e7c2b-3a91-4d6e-8b24-c1d9a7e6f350.png"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// WatermarkRequest configura la marca de agua por defecto de la cuenta. Los
+// campos omitidos toman su valor por defecto.
+type WatermarkRequest struct {
+	ImageID int64   `json:"image_id" example:"12"`
+	Anchor  string  `json:"anchor,omitempty" example:"bottom-right" enums:"top-left,top-right,bottom-left,bottom-right,center,tile"`
+	Opacity float64 `json:"opacity,omitempty" example:"0.5"`
+	Scale   float64 `json:"scale,omitempty" example:"0.25"`
+	Margin  *int    `json:"margin,omitempty" example:"16"`
+}
+
+// WatermarkResponse marca de agua por defecto vigente
+type WatermarkResponse struct {
+	ImageID int64   `json:"image_id" example:"12"`
+	Anchor  string  `json:"anchor" example:"bottom-right"`
+	Opacity float64 `json:"opacity" example:"0.5"`
+	Scale   float64 `json:"scale" example:"0.25"`
+	Margin  int     `json:"margin" example:"16"`
+}
+
+// PresetRequest pipeline con nombre que se aplica con
+// /images/{id}/transform?preset={name}. Format y las opciones de codificación
+// son opcionales.
+type PresetRequest struct {
+	Name        string                    `json:"name" example:"thumb"`
+	Operations  []TransformationOperation `json:"operations"`
+	Format      string                    `json:"format,omitempty" example:"webp" enums:"png,jpeg,gif,tiff,bmp,webp"`
+	Quality     int                       `json:"quality,omitempty" example:"80"`
+	Compression string                    `json:"compression,omitempty" enums:"default,none,fast,best"`
+	PaletteSize int                       `json:"palette_size,omitempty" example:"64"`
+}
+
+// PresetResponse preset guardado
+type PresetResponse struct {
+	ID          int64                     `json:"id" example:"3"`
+	Name        string                    `json:"name" example:"thumb"`
+	Operations  []TransformationOperation `json:"operations"`
+	Format      string                    `json:"format,omitempty" example:"webp"`
+	Quality     int                       `json:"quality,omitempty" example:"80"`
+	Compression string                    `json:"compression,omitempty"`
+	PaletteSize int                       `json:"palette_size,omitempty"`
+	CreatedAt   time.Time                 `json:"created_at"`
+	UpdatedAt   time.Time                 `json:"updated_at"`
+}
+
+// MetadataPolicyRequest define qué metadatos se quitan de las imágenes que
+// sube el usuario
+type MetadataPolicyRequest struct {
+	Policy string `json:"policy" example:"strip_gps" enums:"keep,strip_gps,strip_all"`
+}
+
+// MetadataPolicyResponse política de metadatos vigente
+type MetadataPolicyResponse struct {
+	Policy string `json:"policy" example:"strip_gps"`
+}
+
+// StripMetadataRequest política a aplicar sobre una imagen ya guardada; si se
+// omite se usa strip_all
+type StripMetadataRequest struct {
+	Policy string `json:"policy,omitempty" example:"strip_all" enums:"strip_gps,strip_all"`
+}
+
+// StripMetadataResponse resultado de reescribir una imagen guardada
+type StripMetadataResponse struct {
+	ImageID int64  `json:"image_id" example:"7"`
+	Policy  string `json:"policy" example:"strip_all"`
+	Changed bool   `json:"changed" example:"true"`
+	OldSize int64  `json:"old_size" example:"204800"`
+	NewSize int64  `json:"new_size" example:"198400"`
+}
+
+// GPSAuditItem imagen que todavía contiene su posición de captura. Las
+// coordenadas se omiten cuando solo aparecen en un paquete XMP.
+type GPSAuditItem struct {
+	ID        int64    `json:"id" example:"7"`
+	URL       string   `json:"url" example:"http://localhost:8080/images/rodrick/imagen123.jpg"`
+	Name      string   `json:"name" example:"imagen123.jpg"`
+	Latitude  *float64 `json:"latitude,omitempty" example:"-34.6037"`
+	Longitude *float64 `json:"longitude,omitempty" example:"-58.3816"`
+}
+
+// GPSAuditResponse resultado de revisar las imágenes del usuario
+type GPSAuditResponse struct {
+	Scanned int            `json:"scanned" example:"42"`
+	Images  []GPSAuditItem `json:"images"`
+}
+
+// FontResponse fuente subida por el usuario; se usa en la operación text con
+// "font_id"
+type FontResponse struct {
+	ID        int64     `json:"id" example:"3"`
+	Name      string    `json:"name" example:"Anton"`
+	Family    string    `json:"family" example:"Anton"`
+	Size      int64     `json:"size" example:"136000"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// FontListResponse fuentes disponibles para la operación text: las incluidas
+// en el servidor (por nombre, con "font") y las subidas por el usuario
+type FontListResponse struct {
+	Embedded []string       `json:"embedded" example:"goregular,gobold,gomono"`
+	Fonts    []FontResponse `json:"fonts"`
+}
+
+// ImageNodeResponse es una imagen dentro del árbol de ediciones
+type ImageNodeResponse struct {
+	ID         int64                     `json:"id" example:"12"`
+	ParentID   *int64                    `json:"parent_id,omitempty" example:"7"`
+	URL        string                    `json:"url" example:"http://localhost:8080/images/rodrick/imagen123.png"`
+	Name       string                    `json:"name" example:"imagen123.png"`
+	Size       int64                     `json:"size" example:"204800"`
+	Format     string                    `json:"format" example:"png"`
+	Width      int                       `json:"width" example:"800"`
+	Height     int                       `json:"height" example:"600"`
+	Operations []TransformationOperation `json:"operations"` // pipeline aplicado al padre; vacío en las imágenes subidas
+	CreatedAt  time.Time                 `json:"created_at" example:"2024-03-15T10:30:00Z"`
+}
+
+type DerivativeListResponse struct {
+	ImageID     int64               `json:"image_id" example:"7"`
+	Derivatives []ImageNodeResponse `json:"derivatives"`
+}
+
+// LineageResponse lista las ediciones desde la imagen subida hasta la pedida
+type LineageResponse struct {
+	ImageID  int64               `json:"image_id" example:"12"`
+	Complete bool                `json:"complete" example:"true"` // false si se eliminó algún antecesor
+	Lineage  []ImageNodeResponse `json:"lineage"`
+}
@@ -2,23 +2,35 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 
 	authUC "github.com/RodrigoGonzalez78/internal/application/usecase/auth"
 	"github.com/RodrigoGonzalez78/internal/infrastructure/http/dto"
+	"github.com/RodrigoGonzalez78/internal/infrastructure/http/middleware"
 )
 
 // AuthHandler maneja las peticiones de autenticación
 type AuthHandler struct {
 	registerUC *authUC.RegisterUseCase
 	loginUC    *authUC.LoginUseCase
+	refreshUC  *authUC.RefreshUseCase
+	logoutUC   *authUC.LogoutUseCase
 }
 
 // NewAuthHandler crea una nueva instancia de AuthHandler
-func NewAuthHandler(registerUC *authUC.RegisterUseCase, loginUC *authUC.LoginUseCase) *AuthHandler {
+func NewAuthHandler(
+	registerUC *authUC.RegisterUseCase,
+	loginUC *authUC.LoginUseCase,
+	refreshUC *authUC.RefreshUseCase,
+	logoutUC *authUC.LogoutUseCase,
+) *AuthHandler {
 	return &AuthHandler{
 		registerUC: registerUC,
 		loginUC:    loginUC,
+		refreshUC:  refreshUC,
+		logoutUC:   logoutUC,
 	}
 }
 
@@ -54,7 +66,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 
 // Login godoc
 // @Summary      Inicia sesión
-// @Description  Autentica al usuario y devuelve un token JWT si las credenciales son correctas.
+// @Description  Autentica al usuario y devuelve un access token JWT de vida corta junto con un refresh token.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
@@ -80,9 +92,85 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp := dto.LoginResponse{Token: output.Token}
-
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(resp)
+	json.NewEncoder(w).Encode(toLoginResponse(output))
+}
+
+// Refresh godoc
+// @Summary      Renueva el access token
+// @Description  Canjea un refresh token por un nuevo access token y un nuevo refresh token. El refresh token recibido queda invalidado; si se vuelve a usar se revoca toda la sesión.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        token body dto.RefreshRequest true "Refresh token"
+// @Success      200 {object} dto.LoginResponse
+// @Failure      400 {object} dto.ErrorResponse
+// @Failure      401 {object} dto.ErrorResponse
+// @Router       /token/refresh [post]
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req dto.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error en los datos recibidos: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	output, err := h.refreshUC.Execute(authUC.RefreshInput{RefreshToken: req.RefreshToken})
+	if err != nil {
+		if errors.Is(err, authUC.ErrInvalidRefreshToken) || errors.Is(err, authUC.ErrRefreshTokenReused) {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		http.Error(w, "Error al renovar el token: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toLoginResponse(output))
+}
+
+// Logout godoc
+// @Summary      Cierra la sesión
+// @Description  Revoca el access token usado en la petición y, si se envía, toda la familia del refresh token.
+// @Tags         auth
+// @Accept       json
+// @Security     BearerAuth
+// @Param        token body dto.LogoutRequest false "Refresh token a revocar"
+// @Success      204
+// @Failure      400 {object} dto.ErrorResponse
+// @Failure      401 {object} dto.ErrorResponse
+// @Router       /logout [post]
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Usuario no autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	// El cuerpo es opcional: sin refresh token solo se revoca el access token
+	var req dto.LogoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Error en los datos recibidos: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	input := authUC.LogoutInput{
+		Claims:       claims,
+		RefreshToken: req.RefreshToken,
+	}
+
+	if err := h.logoutUC.Execute(input); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func toLoginResponse(output *authUC.LoginOutput) dto.LoginResponse {
+	return dto.LoginResponse{
+		Token:        output.Token,
+		RefreshToken: output.RefreshToken,
+		ExpiresIn:    int64(output.ExpiresIn.Seconds()),
+	}
 }
@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	imageUC "github.com/RodrigoGonzalez78/internal/application/usecase/image"
+	"github.com/RodrigoGonzalez78/internal/infrastructure/http/dto"
+	"github.com/RodrigoGonzalez78/internal/infrastructure/http/middleware"
+	"github.com/gorilla/mux"
+)
+
+type DerivativeHandler struct {
+	derivativeUC *imageUC.DerivativeUseCase
+	presetUC     *imageUC.PresetUseCase
+}
+
+func NewDerivativeHandler(derivativeUC *imageUC.DerivativeUseCase, presetUC *imageUC.PresetUseCase) *DerivativeHandler {
+	return &DerivativeHandler{
+		derivativeUC: derivativeUC,
+		presetUC:     presetUC,
+	}
+}
+
+// CreateDerivative godoc
+// @Summary      Guarda una transformación como imagen nueva
+// @Description  Acepta el mismo cuerpo y el mismo ?preset= que /images/{id}/transform, pero en lugar de devolver el resultado lo guarda como una imagen nueva del usuario que recuerda su imagen de origen y las operaciones aplicadas. Sin formato se conserva el de la imagen de origen.
+// @Tags         images
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "ID de la imagen de origen"
+// @Param        preset query string false "Nombre de un preset del usuario"
+// @Param        body body dto.TransformationRequest false "Parámetros de transformación"
+// @Success      201 {object} dto.UploadResponse
+// @Failure      400 {object} dto.ErrorResponse
+// @Failure      401 {object} dto.ErrorResponse
+// @Failure      404 {object} dto.ErrorResponse
+// @Failure      413 {object} dto.UploadErrorResponse
+// @Failure      500 {object} dto.ErrorResponse
+// @Router       /images/{id}/derivatives [post]
+func (h *DerivativeHandler) CreateDerivative(w http.ResponseWriter, r *http.Request) {
+	imageID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID de imagen inválido", http.StatusBadRequest)
+		return
+	}
+
+	userData, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || userData == nil {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	input, ok := decodeTransformRequest(w, r, h.presetUC, imageID, userData.UserName)
+	if !ok {
+		return
+	}
+
+	output, err := h.derivativeUC.Save(r.Context(), input)
+	if err != nil {
+		if errors.Is(err, imageUC.ErrImageNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		writeTransformError(w, err, "Error al guardar la imagen: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := dto.UploadResponse{
+		Message: "Imagen guardada exitosamente",
+		Image:   toUploadedImageDetail(output),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// ListDerivatives godoc
+// @Summary      Imágenes derivadas
+// @Description  Lista las imágenes guardadas directamente a partir de la imagen indicada, con las operaciones que se le aplicaron
+// @Tags         images
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path int true "ID de la imagen"
+// @Success      200 {object} dto.DerivativeListResponse
+// @Failure      400 {object} dto.ErrorResponse
+// @Failure      401 {object} dto.ErrorResponse
+// @Failure      404 {object} dto.ErrorResponse
+// @Router       /images/{id}/derivatives [get]
+func (h *DerivativeHandler) ListDerivatives(w http.ResponseWriter, r *http.Request) {
+	imageID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID de imagen inválido", http.StatusBadRequest)
+		return
+	}
+
+	userData, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || userData == nil {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	nodes, err := h.derivativeUC.Derivatives(imageID, userData.UserName)
+	if err != nil {
+		writeDerivativeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.DerivativeListResponse{
+		ImageID:     imageID,
+		Derivatives: toImageNodeResponses(nodes),
+	})
+}
+
+// GetLineage godoc
+// @Summary      Historial de ediciones
+// @Description  Devuelve la cadena de imágenes desde la imagen subida hasta la indicada, con las operaciones de cada paso. Si se eliminó algún antecesor la cadena empieza en el primero que existe y complete es false.
+// @Tags         images
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path int true "ID de la imagen"
+// @Success      200 {object} dto.LineageResponse
+// @Failure      400 {object} dto.ErrorResponse
+// @Failure      401 {object} dto.ErrorResponse
+// @Failure      404 {object} dto.ErrorResponse
+// @Router       /images/{id}/lineage [get]
+func (h *DerivativeHandler) GetLineage(w http.ResponseWriter, r *http.Request) {
+	imageID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID de imagen inválido", http.StatusBadRequest)
+		return
+	}
+
+	userData, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || userData == nil {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	output, err := h.derivativeUC.Lineage(imageID, userData.UserName)
+	if err != nil {
+		writeDerivativeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.LineageResponse{
+		ImageID:  output.ImageID,
+		Complete: output.Complete,
+		Lineage:  toImageNodeResponses(output.Lineage),
+	})
+}
+
+func writeDerivativeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, imageUC.ErrImageNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+func toImageNodeResponses(nodes []imageUC.ImageNode) []dto.ImageNodeResponse {
+	resp := make([]dto.ImageNodeResponse, len(nodes))
+	for i, node := range nodes {
+		resp[i] = dto.ImageNodeResponse{
+			ID:         node.ID,
+			ParentID:   node.ParentID,
+			URL:        node.URL,
+			Name:       node.Name,
+			Size:       node.Size,
+			Format:     node.Format,
+			Width:      node.Width,
+			Height:     node.Height,
+			Operations: make([]dto.TransformationOperation, len(node.Operations)),
+			CreatedAt:  node.CreatedAt,
+		}
+		for j, op := range node.Operations {
+			resp[i].Operations[j] = dto.TransformationOperation{
+				Type:   op.Type,
+				Params: op.Params,
+			}
+		}
+	}
+	return resp
+}
@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	imageUC "github.com/RodrigoGonzalez78/internal/application/usecase/image"
+	"github.com/RodrigoGonzalez78/internal/infrastructure/http/dto"
+	"github.com/RodrigoGonzalez78/internal/infrastructure/http/middleware"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type FontHandler struct {
+	fontUC *imageUC.FontUseCase
+}
+
+func NewFontHandler(fontUC *imageUC.FontUseCase) *FontHandler {
+	return &FontHandler{fontUC: fontUC}
+}
+
+// UploadFont godoc
+// @Summary      Sube una fuente
+// @Description  Sube una fuente TrueType u OpenType propia para usarla en la operación "text" con "font_id". Las fuentes no se sirven públicamente.
+// @Tags         fonts
+// @Security     BearerAuth
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        font formData file true "Fuente (.ttf u .otf)"
+// @Param        name formData string false "Nombre para identificarla (por defecto, la familia declarada en la fuente)"
+// @Success      201 {object} dto.FontResponse
+// @Failure      400 {object} dto.ErrorResponse
+// @Failure      401 {object} dto.ErrorResponse
+// @Failure      413 {object} dto.UploadErrorResponse
+// @Failure      500 {object} dto.ErrorResponse
+// @Router       /fonts [post]
+func (h *FontHandler) UploadFont(w http.ResponseWriter, r *http.Request) {
+	userData, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || userData == nil {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, imageUC.MaxFontBytes+multipartOverhead)
+
+	file, _, err := r.FormFile("font")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeUploadError(w, &imageUC.LimitError{Limit: "bytes", Max: imageUC.MaxFontBytes})
+			return
+		}
+		http.Error(w, "Error al obtener el archivo: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, imageUC.MaxFontBytes+1))
+	if err != nil {
+		http.Error(w, "Error al leer el archivo: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	font, err := h.fontUC.Upload(r.Context(), imageUC.FontUploadInput{
+		UserName: userData.UserName,
+		FileName: uuid.New().String() + ".ttf",
+		Name:     r.FormValue("name"),
+		Data:     data,
+	})
+	if err != nil {
+		var limitErr *imageUC.LimitError
+		switch {
+		case errors.As(err, &limitErr):
+			writeUploadError(w, err)
+		case errors.Is(err, imageUC.ErrInvalidFont):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, "Error al subir la fuente: "+err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(toFontResponse(font))
+}
+
+// ListFonts godoc
+// @Summary      Lista las fuentes disponibles
+// @Description  Devuelve las fuentes incluidas en el servidor (se eligen por nombre con "font") y las subidas por el usuario autenticado (se eligen con "font_id").
+// @Tags         fonts
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} dto.FontListResponse
+// @Failure      401 {object} dto.ErrorResponse
+// @Failure      500 {object} dto.ErrorResponse
+// @Router       /fonts [get]
+func (h *FontHandler) ListFonts(w http.ResponseWriter, r *http.Request) {
+	userData, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || userData == nil {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	fonts, err := h.fontUC.List(userData.UserName)
+	if err != nil {
+		http.Error(w, "Error al obtener las fuentes: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := dto.FontListResponse{
+		Embedded: imageUC.EmbeddedFonts(),
+		Fonts:    make([]dto.FontResponse, len(fonts)),
+	}
+	for i := range fonts {
+		resp.Fonts[i] = toFontResponse(&fonts[i])
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// DeleteFont godoc
+// @Summary      Elimina una fuente
+// @Description  Elimina una fuente subida por el usuario autenticado.
+// @Tags         fonts
+// @Security     BearerAuth
+// @Param        id path int true "ID de la fuente"
+// @Success      204
+// @Failure      400 {object} dto.ErrorResponse
+// @Failure      401 {object} dto.ErrorResponse
+// @Failure      404 {object} dto.ErrorResponse
+// @Router       /fonts/{id} [delete]
+func (h *FontHandler) DeleteFont(w http.ResponseWriter, r *http.Request) {
+	fontID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID de fuente inválido", http.StatusBadRequest)
+		return
+	}
+
+	userData, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || userData == nil {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.fontUC.Delete(r.Context(), userData.UserName, fontID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func toFontResponse(font *imageUC.FontOutput) dto.FontResponse {
+	return dto.FontResponse{
+		ID:        font.ID,
+		Name:      font.Name,
+		Family:    font.Family,
+		Size:      font.Size,
+		CreatedAt: font.CreatedAt,
+	}
+}
@@ -1,64 +1,111 @@
 package handler
 
 import (
-	"bytes"
+	"bufio"
 	"encoding/json"
-	"image"
+	"errors"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"path"
 	"strconv"
 	"strings"
 
-	_ "image/gif"
-	_ "image/jpeg"
-	_ "image/png"
-
 	imageUC "github.com/RodrigoGonzalez78/internal/application/usecase/image"
 	"github.com/RodrigoGonzalez78/internal/infrastructure/http/dto"
 	"github.com/RodrigoGonzalez78/internal/infrastructure/http/middleware"
-	"github.com/disintegration/imaging"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 )
 
+// multipartOverhead es el margen sobre MaxBytes que se permite en el cuerpo
+// para los encabezados y delimitadores del multipart
+const multipartOverhead = 1 << 20
+
+// uploadMemory es lo que se guarda en memoria de cada multipart; el archivo
+// que lo supera se vuelca a un temporal en disco y de ahí se sube por partes
+const uploadMemory = 256 << 10
+
 type ImageHandler struct {
 	uploadUC    *imageUC.UploadUseCase
+	batchUC     *imageUC.BatchUploadUseCase
 	getUC       *imageUC.GetUseCase
 	listUC      *imageUC.ListUseCase
 	transformUC *imageUC.TransformUseCase
+	deleteUC    *imageUC.DeleteUseCase
+	watermarkUC *imageUC.WatermarkUseCase
+	metadataUC  *imageUC.MetadataUseCase
+	privacyUC   *imageUC.PrivacyUseCase
+	presetUC    *imageUC.PresetUseCase
+	limits      imageUC.ImageLimits
 }
 
 func NewImageHandler(
 	uploadUC *imageUC.UploadUseCase,
+	batchUC *imageUC.BatchUploadUseCase,
 	getUC *imageUC.GetUseCase,
 	listUC *imageUC.ListUseCase,
 	transformUC *imageUC.TransformUseCase,
+	deleteUC *imageUC.DeleteUseCase,
+	watermarkUC *imageUC.WatermarkUseCase,
+	metadataUC *imageUC.MetadataUseCase,
+	privacyUC *imageUC.PrivacyUseCase,
+	presetUC *imageUC.PresetUseCase,
+	limits imageUC.ImageLimits,
 ) *ImageHandler {
 	return &ImageHandler{
 		uploadUC:    uploadUC,
+		batchUC:     batchUC,
 		getUC:       getUC,
 		listUC:      listUC,
 		transformUC: transformUC,
+		deleteUC:    deleteUC,
+		watermarkUC: watermarkUC,
+		metadataUC:  metadataUC,
+		privacyUC:   privacyUC,
+		presetUC:    presetUC,
+		limits:      limits,
 	}
 }
 
 // Upload godoc
 // @Summary      Sube una imagen
-// @Description  Permite a un usuario autenticado subir una imagen. Se guarda en MinIO y se almacena la metadata.
+// @Description  Permite a un usuario autenticado subir una imagen. El formato se detecta por el contenido del archivo (no por la extensión) y se rechazan archivos o dimensiones que superen los límites configurados. Antes de guardar el original se quitan los metadatos según el campo "metadata" o, si se omite, la política de la cuenta.
 // @Tags         images
 // @Security     BearerAuth
 // @Accept       multipart/form-data
 // @Produce      json
 // @Param        image formData file true "Imagen a subir (jpg, jpeg, png, gif)"
+// @Param        metadata formData string false "Metadatos a quitar: keep, strip_gps o strip_all" Enums(keep, strip_gps, strip_all)
+// @Param        on_duplicate formData string false "Qué hacer si ya existe una imagen igual o casi igual: allow (por defecto), reject (409) o return_existing (200 con la imagen existente)" Enums(allow, reject, return_existing)
+// @Param        duplicate_threshold formData int false "Distancia de Hamming máxima entre hashes perceptuales para considerar un duplicado (0 a 64, por defecto 4)"
+// @Success      200 {object} dto.UploadResponse "Duplicado con on_duplicate=return_existing: no se guardó nada"
 // @Success      201 {object} dto.UploadResponse
 // @Failure      400 {object} dto.ErrorResponse
 // @Failure      401 {object} dto.ErrorResponse
+// @Failure      409 {object} dto.DuplicateResponse
+// @Failure      413 {object} dto.UploadErrorResponse
+// @Failure      415 {object} dto.UploadErrorResponse
 // @Failure      500 {object} dto.ErrorResponse
 // @Router       /upload [post]
 func (h *ImageHandler) Upload(w http.ResponseWriter, r *http.Request) {
-	file, handler, err := r.FormFile("image")
+	if h.limits.MaxBytes > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, h.limits.MaxBytes+multipartOverhead)
+	}
+
+	// Sin esto FormFile guarda en memoria archivos de hasta 32 MB
+	err := r.ParseMultipartForm(uploadMemory)
+	var file multipart.File
+	var header *multipart.FileHeader
+	if err == nil {
+		file, header, err = r.FormFile("image")
+	}
 	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeUploadError(w, &imageUC.LimitError{Limit: "bytes", Max: h.limits.MaxBytes})
+			return
+		}
 		http.Error(w, "Error al obtener el archivo: "+err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -70,72 +117,291 @@ func (h *ImageHandler) Upload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	extParts := strings.Split(handler.Filename, ".")
-	if len(extParts) < 2 {
-		http.Error(w, "Archivo sin extensión válida", http.StatusBadRequest)
+	// Validar tamaño y formato real antes de subir nada. Las dimensiones se
+	// validan al leer la cabecera, mientras el archivo ya va al storage.
+	body := bufio.NewReader(file)
+	head, err := body.Peek(imageUC.SniffLen)
+	if err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Error al leer el archivo: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
+	info, err := h.limits.Sniff(head, header.Size)
+	if err != nil {
+		writeUploadError(w, err)
+		return
+	}
+
+	options, ok := parseUploadOptions(w, h.privacyUC, userData.UserName, r.FormValue)
+	if !ok {
+		return
+	}
+
+	randomName := uuid.New().String() + "." + info.Extension
 
-	extension := strings.ToLower(extParts[len(extParts)-1])
-	allowedExtensions := map[string]bool{"jpg": true, "jpeg": true, "png": true, "gif": true}
+	input := imageUC.UploadInput{
+		FileName:           randomName,
+		UserName:           userData.UserName,
+		Body:               body,
+		Size:               header.Size,
+		ContentType:        info.ContentType,
+		Format:             info.Format,
+		MetadataPolicy:     options.MetadataPolicy,
+		OnDuplicate:        options.OnDuplicate,
+		DuplicateThreshold: options.DuplicateThreshold,
+	}
 
-	if !allowedExtensions[extension] {
-		http.Error(w, "Formato de archivo no permitido", http.StatusBadRequest)
+	output, err := h.uploadUC.Execute(r.Context(), input)
+	if err != nil {
+		writeUploadFailure(w, err)
 		return
 	}
 
-	// Leer archivo en memoria
-	var buf bytes.Buffer
-	if _, err := io.Copy(&buf, file); err != nil {
-		http.Error(w, "Error al leer el archivo: "+err.Error(), http.StatusInternalServerError)
+	resp := dto.UploadResponse{
+		Message: "Imagen subida exitosamente",
+		Image:   toUploadedImageDetail(output),
+	}
+	status := http.StatusCreated
+	if output.Duplicate {
+		resp.Message = "La imagen ya existía; no se guardó una copia"
+		resp.Duplicate = true
+		status = http.StatusOK
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// UploadBatch godoc
+// @Summary      Sube varias imágenes
+// @Description  Sube todos los archivos del campo "images" en una sola petición, procesando varios a la vez. Cada archivo se valida y se guarda como en POST /upload y el fallo de uno no afecta a los demás: la respuesta es 200 con un resultado por archivo, en el orden en que se enviaron, y el estado que habría tenido cada uno por separado. Las opciones compartidas son las de POST /upload (metadata, on_duplicate y duplicate_threshold) y se aplican a todos los archivos; no hay otras, y los demás campos se ignoran. Con on_duplicate, los archivos del lote iguales o parecidos entre sí se suben en orden y a partir del segundo se tratan como duplicados.
+// @Tags         images
+// @Security     BearerAuth
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        images formData file true "Imágenes a subir (el campo se repite por cada archivo)"
+// @Param        metadata formData string false "Metadatos a quitar de todos los archivos: keep, strip_gps o strip_all" Enums(keep, strip_gps, strip_all)
+// @Param        on_duplicate formData string false "Qué hacer con los archivos que ya existen: allow (por defecto), reject o return_existing" Enums(allow, reject, return_existing)
+// @Param        duplicate_threshold formData int false "Distancia de Hamming máxima entre hashes perceptuales para considerar un duplicado (0 a 64, por defecto 4)"
+// @Success      200 {object} dto.BatchUploadResponse
+// @Failure      400 {object} dto.ErrorResponse
+// @Failure      401 {object} dto.ErrorResponse
+// @Failure      413 {object} dto.UploadErrorResponse
+// @Failure      500 {object} dto.ErrorResponse
+// @Router       /upload/batch [post]
+func (h *ImageHandler) UploadBatch(w http.ResponseWriter, r *http.Request) {
+	userData, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || userData == nil {
+		http.Error(w, "Error al obtener los datos del usuario", http.StatusUnauthorized)
 		return
 	}
 
-	// Obtener dimensiones
-	configImage, format, err := image.DecodeConfig(bytes.NewReader(buf.Bytes()))
-	if err != nil {
-		http.Error(w, "Error al obtener dimensiones de la imagen: "+err.Error(), http.StatusInternalServerError)
+	var maxBody int64
+	if maxFiles := h.batchUC.MaxFiles(); h.limits.MaxBytes > 0 && maxFiles > 0 {
+		maxBody = h.limits.MaxBytes * int64(maxFiles)
+		r.Body = http.MaxBytesReader(w, r.Body, maxBody+multipartOverhead)
+	}
+
+	if err := r.ParseMultipartForm(uploadMemory); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeUploadError(w, &imageUC.LimitError{Limit: "bytes", Max: maxBody})
+			return
+		}
+		http.Error(w, "Error al leer los archivos: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	randomName := uuid.New().String() + "." + extension
+	options, ok := parseUploadOptions(w, h.privacyUC, userData.UserName, r.FormValue)
+	if !ok {
+		return
+	}
 
-	input := imageUC.UploadInput{
-		FileName:    randomName,
-		UserName:    userData.UserName,
-		Data:        buf.Bytes(),
-		ContentType: "image/" + format,
-		Format:      format,
-		Width:       configImage.Width,
-		Height:      configImage.Height,
+	headers := r.MultipartForm.File["images"]
+	files := make([]imageUC.BatchFile, len(headers))
+	for i, header := range headers {
+		files[i] = imageUC.BatchFile{
+			Name: header.Filename,
+			Size: header.Size,
+			Open: func() (io.ReadCloser, error) { return header.Open() },
+		}
 	}
 
-	output, err := h.uploadUC.Execute(r.Context(), input)
+	results, err := h.batchUC.Execute(r.Context(), imageUC.BatchUploadInput{
+		UserName:           userData.UserName,
+		Files:              files,
+		MetadataPolicy:     options.MetadataPolicy,
+		OnDuplicate:        options.OnDuplicate,
+		DuplicateThreshold: options.DuplicateThreshold,
+	})
 	if err != nil {
-		http.Error(w, "Error al subir imagen: "+err.Error(), http.StatusInternalServerError)
+		if errors.Is(err, imageUC.ErrEmptyBatch) || errors.Is(err, imageUC.ErrBatchTooLarge) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Error al subir las imágenes: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	resp := dto.UploadResponse{
-		Message: "Imagen subida exitosamente",
-		Image: dto.UploadedImageDetail{
-			URL:    output.URL,
-			Name:   output.Name,
-			Size:   output.Size,
-			Format: output.Format,
-			Width:  output.Width,
-			Height: output.Height,
-		},
+	resp := dto.BatchUploadResponse{Results: make([]dto.BatchUploadResult, len(results))}
+	for i, result := range results {
+		resp.Results[i] = toBatchUploadResult(i, result)
+		if result.Err == nil {
+			resp.Succeeded++
+		} else {
+			resp.Failed++
+		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
+	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(resp)
 }
 
+// toBatchUploadResult convierte el resultado de un archivo del lote con los
+// mismos estados que writeUploadFailure
+func toBatchUploadResult(index int, result imageUC.BatchResult) dto.BatchUploadResult {
+	resp := dto.BatchUploadResult{Index: index, FileName: result.Name}
+
+	if result.Err == nil {
+		detail := toUploadedImageDetail(result.Image)
+		resp.Image = &detail
+		resp.Status = http.StatusCreated
+		if result.Image.Duplicate {
+			resp.Duplicate = true
+			resp.Status = http.StatusOK
+		}
+		return resp
+	}
+
+	var duplicateErr *imageUC.DuplicateError
+	if errors.As(result.Err, &duplicateErr) {
+		detail := toUploadedImageDetail(duplicateErr.Existing)
+		resp.Image = &detail
+		resp.Duplicate = true
+		resp.Status = http.StatusConflict
+		resp.Error = &dto.BatchUploadError{
+			Error:    "duplicate",
+			Message:  duplicateErr.Error(),
+			Distance: &duplicateErr.Distance,
+		}
+		return resp
+	}
+
+	if status, uploadErr, ok := uploadErrorResponse(result.Err); ok {
+		resp.Status = status
+		resp.Error = &dto.BatchUploadError{
+			Error:   uploadErr.Error,
+			Message: uploadErr.Message,
+			Limit:   uploadErr.Limit,
+			Actual:  uploadErr.Actual,
+			Max:     uploadErr.Max,
+		}
+		return resp
+	}
+
+	resp.Error = &dto.BatchUploadError{Message: result.Err.Error()}
+	switch {
+	case errors.Is(result.Err, imageUC.ErrUnreadableMetadata):
+		resp.Status = http.StatusBadRequest
+		resp.Error.Error = "unreadable_metadata"
+	case errors.Is(result.Err, imageUC.ErrCorruptImage):
+		resp.Status = http.StatusBadRequest
+		resp.Error.Error = "invalid_image"
+	default:
+		resp.Status = http.StatusInternalServerError
+		resp.Error.Error = "upload_failed"
+	}
+	return resp
+}
+
+// uploadOptions son las opciones de una subida que no dependen del archivo
+type uploadOptions struct {
+	MetadataPolicy     imageUC.MetadataPolicy
+	OnDuplicate        imageUC.DuplicatePolicy
+	DuplicateThreshold int
+}
+
+// parseUploadOptions lee las opciones de subida con value, que puede leer un
+// formulario o los metadatos de una subida tus. Si alguna es inválida
+// responde el error y devuelve false.
+func parseUploadOptions(w http.ResponseWriter, privacyUC *imageUC.PrivacyUseCase, userName string, value func(string) string) (uploadOptions, bool) {
+	policy, err := privacyUC.ResolvePolicy(userName, value("metadata"))
+	if err != nil {
+		if errors.Is(err, imageUC.ErrInvalidMetadataPolicy) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return uploadOptions{}, false
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return uploadOptions{}, false
+	}
+
+	onDuplicate, err := imageUC.ParseDuplicatePolicy(value("on_duplicate"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return uploadOptions{}, false
+	}
+
+	threshold := imageUC.DefaultDuplicateThreshold
+	if raw := value("duplicate_threshold"); raw != "" {
+		threshold, err = strconv.Atoi(raw)
+		if err != nil || threshold < 0 || threshold > imageUC.MaxHashDistance {
+			http.Error(w, "duplicate_threshold debe ser un entero entre 0 y 64", http.StatusBadRequest)
+			return uploadOptions{}, false
+		}
+	}
+
+	return uploadOptions{
+		MetadataPolicy:     policy,
+		OnDuplicate:        onDuplicate,
+		DuplicateThreshold: threshold,
+	}, true
+}
+
+// writeUploadFailure responde el error de UploadUseCase.Execute: 409 para un
+// duplicado rechazado, 413 o 415 para un archivo fuera de los límites y 400
+// para metadatos o contenido ilegibles
+func writeUploadFailure(w http.ResponseWriter, err error) {
+	var duplicateErr *imageUC.DuplicateError
+	if errors.As(err, &duplicateErr) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(dto.DuplicateResponse{
+			Error:    "duplicate",
+			Message:  duplicateErr.Error(),
+			Distance: duplicateErr.Distance,
+			Image:    toUploadedImageDetail(duplicateErr.Existing),
+		})
+		return
+	}
+	if errors.Is(err, imageUC.ErrUnreadableMetadata) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var limitErr *imageUC.LimitError
+	if errors.As(err, &limitErr) || errors.Is(err, imageUC.ErrCorruptImage) || errors.Is(err, imageUC.ErrUnsupportedMediaType) {
+		writeUploadError(w, err)
+		return
+	}
+	http.Error(w, "Error al subir imagen: "+err.Error(), http.StatusInternalServerError)
+}
+
+func toUploadedImageDetail(output *imageUC.UploadOutput) dto.UploadedImageDetail {
+	return dto.UploadedImageDetail{
+		ID:       output.ID,
+		ParentID: output.ParentID,
+		URL:      output.URL,
+		Name:     output.Name,
+		Size:     output.Size,
+		Format:   output.Format,
+		Width:    output.Width,
+		Height:   output.Height,
+	}
+}
+
 // GetImage godoc
 // @Summary      Obtener información de una imagen
-// @Description  Devuelve la metadata y URL de una imagen del usuario autenticado
+// @Description  Devuelve la metadata y URL de una imagen del usuario autenticado, junto con sus versiones redimensionadas para srcset. Las versiones se generan en segundo plano después de la subida, así que la lista puede estar vacía al principio.
 // @Tags         images
 // @Security     BearerAuth
 // @Produce      json
@@ -172,18 +438,134 @@ func (h *ImageHandler) GetImage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	resp := dto.ImageDetailResponse{
-		URL:    output.URL,
-		Name:   output.Name,
-		Size:   output.Size,
-		Format: output.Format,
-		Width:  output.Width,
-		Height: output.Height,
+		URL:        output.URL,
+		Name:       output.Name,
+		Size:       output.Size,
+		Format:     output.Format,
+		Width:      output.Width,
+		Height:     output.Height,
+		ParentID:   output.ParentID,
+		Renditions: make([]dto.RenditionResponse, len(output.Renditions)),
+	}
+	for i, rendition := range output.Renditions {
+		resp.Renditions[i] = dto.RenditionResponse{
+			Width:  rendition.Width,
+			Height: rendition.Height,
+			Format: rendition.Format,
+			URL:    rendition.URL,
+			Size:   rendition.Size,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// GetImageMetadata godoc
+// @Summary      Metadatos EXIF de una imagen
+// @Description  Devuelve los datos EXIF leídos al subir la imagen: orientación, cámara, lente, fecha de captura, GPS y exposición. Responde 404 si la imagen no tenía EXIF.
+// @Tags         images
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path int true "ID de la imagen"
+// @Success      200 {object} dto.ImageMetadataResponse
+// @Failure      400 {object} dto.ErrorResponse
+// @Failure      401 {object} dto.ErrorResponse
+// @Failure      404 {object} dto.ErrorResponse
+// @Router       /images/{id}/metadata [get]
+func (h *ImageHandler) GetImageMetadata(w http.ResponseWriter, r *http.Request) {
+	imageID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID de imagen inválido", http.StatusBadRequest)
+		return
+	}
+
+	userData, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || userData == nil {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	output, err := h.metadataUC.Execute(imageUC.MetadataInput{
+		ImageID:  imageID,
+		UserName: userData.UserName,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	resp := dto.ImageMetadataResponse{
+		ImageID:      output.ImageID,
+		Orientation:  output.Orientation,
+		CameraMake:   output.CameraMake,
+		CameraModel:  output.CameraModel,
+		LensMake:     output.LensMake,
+		LensModel:    output.LensModel,
+		Software:     output.Software,
+		CapturedAt:   output.CapturedAt,
+		ExposureTime: output.ExposureTime,
+		FNumber:      output.FNumber,
+		ISO:          output.ISO,
+		FocalLength:  output.FocalLength,
+	}
+	if output.GPS != nil {
+		resp.GPS = &dto.ImageMetadataGPS{
+			Latitude:  output.GPS.Latitude,
+			Longitude: output.GPS.Longitude,
+			Altitude:  output.GPS.Altitude,
+		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
 }
 
+// DeleteImage godoc
+// @Summary      Elimina una imagen
+// @Description  Elimina una imagen del usuario autenticado junto con su archivo y sus derivados cacheados. Responde 202 si la limpieza del storage quedó pendiente de reintento.
+// @Tags         images
+// @Security     BearerAuth
+// @Param        id path int true "ID de la imagen"
+// @Success      204
+// @Success      202
+// @Failure      400 {object} dto.ErrorResponse
+// @Failure      401 {object} dto.ErrorResponse
+// @Failure      404 {object} dto.ErrorResponse
+// @Router       /images/{id} [delete]
+func (h *ImageHandler) DeleteImage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	imageID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID de imagen inválido", http.StatusBadRequest)
+		return
+	}
+
+	userData, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || userData == nil {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	input := imageUC.DeleteInput{
+		ImageID:  imageID,
+		UserName: userData.UserName,
+	}
+
+	output, err := h.deleteUC.Execute(r.Context(), input)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if output.Pending {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // ListUserImages godoc
 // @Summary      Lista imágenes del usuario autenticado
 // @Description  Devuelve las imágenes subidas por el usuario autenticado con soporte de paginación.
@@ -248,17 +630,24 @@ func (h *ImageHandler) ListUserImages(w http.ResponseWriter, r *http.Request) {
 
 // TransformImage godoc
 // @Summary      Aplica transformaciones a una imagen
-// @Description  Aplica transformaciones (resize, crop, rotación, filtros) a una imagen previamente cargada por el usuario
+// @Description  Aplica un pipeline ordenado de operaciones ("operations") a una imagen previamente cargada por el usuario. El objeto "transformations" se acepta como formato anterior (resize → crop → rotate → filtros). Con "preset" se aplica primero el pipeline guardado y después las operaciones del cuerpo, que en ese caso es opcional.
 // @Tags         images
 // @Security     BearerAuth
 // @Produce      image/png
 // @Produce      image/jpeg
 // @Produce      image/gif
+// @Produce      image/tiff
+// @Produce      image/bmp
+// @Produce      image/webp
 // @Param        id path int true "ID de la imagen"
-// @Param        body body dto.TransformationRequest true "Parámetros de transformación"
+// @Param        preset query string false "Nombre de un preset del usuario"
+// @Param        body body dto.TransformationRequest false "Parámetros de transformación"
+// @Param        Accept header string false "Formato de salida si el cuerpo y el preset no definen format (por ejemplo: image/webp,image/jpeg;q=0.8)"
 // @Success      200 {file} file "Imagen transformada"
 // @Failure      400 {object} dto.ErrorResponse
 // @Failure      404 {object} dto.ErrorResponse
+// @Failure      406 {object} dto.ErrorResponse
+// @Failure      413 {object} dto.UploadErrorResponse
 // @Failure      500 {object} dto.ErrorResponse
 // @Router       /images/{id}/transform [post]
 func (h *ImageHandler) TransformImage(w http.ResponseWriter, r *http.Request) {
@@ -276,58 +665,47 @@ func (h *ImageHandler) TransformImage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var req dto.TransformationRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Error al parsear JSON: "+err.Error(), http.StatusBadRequest)
+	input, ok := decodeTransformRequest(w, r, h.presetUC, imageID, userData.UserName)
+	if !ok {
 		return
 	}
 
-	input := imageUC.TransformInput{
-		ImageID:  imageID,
-		UserName: userData.UserName,
-		Resize: imageUC.ResizeParams{
-			Width:  req.Transformations.Resize.Width,
-			Height: req.Transformations.Resize.Height,
-		},
-		Crop: imageUC.CropParams{
-			Width:  req.Transformations.Crop.Width,
-			Height: req.Transformations.Crop.Height,
-			X:      req.Transformations.Crop.X,
-			Y:      req.Transformations.Crop.Y,
-		},
-		Rotate: req.Transformations.Rotate,
-		Format: req.Transformations.Format,
-		Filters: imageUC.FilterParams{
-			Grayscale: req.Transformations.Filters.Grayscale,
-			Sepia:     req.Transformations.Filters.Sepia,
-		},
+	// Sin formato en el cuerpo, el formato sale del header Accept
+	w.Header().Set("Vary", "Accept")
+	if input.Format == "" {
+		format, err := imageUC.NegotiateFormat(r.Header.Get("Accept"), "")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotAcceptable)
+			return
+		}
+		input.Format = format
 	}
 
 	output, err := h.transformUC.Execute(r.Context(), input)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeTransformError(w, err, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set("Content-Type", output.ContentType)
-	w.WriteHeader(http.StatusOK)
-	if err := imaging.Encode(w, output.Image, output.Format); err != nil {
-		http.Error(w, "Error al codificar la imagen: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
+	writeTransformOutput(w, output)
 }
 
 // ServeImage godoc
 // @Summary      Servir imagen
-// @Description  Devuelve el archivo de imagen almacenado en el servidor desde la carpeta local "uploads", sin exponer su ruta real.
+// @Description  Devuelve el archivo de imagen almacenado sin exponer su ruta real. Si el header Accept no admite el formato guardado, la imagen se convierte al vuelo al formato aceptado de mayor prioridad; si ninguno puede generarse responde 406. Si el dueño configuró una marca de agua por defecto, se aplica a todo lo que se sirve, incluidas las versiones responsive y los resultados de trabajos, aunque no cambie el formato.
 // @Tags         images
 // @Produce      image/jpeg
 // @Produce      image/png
 // @Produce      image/gif
+// @Produce      image/tiff
+// @Produce      image/bmp
+// @Produce      image/webp
 // @Param        rest path string true "Ruta relativa de la imagen (por ejemplo: user123/imagen.jpg)"
+// @Param        Accept header string false "Formatos aceptados, con q-values (por ejemplo: image/webp,image/*;q=0.8)"
 // @Success      200 {file} file
 // @Failure      403 {string} string "Acceso prohibido"
 // @Failure      404 {string} string "Archivo no encontrado"
+// @Failure      406 {string} string "Ningún formato aceptable"
 // @Router       /images/{rest} [get]
 func (h *ImageHandler) ServeImage(w http.ResponseWriter, r *http.Request) {
 	objectPath := strings.TrimPrefix(r.URL.Path, "/images/")
@@ -338,6 +716,46 @@ func (h *ImageHandler) ServeImage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Las fuentes, las subidas a medias y la caché de derivados comparten el
+	// storage pero no son públicas, y los blobs solo se sirven por la URL de
+	// una imagen
+	if imageUC.IsFontPath(objectPath) || imageUC.IsUploadPath(objectPath) || imageUC.IsBlobPath(objectPath) ||
+		imageUC.IsCachePath(objectPath) {
+		http.Error(w, "Archivo no encontrado", http.StatusNotFound)
+		return
+	}
+
+	contentType := contentTypeByExtension(objectPath)
+
+	// Solo las imágenes que se pueden decodificar admiten conversión
+	if original := imageUC.ContentTypeFormat(contentType); original != "" {
+		w.Header().Set("Vary", "Accept")
+
+		format, err := imageUC.NegotiateFormat(r.Header.Get("Accept"), original)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotAcceptable)
+			return
+		}
+
+		// Todo lo que se sirve públicamente lleva la marca de agua por defecto
+		// del dueño, aunque no cambie el formato; sin marca ni conversión se
+		// sirve el objeto tal cual se guardó
+		ops := h.watermarkUC.PublicPipeline(objectPath, nil)
+		if format != original || len(ops) > 0 {
+			output, err := h.transformUC.ExecutePath(r.Context(), imageUC.TransformPathInput{
+				ObjectPath: objectPath,
+				Operations: ops,
+				Format:     format,
+			})
+			if err != nil {
+				writeTransformError(w, err, "Archivo no encontrado", http.StatusNotFound)
+				return
+			}
+			writeTransformOutput(w, output)
+			return
+		}
+	}
+
 	reader, err := h.transformUC.ServeImage(r.Context(), objectPath)
 	if err != nil {
 		http.Error(w, "Archivo no encontrado en MinIO", http.StatusNotFound)
@@ -345,18 +763,183 @@ func (h *ImageHandler) ServeImage(w http.ResponseWriter, r *http.Request) {
 	}
 	defer reader.Close()
 
-	// Estimar tipo MIME en base a la extensión
-	switch ext := strings.ToLower(path.Ext(objectPath)); ext {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, reader); errors.Is(err, imageUC.ErrChecksumMismatch) {
+		// La respuesta ya empezó: se corta la conexión para que el cliente
+		// no tome por completa una imagen alterada
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// contentTypeByExtension estima el tipo MIME en base a la extensión
+func contentTypeByExtension(objectPath string) string {
+	switch strings.ToLower(path.Ext(objectPath)) {
 	case ".jpg", ".jpeg":
-		w.Header().Set("Content-Type", "image/jpeg")
+		return "image/jpeg"
 	case ".png":
-		w.Header().Set("Content-Type", "image/png")
+		return "image/png"
 	case ".gif":
-		w.Header().Set("Content-Type", "image/gif")
+		return "image/gif"
+	case ".tif", ".tiff":
+		return "image/tiff"
+	case ".bmp":
+		return "image/bmp"
+	case ".webp":
+		return "image/webp"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// writeUploadError responde 413 o 415 con un cuerpo JSON que explica el
+// rechazo; cualquier otro error de validación es un 400
+func writeUploadError(w http.ResponseWriter, err error) {
+	status, resp, ok := uploadErrorResponse(err)
+	if !ok {
+		http.Error(w, "Imagen inválida: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// uploadErrorResponse arma la respuesta de un archivo que excede los límites
+// (413) o cuyo formato no se admite (415); ok es false para otros errores
+func uploadErrorResponse(err error) (int, dto.UploadErrorResponse, bool) {
+	var limitErr *imageUC.LimitError
+	switch {
+	case errors.As(err, &limitErr):
+		resp := dto.UploadErrorResponse{
+			Error:   "image_too_large",
+			Message: limitErr.Error(),
+			Limit:   limitErr.Limit,
+			Actual:  limitErr.Actual,
+			Max:     limitErr.Max,
+		}
+		if limitErr.Limit == "bytes" {
+			resp.Error = "payload_too_large"
+		}
+		return http.StatusRequestEntityTooLarge, resp, true
+	case errors.Is(err, imageUC.ErrUnsupportedMediaType):
+		return http.StatusUnsupportedMediaType, dto.UploadErrorResponse{
+			Error:   "unsupported_media_type",
+			Message: err.Error(),
+		}, true
 	default:
-		w.Header().Set("Content-Type", "application/octet-stream")
+		return 0, dto.UploadErrorResponse{}, false
 	}
+}
 
+// writeTransformError responde 400 si la transformación pedida es inválida
+// (pipeline, formato u opciones), 413 si excede los límites y en otro caso
+// usa el mensaje y el estado indicados
+func writeTransformError(w http.ResponseWriter, err error, message string, status int) {
+	var stepErr *imageUC.StepError
+	var fieldErr *imageUC.FieldError
+	var limitErr *imageUC.LimitError
+
+	switch {
+	case errors.As(err, &stepErr), errors.As(err, &fieldErr), errors.Is(err, imageUC.ErrUnsupportedFormat):
+		http.Error(w, "Transformación inválida: "+err.Error(), http.StatusBadRequest)
+	case errors.As(err, &limitErr):
+		writeUploadError(w, err)
+	default:
+		http.Error(w, message, status)
+	}
+}
+
+// writeTransformOutput escribe la imagen transformada ya codificada
+func writeTransformOutput(w http.ResponseWriter, output *imageUC.TransformOutput) {
+	cacheStatus := "MISS"
+	if output.Cached {
+		cacheStatus = "HIT"
+	}
+
+	w.Header().Set("Content-Type", output.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(output.Data)))
+	w.Header().Set("X-Cache", cacheStatus)
 	w.WriteHeader(http.StatusOK)
-	io.Copy(w, reader)
+	w.Write(output.Data)
+}
+
+// decodeTransformRequest lee el cuerpo de una transformación y le antepone el
+// preset de ?preset=. Si algo falla ya respondió al cliente y devuelve false.
+func decodeTransformRequest(w http.ResponseWriter, r *http.Request, presetUC *imageUC.PresetUseCase, imageID int64, userName string) (imageUC.TransformInput, bool) {
+	presetName := r.URL.Query().Get("preset")
+
+	// Con un preset el cuerpo es opcional
+	var req dto.TransformationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && (presetName == "" || !errors.Is(err, io.EOF)) {
+		http.Error(w, "Error al parsear JSON: "+err.Error(), http.StatusBadRequest)
+		return imageUC.TransformInput{}, false
+	}
+
+	input := toTransformInput(imageID, userName, req)
+
+	if presetName != "" {
+		var err error
+		input, err = presetUC.Apply(userName, presetName, input)
+		if err != nil {
+			writePresetError(w, err)
+			return imageUC.TransformInput{}, false
+		}
+	}
+	return input, true
+}
+
+// toTransformInput convierte la petición HTTP en la entrada del caso de uso
+func toTransformInput(imageID int64, userName string, req dto.TransformationRequest) imageUC.TransformInput {
+	legacy := req.Transformations
+
+	input := imageUC.TransformInput{
+		ImageID:  imageID,
+		UserName: userName,
+		Format:   req.Format,
+		Encoding: imageUC.EncodeOptions{
+			Quality:     req.Quality,
+			Compression: req.Compression,
+			PaletteSize: req.PaletteSize,
+		},
+	}
+
+	if input.Format == "" {
+		input.Format = legacy.Format
+	}
+
+	if len(req.Operations) > 0 {
+		input.Operations = make([]imageUC.Operation, len(req.Operations))
+		for i, op := range req.Operations {
+			input.Operations[i] = imageUC.Operation{
+				Type:   op.Type,
+				Params: imageUC.OperationParams(op.Params),
+			}
+		}
+		return input
+	}
+
+	input.Resize = imageUC.ResizeParams{
+		Width:      legacy.Resize.Width,
+		Height:     legacy.Resize.Height,
+		Mode:       legacy.Resize.Mode,
+		Gravity:    legacy.Resize.Gravity,
+		Background: legacy.Resize.Background,
+		Filter:     legacy.Resize.Filter,
+		NoUpscale:  legacy.Resize.NoUpscale,
+	}
+	input.Crop = imageUC.CropParams{
+		Width:  legacy.Crop.Width,
+		Height: legacy.Crop.Height,
+		X:      legacy.Crop.X,
+		Y:      legacy.Crop.Y,
+	}
+	input.Rotate = legacy.Rotate
+	input.Filters = imageUC.FilterParams{
+		Grayscale: legacy.Filters.Grayscale,
+		Sepia:     legacy.Filters.Sepia,
+	}
+
+	return input
 }
@@ -0,0 +1,159 @@
+package handler_test
+
+import (
+	"bytes"
+	stdimage "image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	imageUC "github.com/RodrigoGonzalez78/internal/application/usecase/image"
+	"github.com/RodrigoGonzalez78/internal/domain/entity"
+	"github.com/RodrigoGonzalez78/internal/domain/repository/mocks"
+	"github.com/RodrigoGonzalez78/internal/infrastructure/http/handler"
+)
+
+var markColor = color.NRGBA{R: 255, A: 255}
+
+const renditionPath = "testuser/renditions/1/3f0c2a9e-8d4b-4c1e-9a7f-2b6d5e8c1a40/100.png"
+
+func encodePNG(t *testing.T, width, height int, c color.NRGBA) []byte {
+	t.Helper()
+	img := stdimage.NewNRGBA(stdimage.Rect(0, 0, width, height))
+	for i := 0; i < len(img.Pix); i += 4 {
+		copy(img.Pix[i:], []uint8{c.R, c.G, c.B, c.A})
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("no se pudo codificar el PNG de prueba: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// setupServe arma el handler con una imagen blanca de 200x100 (ID 1), una
+// versión suya de 100x50 y una marca de agua roja (ID 2) configurada por
+// defecto en la esquina superior izquierda, con la caché de derivados activa
+func setupServe(t *testing.T) (*handler.ImageHandler, *mocks.MockFileStorage) {
+	t.Helper()
+	imageRepo := mocks.NewMockImageRepository()
+	fileStorage := mocks.NewMockFileStorage()
+	white := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+
+	imageRepo.Images[1] = &entity.Image{ID: 1, Name: "test.png", UserName: "testuser", Path: "testuser/test.png", Format: "png", Width: 200, Height: 100}
+	imageRepo.Images[2] = &entity.Image{ID: 2, Name: "mark.png", UserName: "testuser", Path: "testuser/mark.png", Format: "png", Width: 40, Height: 20}
+	fileStorage.Files["testuser/test.png"] = encodePNG(t, 200, 100, white)
+	fileStorage.Files["testuser/mark.png"] = encodePNG(t, 40, 20, markColor)
+	fileStorage.Files[renditionPath] = encodePNG(t, 100, 50, white)
+
+	userRepo := mocks.NewMockUserRepository()
+	userRepo.Users["testuser"] = entity.NewUser("testuser", "hash")
+	watermarkUC := imageUC.NewWatermarkUseCase(userRepo, imageRepo)
+	margin := 0
+	if _, err := watermarkUC.SetDefault("testuser", imageUC.WatermarkSettings{ImageID: 2, Anchor: "top-left", Opacity: 1, Scale: 0.2, Margin: &margin}); err != nil {
+		t.Fatalf("SetDefault() error = %v", err)
+	}
+
+	limits := imageUC.DefaultImageLimits()
+	cache := imageUC.NewDerivativeCache(fileStorage, 0)
+	transformUC := imageUC.NewTransformUseCase(imageRepo, mocks.NewMockFontRepository(), fileStorage, cache, limits)
+	h := handler.NewImageHandler(nil, nil, nil, nil, transformUC, nil, watermarkUC, nil, nil, nil, limits)
+	return h, fileStorage
+}
+
+func TestImageHandler_ServeImageWatermark(t *testing.T) {
+	tests := []struct {
+		name        string
+		objectPath  string
+		accept      string
+		contentType string
+		marked      bool
+	}{
+		{name: "original en el mismo formato", objectPath: "testuser/test.png", accept: "image/png", contentType: "image/png", marked: true},
+		{name: "conversión de formato", objectPath: "testuser/test.png", accept: "image/jpeg", contentType: "image/jpeg", marked: true},
+		{name: "versión responsive", objectPath: renditionPath, accept: "image/png", contentType: "image/png", marked: true},
+		{name: "la propia marca de agua", objectPath: "testuser/mark.png", accept: "image/png", contentType: "image/png", marked: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, fileStorage := setupServe(t)
+
+			req := httptest.NewRequest(http.MethodGet, "/images/"+tt.objectPath, nil)
+			req.Header.Set("Accept", tt.accept)
+			rec := httptest.NewRecorder()
+			h.ServeImage(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("ServeImage() status = %d, body = %s", rec.Code, rec.Body.String())
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.contentType {
+				t.Errorf("Content-Type = %s, want %s", got, tt.contentType)
+			}
+
+			if !tt.marked {
+				if !bytes.Equal(rec.Body.Bytes(), fileStorage.Files[tt.objectPath]) {
+					t.Error("ServeImage() debe servir el objeto sin cambios")
+				}
+				return
+			}
+
+			img, _, err := stdimage.Decode(rec.Body)
+			if err != nil {
+				t.Fatalf("no se pudo decodificar la respuesta: %v", err)
+			}
+			// La marca ocupa la esquina superior izquierda; el resto sigue blanco
+			corner := color.NRGBAModel.Convert(img.At(2, 2)).(color.NRGBA)
+			if corner.R < 200 || corner.G > 60 || corner.B > 60 {
+				t.Errorf("píxel (2,2) = %v, want la marca de agua", corner)
+			}
+			bounds := img.Bounds()
+			far := color.NRGBAModel.Convert(img.At(bounds.Max.X-2, bounds.Max.Y-2)).(color.NRGBA)
+			if far.G < 200 || far.B < 200 {
+				t.Errorf("píxel opuesto = %v, want blanco", far)
+			}
+		})
+	}
+}
+
+func TestImageHandler_ServeImageHidesInternalObjects(t *testing.T) {
+	h, fileStorage := setupServe(t)
+
+	// Una conversión deja su resultado en la caché de derivados
+	req := httptest.NewRequest(http.MethodGet, "/images/testuser/test.png", nil)
+	req.Header.Set("Accept", "image/jpeg")
+	h.ServeImage(httptest.NewRecorder(), req)
+
+	var cached string
+	for path := range fileStorage.Files {
+		if strings.HasPrefix(path, "cache/") {
+			cached = path
+		}
+	}
+	if cached == "" {
+		t.Fatal("la conversión no quedó en la caché")
+	}
+	fileStorage.Files["testuser/fonts/bold.ttf"] = []byte("fuente")
+	fileStorage.Files["testuser/uploads/3f0c2a9e-8d4b-4c1e-9a7f-2b6d5e8c1a40/0"] = []byte("parte")
+
+	tests := []struct {
+		name       string
+		objectPath string
+	}{
+		{name: "derivado en caché", objectPath: cached},
+		{name: "fuente", objectPath: "testuser/fonts/bold.ttf"},
+		{name: "parte de una subida", objectPath: "testuser/uploads/3f0c2a9e-8d4b-4c1e-9a7f-2b6d5e8c1a40/0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeImage(rec, httptest.NewRequest(http.MethodGet, "/images/"+tt.objectPath, nil))
+			if rec.Code != http.StatusNotFound {
+				t.Errorf("ServeImage(%s) status = %d, want 404", tt.objectPath, rec.Code)
+			}
+		})
+	}
+}
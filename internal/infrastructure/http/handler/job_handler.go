@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	imageUC "github.com/RodrigoGonzalez78/internal/application/usecase/image"
+	"github.com/RodrigoGonzalez78/internal/infrastructure/http/dto"
+	"github.com/RodrigoGonzalez78/internal/infrastructure/http/middleware"
+	"github.com/gorilla/mux"
+)
+
+type JobHandler struct {
+	jobUC *imageUC.TransformJobUseCase
+}
+
+func NewJobHandler(jobUC *imageUC.TransformJobUseCase) *JobHandler {
+	return &JobHandler{jobUC: jobUC}
+}
+
+// CreateTransformJob godoc
+// @Summary      Encola una transformación asíncrona
+// @Description  Acepta el mismo cuerpo que /images/{id}/transform pero procesa la imagen en segundo plano. Devuelve el ID del trabajo para consultar su estado en /jobs/{id}.
+// @Tags         jobs
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "ID de la imagen"
+// @Param        body body dto.TransformationRequest true "Parámetros de transformación"
+// @Success      202 {object} dto.JobResponse
+// @Failure      400 {object} dto.ErrorResponse
+// @Failure      401 {object} dto.ErrorResponse
+// @Failure      404 {object} dto.ErrorResponse
+// @Router       /images/{id}/transform-jobs [post]
+func (h *JobHandler) CreateTransformJob(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	imageID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID de imagen inválido", http.StatusBadRequest)
+		return
+	}
+
+	userData, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || userData == nil {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	var req dto.TransformationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error al parsear JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	output, err := h.jobUC.Submit(r.Context(), toTransformInput(imageID, userData.UserName, req))
+	if err != nil {
+		writeTransformError(w, err, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/jobs/%d", output.ID))
+	writeJob(w, http.StatusAccepted, output)
+}
+
+// GetJob godoc
+// @Summary      Estado de un trabajo de transformación
+// @Description  Devuelve el estado (queued, running, succeeded, failed, canceled) y, si terminó bien, la URL del resultado.
+// @Tags         jobs
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path int true "ID del trabajo"
+// @Success      200 {object} dto.JobResponse
+// @Failure      400 {object} dto.ErrorResponse
+// @Failure      401 {object} dto.ErrorResponse
+// @Failure      404 {object} dto.ErrorResponse
+// @Router       /jobs/{id} [get]
+func (h *JobHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	jobID, userName, ok := jobRequest(w, r)
+	if !ok {
+		return
+	}
+
+	output, err := h.jobUC.Get(jobID, userName)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	writeJob(w, http.StatusOK, output)
+}
+
+// CancelJob godoc
+// @Summary      Cancela un trabajo de transformación
+// @Description  Cancela un trabajo en cola o en ejecución. Un trabajo ya finalizado no puede cancelarse.
+// @Tags         jobs
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path int true "ID del trabajo"
+// @Success      200 {object} dto.JobResponse
+// @Failure      400 {object} dto.ErrorResponse
+// @Failure      401 {object} dto.ErrorResponse
+// @Failure      404 {object} dto.ErrorResponse
+// @Failure      409 {object} dto.ErrorResponse
+// @Router       /jobs/{id}/cancel [post]
+func (h *JobHandler) CancelJob(w http.ResponseWriter, r *http.Request) {
+	jobID, userName, ok := jobRequest(w, r)
+	if !ok {
+		return
+	}
+
+	output, err := h.jobUC.Cancel(jobID, userName)
+	if err != nil {
+		if errors.Is(err, imageUC.ErrJobFinished) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if errors.Is(err, imageUC.ErrJobNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Error al cancelar el trabajo: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJob(w, http.StatusOK, output)
+}
+
+// jobRequest extrae el ID del trabajo y el usuario autenticado
+func jobRequest(w http.ResponseWriter, r *http.Request) (int64, string, bool) {
+	jobID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID de trabajo inválido", http.StatusBadRequest)
+		return 0, "", false
+	}
+
+	userData, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || userData == nil {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return 0, "", false
+	}
+
+	return jobID, userData.UserName, true
+}
+
+func writeJob(w http.ResponseWriter, status int, output *imageUC.JobOutput) {
+	resp := dto.JobResponse{
+		ID:         output.ID,
+		ImageID:    output.ImageID,
+		Status:     string(output.Status),
+		ResultURL:  output.ResultURL,
+		Error:      output.Error,
+		CreatedAt:  output.CreatedAt,
+		StartedAt:  output.StartedAt,
+		FinishedAt: output.FinishedAt,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
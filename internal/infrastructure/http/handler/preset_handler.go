@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	imageUC "github.com/RodrigoGonzalez78/internal/application/usecase/image"
+	"github.com/RodrigoGonzalez78/internal/infrastructure/http/dto"
+	"github.com/RodrigoGonzalez78/internal/infrastructure/http/middleware"
+	"github.com/gorilla/mux"
+)
+
+type PresetHandler struct {
+	presetUC *imageUC.PresetUseCase
+}
+
+func NewPresetHandler(presetUC *imageUC.PresetUseCase) *PresetHandler {
+	return &PresetHandler{presetUC: presetUC}
+}
+
+// CreatePreset godoc
+// @Summary      Crea un preset
+// @Description  Guarda un pipeline de transformación con nombre para aplicarlo con /images/{id}/transform?preset={nombre}. Se valida igual que una transformación.
+// @Tags         presets
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        body body dto.PresetRequest true "Preset"
+// @Success      201 {object} dto.PresetResponse
+// @Failure      400 {object} dto.ErrorResponse
+// @Failure      401 {object} dto.ErrorResponse
+// @Failure      409 {object} dto.ErrorResponse
+// @Router       /presets [post]
+func (h *PresetHandler) CreatePreset(w http.ResponseWriter, r *http.Request) {
+	userData, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || userData == nil {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	var req dto.PresetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error al parsear JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	preset, err := h.presetUC.Create(toPresetInput(userData.UserName, req))
+	if err != nil {
+		writePresetError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(toPresetResponse(preset))
+}
+
+// ListPresets godoc
+// @Summary      Lista los presets
+// @Description  Devuelve los presets del usuario autenticado ordenados por nombre.
+// @Tags         presets
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {array} dto.PresetResponse
+// @Failure      401 {object} dto.ErrorResponse
+// @Failure      500 {object} dto.ErrorResponse
+// @Router       /presets [get]
+func (h *PresetHandler) ListPresets(w http.ResponseWriter, r *http.Request) {
+	userData, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || userData == nil {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	presets, err := h.presetUC.List(userData.UserName)
+	if err != nil {
+		http.Error(w, "Error al obtener los presets: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := make([]dto.PresetResponse, len(presets))
+	for i := range presets {
+		resp[i] = toPresetResponse(&presets[i])
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// GetPreset godoc
+// @Summary      Obtiene un preset
+// @Tags         presets
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path int true "ID del preset"
+// @Success      200 {object} dto.PresetResponse
+// @Failure      400 {object} dto.ErrorResponse
+// @Failure      401 {object} dto.ErrorResponse
+// @Failure      404 {object} dto.ErrorResponse
+// @Router       /presets/{id} [get]
+func (h *PresetHandler) GetPreset(w http.ResponseWriter, r *http.Request) {
+	presetID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID de preset inválido", http.StatusBadRequest)
+		return
+	}
+
+	userData, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || userData == nil {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	preset, err := h.presetUC.Get(presetID, userData.UserName)
+	if err != nil {
+		writePresetError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toPresetResponse(preset))
+}
+
+// UpdatePreset godoc
+// @Summary      Reemplaza un preset
+// @Description  Reemplaza el nombre, el pipeline y el formato de un preset. Se valida igual que al crearlo.
+// @Tags         presets
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id   path int               true "ID del preset"
+// @Param        body body dto.PresetRequest true "Preset"
+// @Success      200 {object} dto.PresetResponse
+// @Failure      400 {object} dto.ErrorResponse
+// @Failure      401 {object} dto.ErrorResponse
+// @Failure      404 {object} dto.ErrorResponse
+// @Failure      409 {object} dto.ErrorResponse
+// @Router       /presets/{id} [put]
+func (h *PresetHandler) UpdatePreset(w http.ResponseWriter, r *http.Request) {
+	presetID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID de preset inválido", http.StatusBadRequest)
+		return
+	}
+
+	userData, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || userData == nil {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	var req dto.PresetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error al parsear JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	preset, err := h.presetUC.Update(presetID, toPresetInput(userData.UserName, req))
+	if err != nil {
+		writePresetError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toPresetResponse(preset))
+}
+
+// DeletePreset godoc
+// @Summary      Elimina un preset
+// @Tags         presets
+// @Security     BearerAuth
+// @Param        id path int true "ID del preset"
+// @Success      204
+// @Failure      400 {object} dto.ErrorResponse
+// @Failure      401 {object} dto.ErrorResponse
+// @Failure      404 {object} dto.ErrorResponse
+// @Router       /presets/{id} [delete]
+func (h *PresetHandler) DeletePreset(w http.ResponseWriter, r *http.Request) {
+	presetID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID de preset inválido", http.StatusBadRequest)
+		return
+	}
+
+	userData, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || userData == nil {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.presetUC.Delete(presetID, userData.UserName); err != nil {
+		writePresetError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writePresetError traduce los errores del caso de uso a códigos HTTP
+func writePresetError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, imageUC.ErrPresetNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, imageUC.ErrPresetExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		writeTransformError(w, err, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func toPresetInput(userName string, req dto.PresetRequest) imageUC.PresetInput {
+	input := imageUC.PresetInput{
+		UserName: userName,
+		Name:     req.Name,
+		Format:   req.Format,
+		Encoding: imageUC.EncodeOptions{
+			Quality:     req.Quality,
+			Compression: req.Compression,
+			PaletteSize: req.PaletteSize,
+		},
+		Operations: make([]imageUC.Operation, len(req.Operations)),
+	}
+	for i, op := range req.Operations {
+		input.Operations[i] = imageUC.Operation{
+			Type:   op.Type,
+			Params: imageUC.OperationParams(op.Params),
+		}
+	}
+	return input
+}
+
+func toPresetResponse(preset *imageUC.PresetOutput) dto.PresetResponse {
+	resp := dto.PresetResponse{
+		ID:          preset.ID,
+		Name:        preset.Name,
+		Operations:  make([]dto.TransformationOperation, len(preset.Operations)),
+		Format:      preset.Format,
+		Quality:     preset.Encoding.Quality,
+		Compression: preset.Encoding.Compression,
+		PaletteSize: preset.Encoding.PaletteSize,
+		CreatedAt:   preset.CreatedAt,
+		UpdatedAt:   preset.UpdatedAt,
+	}
+	for i, op := range preset.Operations {
+		resp.Operations[i] = dto.TransformationOperation{
+			Type:   op.Type,
+			Params: op.Params,
+		}
+	}
+	return resp
+}
@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	imageUC "github.com/RodrigoGonzalez78/internal/application/usecase/image"
+	"github.com/RodrigoGonzalez78/internal/infrastructure/http/dto"
+	"github.com/RodrigoGonzalez78/internal/infrastructure/http/middleware"
+	"github.com/gorilla/mux"
+)
+
+type PrivacyHandler struct {
+	privacyUC *imageUC.PrivacyUseCase
+}
+
+func NewPrivacyHandler(privacyUC *imageUC.PrivacyUseCase) *PrivacyHandler {
+	return &PrivacyHandler{privacyUC: privacyUC}
+}
+
+// GetPolicy godoc
+// @Summary      Política de metadatos
+// @Description  Devuelve qué metadatos se quitan de las imágenes que sube el usuario autenticado: keep (ninguno), strip_gps (solo la posición) o strip_all (todo salvo la orientación).
+// @Tags         account
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} dto.MetadataPolicyResponse
+// @Failure      401 {object} dto.ErrorResponse
+// @Router       /account/metadata-policy [get]
+func (h *PrivacyHandler) GetPolicy(w http.ResponseWriter, r *http.Request) {
+	userData, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || userData == nil {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	policy, err := h.privacyUC.GetPolicy(userData.UserName)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.MetadataPolicyResponse{Policy: string(policy)})
+}
+
+// SetPolicy godoc
+// @Summary      Configura la política de metadatos
+// @Description  Define qué metadatos se quitan de las próximas imágenes que suba el usuario. Cada subida puede indicar otra política con el campo "metadata". No modifica las imágenes ya guardadas (ver /images/{id}/strip-metadata).
+// @Tags         account
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        body body dto.MetadataPolicyRequest true "Política"
+// @Success      200 {object} dto.MetadataPolicyResponse
+// @Failure      400 {object} dto.ErrorResponse
+// @Failure      401 {object} dto.ErrorResponse
+// @Router       /account/metadata-policy [put]
+func (h *PrivacyHandler) SetPolicy(w http.ResponseWriter, r *http.Request) {
+	userData, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || userData == nil {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	var req dto.MetadataPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error al parsear JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	policy, err := h.privacyUC.SetPolicy(userData.UserName, req.Policy)
+	if err != nil {
+		if errors.Is(err, imageUC.ErrInvalidMetadataPolicy) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.MetadataPolicyResponse{Policy: string(policy)})
+}
+
+// StripMetadata godoc
+// @Summary      Quita los metadatos de una imagen guardada
+// @Description  Reescribe el archivo original sin volver a codificar los píxeles: strip_gps quita la posición de captura y strip_all (por defecto) quita EXIF, XMP, IPTC y comentarios conservando la orientación. Los derivados en caché se descartan.
+// @Tags         images
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id   path int                       true  "ID de la imagen"
+// @Param        body body dto.StripMetadataRequest false "Política a aplicar"
+// @Success      200 {object} dto.StripMetadataResponse
+// @Failure      400 {object} dto.ErrorResponse
+// @Failure      401 {object} dto.ErrorResponse
+// @Failure      404 {object} dto.ErrorResponse
+// @Failure      422 {object} dto.ErrorResponse
+// @Router       /images/{id}/strip-metadata [post]
+func (h *PrivacyHandler) StripMetadata(w http.ResponseWriter, r *http.Request) {
+	imageID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID de imagen inválido", http.StatusBadRequest)
+		return
+	}
+
+	userData, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || userData == nil {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	// El cuerpo es opcional
+	var req dto.StripMetadataRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Error al parsear JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	output, err := h.privacyUC.StripStored(r.Context(), imageUC.StripMetadataInput{
+		ImageID:  imageID,
+		UserName: userData.UserName,
+		Policy:   req.Policy,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, imageUC.ErrInvalidMetadataPolicy):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, imageUC.ErrUnreadableMetadata):
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		default:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.StripMetadataResponse{
+		ImageID: output.ImageID,
+		Policy:  string(output.Policy),
+		Changed: output.Changed,
+		OldSize: output.OldSize,
+		NewSize: output.NewSize,
+	})
+}
+
+// AuditGPS godoc
+// @Summary      Imágenes con posición de captura
+// @Description  Revisa los archivos guardados del usuario autenticado y lista los que todavía contienen coordenadas GPS, en EXIF o en XMP, y por lo tanto las exponen al servirse públicamente.
+// @Tags         images
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} dto.GPSAuditResponse
+// @Failure      401 {object} dto.ErrorResponse
+// @Failure      500 {object} dto.ErrorResponse
+// @Router       /user-images/gps [get]
+func (h *PrivacyHandler) AuditGPS(w http.ResponseWriter, r *http.Request) {
+	userData, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || userData == nil {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	output, err := h.privacyUC.AuditGPS(r.Context(), userData.UserName)
+	if err != nil {
+		http.Error(w, "Error al revisar las imágenes: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := dto.GPSAuditResponse{
+		Scanned: output.Scanned,
+		Images:  make([]dto.GPSAuditItem, len(output.Images)),
+	}
+	for i, item := range output.Images {
+		resp.Images[i] = dto.GPSAuditItem{
+			ID:        item.ID,
+			URL:       item.URL,
+			Name:      item.Name,
+			Latitude:  item.Latitude,
+			Longitude: item.Longitude,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
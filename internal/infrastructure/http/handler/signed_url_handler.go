@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	imageUC "github.com/RodrigoGonzalez78/internal/application/usecase/image"
+	"github.com/RodrigoGonzalez78/internal/infrastructure/http/dto"
+	"github.com/RodrigoGonzalez78/internal/infrastructure/http/middleware"
+	"github.com/gorilla/mux"
+)
+
+type SignedURLHandler struct {
+	signedURLUC *imageUC.SignedURLUseCase
+	transformUC *imageUC.TransformUseCase
+	watermarkUC *imageUC.WatermarkUseCase
+}
+
+func NewSignedURLHandler(
+	signedURLUC *imageUC.SignedURLUseCase,
+	transformUC *imageUC.TransformUseCase,
+	watermarkUC *imageUC.WatermarkUseCase,
+) *SignedURLHandler {
+	return &SignedURLHandler{
+		signedURLUC: signedURLUC,
+		transformUC: transformUC,
+		watermarkUC: watermarkUC,
+	}
+}
+
+// CreateSignedURL godoc
+// @Summary      Genera una URL de transformación firmada
+// @Description  Devuelve una URL GET pública (firmada con HMAC) que aplica las operaciones indicadas a la imagen, para usar en etiquetas <img> o detrás de un CDN sin enviar el token.
+// @Tags         images
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "ID de la imagen"
+// @Param        body body dto.SignedURLRequest true "Operaciones en formato de URL (por ejemplo rs:800:600/gs)"
+// @Success      201 {object} dto.SignedURLResponse
+// @Failure      400 {object} dto.ErrorResponse
+// @Failure      401 {object} dto.ErrorResponse
+// @Failure      404 {object} dto.ErrorResponse
+// @Router       /images/{id}/signed-url [post]
+func (h *SignedURLHandler) CreateSignedURL(w http.ResponseWriter, r *http.Request) {
+	imageID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID de imagen inválido", http.StatusBadRequest)
+		return
+	}
+
+	userData, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || userData == nil {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	var req dto.SignedURLRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error al parsear JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	output, err := h.signedURLUC.Sign(imageUC.SignURLInput{
+		ImageID:    imageID,
+		UserName:   userData.UserName,
+		Operations: req.Operations,
+		Format:     req.Format,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(dto.SignedURLResponse{URL: output.URL})
+}
+
+// ServeTransform godoc
+// @Summary      Sirve una imagen transformada mediante URL firmada
+// @Description  Verifica la firma HMAC de la ruta, aplica las operaciones codificadas en ella (por ejemplo rs:800:600/crop:400:300:0:0/f:jpeg) y devuelve la imagen resultante. No requiere token. Si el dueño configuró una marca de agua por defecto, se aplica al final.
+// @Tags         images
+// @Produce      image/png
+// @Produce      image/jpeg
+// @Produce      image/gif
+// @Produce      image/tiff
+// @Produce      image/bmp
+// @Produce      image/webp
+// @Param        signature path string true "Firma HMAC-SHA256 en base64 URL-safe"
+// @Param        rest path string true "Operaciones seguidas de usuario/archivo"
+// @Success      200 {file} file "Imagen transformada"
+// @Failure      400 {string} string "Ruta u operaciones inválidas"
+// @Failure      403 {string} string "Firma inválida"
+// @Failure      404 {string} string "Imagen no encontrada"
+// @Failure      406 {string} string "Ningún formato aceptable"
+// @Router       /t/{signature}/{rest} [get]
+func (h *SignedURLHandler) ServeTransform(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	input, err := h.signedURLUC.Resolve(vars["signature"], vars["rest"])
+	if err != nil {
+		if errors.Is(err, imageUC.ErrInvalidSignature) {
+			http.Error(w, "Firma inválida", http.StatusForbidden)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Sin segmento "f:", el formato sale del header Accept
+	w.Header().Set("Vary", "Accept")
+	if input.Format == "" {
+		format, err := imageUC.NegotiateFormat(r.Header.Get("Accept"), "")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotAcceptable)
+			return
+		}
+		input.Format = format
+	}
+
+	input.Operations = h.watermarkUC.PublicPipeline(input.ObjectPath, input.Operations)
+
+	output, err := h.transformUC.ExecutePath(r.Context(), *input)
+	if err != nil {
+		writeTransformError(w, err, "Imagen no encontrada", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	writeTransformOutput(w, output)
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	imageUC "github.com/RodrigoGonzalez78/internal/application/usecase/image"
+	"github.com/RodrigoGonzalez78/internal/infrastructure/http/dto"
+	"github.com/RodrigoGonzalez78/internal/infrastructure/http/middleware"
+	"github.com/gorilla/mux"
+)
+
+type SimilarityHandler struct {
+	similarityUC *imageUC.SimilarityUseCase
+}
+
+func NewSimilarityHandler(similarityUC *imageUC.SimilarityUseCase) *SimilarityHandler {
+	return &SimilarityHandler{similarityUC: similarityUC}
+}
+
+// FindSimilar godoc
+// @Summary      Imágenes parecidas
+// @Description  Lista las demás imágenes del usuario cuyo hash perceptual (dHash) está a una distancia de Hamming menor o igual que threshold, de la más parecida a la menos parecida. 0 encuentra copias exactas o recomprimidas; alrededor de 10, versiones retocadas o redimensionadas. Responde 422 si la imagen todavía no tiene hash.
+// @Tags         images
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path int true "ID de la imagen"
+// @Param        threshold query int false "Distancia máxima, de 0 a 64 (por defecto 10)"
+// @Success      200 {object} dto.SimilarImagesResponse
+// @Failure      400 {object} dto.ErrorResponse
+// @Failure      401 {object} dto.ErrorResponse
+// @Failure      404 {object} dto.ErrorResponse
+// @Failure      422 {object} dto.ErrorResponse
+// @Router       /images/{id}/similar [get]
+func (h *SimilarityHandler) FindSimilar(w http.ResponseWriter, r *http.Request) {
+	imageID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID de imagen inválido", http.StatusBadRequest)
+		return
+	}
+
+	userData, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || userData == nil {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	threshold := imageUC.DefaultSimilarityThreshold
+	if value := r.URL.Query().Get("threshold"); value != "" {
+		threshold, err = strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "threshold debe ser un entero", http.StatusBadRequest)
+			return
+		}
+	}
+
+	output, err := h.similarityUC.Similar(imageID, userData.UserName, threshold)
+	if err != nil {
+		var fieldErr *imageUC.FieldError
+		switch {
+		case errors.As(err, &fieldErr):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, imageUC.ErrImageNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, imageUC.ErrHashUnavailable):
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	resp := dto.SimilarImagesResponse{
+		ImageID:   output.ImageID,
+		Threshold: output.Threshold,
+		Images:    make([]dto.SimilarImageItem, len(output.Images)),
+	}
+	for i, img := range output.Images {
+		resp.Images[i] = dto.SimilarImageItem{
+			ImageItem: dto.ImageItem{
+				ID:     img.ID,
+				URL:    img.URL,
+				Name:   img.Name,
+				Size:   img.Size,
+				Format: img.Format,
+				Width:  img.Width,
+				Height: img.Height,
+			},
+			Distance: img.Distance,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
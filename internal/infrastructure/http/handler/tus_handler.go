@@ -0,0 +1,335 @@
+package handler
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	imageUC "github.com/RodrigoGonzalez78/internal/application/usecase/image"
+	"github.com/RodrigoGonzalez78/internal/infrastructure/http/middleware"
+	"github.com/gorilla/mux"
+)
+
+// tusVersion es la única versión del protocolo tus que se implementa
+const tusVersion = "1.0.0"
+
+// tusExtensions son las extensiones de tus soportadas
+const tusExtensions = "creation,termination,checksum,expiration"
+
+// statusChecksumMismatch es el estado que define la extensión checksum de
+// tus cuando el contenido no coincide
+const statusChecksumMismatch = 460
+
+type TusHandler struct {
+	resumableUC *imageUC.ResumableUploadUseCase
+	privacyUC   *imageUC.PrivacyUseCase
+	limits      imageUC.ImageLimits
+}
+
+func NewTusHandler(resumableUC *imageUC.ResumableUploadUseCase, privacyUC *imageUC.PrivacyUseCase, limits imageUC.ImageLimits) *TusHandler {
+	return &TusHandler{
+		resumableUC: resumableUC,
+		privacyUC:   privacyUC,
+		limits:      limits,
+	}
+}
+
+// Options godoc
+// @Summary      Capacidades del servidor tus
+// @Description  Informa la versión y las extensiones de tus soportadas, el tamaño máximo de una subida y los algoritmos de checksum aceptados. No requiere autenticación.
+// @Tags         uploads
+// @Success      204 "Capacidades en los encabezados Tus-Version, Tus-Extension, Tus-Max-Size y Tus-Checksum-Algorithm"
+// @Router       /uploads/tus [options]
+func (h *TusHandler) Options(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Tus-Resumable", tusVersion)
+	w.Header().Set("Tus-Version", tusVersion)
+	w.Header().Set("Tus-Extension", tusExtensions)
+	w.Header().Set("Tus-Checksum-Algorithm", strings.Join(imageUC.ChecksumAlgorithms(), ","))
+	if h.limits.MaxBytes > 0 {
+		w.Header().Set("Tus-Max-Size", strconv.FormatInt(h.limits.MaxBytes, 10))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Create godoc
+// @Summary      Inicia una subida reanudable
+// @Description  Crea una subida tus de Upload-Length bytes. Upload-Metadata acepta las claves metadata, on_duplicate y duplicate_threshold con el mismo significado que en POST /upload; el resto se guarda y se devuelve sin interpretar. La subida vence si pasa el tiempo configurado sin recibir datos.
+// @Tags         uploads
+// @Security     BearerAuth
+// @Param        Tus-Resumable header string true "Versión del protocolo" Enums(1.0.0)
+// @Param        Upload-Length header int true "Tamaño total en bytes"
+// @Param        Upload-Metadata header string false "Pares clave valor-en-base64 separados por coma"
+// @Success      201 "Subida creada; la URL está en Location y el vencimiento en Upload-Expires"
+// @Failure      400 {object} dto.ErrorResponse
+// @Failure      401 {object} dto.ErrorResponse
+// @Failure      412 {object} dto.ErrorResponse
+// @Failure      413 {object} dto.UploadErrorResponse
+// @Failure      500 {object} dto.ErrorResponse
+// @Router       /uploads/tus [post]
+func (h *TusHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if !checkTusResumable(w, r) {
+		return
+	}
+
+	userData, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || userData == nil {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	length, err := strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+	if err != nil || length < 0 {
+		http.Error(w, "Upload-Length debe ser un entero no negativo", http.StatusBadRequest)
+		return
+	}
+
+	rawMetadata := r.Header.Get("Upload-Metadata")
+	metadata, err := parseTusMetadata(rawMetadata)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	options, ok := parseUploadOptions(w, h.privacyUC, userData.UserName, func(key string) string { return metadata[key] })
+	if !ok {
+		return
+	}
+
+	output, err := h.resumableUC.Create(imageUC.CreateResumableInput{
+		UserName:           userData.UserName,
+		Length:             length,
+		Metadata:           rawMetadata,
+		MetadataPolicy:     options.MetadataPolicy,
+		OnDuplicate:        options.OnDuplicate,
+		DuplicateThreshold: options.DuplicateThreshold,
+	})
+	if err != nil {
+		writeTusError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", "/uploads/tus/"+output.ID)
+	w.Header().Set("Upload-Expires", output.ExpiresAt.UTC().Format(http.TimeFormat))
+	w.WriteHeader(http.StatusCreated)
+}
+
+// Head godoc
+// @Summary      Estado de una subida reanudable
+// @Description  Devuelve en Upload-Offset cuántos bytes se guardaron, para que el cliente retome desde ahí. Si la subida ya creó la imagen, su ID y URL van en X-Image-ID y X-Image-URL.
+// @Tags         uploads
+// @Security     BearerAuth
+// @Param        Tus-Resumable header string true "Versión del protocolo" Enums(1.0.0)
+// @Param        id path string true "ID de la subida"
+// @Success      200 "Estado en Upload-Offset, Upload-Length, Upload-Metadata y Upload-Expires"
+// @Failure      401 {object} dto.ErrorResponse
+// @Failure      404 {object} dto.ErrorResponse
+// @Failure      410 {object} dto.ErrorResponse
+// @Failure      412 {object} dto.ErrorResponse
+// @Router       /uploads/tus/{id} [head]
+func (h *TusHandler) Head(w http.ResponseWriter, r *http.Request) {
+	if !checkTusResumable(w, r) {
+		return
+	}
+
+	userData, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || userData == nil {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	output, err := h.resumableUC.Get(mux.Vars(r)["id"], userData.UserName)
+	if err != nil {
+		writeTusError(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Upload-Length", strconv.FormatInt(output.Length, 10))
+	if output.Metadata != "" {
+		w.Header().Set("Upload-Metadata", output.Metadata)
+	}
+	writeTusProgress(w, output)
+	w.WriteHeader(http.StatusOK)
+}
+
+// Patch godoc
+// @Summary      Envía datos de una subida reanudable
+// @Description  Guarda el cuerpo a partir de Upload-Offset, que debe coincidir con lo recibido hasta ahora. Sin Upload-Checksum se conserva lo que llegó aunque la conexión se corte; con checksum la petición se acepta entera o no se acepta. Al recibir el último byte la imagen se valida y se crea como en POST /upload; si eso falla por un error pasajero, una petición vacía con el offset final lo reintenta.
+// @Tags         uploads
+// @Security     BearerAuth
+// @Accept       application/offset+octet-stream
+// @Param        Tus-Resumable header string true "Versión del protocolo" Enums(1.0.0)
+// @Param        Upload-Offset header int true "Offset en el que empieza el cuerpo"
+// @Param        Upload-Checksum header string false "Algoritmo y checksum en base64 del cuerpo, por ejemplo: sha1 Kq5sNclPz7QV2+lfQIuc6R7oRu0="
+// @Param        id path string true "ID de la subida"
+// @Success      204 "Nuevo offset en Upload-Offset; al completarse, la imagen en X-Image-ID y X-Image-URL"
+// @Failure      400 {object} dto.ErrorResponse
+// @Failure      401 {object} dto.ErrorResponse
+// @Failure      404 {object} dto.ErrorResponse
+// @Failure      409 {object} dto.DuplicateResponse "El offset no coincide o la imagen es un duplicado rechazado"
+// @Failure      410 {object} dto.ErrorResponse
+// @Failure      412 {object} dto.ErrorResponse
+// @Failure      413 {object} dto.UploadErrorResponse
+// @Failure      415 {object} dto.UploadErrorResponse
+// @Failure      460 {object} dto.ErrorResponse "El checksum no coincide"
+// @Failure      500 {object} dto.ErrorResponse
+// @Router       /uploads/tus/{id} [patch]
+func (h *TusHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	if !checkTusResumable(w, r) {
+		return
+	}
+
+	userData, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || userData == nil {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	if r.Header.Get("Content-Type") != "application/offset+octet-stream" {
+		http.Error(w, "Content-Type debe ser application/offset+octet-stream", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		http.Error(w, "Upload-Offset debe ser un entero no negativo", http.StatusBadRequest)
+		return
+	}
+
+	var checksum *imageUC.PartChecksum
+	if value := r.Header.Get("Upload-Checksum"); value != "" {
+		checksum, err = parseTusChecksum(value)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	output, err := h.resumableUC.Append(r.Context(), imageUC.AppendInput{
+		ID:       mux.Vars(r)["id"],
+		UserName: userData.UserName,
+		Offset:   offset,
+		Body:     r.Body,
+		Checksum: checksum,
+	})
+	if err != nil {
+		writeTusError(w, err)
+		return
+	}
+
+	writeTusProgress(w, output)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Terminate godoc
+// @Summary      Cancela una subida reanudable
+// @Description  Borra la subida y los datos recibidos. Una imagen ya creada por la subida no se borra.
+// @Tags         uploads
+// @Security     BearerAuth
+// @Param        Tus-Resumable header string true "Versión del protocolo" Enums(1.0.0)
+// @Param        id path string true "ID de la subida"
+// @Success      204 "Subida cancelada"
+// @Failure      401 {object} dto.ErrorResponse
+// @Failure      404 {object} dto.ErrorResponse
+// @Failure      412 {object} dto.ErrorResponse
+// @Failure      500 {object} dto.ErrorResponse
+// @Router       /uploads/tus/{id} [delete]
+func (h *TusHandler) Terminate(w http.ResponseWriter, r *http.Request) {
+	if !checkTusResumable(w, r) {
+		return
+	}
+
+	userData, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || userData == nil {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.resumableUC.Terminate(r.Context(), mux.Vars(r)["id"], userData.UserName); err != nil {
+		writeTusError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// checkTusResumable exige que el cliente hable la versión soportada. Toda
+// respuesta lleva Tus-Resumable.
+func checkTusResumable(w http.ResponseWriter, r *http.Request) bool {
+	w.Header().Set("Tus-Resumable", tusVersion)
+	if r.Header.Get("Tus-Resumable") != tusVersion {
+		w.Header().Set("Tus-Version", tusVersion)
+		http.Error(w, "Versión de tus no soportada; se requiere Tus-Resumable: "+tusVersion, http.StatusPreconditionFailed)
+		return false
+	}
+	return true
+}
+
+// writeTusProgress escribe el offset, el vencimiento y, si la subida creó
+// una imagen, su ID y URL
+func writeTusProgress(w http.ResponseWriter, output *imageUC.ResumableOutput) {
+	w.Header().Set("Upload-Offset", strconv.FormatInt(output.Offset, 10))
+	w.Header().Set("Upload-Expires", output.ExpiresAt.UTC().Format(http.TimeFormat))
+	if output.Image != nil {
+		w.Header().Set("X-Image-ID", strconv.FormatInt(output.Image.ID, 10))
+		w.Header().Set("X-Image-URL", output.Image.URL)
+	}
+}
+
+// writeTusError responde los errores propios del protocolo; el resto son
+// errores al crear la imagen y se responden como en POST /upload
+func writeTusError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, imageUC.ErrUploadNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, imageUC.ErrUploadExpired):
+		http.Error(w, err.Error(), http.StatusGone)
+	case errors.Is(err, imageUC.ErrOffsetMismatch):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, imageUC.ErrUploadExceedsLength):
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+	case errors.Is(err, imageUC.ErrEmptyUpload), errors.Is(err, imageUC.ErrUnsupportedChecksum):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, imageUC.ErrPartChecksumMismatch):
+		http.Error(w, err.Error(), statusChecksumMismatch)
+	default:
+		writeUploadFailure(w, err)
+	}
+}
+
+// parseTusMetadata decodifica Upload-Metadata: pares "clave valor" separados
+// por coma, con el valor en base64 y opcional
+func parseTusMetadata(header string) (map[string]string, error) {
+	metadata := make(map[string]string)
+	if strings.TrimSpace(header) == "" {
+		return metadata, nil
+	}
+
+	for _, pair := range strings.Split(header, ",") {
+		key, encoded, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			return nil, errors.New("Upload-Metadata tiene una clave vacía")
+		}
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, errors.New("Upload-Metadata: el valor de " + key + " no es base64 válido")
+		}
+		metadata[key] = string(value)
+	}
+	return metadata, nil
+}
+
+// parseTusChecksum decodifica Upload-Checksum: el algoritmo y el checksum en
+// base64 separados por un espacio
+func parseTusChecksum(header string) (*imageUC.PartChecksum, error) {
+	algorithm, encoded, ok := strings.Cut(header, " ")
+	if !ok {
+		return nil, errors.New("Upload-Checksum debe tener el formato \"algoritmo checksum\"")
+	}
+	sum, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.New("Upload-Checksum: el checksum no es base64 válido")
+	}
+	return &imageUC.PartChecksum{Algorithm: algorithm, Sum: sum}, nil
+}
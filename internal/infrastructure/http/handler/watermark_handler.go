@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	imageUC "github.com/RodrigoGonzalez78/internal/application/usecase/image"
+	"github.com/RodrigoGonzalez78/internal/infrastructure/http/dto"
+	"github.com/RodrigoGonzalez78/internal/infrastructure/http/middleware"
+)
+
+type WatermarkHandler struct {
+	watermarkUC *imageUC.WatermarkUseCase
+}
+
+func NewWatermarkHandler(watermarkUC *imageUC.WatermarkUseCase) *WatermarkHandler {
+	return &WatermarkHandler{watermarkUC: watermarkUC}
+}
+
+// GetDefault godoc
+// @Summary      Marca de agua por defecto
+// @Description  Devuelve la marca de agua que se aplica a las imágenes públicas del usuario autenticado.
+// @Tags         account
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} dto.WatermarkResponse
+// @Failure      401 {object} dto.ErrorResponse
+// @Failure      404 {object} dto.ErrorResponse
+// @Router       /account/watermark [get]
+func (h *WatermarkHandler) GetDefault(w http.ResponseWriter, r *http.Request) {
+	userData, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || userData == nil {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	settings, err := h.watermarkUC.GetDefault(userData.UserName)
+	if err != nil {
+		if errors.Is(err, imageUC.ErrNoDefaultWatermark) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeWatermark(w, settings)
+}
+
+// SetDefault godoc
+// @Summary      Configura la marca de agua por defecto
+// @Description  Define una imagen PNG propia como marca de agua. Se aplica automáticamente a las imágenes del usuario servidas públicamente (/images/{usuario}/{archivo} y URLs firmadas), salvo a la propia imagen de la marca de agua.
+// @Tags         account
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        body body dto.WatermarkRequest true "Imagen y posición de la marca de agua"
+// @Success      200 {object} dto.WatermarkResponse
+// @Failure      400 {object} dto.ErrorResponse
+// @Failure      401 {object} dto.ErrorResponse
+// @Router       /account/watermark [put]
+func (h *WatermarkHandler) SetDefault(w http.ResponseWriter, r *http.Request) {
+	userData, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || userData == nil {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	var req dto.WatermarkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error al parsear JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	settings, err := h.watermarkUC.SetDefault(userData.UserName, imageUC.WatermarkSettings{
+		ImageID: req.ImageID,
+		Anchor:  req.Anchor,
+		Opacity: req.Opacity,
+		Scale:   req.Scale,
+		Margin:  req.Margin,
+	})
+	if err != nil {
+		var fieldErr *imageUC.FieldError
+		if errors.As(err, &fieldErr) {
+			http.Error(w, "Marca de agua inválida: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeWatermark(w, settings)
+}
+
+// ClearDefault godoc
+// @Summary      Elimina la marca de agua por defecto
+// @Description  Deja de aplicar la marca de agua a las imágenes públicas del usuario autenticado.
+// @Tags         account
+// @Security     BearerAuth
+// @Success      204
+// @Failure      401 {object} dto.ErrorResponse
+// @Router       /account/watermark [delete]
+func (h *WatermarkHandler) ClearDefault(w http.ResponseWriter, r *http.Request) {
+	userData, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || userData == nil {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.watermarkUC.ClearDefault(userData.UserName); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeWatermark(w http.ResponseWriter, settings *imageUC.WatermarkSettings) {
+	resp := dto.WatermarkResponse{
+		ImageID: settings.ImageID,
+		Anchor:  settings.Anchor,
+		Opacity: settings.Opacity,
+		Scale:   settings.Scale,
+	}
+	if settings.Margin != nil {
+		resp.Margin = *settings.Margin
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -4,15 +4,20 @@ import (
 	"context"
 	"net/http"
 
+	"github.com/RodrigoGonzalez78/internal/domain/repository"
 	"github.com/RodrigoGonzalez78/internal/domain/service"
 )
 
 type JWTMiddleware struct {
 	tokenService *service.TokenService
+	revokedRepo  repository.RevokedTokenRepository
 }
 
-func NewJWTMiddleware(tokenService *service.TokenService) *JWTMiddleware {
-	return &JWTMiddleware{tokenService: tokenService}
+func NewJWTMiddleware(tokenService *service.TokenService, revokedRepo repository.RevokedTokenRepository) *JWTMiddleware {
+	return &JWTMiddleware{
+		tokenService: tokenService,
+		revokedRepo:  revokedRepo,
+	}
 }
 
 type contextKey string
@@ -33,6 +38,22 @@ func (m *JWTMiddleware) Authenticate(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
+		// Sin jti no hay forma de revocar el token, así que no se acepta
+		if claims.ID == "" {
+			http.Error(w, "Token inválido: falta el identificador (jti)", http.StatusUnauthorized)
+			return
+		}
+
+		revoked, err := m.revokedRepo.IsRevoked(claims.ID)
+		if err != nil {
+			http.Error(w, "Error al verificar el token", http.StatusInternalServerError)
+			return
+		}
+		if revoked {
+			http.Error(w, "Token revocado", http.StatusUnauthorized)
+			return
+		}
+
 		ctx := context.WithValue(r.Context(), userDataKey, claims)
 		next(w, r.WithContext(ctx))
 	}
@@ -0,0 +1,84 @@
+package gorm
+
+import (
+	"github.com/RodrigoGonzalez78/internal/domain/entity"
+	"github.com/RodrigoGonzalez78/internal/infrastructure/persistence/gorm/models"
+	"gorm.io/gorm"
+)
+
+type BlobRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewBlobRepository(db *gorm.DB) *BlobRepositoryGorm {
+	return &BlobRepositoryGorm{db: db}
+}
+
+func (r *BlobRepositoryGorm) Acquire(blob *entity.Blob) (bool, error) {
+	created := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.BlobModel{}).
+			Where("checksum = ?", blob.Checksum).
+			Update("ref_count", gorm.Expr("ref_count + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			return nil
+		}
+
+		created = true
+		model := r.toModel(blob)
+		model.RefCount = 1
+		return tx.Create(model).Error
+	})
+	return created, err
+}
+
+func (r *BlobRepositoryGorm) Release(checksum string) (int64, error) {
+	var remaining int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var model models.BlobModel
+		if err := tx.Where("checksum = ?", checksum).First(&model).Error; err != nil {
+			return err
+		}
+
+		remaining = model.RefCount - 1
+		if remaining <= 0 {
+			remaining = 0
+			return tx.Delete(&model).Error
+		}
+		return tx.Model(&model).Update("ref_count", remaining).Error
+	})
+	return remaining, err
+}
+
+func (r *BlobRepositoryGorm) FindByChecksum(checksum string) (*entity.Blob, error) {
+	var model models.BlobModel
+	if err := r.db.Where("checksum = ?", checksum).First(&model).Error; err != nil {
+		return nil, err
+	}
+	return r.toEntity(&model), nil
+}
+
+func (r *BlobRepositoryGorm) toModel(blob *entity.Blob) *models.BlobModel {
+	return &models.BlobModel{
+		Checksum:    blob.Checksum,
+		Path:        blob.Path,
+		Size:        blob.Size,
+		ContentType: blob.ContentType,
+		RefCount:    blob.RefCount,
+		CreatedAt:   blob.CreatedAt,
+	}
+}
+
+func (r *BlobRepositoryGorm) toEntity(model *models.BlobModel) *entity.Blob {
+	return &entity.Blob{
+		Checksum:    model.Checksum,
+		Path:        model.Path,
+		Size:        model.Size,
+		ContentType: model.ContentType,
+		RefCount:    model.RefCount,
+		CreatedAt:   model.CreatedAt,
+	}
+}
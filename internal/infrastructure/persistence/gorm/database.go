@@ -23,7 +23,19 @@ func NewDatabase(dbPath string) (*Database, error) {
 }
 
 func (d *Database) Migrate() error {
-	return d.DB.AutoMigrate(&models.UserModel{}, &models.ImageModel{})
+	return d.DB.AutoMigrate(
+		&models.UserModel{},
+		&models.ImageModel{},
+		&models.RefreshTokenModel{},
+		&models.RevokedTokenModel{},
+		&models.TransformJobModel{},
+		&models.FontModel{},
+		&models.ImageMetadataModel{},
+		&models.PresetModel{},
+		&models.RenditionModel{},
+		&models.BlobModel{},
+		&models.ResumableUploadModel{},
+	)
 }
 
 func (d *Database) Close() error {
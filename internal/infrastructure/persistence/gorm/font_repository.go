@@ -0,0 +1,77 @@
+package gorm
+
+import (
+	"github.com/RodrigoGonzalez78/internal/domain/entity"
+	"github.com/RodrigoGonzalez78/internal/infrastructure/persistence/gorm/models"
+	"gorm.io/gorm"
+)
+
+type FontRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewFontRepository(db *gorm.DB) *FontRepositoryGorm {
+	return &FontRepositoryGorm{db: db}
+}
+
+func (r *FontRepositoryGorm) Create(font *entity.Font) error {
+	model := r.toModel(font)
+	if err := r.db.Create(model).Error; err != nil {
+		return err
+	}
+	font.ID = model.ID
+	return nil
+}
+
+func (r *FontRepositoryGorm) FindByID(id int64) (*entity.Font, error) {
+	var model models.FontModel
+	err := r.db.Where("id = ?", id).First(&model).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntity(&model), nil
+}
+
+func (r *FontRepositoryGorm) FindByUser(userName string) ([]entity.Font, error) {
+	var modelsResult []models.FontModel
+	err := r.db.Where("user_name = ?", userName).
+		Order("id").
+		Find(&modelsResult).Error
+	if err != nil {
+		return nil, err
+	}
+
+	fonts := make([]entity.Font, len(modelsResult))
+	for i, model := range modelsResult {
+		fonts[i] = *r.toEntity(&model)
+	}
+	return fonts, nil
+}
+
+func (r *FontRepositoryGorm) Delete(id int64) error {
+	return r.db.Delete(&models.FontModel{}, id).Error
+}
+
+func (r *FontRepositoryGorm) toModel(font *entity.Font) *models.FontModel {
+	return &models.FontModel{
+		ID:        font.ID,
+		UserName:  font.UserName,
+		Name:      font.Name,
+		Family:    font.Family,
+		Path:      font.Path,
+		Size:      font.Size,
+		CreatedAt: font.CreatedAt,
+	}
+}
+
+func (r *FontRepositoryGorm) toEntity(model *models.FontModel) *entity.Font {
+	return &entity.Font{
+		ID:        model.ID,
+		UserName:  model.UserName,
+		Name:      model.Name,
+		Family:    model.Family,
+		Path:      model.Path,
+		Size:      model.Size,
+		CreatedAt: model.CreatedAt,
+	}
+}
@@ -0,0 +1,72 @@
+package gorm
+
+import (
+	"github.com/RodrigoGonzalez78/internal/domain/entity"
+	"github.com/RodrigoGonzalez78/internal/infrastructure/persistence/gorm/models"
+	"gorm.io/gorm"
+)
+
+type ImageMetadataRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewImageMetadataRepository(db *gorm.DB) *ImageMetadataRepositoryGorm {
+	return &ImageMetadataRepositoryGorm{db: db}
+}
+
+func (r *ImageMetadataRepositoryGorm) Save(metadata *entity.ImageMetadata) error {
+	return r.db.Save(r.toModel(metadata)).Error
+}
+
+func (r *ImageMetadataRepositoryGorm) FindByImageID(imageID int64) (*entity.ImageMetadata, error) {
+	var model models.ImageMetadataModel
+	err := r.db.Where("image_id = ?", imageID).First(&model).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntity(&model), nil
+}
+
+func (r *ImageMetadataRepositoryGorm) Delete(imageID int64) error {
+	return r.db.Where("image_id = ?", imageID).Delete(&models.ImageMetadataModel{}).Error
+}
+
+func (r *ImageMetadataRepositoryGorm) toModel(metadata *entity.ImageMetadata) *models.ImageMetadataModel {
+	return &models.ImageMetadataModel{
+		ImageID:      metadata.ImageID,
+		Orientation:  metadata.Orientation,
+		CameraMake:   metadata.CameraMake,
+		CameraModel:  metadata.CameraModel,
+		LensMake:     metadata.LensMake,
+		LensModel:    metadata.LensModel,
+		Software:     metadata.Software,
+		CapturedAt:   metadata.CapturedAt,
+		Latitude:     metadata.Latitude,
+		Longitude:    metadata.Longitude,
+		Altitude:     metadata.Altitude,
+		ExposureTime: metadata.ExposureTime,
+		FNumber:      metadata.FNumber,
+		ISO:          metadata.ISO,
+		FocalLength:  metadata.FocalLength,
+	}
+}
+
+func (r *ImageMetadataRepositoryGorm) toEntity(model *models.ImageMetadataModel) *entity.ImageMetadata {
+	return &entity.ImageMetadata{
+		ImageID:      model.ImageID,
+		Orientation:  model.Orientation,
+		CameraMake:   model.CameraMake,
+		CameraModel:  model.CameraModel,
+		LensMake:     model.LensMake,
+		LensModel:    model.LensModel,
+		Software:     model.Software,
+		CapturedAt:   model.CapturedAt,
+		Latitude:     model.Latitude,
+		Longitude:    model.Longitude,
+		Altitude:     model.Altitude,
+		ExposureTime: model.ExposureTime,
+		FNumber:      model.FNumber,
+		ISO:          model.ISO,
+		FocalLength:  model.FocalLength,
+	}
+}
@@ -1,6 +1,8 @@
 package gorm
 
 import (
+	"time"
+
 	"github.com/RodrigoGonzalez78/internal/domain/entity"
 	"github.com/RodrigoGonzalez78/internal/infrastructure/persistence/gorm/models"
 	"gorm.io/gorm"
@@ -16,12 +18,16 @@ func NewImageRepository(db *gorm.DB) *ImageRepositoryGorm {
 
 func (r *ImageRepositoryGorm) Create(image *entity.Image) error {
 	model := r.toModel(image)
-	return r.db.Create(model).Error
+	if err := r.db.Create(model).Error; err != nil {
+		return err
+	}
+	image.ID = model.ImageID
+	return nil
 }
 
 func (r *ImageRepositoryGorm) FindByID(id int64) (*entity.Image, error) {
 	var model models.ImageModel
-	err := r.db.Where("image_id = ?", id).First(&model).Error
+	err := r.db.Where("image_id = ? AND pending_delete_at IS NULL", id).First(&model).Error
 	if err != nil {
 		return nil, err
 	}
@@ -33,7 +39,8 @@ func (r *ImageRepositoryGorm) FindByUser(userName string, page, limit int) ([]en
 	offset := (page - 1) * limit
 
 	err := r.db.Model(&models.ImageModel{}).
-		Where("user_name = ?", userName).
+		Where("user_name = ? AND pending_delete_at IS NULL", userName).
+		Order("image_id").
 		Limit(limit).
 		Offset(offset).
 		Find(&modelsResult).Error
@@ -43,7 +50,7 @@ func (r *ImageRepositoryGorm) FindByUser(userName string, page, limit int) ([]en
 
 	var total int64
 	err = r.db.Model(&models.ImageModel{}).
-		Where("user_name = ?", userName).
+		Where("user_name = ? AND pending_delete_at IS NULL", userName).
 		Count(&total).Error
 	if err != nil {
 		return nil, 0, err
@@ -57,30 +64,172 @@ func (r *ImageRepositoryGorm) FindByUser(userName string, page, limit int) ([]en
 	return images, total, nil
 }
 
+func (r *ImageRepositoryGorm) FindByName(userName, name string) (*entity.Image, error) {
+	var model models.ImageModel
+	err := r.db.Where("user_name = ? AND name = ? AND pending_delete_at IS NULL", userName, name).
+		Order("image_id").
+		First(&model).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntity(&model), nil
+}
+
+func (r *ImageRepositoryGorm) FindChildren(parentID int64) ([]entity.Image, error) {
+	var modelsResult []models.ImageModel
+	err := r.db.Where("parent_id = ? AND pending_delete_at IS NULL", parentID).
+		Order("image_id").
+		Find(&modelsResult).Error
+	if err != nil {
+		return nil, err
+	}
+
+	images := make([]entity.Image, len(modelsResult))
+	for i, model := range modelsResult {
+		images[i] = *r.toEntity(&model)
+	}
+
+	return images, nil
+}
+
+func (r *ImageRepositoryGorm) FindHashedByUser(userName string) ([]entity.Image, error) {
+	var modelsResult []models.ImageModel
+	err := r.db.Where("user_name = ? AND perceptual_hash <> '' AND pending_delete_at IS NULL", userName).
+		Order("image_id").
+		Find(&modelsResult).Error
+	if err != nil {
+		return nil, err
+	}
+
+	images := make([]entity.Image, len(modelsResult))
+	for i, model := range modelsResult {
+		images[i] = *r.toEntity(&model)
+	}
+
+	return images, nil
+}
+
+func (r *ImageRepositoryGorm) FindUnhashed(afterID int64, limit int) ([]entity.Image, error) {
+	var modelsResult []models.ImageModel
+	err := r.db.Where("image_id > ? AND (perceptual_hash = '' OR perceptual_hash IS NULL) AND pending_delete_at IS NULL", afterID).
+		Order("image_id").
+		Limit(limit).
+		Find(&modelsResult).Error
+	if err != nil {
+		return nil, err
+	}
+
+	images := make([]entity.Image, len(modelsResult))
+	for i, model := range modelsResult {
+		images[i] = *r.toEntity(&model)
+	}
+
+	return images, nil
+}
+
+func (r *ImageRepositoryGorm) UpdatePerceptualHash(id int64, hash string) error {
+	result := r.db.Model(&models.ImageModel{}).
+		Where("image_id = ?", id).
+		Update("perceptual_hash", hash)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *ImageRepositoryGorm) UpdateObject(id int64, path string, size int64) error {
+	result := r.db.Model(&models.ImageModel{}).
+		Where("image_id = ?", id).
+		Updates(map[string]interface{}{"path": path, "size": size})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *ImageRepositoryGorm) MarkPendingDelete(id int64) error {
+	result := r.db.Model(&models.ImageModel{}).
+		Where("image_id = ?", id).
+		Update("pending_delete_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *ImageRepositoryGorm) FindPendingDelete(limit int) ([]entity.Image, error) {
+	var modelsResult []models.ImageModel
+	err := r.db.Where("pending_delete_at IS NOT NULL").
+		Order("pending_delete_at").
+		Limit(limit).
+		Find(&modelsResult).Error
+	if err != nil {
+		return nil, err
+	}
+
+	images := make([]entity.Image, len(modelsResult))
+	for i, model := range modelsResult {
+		images[i] = *r.toEntity(&model)
+	}
+
+	return images, nil
+}
+
+// Delete borra la imagen, sus metadatos y sus versiones. SQLite no aplica
+// las claves foráneas por defecto, así que la cascada se hace explícita.
+func (r *ImageRepositoryGorm) Delete(id int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("image_id = ?", id).Delete(&models.ImageMetadataModel{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("image_id = ?", id).Delete(&models.RenditionModel{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("image_id = ?", id).Delete(&models.ImageModel{}).Error
+	})
+}
+
 func (r *ImageRepositoryGorm) toModel(image *entity.Image) *models.ImageModel {
 	return &models.ImageModel{
-		ImageID:   image.ID,
-		Name:      image.Name,
-		UserName:  image.UserName,
-		Path:      image.Path,
-		Size:      image.Size,
-		Format:    image.Format,
-		Width:     image.Width,
-		Height:    image.Height,
-		CreatedAt: image.CreatedAt,
+		ImageID:         image.ID,
+		Name:            image.Name,
+		UserName:        image.UserName,
+		Path:            image.Path,
+		Size:            image.Size,
+		Format:          image.Format,
+		Width:           image.Width,
+		Height:          image.Height,
+		CreatedAt:       image.CreatedAt,
+		PendingDeleteAt: image.PendingDeleteAt,
+		ParentID:        image.ParentID,
+		Operations:      image.Operations,
+		PerceptualHash:  image.PerceptualHash,
 	}
 }
 
 func (r *ImageRepositoryGorm) toEntity(model *models.ImageModel) *entity.Image {
 	return &entity.Image{
-		ID:        model.ImageID,
-		Name:      model.Name,
-		UserName:  model.UserName,
-		Path:      model.Path,
-		Size:      model.Size,
-		Format:    model.Format,
-		Width:     model.Width,
-		Height:    model.Height,
-		CreatedAt: model.CreatedAt,
+		ID:              model.ImageID,
+		Name:            model.Name,
+		UserName:        model.UserName,
+		Path:            model.Path,
+		Size:            model.Size,
+		Format:          model.Format,
+		Width:           model.Width,
+		Height:          model.Height,
+		CreatedAt:       model.CreatedAt,
+		PendingDeleteAt: model.PendingDeleteAt,
+		ParentID:        model.ParentID,
+		Operations:      model.Operations,
+		PerceptualHash:  model.PerceptualHash,
 	}
 }
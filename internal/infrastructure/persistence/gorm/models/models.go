@@ -3,8 +3,10 @@ package models
 import "time"
 
 type UserModel struct {
-	UserName string `gorm:"primaryKey"`
-	Password string `gorm:"not null"`
+	UserName         string `gorm:"primaryKey"`
+	Password         string `gorm:"not null"`
+	DefaultWatermark string
+	MetadataPolicy   string
 }
 
 func (UserModel) TableName() string {
@@ -12,18 +14,180 @@ func (UserModel) TableName() string {
 }
 
 type ImageModel struct {
-	ImageID   int64     `gorm:"primaryKey;autoIncrement"`
-	Name      string    `gorm:"not null"`
+	ImageID         int64     `gorm:"primaryKey;autoIncrement"`
+	Name            string    `gorm:"not null"`
+	UserName        string    `gorm:"not null;index"`
+	User            UserModel `gorm:"foreignKey:UserName;references:UserName;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Path            string    `gorm:"not null"`
+	Size            int64     `gorm:"not null"`
+	Format          string    `gorm:"not null"`
+	Width           int
+	Height          int
+	CreatedAt       time.Time  `gorm:"autoCreateTime"`
+	PendingDeleteAt *time.Time `gorm:"index"`
+	// ParentID no tiene clave foránea: las imágenes derivadas sobreviven a
+	// la eliminación de su padre
+	ParentID       *int64 `gorm:"index"`
+	Operations     string
+	PerceptualHash string
+}
+
+func (ImageModel) TableName() string {
+	return "images"
+}
+
+type RefreshTokenModel struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement"`
+	TokenHash string    `gorm:"not null;uniqueIndex"`
 	UserName  string    `gorm:"not null;index"`
 	User      UserModel `gorm:"foreignKey:UserName;references:UserName;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	FamilyID  string    `gorm:"not null;index"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+}
+
+func (RefreshTokenModel) TableName() string {
+	return "refresh_tokens"
+}
+
+type RevokedTokenModel struct {
+	JTI       string    `gorm:"primaryKey"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
+
+func (RevokedTokenModel) TableName() string {
+	return "revoked_tokens"
+}
+
+type TransformJobModel struct {
+	ID         int64     `gorm:"primaryKey;autoIncrement"`
+	UserName   string    `gorm:"not null;index"`
+	User       UserModel `gorm:"foreignKey:UserName;references:UserName;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	ImageID    int64     `gorm:"not null;index"`
+	Operations string    `gorm:"not null"`
+	Format     string
+	Encoding   string
+	Status     string `gorm:"not null;index"`
+	ResultPath string
+	Error      string
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	StartedAt  *time.Time
+	FinishedAt *time.Time `gorm:"index"`
+}
+
+func (TransformJobModel) TableName() string {
+	return "transform_jobs"
+}
+
+type FontModel struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement"`
+	UserName  string    `gorm:"not null;index"`
+	User      UserModel `gorm:"foreignKey:UserName;references:UserName;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Name      string    `gorm:"not null"`
+	Family    string
 	Path      string    `gorm:"not null"`
 	Size      int64     `gorm:"not null"`
-	Format    string    `gorm:"not null"`
-	Width     int
-	Height    int
 	CreatedAt time.Time `gorm:"autoCreateTime"`
 }
 
-func (ImageModel) TableName() string {
-	return "images"
+func (FontModel) TableName() string {
+	return "fonts"
+}
+
+// ImageMetadataModel datos EXIF de una imagen; se borra junto con la imagen
+type ImageMetadataModel struct {
+	ImageID      int64      `gorm:"primaryKey;autoIncrement:false"`
+	Image        ImageModel `gorm:"foreignKey:ImageID;references:ImageID;constraint:OnDelete:CASCADE"`
+	Orientation  int
+	CameraMake   string
+	CameraModel  string
+	LensMake     string
+	LensModel    string
+	Software     string
+	CapturedAt   *time.Time
+	Latitude     *float64
+	Longitude    *float64
+	Altitude     *float64
+	ExposureTime float64
+	FNumber      float64
+	ISO          int
+	FocalLength  float64
+}
+
+func (ImageMetadataModel) TableName() string {
+	return "image_metadata"
+}
+
+// PresetModel pipeline con nombre; el nombre es único por usuario
+type PresetModel struct {
+	ID         int64     `gorm:"primaryKey;autoIncrement"`
+	UserName   string    `gorm:"not null;uniqueIndex:idx_presets_user_name"`
+	User       UserModel `gorm:"foreignKey:UserName;references:UserName;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Name       string    `gorm:"not null;uniqueIndex:idx_presets_user_name"`
+	Operations string    `gorm:"not null"`
+	Format     string
+	Encoding   string
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime"`
+}
+
+func (PresetModel) TableName() string {
+	return "presets"
+}
+
+// RenditionModel versión redimensionada de una imagen; se borra junto con la
+// imagen
+type RenditionModel struct {
+	ID        int64      `gorm:"primaryKey;autoIncrement"`
+	ImageID   int64      `gorm:"not null;index"`
+	Image     ImageModel `gorm:"foreignKey:ImageID;references:ImageID;constraint:OnDelete:CASCADE"`
+	Width     int        `gorm:"not null"`
+	Height    int        `gorm:"not null"`
+	Format    string     `gorm:"not null"`
+	Path      string     `gorm:"not null"`
+	Size      int64      `gorm:"not null"`
+	CreatedAt time.Time  `gorm:"autoCreateTime"`
+}
+
+func (RenditionModel) TableName() string {
+	return "renditions"
+}
+
+// BlobModel contenido guardado una sola vez bajo su SHA-256, con la cantidad
+// de imágenes que lo referencian
+type BlobModel struct {
+	Checksum    string    `gorm:"primaryKey;size:64"`
+	Path        string    `gorm:"not null"`
+	Size        int64     `gorm:"not null"`
+	ContentType string    `gorm:"not null"`
+	RefCount    int64     `gorm:"not null"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+}
+
+func (BlobModel) TableName() string {
+	return "blobs"
+}
+
+// ResumableUploadModel subida reanudable en curso. Parts guarda los offsets
+// de las partes separados por comas.
+type ResumableUploadModel struct {
+	ID                 string    `gorm:"primaryKey;size:36"`
+	UserName           string    `gorm:"not null;index"`
+	User               UserModel `gorm:"foreignKey:UserName;references:UserName;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Length             int64     `gorm:"not null"`
+	Offset             int64     `gorm:"column:upload_offset;not null"`
+	Parts              string
+	Metadata           string
+	MetadataPolicy     string
+	OnDuplicate        string
+	DuplicateThreshold int
+	ImageID            *int64
+	CreatedAt          time.Time `gorm:"autoCreateTime"`
+	ExpiresAt          time.Time `gorm:"not null;index"`
+}
+
+func (ResumableUploadModel) TableName() string {
+	return "resumable_uploads"
 }
@@ -0,0 +1,107 @@
+package gorm
+
+import (
+	"github.com/RodrigoGonzalez78/internal/domain/entity"
+	"github.com/RodrigoGonzalez78/internal/infrastructure/persistence/gorm/models"
+	"gorm.io/gorm"
+)
+
+type PresetRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewPresetRepository(db *gorm.DB) *PresetRepositoryGorm {
+	return &PresetRepositoryGorm{db: db}
+}
+
+func (r *PresetRepositoryGorm) Create(preset *entity.Preset) error {
+	model := r.toModel(preset)
+	if err := r.db.Create(model).Error; err != nil {
+		return err
+	}
+	preset.ID = model.ID
+	return nil
+}
+
+func (r *PresetRepositoryGorm) FindByID(id int64) (*entity.Preset, error) {
+	var model models.PresetModel
+	err := r.db.Where("id = ?", id).First(&model).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntity(&model), nil
+}
+
+func (r *PresetRepositoryGorm) FindByName(userName, name string) (*entity.Preset, error) {
+	var model models.PresetModel
+	err := r.db.Where("user_name = ? AND name = ?", userName, name).First(&model).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntity(&model), nil
+}
+
+func (r *PresetRepositoryGorm) FindByUser(userName string) ([]entity.Preset, error) {
+	var modelsResult []models.PresetModel
+	err := r.db.Where("user_name = ?", userName).
+		Order("name").
+		Find(&modelsResult).Error
+	if err != nil {
+		return nil, err
+	}
+
+	presets := make([]entity.Preset, len(modelsResult))
+	for i, model := range modelsResult {
+		presets[i] = *r.toEntity(&model)
+	}
+	return presets, nil
+}
+
+func (r *PresetRepositoryGorm) Update(preset *entity.Preset) error {
+	result := r.db.Model(&models.PresetModel{}).
+		Where("id = ?", preset.ID).
+		Updates(map[string]interface{}{
+			"name":       preset.Name,
+			"operations": preset.Operations,
+			"format":     preset.Format,
+			"encoding":   preset.Encoding,
+			"updated_at": preset.UpdatedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *PresetRepositoryGorm) Delete(id int64) error {
+	return r.db.Delete(&models.PresetModel{}, id).Error
+}
+
+func (r *PresetRepositoryGorm) toModel(preset *entity.Preset) *models.PresetModel {
+	return &models.PresetModel{
+		ID:         preset.ID,
+		UserName:   preset.UserName,
+		Name:       preset.Name,
+		Operations: preset.Operations,
+		Format:     preset.Format,
+		Encoding:   preset.Encoding,
+		CreatedAt:  preset.CreatedAt,
+		UpdatedAt:  preset.UpdatedAt,
+	}
+}
+
+func (r *PresetRepositoryGorm) toEntity(model *models.PresetModel) *entity.Preset {
+	return &entity.Preset{
+		ID:         model.ID,
+		UserName:   model.UserName,
+		Name:       model.Name,
+		Operations: model.Operations,
+		Format:     model.Format,
+		Encoding:   model.Encoding,
+		CreatedAt:  model.CreatedAt,
+		UpdatedAt:  model.UpdatedAt,
+	}
+}
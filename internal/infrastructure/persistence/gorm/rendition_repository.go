@@ -0,0 +1,100 @@
+package gorm
+
+import (
+	"errors"
+
+	"github.com/RodrigoGonzalez78/internal/domain/entity"
+	"github.com/RodrigoGonzalez78/internal/infrastructure/persistence/gorm/models"
+	"gorm.io/gorm"
+)
+
+type RenditionRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewRenditionRepository(db *gorm.DB) *RenditionRepositoryGorm {
+	return &RenditionRepositoryGorm{db: db}
+}
+
+// errImageGone revierte la transacción de ReplaceForImage cuando la imagen
+// ya no existe
+var errImageGone = errors.New("la imagen ya no existe")
+
+func (r *RenditionRepositoryGorm) ReplaceForImage(imageID int64, renditions []entity.Rendition) (bool, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// El borrado va primero para que la transacción tome el bloqueo de
+		// escritura antes de comprobar la imagen: una eliminación concurrente
+		// termina antes o después, nunca entre la comprobación y el insert
+		if err := tx.Where("image_id = ?", imageID).Delete(&models.RenditionModel{}).Error; err != nil {
+			return err
+		}
+
+		var count int64
+		err := tx.Model(&models.ImageModel{}).
+			Where("image_id = ? AND pending_delete_at IS NULL", imageID).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return errImageGone
+		}
+
+		if len(renditions) == 0 {
+			return nil
+		}
+
+		modelsToCreate := make([]models.RenditionModel, len(renditions))
+		for i := range renditions {
+			modelsToCreate[i] = *r.toModel(&renditions[i])
+			modelsToCreate[i].ImageID = imageID
+		}
+		return tx.Create(&modelsToCreate).Error
+	})
+	if errors.Is(err, errImageGone) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *RenditionRepositoryGorm) FindByImageID(imageID int64) ([]entity.Rendition, error) {
+	var modelsResult []models.RenditionModel
+	err := r.db.Where("image_id = ?", imageID).
+		Order("width, format").
+		Find(&modelsResult).Error
+	if err != nil {
+		return nil, err
+	}
+
+	renditions := make([]entity.Rendition, len(modelsResult))
+	for i, model := range modelsResult {
+		renditions[i] = *r.toEntity(&model)
+	}
+	return renditions, nil
+}
+
+func (r *RenditionRepositoryGorm) toModel(rendition *entity.Rendition) *models.RenditionModel {
+	return &models.RenditionModel{
+		ID:        rendition.ID,
+		ImageID:   rendition.ImageID,
+		Width:     rendition.Width,
+		Height:    rendition.Height,
+		Format:    rendition.Format,
+		Path:      rendition.Path,
+		Size:      rendition.Size,
+		CreatedAt: rendition.CreatedAt,
+	}
+}
+
+func (r *RenditionRepositoryGorm) toEntity(model *models.RenditionModel) *entity.Rendition {
+	return &entity.Rendition{
+		ID:        model.ID,
+		ImageID:   model.ImageID,
+		Width:     model.Width,
+		Height:    model.Height,
+		Format:    model.Format,
+		Path:      model.Path,
+		Size:      model.Size,
+		CreatedAt: model.CreatedAt,
+	}
+}
@@ -0,0 +1,133 @@
+package gorm
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/RodrigoGonzalez78/internal/domain/entity"
+	"github.com/RodrigoGonzalez78/internal/infrastructure/persistence/gorm/models"
+	"gorm.io/gorm"
+)
+
+type ResumableUploadRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewResumableUploadRepository(db *gorm.DB) *ResumableUploadRepositoryGorm {
+	return &ResumableUploadRepositoryGorm{db: db}
+}
+
+func (r *ResumableUploadRepositoryGorm) Create(upload *entity.ResumableUpload) error {
+	return r.db.Create(r.toModel(upload)).Error
+}
+
+func (r *ResumableUploadRepositoryGorm) FindByID(id string) (*entity.ResumableUpload, error) {
+	var model models.ResumableUploadModel
+	if err := r.db.Where("id = ?", id).First(&model).Error; err != nil {
+		return nil, err
+	}
+	return r.toEntity(&model), nil
+}
+
+func (r *ResumableUploadRepositoryGorm) Advance(upload *entity.ResumableUpload, from int64) (bool, error) {
+	result := r.db.Model(&models.ResumableUploadModel{}).
+		Where("id = ? AND upload_offset = ?", upload.ID, from).
+		Updates(map[string]interface{}{
+			"upload_offset": upload.Offset,
+			"parts":         formatParts(upload.Parts),
+			"expires_at":    upload.ExpiresAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *ResumableUploadRepositoryGorm) Complete(id string, imageID int64) error {
+	result := r.db.Model(&models.ResumableUploadModel{}).
+		Where("id = ?", id).
+		Update("image_id", imageID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *ResumableUploadRepositoryGorm) Delete(id string) error {
+	return r.db.Where("id = ?", id).Delete(&models.ResumableUploadModel{}).Error
+}
+
+func (r *ResumableUploadRepositoryGorm) FindExpired(before time.Time, limit int) ([]entity.ResumableUpload, error) {
+	var modelsResult []models.ResumableUploadModel
+	err := r.db.Where("expires_at < ?", before).
+		Order("expires_at").
+		Limit(limit).
+		Find(&modelsResult).Error
+	if err != nil {
+		return nil, err
+	}
+
+	uploads := make([]entity.ResumableUpload, len(modelsResult))
+	for i, model := range modelsResult {
+		uploads[i] = *r.toEntity(&model)
+	}
+	return uploads, nil
+}
+
+func (r *ResumableUploadRepositoryGorm) toModel(upload *entity.ResumableUpload) *models.ResumableUploadModel {
+	return &models.ResumableUploadModel{
+		ID:                 upload.ID,
+		UserName:           upload.UserName,
+		Length:             upload.Length,
+		Offset:             upload.Offset,
+		Parts:              formatParts(upload.Parts),
+		Metadata:           upload.Metadata,
+		MetadataPolicy:     upload.MetadataPolicy,
+		OnDuplicate:        upload.OnDuplicate,
+		DuplicateThreshold: upload.DuplicateThreshold,
+		ImageID:            upload.ImageID,
+		CreatedAt:          upload.CreatedAt,
+		ExpiresAt:          upload.ExpiresAt,
+	}
+}
+
+func (r *ResumableUploadRepositoryGorm) toEntity(model *models.ResumableUploadModel) *entity.ResumableUpload {
+	return &entity.ResumableUpload{
+		ID:                 model.ID,
+		UserName:           model.UserName,
+		Length:             model.Length,
+		Offset:             model.Offset,
+		Parts:              parseParts(model.Parts),
+		Metadata:           model.Metadata,
+		MetadataPolicy:     model.MetadataPolicy,
+		OnDuplicate:        model.OnDuplicate,
+		DuplicateThreshold: model.DuplicateThreshold,
+		ImageID:            model.ImageID,
+		CreatedAt:          model.CreatedAt,
+		ExpiresAt:          model.ExpiresAt,
+	}
+}
+
+func formatParts(parts []int64) string {
+	values := make([]string, len(parts))
+	for i, offset := range parts {
+		values[i] = strconv.FormatInt(offset, 10)
+	}
+	return strings.Join(values, ",")
+}
+
+// parseParts ignora los valores que no son números; solo los escribe
+// formatParts
+func parseParts(value string) []int64 {
+	var parts []int64
+	for _, item := range strings.Split(value, ",") {
+		if offset, err := strconv.ParseInt(item, 10, 64); err == nil {
+			parts = append(parts, offset)
+		}
+	}
+	return parts
+}
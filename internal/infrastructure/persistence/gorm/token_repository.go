@@ -0,0 +1,110 @@
+package gorm
+
+import (
+	"time"
+
+	"github.com/RodrigoGonzalez78/internal/domain/entity"
+	"github.com/RodrigoGonzalez78/internal/infrastructure/persistence/gorm/models"
+	"gorm.io/gorm"
+)
+
+type RefreshTokenRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) *RefreshTokenRepositoryGorm {
+	return &RefreshTokenRepositoryGorm{db: db}
+}
+
+func (r *RefreshTokenRepositoryGorm) Create(token *entity.RefreshToken) error {
+	// Aprovechar la escritura para limpiar tokens que ya expiraron
+	if err := r.db.Where("expires_at < ?", time.Now()).Delete(&models.RefreshTokenModel{}).Error; err != nil {
+		return err
+	}
+
+	model := r.toModel(token)
+	if err := r.db.Create(model).Error; err != nil {
+		return err
+	}
+	token.ID = model.ID
+	return nil
+}
+
+func (r *RefreshTokenRepositoryGorm) FindByHash(tokenHash string) (*entity.RefreshToken, error) {
+	var model models.RefreshTokenModel
+	err := r.db.Where("token_hash = ?", tokenHash).First(&model).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntity(&model), nil
+}
+
+func (r *RefreshTokenRepositoryGorm) MarkUsed(id int64) (bool, error) {
+	// La condición used_at IS NULL hace que solo una rotación concurrente gane
+	result := r.db.Model(&models.RefreshTokenModel{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *RefreshTokenRepositoryGorm) RevokeFamily(familyID string) error {
+	return r.db.Model(&models.RefreshTokenModel{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *RefreshTokenRepositoryGorm) toModel(token *entity.RefreshToken) *models.RefreshTokenModel {
+	return &models.RefreshTokenModel{
+		ID:        token.ID,
+		TokenHash: token.TokenHash,
+		UserName:  token.UserName,
+		FamilyID:  token.FamilyID,
+		ExpiresAt: token.ExpiresAt,
+		CreatedAt: token.CreatedAt,
+		UsedAt:    token.UsedAt,
+		RevokedAt: token.RevokedAt,
+	}
+}
+
+func (r *RefreshTokenRepositoryGorm) toEntity(model *models.RefreshTokenModel) *entity.RefreshToken {
+	return &entity.RefreshToken{
+		ID:        model.ID,
+		TokenHash: model.TokenHash,
+		UserName:  model.UserName,
+		FamilyID:  model.FamilyID,
+		ExpiresAt: model.ExpiresAt,
+		CreatedAt: model.CreatedAt,
+		UsedAt:    model.UsedAt,
+		RevokedAt: model.RevokedAt,
+	}
+}
+
+type RevokedTokenRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewRevokedTokenRepository(db *gorm.DB) *RevokedTokenRepositoryGorm {
+	return &RevokedTokenRepositoryGorm{db: db}
+}
+
+func (r *RevokedTokenRepositoryGorm) Revoke(jti string, expiresAt time.Time) error {
+	// Un jti expirado ya no puede usarse, así que no hace falta recordarlo
+	if err := r.db.Where("expires_at < ?", time.Now()).Delete(&models.RevokedTokenModel{}).Error; err != nil {
+		return err
+	}
+
+	model := &models.RevokedTokenModel{JTI: jti, ExpiresAt: expiresAt}
+	return r.db.Save(model).Error
+}
+
+func (r *RevokedTokenRepositoryGorm) IsRevoked(jti string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.RevokedTokenModel{}).Where("jti = ?", jti).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
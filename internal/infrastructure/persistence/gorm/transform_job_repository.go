@@ -0,0 +1,164 @@
+package gorm
+
+import (
+	"time"
+
+	"github.com/RodrigoGonzalez78/internal/domain/entity"
+	"github.com/RodrigoGonzalez78/internal/infrastructure/persistence/gorm/models"
+	"gorm.io/gorm"
+)
+
+type TransformJobRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewTransformJobRepository(db *gorm.DB) *TransformJobRepositoryGorm {
+	return &TransformJobRepositoryGorm{db: db}
+}
+
+func (r *TransformJobRepositoryGorm) Create(job *entity.TransformJob) error {
+	model := r.toModel(job)
+	if err := r.db.Create(model).Error; err != nil {
+		return err
+	}
+	job.ID = model.ID
+	return nil
+}
+
+func (r *TransformJobRepositoryGorm) FindByID(id int64) (*entity.TransformJob, error) {
+	var model models.TransformJobModel
+	err := r.db.Where("id = ?", id).First(&model).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntity(&model), nil
+}
+
+func (r *TransformJobRepositoryGorm) FindQueued(limit int) ([]entity.TransformJob, error) {
+	var modelsResult []models.TransformJobModel
+	err := r.db.Where("status = ?", string(entity.JobQueued)).
+		Order("id").
+		Limit(limit).
+		Find(&modelsResult).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntities(modelsResult), nil
+}
+
+func (r *TransformJobRepositoryGorm) MarkRunning(id int64) (bool, error) {
+	result := r.db.Model(&models.TransformJobModel{}).
+		Where("id = ? AND status = ?", id, string(entity.JobQueued)).
+		Updates(map[string]interface{}{
+			"status":     string(entity.JobRunning),
+			"started_at": time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *TransformJobRepositoryGorm) Finish(job *entity.TransformJob) (bool, error) {
+	result := r.db.Model(&models.TransformJobModel{}).
+		Where("id = ? AND status = ?", job.ID, string(entity.JobRunning)).
+		Updates(map[string]interface{}{
+			"status":      string(job.Status),
+			"result_path": job.ResultPath,
+			"error":       job.Error,
+			"finished_at": job.FinishedAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *TransformJobRepositoryGorm) Cancel(id int64) (bool, error) {
+	result := r.db.Model(&models.TransformJobModel{}).
+		Where("id = ? AND status IN ?", id, []string{string(entity.JobQueued), string(entity.JobRunning)}).
+		Updates(map[string]interface{}{
+			"status":      string(entity.JobCanceled),
+			"finished_at": time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *TransformJobRepositoryGorm) RequeueRunning() (int64, error) {
+	result := r.db.Model(&models.TransformJobModel{}).
+		Where("status = ?", string(entity.JobRunning)).
+		Updates(map[string]interface{}{
+			"status":     string(entity.JobQueued),
+			"started_at": nil,
+		})
+	return result.RowsAffected, result.Error
+}
+
+func (r *TransformJobRepositoryGorm) FindByImage(imageID int64) ([]entity.TransformJob, error) {
+	var modelsResult []models.TransformJobModel
+	if err := r.db.Where("image_id = ?", imageID).Order("id").Find(&modelsResult).Error; err != nil {
+		return nil, err
+	}
+	return r.toEntities(modelsResult), nil
+}
+
+func (r *TransformJobRepositoryGorm) FindFinishedBefore(before time.Time, limit int) ([]entity.TransformJob, error) {
+	var modelsResult []models.TransformJobModel
+	err := r.db.Where("finished_at < ?", before).
+		Order("finished_at").
+		Limit(limit).
+		Find(&modelsResult).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntities(modelsResult), nil
+}
+
+func (r *TransformJobRepositoryGorm) Delete(id int64) error {
+	return r.db.Delete(&models.TransformJobModel{}, id).Error
+}
+
+func (r *TransformJobRepositoryGorm) toEntities(modelsResult []models.TransformJobModel) []entity.TransformJob {
+	jobs := make([]entity.TransformJob, len(modelsResult))
+	for i, model := range modelsResult {
+		jobs[i] = *r.toEntity(&model)
+	}
+	return jobs
+}
+
+func (r *TransformJobRepositoryGorm) toModel(job *entity.TransformJob) *models.TransformJobModel {
+	return &models.TransformJobModel{
+		ID:         job.ID,
+		UserName:   job.UserName,
+		ImageID:    job.ImageID,
+		Operations: job.Operations,
+		Format:     job.Format,
+		Encoding:   job.Encoding,
+		Status:     string(job.Status),
+		ResultPath: job.ResultPath,
+		Error:      job.Error,
+		CreatedAt:  job.CreatedAt,
+		StartedAt:  job.StartedAt,
+		FinishedAt: job.FinishedAt,
+	}
+}
+
+func (r *TransformJobRepositoryGorm) toEntity(model *models.TransformJobModel) *entity.TransformJob {
+	return &entity.TransformJob{
+		ID:         model.ID,
+		UserName:   model.UserName,
+		ImageID:    model.ImageID,
+		Operations: model.Operations,
+		Format:     model.Format,
+		Encoding:   model.Encoding,
+		Status:     entity.JobStatus(model.Status),
+		ResultPath: model.ResultPath,
+		Error:      model.Error,
+		CreatedAt:  model.CreatedAt,
+		StartedAt:  model.StartedAt,
+		FinishedAt: model.FinishedAt,
+	}
+}
@@ -37,16 +37,46 @@ func (r *UserRepositoryGorm) ExistsByUserName(userName string) (bool, error) {
 	return count > 0, nil
 }
 
+func (r *UserRepositoryGorm) UpdateDefaultWatermark(userName, watermark string) error {
+	result := r.db.Model(&models.UserModel{}).
+		Where("user_name = ?", userName).
+		Update("default_watermark", watermark)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *UserRepositoryGorm) UpdateMetadataPolicy(userName, policy string) error {
+	result := r.db.Model(&models.UserModel{}).
+		Where("user_name = ?", userName).
+		Update("metadata_policy", policy)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
 func (r *UserRepositoryGorm) toModel(user *entity.User) *models.UserModel {
 	return &models.UserModel{
-		UserName: user.UserName,
-		Password: user.Password,
+		UserName:         user.UserName,
+		Password:         user.Password,
+		DefaultWatermark: user.DefaultWatermark,
+		MetadataPolicy:   user.MetadataPolicy,
 	}
 }
 
 func (r *UserRepositoryGorm) toEntity(model *models.UserModel) *entity.User {
 	return &entity.User{
-		UserName: model.UserName,
-		Password: model.Password,
+		UserName:         model.UserName,
+		Password:         model.Password,
+		DefaultWatermark: model.DefaultWatermark,
+		MetadataPolicy:   model.MetadataPolicy,
 	}
 }
@@ -0,0 +1,203 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/RodrigoGonzalez78/internal/domain/repository"
+)
+
+const tempPattern = ".upload-*.tmp"
+
+// FileStorage guarda los objetos como archivos bajo un directorio raíz
+type FileStorage struct {
+	root string
+}
+
+func NewFileStorage(root string) (*FileStorage, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &FileStorage{root: root}, nil
+}
+
+// Upload escribe en un archivo temporal del mismo directorio y lo renombra,
+// de modo que los lectores nunca ven un archivo a medio escribir. El
+// contenido se copia por bloques sin cargarlo entero en memoria
+func (s *FileStorage) Upload(ctx context.Context, objectPath string, reader io.Reader, size int64, contentType string) error {
+	target, err := s.resolve(objectPath)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(target)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, tempPattern)
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+
+	written, err := io.Copy(tmp, reader)
+	if err == nil && size != repository.UnknownSize && written != size {
+		err = fmt.Errorf("se esperaban %d bytes y se leyeron %d", size, written)
+	}
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+
+	if err := os.Rename(tmpName, target); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	return nil
+}
+
+func (s *FileStorage) Get(ctx context.Context, objectPath string) (io.ReadCloser, error) {
+	target, err := s.resolve(objectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(target)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return file, nil
+}
+
+func (s *FileStorage) Stat(ctx context.Context, objectPath string) (*repository.ObjectInfo, error) {
+	target, err := s.resolve(objectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(target)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	if info.IsDir() {
+		return nil, repository.ErrObjectNotFound
+	}
+
+	return &repository.ObjectInfo{
+		Path:         objectPath,
+		Size:         info.Size(),
+		ETag:         fmt.Sprintf("%x-%x", info.ModTime().UnixNano(), info.Size()),
+		ContentType:  mime.TypeByExtension(path.Ext(objectPath)),
+		LastModified: info.ModTime(),
+	}, nil
+}
+
+func (s *FileStorage) Move(ctx context.Context, from, to string) error {
+	source, err := s.resolve(from)
+	if err != nil {
+		return err
+	}
+	target, err := s.resolve(to)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	if err := os.Rename(source, target); err != nil {
+		return mapError(err)
+	}
+	return nil
+}
+
+func (s *FileStorage) Delete(ctx context.Context, objectPath string) error {
+	target, err := s.resolve(objectPath)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(target); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *FileStorage) DeletePrefix(ctx context.Context, prefix string) error {
+	// Recorrer solo el directorio más profundo que contiene al prefijo
+	start := s.root
+	if dir := path.Dir(prefix + "x"); dir != "." {
+		resolved, err := s.resolve(dir)
+		if err != nil {
+			return err
+		}
+		start = resolved
+	}
+
+	err := filepath.WalkDir(start, func(current string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.root, current)
+		if err != nil {
+			return err
+		}
+		if !strings.HasPrefix(filepath.ToSlash(rel), prefix) {
+			return nil
+		}
+		if err := os.Remove(current); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// resolve convierte la ruta del objeto en una ruta del sistema de archivos
+// dentro de la raíz
+func (s *FileStorage) resolve(objectPath string) (string, error) {
+	cleaned := path.Clean("/" + objectPath)
+	if cleaned == "/" || strings.Contains(objectPath, "..") {
+		return "", fmt.Errorf("ruta de objeto inválida: %q", objectPath)
+	}
+	return filepath.Join(s.root, filepath.FromSlash(cleaned)), nil
+}
+
+func mapError(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %v", repository.ErrObjectNotFound, err)
+	}
+	return err
+}
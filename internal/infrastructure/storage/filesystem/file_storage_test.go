@@ -0,0 +1,34 @@
+package filesystem_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/RodrigoGonzalez78/internal/domain/repository"
+	"github.com/RodrigoGonzalez78/internal/infrastructure/storage/filesystem"
+	"github.com/RodrigoGonzalez78/internal/infrastructure/storage/storagetest"
+)
+
+func TestFileStorage_Conformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) repository.FileStorage {
+		storage, err := filesystem.NewFileStorage(t.TempDir())
+		if err != nil {
+			t.Fatalf("NewFileStorage() error = %v", err)
+		}
+		return storage
+	})
+}
+
+func TestFileStorage_RejectsPathTraversal(t *testing.T) {
+	storage, err := filesystem.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStorage() error = %v", err)
+	}
+
+	for _, path := range []string{"../fuera.png", "user/../../fuera.png", ""} {
+		if err := storage.Upload(context.Background(), path, strings.NewReader("x"), 1, "image/png"); err == nil {
+			t.Errorf("Upload(%q) debe fallar", path)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/RodrigoGonzalez78/internal/domain/repository"
+)
+
+type object struct {
+	data         []byte
+	contentType  string
+	etag         string
+	lastModified time.Time
+}
+
+// FileStorage guarda los objetos en memoria. Pensado para tests y desarrollo
+// local: el contenido se pierde al reiniciar.
+type FileStorage struct {
+	mu      sync.RWMutex
+	objects map[string]object
+}
+
+func NewFileStorage() *FileStorage {
+	return &FileStorage{objects: make(map[string]object)}
+}
+
+func (s *FileStorage) Upload(ctx context.Context, path string, reader io.Reader, size int64, contentType string) error {
+	stored, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	if size != repository.UnknownSize && int64(len(stored)) != size {
+		return fmt.Errorf("se esperaban %d bytes y se leyeron %d", size, len(stored))
+	}
+	sum := md5.Sum(stored)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.objects[path] = object{
+		data:         stored,
+		contentType:  contentType,
+		etag:         hex.EncodeToString(sum[:]),
+		lastModified: time.Now(),
+	}
+	return nil
+}
+
+func (s *FileStorage) Get(ctx context.Context, path string) (io.ReadCloser, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	obj, ok := s.objects[path]
+	if !ok {
+		return nil, repository.ErrObjectNotFound
+	}
+	// Los datos guardados nunca se modifican, así que se pueden compartir
+	return io.NopCloser(bytes.NewReader(obj.data)), nil
+}
+
+func (s *FileStorage) Stat(ctx context.Context, path string) (*repository.ObjectInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	obj, ok := s.objects[path]
+	if !ok {
+		return nil, repository.ErrObjectNotFound
+	}
+
+	return &repository.ObjectInfo{
+		Path:         path,
+		Size:         int64(len(obj.data)),
+		ETag:         obj.etag,
+		ContentType:  obj.contentType,
+		LastModified: obj.lastModified,
+	}, nil
+}
+
+func (s *FileStorage) Move(ctx context.Context, from, to string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	obj, ok := s.objects[from]
+	if !ok {
+		return repository.ErrObjectNotFound
+	}
+	s.objects[to] = obj
+	delete(s.objects, from)
+	return nil
+}
+
+func (s *FileStorage) Delete(ctx context.Context, path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.objects, path)
+	return nil
+}
+
+func (s *FileStorage) DeletePrefix(ctx context.Context, prefix string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for path := range s.objects {
+		if strings.HasPrefix(path, prefix) {
+			delete(s.objects, path)
+		}
+	}
+	return nil
+}
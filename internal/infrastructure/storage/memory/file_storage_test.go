@@ -0,0 +1,15 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/RodrigoGonzalez78/internal/domain/repository"
+	"github.com/RodrigoGonzalez78/internal/infrastructure/storage/memory"
+	"github.com/RodrigoGonzalez78/internal/infrastructure/storage/storagetest"
+)
+
+func TestFileStorage_Conformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) repository.FileStorage {
+		return memory.NewFileStorage()
+	})
+}
@@ -1,14 +1,19 @@
 package minio
 
 import (
-	"bytes"
 	"context"
+	"fmt"
 	"io"
 
+	"github.com/RodrigoGonzalez78/internal/domain/repository"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
 )
 
+// partSize tamaño de cada parte en subidas multipart. Acota la memoria por
+// subida cuando el largo es desconocido: minio-go bufferiza una parte a la vez
+const partSize = 16 << 20
+
 type FileStorage struct {
 	client     *minio.Client
 	bucketName string
@@ -41,14 +46,82 @@ func NewFileStorage(endpoint, accessKey, secretKey, bucket string, useSSL bool)
 	}, nil
 }
 
-func (fs *FileStorage) Upload(ctx context.Context, path string, data []byte, contentType string) error {
-	reader := bytes.NewReader(data)
-	_, err := fs.client.PutObject(ctx, fs.bucketName, path, reader, int64(len(data)), minio.PutObjectOptions{
+// Upload usa un PUT simple hasta partSize y multipart por encima o cuando
+// el largo es desconocido; si la subida falla minio-go aborta las partes
+func (fs *FileStorage) Upload(ctx context.Context, path string, reader io.Reader, size int64, contentType string) error {
+	_, err := fs.client.PutObject(ctx, fs.bucketName, path, reader, size, minio.PutObjectOptions{
 		ContentType: contentType,
+		PartSize:    partSize,
 	})
 	return err
 }
 
 func (fs *FileStorage) Get(ctx context.Context, path string) (io.ReadCloser, error) {
-	return fs.client.GetObject(ctx, fs.bucketName, path, minio.GetObjectOptions{})
+	object, err := fs.client.GetObject(ctx, fs.bucketName, path, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, mapError(err)
+	}
+
+	// GetObject es perezoso: sin Stat los errores recién aparecen al leer
+	if _, err := object.Stat(); err != nil {
+		object.Close()
+		return nil, mapError(err)
+	}
+
+	return object, nil
+}
+
+// Move copia del lado del servidor y borra el origen: S3 no tiene rename
+func (fs *FileStorage) Move(ctx context.Context, from, to string) error {
+	_, err := fs.client.CopyObject(ctx,
+		minio.CopyDestOptions{Bucket: fs.bucketName, Object: to},
+		minio.CopySrcOptions{Bucket: fs.bucketName, Object: from},
+	)
+	if err != nil {
+		return mapError(err)
+	}
+	return fs.Delete(ctx, from)
+}
+
+func (fs *FileStorage) Delete(ctx context.Context, path string) error {
+	return fs.client.RemoveObject(ctx, fs.bucketName, path, minio.RemoveObjectOptions{})
+}
+
+func (fs *FileStorage) DeletePrefix(ctx context.Context, prefix string) error {
+	objects := fs.client.ListObjects(ctx, fs.bucketName, minio.ListObjectsOptions{
+		Prefix:    prefix,
+		Recursive: true,
+	})
+
+	for object := range objects {
+		if object.Err != nil {
+			return object.Err
+		}
+		if err := fs.Delete(ctx, object.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (fs *FileStorage) Stat(ctx context.Context, path string) (*repository.ObjectInfo, error) {
+	info, err := fs.client.StatObject(ctx, fs.bucketName, path, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, mapError(err)
+	}
+
+	return &repository.ObjectInfo{
+		Path:         path,
+		Size:         info.Size,
+		ETag:         info.ETag,
+		ContentType:  info.ContentType,
+		LastModified: info.LastModified,
+	}, nil
+}
+
+func mapError(err error) error {
+	if minio.ToErrorResponse(err).Code == minio.NoSuchKey {
+		return fmt.Errorf("%w: %v", repository.ErrObjectNotFound, err)
+	}
+	return err
 }
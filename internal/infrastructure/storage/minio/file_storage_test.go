@@ -0,0 +1,41 @@
+package minio_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/RodrigoGonzalez78/internal/domain/repository"
+	"github.com/RodrigoGonzalez78/internal/infrastructure/storage/minio"
+	"github.com/RodrigoGonzalez78/internal/infrastructure/storage/storagetest"
+)
+
+// Requiere un MinIO accesible, por ejemplo:
+//
+//	MINIO_TEST_ENDPOINT=localhost:9000 go test ./internal/infrastructure/storage/minio/
+func TestFileStorage_Conformance(t *testing.T) {
+	endpoint := os.Getenv("MINIO_TEST_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("MINIO_TEST_ENDPOINT no definido; se omite la prueba de conformidad contra MinIO")
+	}
+
+	storagetest.Run(t, func(t *testing.T) repository.FileStorage {
+		storage, err := minio.NewFileStorage(
+			endpoint,
+			envOrDefault("MINIO_TEST_ACCESS_KEY", "minioadmin"),
+			envOrDefault("MINIO_TEST_SECRET_KEY", "minioadmin"),
+			envOrDefault("MINIO_TEST_BUCKET", "storagetest"),
+			false,
+		)
+		if err != nil {
+			t.Fatalf("NewFileStorage() error = %v", err)
+		}
+		return storage
+	})
+}
+
+func envOrDefault(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,281 @@
+// Package storagetest contiene la batería de pruebas de conformidad que todo
+// backend de repository.FileStorage debe pasar.
+package storagetest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/RodrigoGonzalez78/internal/domain/repository"
+)
+
+// Run ejecuta la batería de conformidad. Todas las claves se crean bajo un
+// prefijo único, por lo que puede correr contra un bucket compartido.
+func Run(t *testing.T, newStorage func(t *testing.T) repository.FileStorage) {
+	t.Helper()
+
+	base := fmt.Sprintf("storagetest-%d/", time.Now().UnixNano())
+
+	t.Run("UploadGet", func(t *testing.T) {
+		storage := newStorage(t)
+		ctx := context.Background()
+		path := base + "upload/user/image.png"
+		data := []byte("contenido de prueba")
+
+		if err := upload(ctx, storage, path, data); err != nil {
+			t.Fatalf("Upload() error = %v", err)
+		}
+
+		if got := read(t, storage, path); !bytes.Equal(got, data) {
+			t.Errorf("Get() = %q, want %q", got, data)
+		}
+	})
+
+	t.Run("Overwrite", func(t *testing.T) {
+		storage := newStorage(t)
+		ctx := context.Background()
+		path := base + "overwrite/image.png"
+
+		if err := upload(ctx, storage, path, []byte("versión 1")); err != nil {
+			t.Fatalf("Upload() error = %v", err)
+		}
+		first, err := storage.Stat(ctx, path)
+		if err != nil {
+			t.Fatalf("Stat() error = %v", err)
+		}
+
+		if err := upload(ctx, storage, path, []byte("versión número 2")); err != nil {
+			t.Fatalf("Upload() error = %v", err)
+		}
+		second, err := storage.Stat(ctx, path)
+		if err != nil {
+			t.Fatalf("Stat() error = %v", err)
+		}
+
+		if got := read(t, storage, path); string(got) != "versión número 2" {
+			t.Errorf("Get() = %q, want la segunda versión", got)
+		}
+		if first.ETag == second.ETag {
+			t.Error("el ETag debe cambiar al reemplazar el objeto")
+		}
+	})
+
+	t.Run("Stat", func(t *testing.T) {
+		storage := newStorage(t)
+		ctx := context.Background()
+		path := base + "stat/image.png"
+		data := []byte("12345")
+
+		if err := upload(ctx, storage, path, data); err != nil {
+			t.Fatalf("Upload() error = %v", err)
+		}
+
+		info, err := storage.Stat(ctx, path)
+		if err != nil {
+			t.Fatalf("Stat() error = %v", err)
+		}
+		if info.Size != int64(len(data)) {
+			t.Errorf("Stat().Size = %d, want %d", info.Size, len(data))
+		}
+		if info.ETag == "" {
+			t.Error("Stat().ETag vacío")
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		storage := newStorage(t)
+		ctx := context.Background()
+		path := base + "missing/image.png"
+
+		if _, err := storage.Stat(ctx, path); !errors.Is(err, repository.ErrObjectNotFound) {
+			t.Errorf("Stat() error = %v, want ErrObjectNotFound", err)
+		}
+		if reader, err := storage.Get(ctx, path); !errors.Is(err, repository.ErrObjectNotFound) {
+			if reader != nil {
+				reader.Close()
+			}
+			t.Errorf("Get() error = %v, want ErrObjectNotFound", err)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		storage := newStorage(t)
+		ctx := context.Background()
+		path := base + "delete/image.png"
+
+		if err := upload(ctx, storage, path, []byte("x")); err != nil {
+			t.Fatalf("Upload() error = %v", err)
+		}
+		if err := storage.Delete(ctx, path); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if _, err := storage.Stat(ctx, path); !errors.Is(err, repository.ErrObjectNotFound) {
+			t.Errorf("Stat() tras Delete error = %v, want ErrObjectNotFound", err)
+		}
+
+		// Eliminar un objeto inexistente no es un error
+		if err := storage.Delete(ctx, path); err != nil {
+			t.Errorf("Delete() de objeto inexistente error = %v", err)
+		}
+	})
+
+	t.Run("DeletePrefix", func(t *testing.T) {
+		storage := newStorage(t)
+		ctx := context.Background()
+		prefix := base + "prefix/"
+
+		keep := []string{prefix + "otro/a.png", prefix + "cache2/b.png"}
+		remove := []string{prefix + "cache/a.png", prefix + "cache/sub/b.png"}
+
+		for _, path := range append(keep, remove...) {
+			if err := upload(ctx, storage, path, []byte(path)); err != nil {
+				t.Fatalf("Upload(%s) error = %v", path, err)
+			}
+		}
+
+		if err := storage.DeletePrefix(ctx, prefix+"cache/"); err != nil {
+			t.Fatalf("DeletePrefix() error = %v", err)
+		}
+
+		for _, path := range remove {
+			if _, err := storage.Stat(ctx, path); !errors.Is(err, repository.ErrObjectNotFound) {
+				t.Errorf("%s debería haberse eliminado (error = %v)", path, err)
+			}
+		}
+		for _, path := range keep {
+			if _, err := storage.Stat(ctx, path); err != nil {
+				t.Errorf("%s no debería haberse eliminado (error = %v)", path, err)
+			}
+		}
+	})
+
+	t.Run("StreamUnknownSize", func(t *testing.T) {
+		storage := newStorage(t)
+		ctx := context.Background()
+		path := base + "stream/image.png"
+		data := bytes.Repeat([]byte("0123456789abcdef"), 256*1024)
+
+		// Un reader sin Len ni Seek obliga al backend a consumir por bloques
+		reader := io.MultiReader(bytes.NewReader(data[:1000]), bytes.NewReader(data[1000:]))
+		if err := storage.Upload(ctx, path, reader, repository.UnknownSize, "image/png"); err != nil {
+			t.Fatalf("Upload() error = %v", err)
+		}
+
+		info, err := storage.Stat(ctx, path)
+		if err != nil {
+			t.Fatalf("Stat() error = %v", err)
+		}
+		if info.Size != int64(len(data)) {
+			t.Errorf("Stat().Size = %d, want %d", info.Size, len(data))
+		}
+		if got := read(t, storage, path); !bytes.Equal(got, data) {
+			t.Error("el contenido leído no coincide con el subido")
+		}
+	})
+
+	t.Run("FailedUpload", func(t *testing.T) {
+		storage := newStorage(t)
+		ctx := context.Background()
+		path := base + "failed/image.png"
+
+		reader := io.MultiReader(bytes.NewReader([]byte("parcial")), failingReader{})
+		if err := storage.Upload(ctx, path, reader, repository.UnknownSize, "image/png"); err == nil {
+			t.Fatal("Upload() con reader fallido debe devolver error")
+		}
+		if _, err := storage.Stat(ctx, path); !errors.Is(err, repository.ErrObjectNotFound) {
+			t.Errorf("no debe quedar un objeto parcial (Stat() error = %v)", err)
+		}
+	})
+
+	t.Run("Move", func(t *testing.T) {
+		storage := newStorage(t)
+		ctx := context.Background()
+		from := base + "move/staging/tmp"
+		to := base + "move/final/image.png"
+
+		if err := upload(ctx, storage, from, []byte("movido")); err != nil {
+			t.Fatalf("Upload() error = %v", err)
+		}
+		if err := upload(ctx, storage, to, []byte("anterior")); err != nil {
+			t.Fatalf("Upload() error = %v", err)
+		}
+		if err := storage.Move(ctx, from, to); err != nil {
+			t.Fatalf("Move() error = %v", err)
+		}
+
+		if got := read(t, storage, to); string(got) != "movido" {
+			t.Errorf("Get(destino) = %q, want %q", got, "movido")
+		}
+		if _, err := storage.Stat(ctx, from); !errors.Is(err, repository.ErrObjectNotFound) {
+			t.Errorf("el origen debe desaparecer tras Move (Stat() error = %v)", err)
+		}
+		if err := storage.Move(ctx, from, to); !errors.Is(err, repository.ErrObjectNotFound) {
+			t.Errorf("Move() de origen inexistente error = %v, want ErrObjectNotFound", err)
+		}
+	})
+
+	t.Run("ConcurrentOverwrite", func(t *testing.T) {
+		storage := newStorage(t)
+		ctx := context.Background()
+		path := base + "concurrent/image.png"
+
+		versions := make([][]byte, 8)
+		for i := range versions {
+			versions[i] = bytes.Repeat([]byte{byte('a' + i)}, 64*1024)
+		}
+
+		var wg sync.WaitGroup
+		for _, data := range versions {
+			wg.Add(1)
+			go func(data []byte) {
+				defer wg.Done()
+				if err := upload(ctx, storage, path, data); err != nil {
+					t.Errorf("Upload() error = %v", err)
+				}
+			}(data)
+		}
+		wg.Wait()
+
+		// El resultado debe ser una de las versiones completas, nunca una mezcla
+		got := read(t, storage, path)
+		for _, data := range versions {
+			if bytes.Equal(got, data) {
+				return
+			}
+		}
+		t.Error("el contenido final no coincide con ninguna de las versiones subidas")
+	})
+}
+
+func upload(ctx context.Context, storage repository.FileStorage, path string, data []byte) error {
+	return storage.Upload(ctx, path, bytes.NewReader(data), int64(len(data)), "image/png")
+}
+
+// failingReader simula una conexión que se corta a mitad de la subida
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("conexión interrumpida")
+}
+
+func read(t *testing.T, storage repository.FileStorage, path string) []byte {
+	t.Helper()
+
+	reader, err := storage.Get(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Get(%s) error = %v", path, err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll(%s) error = %v", path, err)
+	}
+	return data
+}
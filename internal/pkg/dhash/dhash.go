@@ -0,0 +1,116 @@
+// Package dhash calcula el hash perceptual por diferencias (dHash) de una
+// imagen.
+//
+// La imagen se reduce a 9x8 celdas de luminancia promedio y cada bit indica
+// si una celda es más clara que su vecina de la derecha. Dos imágenes que se
+// ven iguales (otra escala, otra compresión, pequeños retoques) producen
+// hashes con pocos bits distintos, así que la distancia de Hamming mide qué
+// tan parecidas son.
+package dhash
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"math/bits"
+	"strconv"
+)
+
+const (
+	cols = 9
+	rows = 8
+)
+
+// ErrInvalid indica que el texto no es un hash de 16 dígitos hexadecimales
+var ErrInvalid = errors.New("dhash: hash inválido")
+
+// Compute devuelve el hash de 64 bits de la imagen. Recorre cada píxel una
+// sola vez y no reserva memoria por píxel.
+func Compute(img image.Image) uint64 {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= 0 || h <= 0 {
+		return 0
+	}
+
+	var cells [rows][cols]uint64
+	for row := 0; row < rows; row++ {
+		y0, y1 := span(b.Min.Y, h, row, rows)
+		for col := 0; col < cols; col++ {
+			x0, x1 := span(b.Min.X, w, col, cols)
+
+			var sum uint64
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					sum += uint64(luma(img, x, y))
+				}
+			}
+			cells[row][col] = sum / uint64((x1-x0)*(y1-y0))
+		}
+	}
+
+	var hash uint64
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols-1; col++ {
+			hash <<= 1
+			if cells[row][col] > cells[row][col+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// Distance devuelve la cantidad de bits distintos entre dos hashes (de 0 a 64)
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// Format devuelve el hash como 16 dígitos hexadecimales
+func Format(hash uint64) string {
+	return fmt.Sprintf("%016x", hash)
+}
+
+// Parse lee un hash escrito con Format
+func Parse(value string) (uint64, error) {
+	if len(value) != 16 {
+		return 0, ErrInvalid
+	}
+	hash, err := strconv.ParseUint(value, 16, 64)
+	if err != nil {
+		return 0, ErrInvalid
+	}
+	return hash, nil
+}
+
+// span devuelve el rango de píxeles de la celda i de n sobre un eje de
+// longitud size. En imágenes más chicas que la grilla las celdas comparten
+// píxeles, pero nunca quedan vacías.
+func span(min, size, i, n int) (int, int) {
+	start := min + i*size/n
+	end := min + (i+1)*size/n
+	if end <= start {
+		end = start + 1
+	}
+	if end > min+size {
+		start, end = min+size-1, min+size
+	}
+	return start, end
+}
+
+// luma devuelve la luminancia de 8 bits del píxel, leyendo directamente los
+// planos de los tipos más comunes
+func luma(img image.Image, x, y int) uint32 {
+	switch m := img.(type) {
+	case *image.YCbCr:
+		return uint32(m.Y[m.YOffset(x, y)])
+	case *image.Gray:
+		return uint32(m.Pix[m.PixOffset(x, y)])
+	case *image.NRGBA:
+		i := m.PixOffset(x, y)
+		r, g, b, a := uint32(m.Pix[i]), uint32(m.Pix[i+1]), uint32(m.Pix[i+2]), uint32(m.Pix[i+3])
+		return (19595*r + 38470*g + 7471*b + 1<<15) >> 16 * a / 255
+	}
+	r, g, b, _ := img.At(x, y).RGBA()
+	return (19595*r + 38470*g + 7471*b + 1<<15) >> 24
+}
@@ -0,0 +1,105 @@
+package dhash_test
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+
+	"github.com/RodrigoGonzalez78/internal/pkg/dhash"
+	"golang.org/x/image/draw"
+)
+
+// scene dibuja formas suaves que se reconocen a cualquier escala
+func scene(w, h int, mirrored bool) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			fx, fy := float64(x)/float64(w), float64(y)/float64(h)
+			if mirrored {
+				fx = 1 - fx
+			}
+			v := uint8(255 * fx * fy)
+			if (fx-0.3)*(fx-0.3)+(fy-0.6)*(fy-0.6) < 0.04 {
+				v = 240
+			}
+			img.SetNRGBA(x, y, color.NRGBA{R: v, G: v / 2, B: 255 - v, A: 255})
+		}
+	}
+	return img
+}
+
+func scaled(src image.Image, w, h int) image.Image {
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Src, nil)
+	return dst
+}
+
+func reencoded(t *testing.T, src image.Image, quality int) image.Image {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, src, &jpeg.Options{Quality: quality}); err != nil {
+		t.Fatalf("jpeg.Encode() error = %v", err)
+	}
+	img, err := jpeg.Decode(&buf)
+	if err != nil {
+		t.Fatalf("jpeg.Decode() error = %v", err)
+	}
+	return img
+}
+
+func TestCompute(t *testing.T) {
+	original := scene(640, 480, false)
+	hash := dhash.Compute(original)
+
+	tests := []struct {
+		name    string
+		img     image.Image
+		maxDist int
+		minDist int
+	}{
+		{name: "misma imagen", img: original, maxDist: 0},
+		{name: "reducida", img: scaled(original, 160, 120), maxDist: 4},
+		{name: "JPEG de baja calidad", img: reencoded(t, original, 30), maxDist: 4},
+		{name: "reducida y en JPEG", img: reencoded(t, scaled(original, 320, 240), 60), maxDist: 4},
+		{name: "espejada", img: scene(640, 480, true), minDist: 16, maxDist: 64},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dhash.Distance(hash, dhash.Compute(tt.img))
+			if got < tt.minDist || got > tt.maxDist {
+				t.Errorf("Distance() = %d, want entre %d y %d", got, tt.minDist, tt.maxDist)
+			}
+		})
+	}
+}
+
+func TestCompute_SmallAndOffset(t *testing.T) {
+	// Imágenes más chicas que la grilla y con origen distinto de cero
+	for _, img := range []image.Image{
+		scene(1, 1, false),
+		scene(3, 2, false),
+		scene(40, 30, false).SubImage(image.Rect(10, 5, 30, 25)),
+		image.NewGray(image.Rect(0, 0, 0, 0)),
+	} {
+		dhash.Compute(img)
+	}
+}
+
+func TestFormatParse(t *testing.T) {
+	for _, hash := range []uint64{0, 1, 0xfedcba9876543210, ^uint64(0)} {
+		got, err := dhash.Parse(dhash.Format(hash))
+		if err != nil || got != hash {
+			t.Errorf("Parse(Format(%x)) = %x, %v", hash, got, err)
+		}
+	}
+
+	for _, value := range []string{"", "abc", "zz00000000000000", "00000000000000000"} {
+		if _, err := dhash.Parse(value); !errors.Is(err, dhash.ErrInvalid) {
+			t.Errorf("Parse(%q) error = %v, want ErrInvalid", value, err)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+// Package exif lee los datos EXIF de imágenes JPEG, PNG y WebP.
+//
+// Solo se interpretan las etiquetas que usa el servidor (orientación,
+// cámara, lente, fecha de captura, GPS y exposición); el resto se ignora.
+// Todos los desplazamientos se verifican, así que un bloque corrupto produce
+// ErrInvalid y nunca un pánico.
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound indica que la imagen no tiene bloque EXIF
+	ErrNotFound = errors.New("exif: la imagen no tiene datos EXIF")
+
+	// ErrInvalid indica que el bloque EXIF está truncado o mal formado
+	ErrInvalid = errors.New("exif: datos EXIF corruptos")
+)
+
+// Etiquetas que se interpretan
+const (
+	tagMake             = 0x010F
+	tagModel            = 0x0110
+	tagOrientation      = 0x0112
+	tagSoftware         = 0x0131
+	tagDateTime         = 0x0132
+	tagExifIFD          = 0x8769
+	tagGPSIFD           = 0x8825
+	tagExposureTime     = 0x829A
+	tagFNumber          = 0x829D
+	tagISO              = 0x8827
+	tagDateTimeOriginal = 0x9003
+	tagOffsetOriginal   = 0x9011
+	tagFocalLength      = 0x920A
+	tagLensMake         = 0xA433
+	tagLensModel        = 0xA434
+
+	tagGPSLatitudeRef  = 0x0001
+	tagGPSLatitude     = 0x0002
+	tagGPSLongitudeRef = 0x0003
+	tagGPSLongitude    = 0x0004
+	tagGPSAltitudeRef  = 0x0005
+	tagGPSAltitude     = 0x0006
+)
+
+// dateLayout es el formato de fecha de EXIF ("2024:03:15 10:30:00")
+const dateLayout = "2006:01:02 15:04:05"
+
+// GPS posición de captura en grados decimales; Altitude en metros sobre el
+// nivel del mar
+type GPS struct {
+	Latitude  float64
+	Longitude float64
+	Altitude  *float64
+}
+
+// Data datos EXIF interpretados. Los campos ausentes quedan en su valor cero.
+type Data struct {
+	// Orientation valor de la etiqueta Orientation (1 a 8); 1 si no está
+	Orientation int
+	Make        string
+	Model       string
+	LensMake    string
+	LensModel   string
+	Software    string
+	// CapturedAt fecha de captura; sin zona horaria en el archivo se
+	// interpreta como UTC
+	CapturedAt   time.Time
+	GPS          *GPS
+	ExposureTime float64 // segundos
+	FNumber      float64
+	ISO          int
+	FocalLength  float64 // milímetros
+}
+
+// Parse extrae y decodifica los datos EXIF de un JPEG, PNG o WebP
+func Parse(data []byte) (*Data, error) {
+	raw, err := Extract(data)
+	if err != nil {
+		return nil, err
+	}
+	return Decode(raw)
+}
+
+// Orientation devuelve la orientación EXIF de la imagen o 1 si no tiene
+// datos EXIF o no se pueden leer
+func Orientation(data []byte) int {
+	parsed, err := Parse(data)
+	if err != nil {
+		return 1
+	}
+	return parsed.Orientation
+}
+
+// Extract devuelve el bloque TIFF con los datos EXIF de un JPEG (segmento
+// APP1), un PNG (chunk eXIf) o un WebP (chunk EXIF)
+func Extract(data []byte) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8}):
+		return extractJPEG(data)
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return extractPNG(data)
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return extractWebP(data)
+	}
+	return nil, ErrNotFound
+}
+
+var exifHeader = []byte("Exif\x00\x00")
+
+func extractJPEG(data []byte) ([]byte, error) {
+	i := 2
+	for i+4 <= len(data) {
+		if data[i] != 0xFF {
+			return nil, ErrInvalid
+		}
+		marker := data[i+1]
+		switch {
+		case marker == 0xFF:
+			// Byte de relleno antes del marcador
+			i++
+			continue
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD8):
+			// Marcadores sin longitud
+			i += 2
+			continue
+		case marker == 0xDA || marker == 0xD9:
+			// Desde el inicio de los datos comprimidos ya no hay metadatos
+			return nil, ErrNotFound
+		}
+
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			return nil, ErrInvalid
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, exifHeader) {
+			return segment[len(exifHeader):], nil
+		}
+		i += 2 + length
+	}
+	return nil, ErrNotFound
+}
+
+func extractPNG(data []byte) ([]byte, error) {
+	i := 8
+	for i+8 <= len(data) {
+		length := int64(binary.BigEndian.Uint32(data[i:]))
+		chunkType := string(data[i+4 : i+8])
+		end := int64(i) + 12 + length
+		if end > int64(len(data)) {
+			return nil, ErrInvalid
+		}
+		switch chunkType {
+		case "eXIf":
+			return data[i+8 : i+8+int(length)], nil
+		case "IEND":
+			return nil, ErrNotFound
+		}
+		i = int(end)
+	}
+	return nil, ErrNotFound
+}
+
+func extractWebP(data []byte) ([]byte, error) {
+	i := 12
+	for i+8 <= len(data) {
+		size := int64(binary.LittleEndian.Uint32(data[i+4:]))
+		end := int64(i) + 8 + size
+		if end > int64(len(data)) {
+			return nil, ErrInvalid
+		}
+		if string(data[i:i+4]) == "EXIF" {
+			// Algunos programas conservan el prefijo de JPEG dentro del chunk
+			return bytes.TrimPrefix(data[i+8:end], exifHeader), nil
+		}
+		// Los chunks se rellenan hasta una longitud par
+		i = int(end + size&1)
+	}
+	return nil, ErrNotFound
+}
+
+// Decode interpreta un bloque TIFF con datos EXIF
+func Decode(raw []byte) (*Data, error) {
+	if len(raw) < 8 {
+		return nil, ErrInvalid
+	}
+
+	var order binary.ByteOrder
+	switch string(raw[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return nil, ErrInvalid
+	}
+	if order.Uint16(raw[2:]) != 42 {
+		return nil, ErrInvalid
+	}
+
+	r := tiffReader{raw: raw, order: order}
+	ifd0, err := r.readIFD(order.Uint32(raw[4:]))
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Data{
+		Orientation: 1,
+		Make:        ifd0.string(tagMake),
+		Model:       ifd0.string(tagModel),
+		Software:    ifd0.string(tagSoftware),
+	}
+	if v, ok := ifd0.uint(tagOrientation); ok && v >= 1 && v <= 8 {
+		d.Orientation = int(v)
+	}
+	capturedAt := ifd0.string(tagDateTime)
+
+	// Las sub-IFD dañadas se ignoran para no perder lo que sí se pudo leer
+	if offset, ok := ifd0.uint(tagExifIFD); ok {
+		if sub, err := r.readIFD(offset); err == nil {
+			d.LensMake = sub.string(tagLensMake)
+			d.LensModel = sub.string(tagLensModel)
+			d.ExposureTime, _ = sub.rational(tagExposureTime, 0)
+			d.FNumber, _ = sub.rational(tagFNumber, 0)
+			d.FocalLength, _ = sub.rational(tagFocalLength, 0)
+			if iso, ok := sub.uint(tagISO); ok {
+				d.ISO = int(iso)
+			}
+			if original := sub.string(tagDateTimeOriginal); original != "" {
+				capturedAt = original
+			}
+			d.CapturedAt = parseDate(capturedAt, sub.string(tagOffsetOriginal))
+		}
+	}
+	if d.CapturedAt.IsZero() {
+		d.CapturedAt = parseDate(capturedAt, "")
+	}
+
+	if offset, ok := ifd0.uint(tagGPSIFD); ok {
+		if sub, err := r.readIFD(offset); err == nil {
+			d.GPS = decodeGPS(sub)
+		}
+	}
+
+	return d, nil
+}
+
+func parseDate(value, offset string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+	if offset != "" {
+		if t, err := time.Parse(dateLayout+"-07:00", value+offset); err == nil {
+			return t
+		}
+	}
+	t, _ := time.Parse(dateLayout, value)
+	return t
+}
+
+func decodeGPS(sub ifd) *GPS {
+	lat, okLat := sub.degrees(tagGPSLatitude)
+	lon, okLon := sub.degrees(tagGPSLongitude)
+	if !okLat || !okLon || math.Abs(lat) > 90 || math.Abs(lon) > 180 {
+		return nil
+	}
+	if strings.EqualFold(sub.string(tagGPSLatitudeRef), "S") {
+		lat = -lat
+	}
+	if strings.EqualFold(sub.string(tagGPSLongitudeRef), "W") {
+		lon = -lon
+	}
+
+	gps := &GPS{Latitude: lat, Longitude: lon}
+	if alt, ok := sub.rational(tagGPSAltitude, 0); ok {
+		// Referencia 1 indica altura bajo el nivel del mar
+		if ref, ok := sub.uint(tagGPSAltitudeRef); ok && ref == 1 {
+			alt = -alt
+		}
+		gps.Altitude = &alt
+	}
+	return gps
+}
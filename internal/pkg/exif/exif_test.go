@@ -0,0 +1,180 @@
+package exif_test
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/RodrigoGonzalez78/internal/pkg/exif"
+	"github.com/RodrigoGonzalez78/internal/pkg/exif/exiftest"
+)
+
+// cameraBlock reproduce los datos de una foto de teléfono típica
+var cameraBlock = exiftest.Block{
+	IFD0: []exiftest.Tag{
+		exiftest.ASCII(0x010F, "Google"),
+		exiftest.ASCII(0x0110, "Pixel 7"),
+		exiftest.Short(0x0112, 6),
+		exiftest.ASCII(0x0132, "2024:03:15 12:00:00"),
+	},
+	Exif: []exiftest.Tag{
+		exiftest.Rationals(0x829A, [2]uint32{1, 125}),
+		exiftest.Rationals(0x829D, [2]uint32{18, 10}),
+		exiftest.Short(0x8827, 200),
+		exiftest.ASCII(0x9003, "2024:03:15 10:30:00"),
+		exiftest.ASCII(0x9011, "-03:00"),
+		exiftest.Rationals(0x920A, [2]uint32{681, 100}),
+		exiftest.ASCII(0xA434, "Pixel 7 back camera"),
+	},
+	GPS: []exiftest.Tag{
+		exiftest.ASCII(0x0001, "S"),
+		exiftest.Rationals(0x0002, [2]uint32{34, 1}, [2]uint32{36, 1}, [2]uint32{1800, 100}),
+		exiftest.ASCII(0x0003, "W"),
+		exiftest.Rationals(0x0004, [2]uint32{58, 1}, [2]uint32{22, 1}, [2]uint32{3600, 100}),
+		exiftest.Byte(0x0005, 0),
+		exiftest.Rationals(0x0006, [2]uint32{25, 1}),
+	},
+}
+
+func encodedJPEG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, 8, 8)), nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func encodedPNG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 8, 8))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestParse(t *testing.T) {
+	bigEndian := cameraBlock
+	bigEndian.BigEndian = true
+
+	tests := []struct {
+		name string
+		data func(t *testing.T) []byte
+	}{
+		{name: "JPEG little endian", data: func(t *testing.T) []byte { return cameraBlock.JPEG(encodedJPEG(t)) }},
+		{name: "JPEG big endian", data: func(t *testing.T) []byte { return bigEndian.JPEG(encodedJPEG(t)) }},
+		{name: "PNG", data: func(t *testing.T) []byte { return cameraBlock.PNG(encodedPNG(t)) }},
+	}
+
+	wantCapture := time.Date(2024, 3, 15, 13, 30, 0, 0, time.UTC)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := tt.data(t)
+
+			// El contenedor modificado tiene que seguir siendo una imagen válida
+			if _, _, err := image.Decode(bytes.NewReader(data)); err != nil {
+				t.Fatalf("image.Decode() error = %v", err)
+			}
+
+			got, err := exif.Parse(data)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			if got.Orientation != 6 || got.Make != "Google" || got.Model != "Pixel 7" || got.LensModel != "Pixel 7 back camera" {
+				t.Errorf("Parse() = %+v", got)
+			}
+			if !got.CapturedAt.Equal(wantCapture) {
+				t.Errorf("CapturedAt = %v, want %v", got.CapturedAt, wantCapture)
+			}
+			if got.ExposureTime != 0.008 || got.FNumber != 1.8 || got.ISO != 200 || got.FocalLength != 6.81 {
+				t.Errorf("exposición = %v s f/%v ISO %v %v mm", got.ExposureTime, got.FNumber, got.ISO, got.FocalLength)
+			}
+
+			if got.GPS == nil || got.GPS.Altitude == nil {
+				t.Fatalf("GPS = %+v, want posición con altitud", got.GPS)
+			}
+			if math.Abs(got.GPS.Latitude+34.605) > 1e-9 || math.Abs(got.GPS.Longitude+58.3766667) > 1e-6 || *got.GPS.Altitude != 25 {
+				t.Errorf("GPS = %v, %v, %v", got.GPS.Latitude, got.GPS.Longitude, *got.GPS.Altitude)
+			}
+		})
+	}
+}
+
+func TestParse_Partial(t *testing.T) {
+	// Sin sub-IFD: la fecha sale de IFD0 y no hay GPS
+	block := exiftest.Block{IFD0: []exiftest.Tag{
+		exiftest.ASCII(0x0132, "2023:01:02 03:04:05"),
+		exiftest.Short(0x0112, 42),
+	}}
+
+	got, err := exif.Parse(block.JPEG(encodedJPEG(t)))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got.Orientation != 1 {
+		t.Errorf("Orientation fuera de rango = %d, want 1", got.Orientation)
+	}
+	if want := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC); !got.CapturedAt.Equal(want) {
+		t.Errorf("CapturedAt = %v, want %v", got.CapturedAt, want)
+	}
+	if got.GPS != nil {
+		t.Errorf("GPS = %+v, want nil", got.GPS)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	valid := exiftest.WithOrientation(3).JPEG(encodedJPEG(t))
+
+	// Bloque TIFF con el puntero a IFD0 fuera de los datos
+	broken := exiftest.Block{BigEndian: true}.TIFF()
+	broken[7] = 0xFF
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{name: "JPEG sin EXIF", data: encodedJPEG(t), wantErr: exif.ErrNotFound},
+		{name: "PNG sin EXIF", data: encodedPNG(t), wantErr: exif.ErrNotFound},
+		{name: "formato desconocido", data: []byte("GIF89a"), wantErr: exif.ErrNotFound},
+		{name: "segmento truncado", data: valid[:20], wantErr: exif.ErrInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := exif.Parse(tt.data); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Parse() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := exif.Decode(broken); !errors.Is(err, exif.ErrInvalid) {
+		t.Errorf("Decode() con IFD fuera del bloque error = %v, want ErrInvalid", err)
+	}
+	if got := exif.Orientation(valid); got != 3 {
+		t.Errorf("Orientation() = %d, want 3", got)
+	}
+	if got := exif.Orientation(valid[:20]); got != 1 {
+		t.Errorf("Orientation() con datos corruptos = %d, want 1", got)
+	}
+}
+
+// TestParse_NeverPanics recorta y altera un bloque válido byte a byte
+func TestParse_NeverPanics(t *testing.T) {
+	data := cameraBlock.JPEG(encodedJPEG(t))
+	for i := 0; i < 400 && i < len(data); i++ {
+		exif.Parse(data[:i])
+
+		mutated := append([]byte(nil), data...)
+		mutated[i] ^= 0xFF
+		exif.Parse(mutated)
+	}
+}
@@ -0,0 +1,197 @@
+// Package exiftest construye bloques EXIF e imágenes que los contienen para
+// las pruebas de los paquetes que leen metadatos.
+package exiftest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+)
+
+// Tipos de campo TIFF que sabe escribir el constructor
+const (
+	TypeByte     = 1
+	TypeASCII    = 2
+	TypeShort    = 3
+	TypeLong     = 4
+	TypeRational = 5
+)
+
+// Tag es una entrada de directorio. Según el tipo se usa Text, Ints o
+// Rationals (pares numerador/denominador).
+type Tag struct {
+	ID        uint16
+	Type      uint16
+	Text      string
+	Ints      []uint32
+	Rationals [][2]uint32
+}
+
+// ASCII crea una etiqueta de texto
+func ASCII(id uint16, text string) Tag {
+	return Tag{ID: id, Type: TypeASCII, Text: text}
+}
+
+// Short crea una etiqueta con un entero de 16 bits
+func Short(id uint16, value uint16) Tag {
+	return Tag{ID: id, Type: TypeShort, Ints: []uint32{uint32(value)}}
+}
+
+// Byte crea una etiqueta con un byte
+func Byte(id uint16, value uint8) Tag {
+	return Tag{ID: id, Type: TypeByte, Ints: []uint32{uint32(value)}}
+}
+
+// Rationals crea una etiqueta con uno o más valores racionales
+func Rationals(id uint16, values ...[2]uint32) Tag {
+	return Tag{ID: id, Type: TypeRational, Rationals: values}
+}
+
+// Block describe un bloque EXIF con sus tres directorios
+type Block struct {
+	BigEndian bool
+	IFD0      []Tag
+	Exif      []Tag
+	GPS       []Tag
+}
+
+// WithOrientation devuelve un bloque que solo tiene la etiqueta Orientation
+func WithOrientation(orientation uint16) Block {
+	return Block{IFD0: []Tag{Short(0x0112, orientation)}}
+}
+
+// TIFF serializa el bloque con la cabecera TIFF
+func (b Block) TIFF() []byte {
+	var order binary.AppendByteOrder = binary.LittleEndian
+	header := []byte("II\x2a\x00")
+	if b.BigEndian {
+		order = binary.BigEndian
+		header = []byte("MM\x00\x2a")
+	}
+
+	// Los punteros a las sub-IFD se agregan al directorio principal
+	ifd0 := append([]Tag(nil), b.IFD0...)
+	if len(b.Exif) > 0 {
+		ifd0 = append(ifd0, Tag{ID: 0x8769, Type: TypeLong, Ints: []uint32{0}})
+	}
+	if len(b.GPS) > 0 {
+		ifd0 = append(ifd0, Tag{ID: 0x8825, Type: TypeLong, Ints: []uint32{0}})
+	}
+
+	ifd0Offset := uint32(8)
+	exifOffset := ifd0Offset + uint32(ifdSize(order, ifd0))
+	gpsOffset := exifOffset + uint32(ifdSize(order, b.Exif))
+	for i := range ifd0 {
+		switch ifd0[i].ID {
+		case 0x8769:
+			ifd0[i].Ints = []uint32{exifOffset}
+		case 0x8825:
+			ifd0[i].Ints = []uint32{gpsOffset}
+		}
+	}
+
+	out := append([]byte(nil), header...)
+	out = order.AppendUint32(out, ifd0Offset)
+	out = appendIFD(out, order, ifd0)
+	if len(b.Exif) > 0 {
+		out = appendIFD(out, order, b.Exif)
+	}
+	if len(b.GPS) > 0 {
+		out = appendIFD(out, order, b.GPS)
+	}
+	return out
+}
+
+// JPEG inserta el bloque como segmento APP1 después del marcador SOI
+func (b Block) JPEG(jpeg []byte) []byte {
+	payload := append([]byte("Exif\x00\x00"), b.TIFF()...)
+	segment := []byte{0xFF, 0xE1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	out := append([]byte(nil), jpeg[:2]...)
+	out = append(out, segment...)
+	return append(out, jpeg[2:]...)
+}
+
+// PNG inserta el bloque como chunk eXIf después del chunk IHDR
+func (b Block) PNG(png []byte) []byte {
+	payload := b.TIFF()
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(payload)))
+	chunk = append(chunk, "eXIf"...)
+	chunk = append(chunk, payload...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+
+	// Firma (8) + IHDR (4 de longitud, 4 de tipo, 13 de datos y 4 de CRC)
+	const ihdrEnd = 8 + 25
+	out := append([]byte(nil), png[:ihdrEnd]...)
+	out = append(out, chunk...)
+	return append(out, png[ihdrEnd:]...)
+}
+
+func (t Tag) encode(order binary.AppendByteOrder) (count uint32, data []byte) {
+	switch t.Type {
+	case TypeASCII:
+		return uint32(len(t.Text) + 1), append([]byte(t.Text), 0)
+	case TypeRational:
+		for _, r := range t.Rationals {
+			data = order.AppendUint32(data, r[0])
+			data = order.AppendUint32(data, r[1])
+		}
+		return uint32(len(t.Rationals)), data
+	}
+	for _, v := range t.Ints {
+		switch t.Type {
+		case TypeByte:
+			data = append(data, byte(v))
+		case TypeShort:
+			data = order.AppendUint16(data, uint16(v))
+		default:
+			data = order.AppendUint32(data, v)
+		}
+	}
+	return uint32(len(t.Ints)), data
+}
+
+// ifdSize tamaño del directorio más los valores que no entran en la entrada
+func ifdSize(order binary.AppendByteOrder, tags []Tag) int {
+	if len(tags) == 0 {
+		return 0
+	}
+	size := 2 + 12*len(tags) + 4
+	for _, t := range tags {
+		if _, data := t.encode(order); len(data) > 4 {
+			size += len(data) + len(data)%2
+		}
+	}
+	return size
+}
+
+func appendIFD(out []byte, order binary.AppendByteOrder, tags []Tag) []byte {
+	start := len(out)
+	dataOffset := start + 2 + 12*len(tags) + 4
+
+	var entries, extra bytes.Buffer
+	for _, t := range tags {
+		count, data := t.encode(order)
+		entry := order.AppendUint16(nil, t.ID)
+		entry = order.AppendUint16(entry, t.Type)
+		entry = order.AppendUint32(entry, count)
+		if len(data) <= 4 {
+			entry = append(entry, data...)
+			entry = append(entry, make([]byte, 4-len(data))...)
+		} else {
+			entry = order.AppendUint32(entry, uint32(dataOffset+extra.Len()))
+			extra.Write(data)
+			if len(data)%2 == 1 {
+				extra.WriteByte(0)
+			}
+		}
+		entries.Write(entry)
+	}
+
+	out = order.AppendUint16(out, uint16(len(tags)))
+	out = append(out, entries.Bytes()...)
+	out = order.AppendUint32(out, 0) // sin IFD siguiente
+	return append(out, extra.Bytes()...)
+}
@@ -0,0 +1,341 @@
+package exif
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+)
+
+// StripMode indica qué metadatos elimina Strip
+type StripMode int
+
+const (
+	// StripGPS borra solo la posición: el directorio GPS de EXIF y los
+	// paquetes XMP que la repiten
+	StripGPS StripMode = iota
+
+	// StripAll borra EXIF, XMP, IPTC y comentarios. Se conserva únicamente
+	// la orientación para que la imagen se siga viendo derecha.
+	StripAll
+)
+
+// xmpGPSMarker aparece en los paquetes XMP que contienen coordenadas
+var xmpGPSMarker = []byte("exif:GPS")
+
+// ContainsGPS indica si la imagen tiene coordenadas en su bloque EXIF o en
+// un paquete XMP. Basta con el comienzo del archivo, donde están los
+// metadatos.
+func ContainsGPS(data []byte) bool {
+	if parsed, err := Parse(data); err == nil && parsed.GPS != nil {
+		return true
+	}
+	return bytes.Contains(data, xmpGPSMarker)
+}
+
+// Strip elimina metadatos de un JPEG o un PNG reescribiendo solo sus
+// segmentos o chunks, sin volver a codificar los píxeles. Otros formatos se
+// devuelven sin cambios.
+func Strip(data []byte, mode StripMode) ([]byte, error) {
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	if err := StripTo(out, bytes.NewReader(data), mode); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// StripTo hace lo mismo que Strip leyendo de r y escribiendo en w a medida
+// que avanza. Solo guarda en memoria un segmento o chunk de metadatos por
+// vez, así que sirve para archivos que no conviene cargar enteros.
+func StripTo(w io.Writer, r io.Reader, mode StripMode) error {
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(len(pngSignature))
+
+	switch {
+	case bytes.HasPrefix(head, []byte{0xFF, 0xD8}):
+		return stripJPEG(w, br, mode)
+	case bytes.HasPrefix(head, pngSignature):
+		return stripPNG(w, br, mode)
+	}
+	_, err := io.Copy(w, br)
+	return err
+}
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// maxMetadataChunk acota el chunk de PNG que se lee entero para revisarlo.
+// Uno más grande no es una imagen real y se descarta sin mirarlo, porque
+// podría contener la posición.
+const maxMetadataChunk = 1 << 20
+
+func stripJPEG(w io.Writer, r *bufio.Reader, mode StripMode) error {
+	if err := copyExact(w, r, 2); err != nil {
+		return err
+	}
+
+	for {
+		head, err := r.Peek(4)
+		if err != nil {
+			return truncated(err)
+		}
+		if head[0] != 0xFF {
+			return ErrInvalid
+		}
+		marker := head[1]
+		switch {
+		case marker == 0xFF:
+			if err := copyExact(w, r, 1); err != nil {
+				return err
+			}
+			continue
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD8):
+			if err := copyExact(w, r, 2); err != nil {
+				return err
+			}
+			continue
+		case marker == 0xDA || marker == 0xD9:
+			// El resto son datos comprimidos, que se copian tal cual
+			_, err := io.Copy(w, r)
+			return err
+		}
+
+		length := int(binary.BigEndian.Uint16(head[2:]))
+		if length < 2 {
+			return ErrInvalid
+		}
+		segment := make([]byte, 2+length)
+		if _, err := io.ReadFull(r, segment); err != nil {
+			return truncated(err)
+		}
+		payload := segment[4:]
+
+		switch {
+		case marker == 0xE1 && bytes.HasPrefix(payload, exifHeader):
+			tiff, err := stripTIFF(payload[len(exifHeader):], mode)
+			if err != nil {
+				return err
+			}
+			if tiff != nil {
+				segment = appendJPEGSegment(nil, 0xE1, append(append([]byte(nil), exifHeader...), tiff...))
+				if _, err := w.Write(segment); err != nil {
+					return err
+				}
+			}
+			continue
+		case marker == 0xE1:
+			// XMP y otros paquetes en APP1
+			if mode == StripAll || bytes.Contains(payload, xmpGPSMarker) {
+				continue
+			}
+		case marker == 0xED || marker == 0xFE:
+			// IPTC de Photoshop y comentarios
+			if mode == StripAll {
+				continue
+			}
+		}
+		if _, err := w.Write(segment); err != nil {
+			return err
+		}
+	}
+}
+
+func appendJPEGSegment(out []byte, marker byte, payload []byte) []byte {
+	out = append(out, 0xFF, marker)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(payload)+2))
+	return append(out, payload...)
+}
+
+// textChunks son los chunks de PNG con texto libre (incluido XMP)
+var textChunks = map[string]bool{"tEXt": true, "zTXt": true, "iTXt": true}
+
+func stripPNG(w io.Writer, r *bufio.Reader, mode StripMode) error {
+	if err := copyExact(w, r, int64(len(pngSignature))); err != nil {
+		return err
+	}
+
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return truncated(err)
+		}
+		length := int64(binary.BigEndian.Uint32(header))
+		chunkType := string(header[4:])
+
+		if chunkType == "eXIf" || textChunks[chunkType] {
+			if length > maxMetadataChunk {
+				if _, err := r.Discard(int(length) + 4); err != nil {
+					return truncated(err)
+				}
+				continue
+			}
+
+			chunk := make([]byte, 12+length)
+			copy(chunk, header)
+			if _, err := io.ReadFull(r, chunk[8:]); err != nil {
+				return truncated(err)
+			}
+			body := chunk[8 : 8+length]
+
+			switch {
+			case chunkType == "eXIf":
+				tiff, err := stripTIFF(body, mode)
+				if err != nil {
+					return err
+				}
+				if tiff == nil {
+					continue
+				}
+				chunk = appendPNGChunk(nil, "eXIf", tiff)
+			case mode == StripAll || bytes.Contains(body, xmpGPSMarker):
+				continue
+			}
+			if _, err := w.Write(chunk); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if chunkType == "tIME" && mode == StripAll {
+			if _, err := r.Discard(int(length) + 4); err != nil {
+				return truncated(err)
+			}
+			continue
+		}
+
+		if _, err := w.Write(header); err != nil {
+			return err
+		}
+		if err := copyExact(w, r, length+4); err != nil {
+			return err
+		}
+		if chunkType == "IEND" {
+			return nil
+		}
+	}
+}
+
+func appendPNGChunk(out []byte, chunkType string, body []byte) []byte {
+	start := len(out)
+	out = binary.BigEndian.AppendUint32(out, uint32(len(body)))
+	out = append(out, chunkType...)
+	out = append(out, body...)
+	return binary.BigEndian.AppendUint32(out, crc32.ChecksumIEEE(out[start+4:]))
+}
+
+// copyExact copia n bytes; si el archivo termina antes está truncado
+func copyExact(w io.Writer, r io.Reader, n int64) error {
+	if _, err := io.CopyN(w, r, n); err != nil {
+		return truncated(err)
+	}
+	return nil
+}
+
+// truncated traduce el fin prematuro de la entrada a ErrInvalid y deja
+// pasar los demás errores de lectura o escritura
+func truncated(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, bufio.ErrBufferFull) {
+		return ErrInvalid
+	}
+	return err
+}
+
+// stripTIFF devuelve el bloque EXIF que debe quedar en el archivo, o nil si
+// hay que quitarlo entero
+func stripTIFF(raw []byte, mode StripMode) ([]byte, error) {
+	if mode == StripAll {
+		parsed, err := Decode(raw)
+		if err != nil || parsed.Orientation == 1 {
+			return nil, nil
+		}
+		return orientationTIFF(parsed.Orientation), nil
+	}
+	return removeGPS(raw)
+}
+
+// orientationTIFF crea un bloque EXIF mínimo con la etiqueta Orientation
+func orientationTIFF(orientation int) []byte {
+	out := []byte("II\x2a\x00")
+	out = binary.LittleEndian.AppendUint32(out, 8)
+	out = binary.LittleEndian.AppendUint16(out, 1)
+	out = binary.LittleEndian.AppendUint16(out, tagOrientation)
+	out = binary.LittleEndian.AppendUint16(out, typeShort)
+	out = binary.LittleEndian.AppendUint32(out, 1)
+	out = binary.LittleEndian.AppendUint16(out, uint16(orientation))
+	out = append(out, 0, 0)
+	return binary.LittleEndian.AppendUint32(out, 0)
+}
+
+// removeGPS borra el directorio GPS sobre una copia del bloque: pone en cero
+// sus entradas y valores y quita el puntero de IFD0 desplazando las entradas
+// siguientes. El tamaño del bloque no cambia, así que los demás
+// desplazamientos siguen siendo válidos.
+func removeGPS(raw []byte) ([]byte, error) {
+	// Un bloque que no se puede interpretar podría contener la posición, así
+	// que se quita entero
+	if _, err := Decode(raw); err != nil {
+		return nil, nil
+	}
+	out := append([]byte(nil), raw...)
+
+	order := binary.ByteOrder(binary.LittleEndian)
+	if string(out[:2]) == "MM" {
+		order = binary.BigEndian
+	}
+	r := tiffReader{raw: out, order: order}
+
+	start := int(order.Uint32(out[4:]))
+	n := int(order.Uint16(out[start:]))
+	for k := 0; k < n; k++ {
+		entry := start + 2 + k*12
+		if order.Uint16(out[entry:]) != tagGPSIFD {
+			continue
+		}
+
+		gpsOffset := order.Uint32(out[entry+8:])
+		zeroIFD(r, gpsOffset)
+
+		// Quitar la entrada y mover el puntero a la IFD siguiente
+		tail := start + 2 + n*12 + 4
+		if tail > len(out) {
+			tail = start + 2 + n*12
+		}
+		copy(out[entry:], out[entry+12:tail])
+		clear(out[tail-12 : tail])
+		order.PutUint16(out[start:], uint16(n-1))
+		break
+	}
+	return out, nil
+}
+
+// zeroIFD pone en cero un directorio y los valores que guarda fuera de sus
+// entradas
+func zeroIFD(r tiffReader, offset uint32) {
+	start := int64(offset)
+	if start+2 > int64(len(r.raw)) {
+		return
+	}
+	n := int64(r.order.Uint16(r.raw[start:]))
+	end := start + 2 + n*12
+	if n > maxEntries || end > int64(len(r.raw)) {
+		return
+	}
+
+	for k := int64(0); k < n; k++ {
+		entry := r.raw[start+2+k*12:]
+		size, ok := typeSizes[r.order.Uint16(entry[2:])]
+		if !ok {
+			continue
+		}
+		total := int64(r.order.Uint32(entry[4:])) * int64(size)
+		if total <= 4 {
+			continue
+		}
+		valueOffset := int64(r.order.Uint32(entry[8:]))
+		if valueOffset+total <= int64(len(r.raw)) {
+			clear(r.raw[valueOffset : valueOffset+total])
+		}
+	}
+	clear(r.raw[start:end])
+}
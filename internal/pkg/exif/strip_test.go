@@ -0,0 +1,225 @@
+package exif_test
+
+import (
+	"bytes"
+	"errors"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/RodrigoGonzalez78/internal/pkg/exif"
+	"github.com/RodrigoGonzalez78/internal/pkg/exif/exiftest"
+)
+
+// withSegment agrega un segmento JPEG justo después de SOI
+func withSegment(data []byte, marker byte, payload string) []byte {
+	segment := []byte{0xFF, marker, byte((len(payload) + 2) >> 8), byte(len(payload) + 2)}
+	segment = append(segment, payload...)
+	return append(append(append([]byte(nil), data[:2]...), segment...), data[2:]...)
+}
+
+const (
+	xmpWithGPS    = "http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta><rdf:Description exif:GPSLatitude=\"34,36.3S\"/></x:xmpmeta>"
+	xmpWithoutGPS = "http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta><rdf:Description xmp:Rating=\"5\"/></x:xmpmeta>"
+)
+
+func TestStrip(t *testing.T) {
+	bigEndian := cameraBlock
+	bigEndian.BigEndian = true
+
+	tests := []struct {
+		name  string
+		data  func(t *testing.T) []byte
+		check func(t *testing.T, data []byte)
+	}{
+		{
+			name: "JPEG",
+			data: func(t *testing.T) []byte { return cameraBlock.JPEG(encodedJPEG(t)) },
+			check: func(t *testing.T, data []byte) {
+				if _, err := jpeg.Decode(bytes.NewReader(data)); err != nil {
+					t.Errorf("jpeg.Decode() error = %v", err)
+				}
+			},
+		},
+		{
+			name: "JPEG big endian",
+			data: func(t *testing.T) []byte { return bigEndian.JPEG(encodedJPEG(t)) },
+			check: func(t *testing.T, data []byte) {
+				if _, err := jpeg.Decode(bytes.NewReader(data)); err != nil {
+					t.Errorf("jpeg.Decode() error = %v", err)
+				}
+			},
+		},
+		{
+			name: "PNG",
+			data: func(t *testing.T) []byte { return cameraBlock.PNG(encodedPNG(t)) },
+			check: func(t *testing.T, data []byte) {
+				// El decodificador de PNG verifica el CRC de cada chunk
+				if _, err := png.Decode(bytes.NewReader(data)); err != nil {
+					t.Errorf("png.Decode() error = %v", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+" solo GPS", func(t *testing.T) {
+			data := tt.data(t)
+			stripped, err := exif.Strip(data, exif.StripGPS)
+			if err != nil {
+				t.Fatalf("Strip() error = %v", err)
+			}
+			tt.check(t, stripped)
+
+			got, err := exif.Parse(stripped)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got.GPS != nil {
+				t.Errorf("GPS = %+v, want nil", got.GPS)
+			}
+			if got.Make != "Google" || got.Model != "Pixel 7" || got.Orientation != 6 || got.ISO != 200 {
+				t.Errorf("se perdieron datos de la cámara: %+v", got)
+			}
+			if exif.ContainsGPS(stripped) {
+				t.Error("ContainsGPS() = true después de quitar la posición")
+			}
+			// Los valores de las coordenadas tampoco quedan en el archivo
+			if bytes.Contains(stripped, []byte{34, 0, 0, 0, 1, 0, 0, 0, 36, 0, 0, 0}) {
+				t.Error("los valores de latitud siguen en el archivo")
+			}
+		})
+
+		t.Run(tt.name+" todo", func(t *testing.T) {
+			data := tt.data(t)
+			stripped, err := exif.Strip(data, exif.StripAll)
+			if err != nil {
+				t.Fatalf("Strip() error = %v", err)
+			}
+			tt.check(t, stripped)
+			if len(stripped) >= len(data) {
+				t.Errorf("len = %d, want < %d", len(stripped), len(data))
+			}
+
+			got, err := exif.Parse(stripped)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got.Orientation != 6 || got.Make != "" || got.GPS != nil || !got.CapturedAt.IsZero() {
+				t.Errorf("Parse() = %+v, want solo Orientation 6", got)
+			}
+		})
+	}
+}
+
+func TestStrip_AllWithoutOrientation(t *testing.T) {
+	block := cameraBlock
+	block.IFD0 = []exiftest.Tag{exiftest.ASCII(0x010F, "Google")}
+
+	stripped, err := exif.Strip(block.JPEG(encodedJPEG(t)), exif.StripAll)
+	if err != nil {
+		t.Fatalf("Strip() error = %v", err)
+	}
+	if _, err := exif.Parse(stripped); !errors.Is(err, exif.ErrNotFound) {
+		t.Errorf("Parse() error = %v, want ErrNotFound", err)
+	}
+	if !bytes.Equal(stripped, encodedJPEG(t)) {
+		t.Error("sin orientación el resultado debe ser la imagen sin segmentos de metadatos")
+	}
+}
+
+func TestStrip_OtherSegments(t *testing.T) {
+	base := encodedJPEG(t)
+	tests := []struct {
+		name    string
+		data    []byte
+		mode    exif.StripMode
+		removed bool
+	}{
+		{name: "XMP con GPS", data: withSegment(base, 0xE1, xmpWithGPS), mode: exif.StripGPS, removed: true},
+		{name: "XMP sin GPS", data: withSegment(base, 0xE1, xmpWithoutGPS), mode: exif.StripGPS, removed: false},
+		{name: "XMP sin GPS, todo", data: withSegment(base, 0xE1, xmpWithoutGPS), mode: exif.StripAll, removed: true},
+		{name: "comentario", data: withSegment(base, 0xFE, "hecho en casa"), mode: exif.StripGPS, removed: false},
+		{name: "comentario, todo", data: withSegment(base, 0xFE, "hecho en casa"), mode: exif.StripAll, removed: true},
+		{name: "IPTC, todo", data: withSegment(base, 0xED, "Photoshop 3.0\x00"), mode: exif.StripAll, removed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stripped, err := exif.Strip(tt.data, tt.mode)
+			if err != nil {
+				t.Fatalf("Strip() error = %v", err)
+			}
+			want := tt.data
+			if tt.removed {
+				want = base
+			}
+			if !bytes.Equal(stripped, want) {
+				t.Errorf("len = %d, want %d", len(stripped), len(want))
+			}
+		})
+	}
+
+	if !exif.ContainsGPS(withSegment(base, 0xE1, xmpWithGPS)) {
+		t.Error("ContainsGPS() con XMP = false, want true")
+	}
+}
+
+func TestStrip_Errors(t *testing.T) {
+	webp := []byte("RIFF\x10\x00\x00\x00WEBPVP8 ")
+	if got, err := exif.Strip(webp, exif.StripAll); err != nil || !bytes.Equal(got, webp) {
+		t.Errorf("Strip() de otro formato = %v, %v; want sin cambios", got, err)
+	}
+
+	data := cameraBlock.JPEG(encodedJPEG(t))
+	if _, err := exif.Strip(data[:30], exif.StripGPS); !errors.Is(err, exif.ErrInvalid) {
+		t.Errorf("Strip() JPEG cortado error = %v, want ErrInvalid", err)
+	}
+
+	png := cameraBlock.PNG(encodedPNG(t))
+	if _, err := exif.Strip(png[:len(png)-6], exif.StripGPS); !errors.Is(err, exif.ErrInvalid) {
+		t.Errorf("Strip() PNG cortado error = %v, want ErrInvalid", err)
+	}
+
+	// Nunca debe entrar en pánico con datos alterados
+	for i := 0; i < 400 && i < len(data); i++ {
+		mutated := append([]byte(nil), data...)
+		mutated[i] ^= 0xFF
+		exif.Strip(mutated, exif.StripGPS)
+		exif.Strip(mutated, exif.StripAll)
+	}
+}
+
+func TestStripTo(t *testing.T) {
+	inputs := map[string][]byte{
+		"JPEG": withSegment(cameraBlock.JPEG(encodedJPEG(t)), 0xE1, xmpWithGPS),
+		"PNG":  cameraBlock.PNG(encodedPNG(t)),
+	}
+
+	for name, data := range inputs {
+		for _, mode := range []exif.StripMode{exif.StripGPS, exif.StripAll} {
+			want, err := exif.Strip(data, mode)
+			if err != nil {
+				t.Fatalf("%s: Strip() error = %v", name, err)
+			}
+
+			// Leer de a un byte obliga a cruzar cada segmento entre lecturas
+			var got bytes.Buffer
+			if err := exif.StripTo(&got, iotest.OneByteReader(bytes.NewReader(data)), mode); err != nil {
+				t.Fatalf("%s: StripTo() error = %v", name, err)
+			}
+			if !bytes.Equal(got.Bytes(), want) {
+				t.Errorf("%s modo %d: StripTo() difiere de Strip()", name, mode)
+			}
+		}
+	}
+
+	// Un error de lectura se devuelve tal cual, no como archivo corrupto
+	readErr := errors.New("conexión interrumpida")
+	reader := io.MultiReader(bytes.NewReader(inputs["JPEG"][:40]), iotest.ErrReader(readErr))
+	if err := exif.StripTo(io.Discard, reader, exif.StripGPS); !errors.Is(err, readErr) {
+		t.Errorf("StripTo() con lectura fallida error = %v, want %v", err, readErr)
+	}
+}
@@ -0,0 +1,154 @@
+package exif
+
+import (
+	"encoding/binary"
+	"strings"
+)
+
+// Tipos de campo TIFF
+const (
+	typeByte      = 1
+	typeASCII     = 2
+	typeShort     = 3
+	typeLong      = 4
+	typeRational  = 5
+	typeUndefined = 7
+	typeSLong     = 9
+	typeSRational = 10
+)
+
+// maxEntries acota las entradas de un directorio; un archivo real no tiene
+// más de unas pocas decenas
+const maxEntries = 1000
+
+var typeSizes = map[uint16]int{
+	typeByte:      1,
+	typeASCII:     1,
+	typeShort:     2,
+	typeLong:      4,
+	typeRational:  8,
+	typeUndefined: 1,
+	typeSLong:     4,
+	typeSRational: 8,
+}
+
+// field valor crudo de una entrada de directorio
+type field struct {
+	typ   uint16
+	count int
+	data  []byte
+	order binary.ByteOrder
+}
+
+// ifd directorio de etiquetas indexado por número de etiqueta
+type ifd map[uint16]field
+
+type tiffReader struct {
+	raw   []byte
+	order binary.ByteOrder
+}
+
+// readIFD lee el directorio que empieza en offset. Las entradas de tipos
+// desconocidos o con datos fuera del bloque se descartan.
+func (r tiffReader) readIFD(offset uint32) (ifd, error) {
+	start := int64(offset)
+	if start+2 > int64(len(r.raw)) {
+		return nil, ErrInvalid
+	}
+	n := int(r.order.Uint16(r.raw[start:]))
+	if n > maxEntries || start+2+int64(n)*12 > int64(len(r.raw)) {
+		return nil, ErrInvalid
+	}
+
+	dir := make(ifd, n)
+	for i := 0; i < n; i++ {
+		entry := r.raw[start+2+int64(i)*12:]
+		tag := r.order.Uint16(entry)
+		typ := r.order.Uint16(entry[2:])
+		count := int64(r.order.Uint32(entry[4:]))
+
+		size, ok := typeSizes[typ]
+		if !ok {
+			continue
+		}
+		total := count * int64(size)
+
+		var data []byte
+		if total <= 4 {
+			data = entry[8 : 8+total]
+		} else {
+			valueOffset := int64(r.order.Uint32(entry[8:]))
+			if valueOffset+total > int64(len(r.raw)) {
+				continue
+			}
+			data = r.raw[valueOffset : valueOffset+total]
+		}
+		dir[tag] = field{typ: typ, count: int(count), data: data, order: r.order}
+	}
+	return dir, nil
+}
+
+// uint devuelve el primer valor de un campo entero
+func (d ifd) uint(tag uint16) (uint32, bool) {
+	f, ok := d[tag]
+	if !ok || f.count < 1 {
+		return 0, false
+	}
+	switch f.typ {
+	case typeByte, typeUndefined:
+		return uint32(f.data[0]), true
+	case typeShort:
+		return uint32(f.order.Uint16(f.data)), true
+	case typeLong:
+		return f.order.Uint32(f.data), true
+	}
+	return 0, false
+}
+
+// rational devuelve el valor i de un campo racional
+func (d ifd) rational(tag uint16, i int) (float64, bool) {
+	f, ok := d[tag]
+	if !ok || i >= f.count {
+		return 0, false
+	}
+	value := f.data[i*8:]
+	switch f.typ {
+	case typeRational:
+		num, den := f.order.Uint32(value), f.order.Uint32(value[4:])
+		if den == 0 {
+			return 0, false
+		}
+		return float64(num) / float64(den), true
+	case typeSRational:
+		num, den := int32(f.order.Uint32(value)), int32(f.order.Uint32(value[4:]))
+		if den == 0 {
+			return 0, false
+		}
+		return float64(num) / float64(den), true
+	}
+	return 0, false
+}
+
+// degrees convierte un campo de grados, minutos y segundos a grados decimales
+func (d ifd) degrees(tag uint16) (float64, bool) {
+	deg, ok1 := d.rational(tag, 0)
+	minutes, ok2 := d.rational(tag, 1)
+	seconds, ok3 := d.rational(tag, 2)
+	if !ok1 || !ok2 || !ok3 {
+		return 0, false
+	}
+	return deg + minutes/60 + seconds/3600, true
+}
+
+// string devuelve un campo de texto sin el terminador ni espacios de relleno
+func (d ifd) string(tag uint16) string {
+	f, ok := d[tag]
+	if !ok || (f.typ != typeASCII && f.typ != typeUndefined) {
+		return ""
+	}
+	value := string(f.data)
+	if i := strings.IndexByte(value, 0); i >= 0 {
+		value = value[:i]
+	}
+	return strings.TrimSpace(value)
+}
@@ -0,0 +1,174 @@
+// Package webp implementa un codificador WebP sin pérdida (VP8L) en Go puro.
+//
+// El flujo generado usa la transformación "subtract green", una
+// transformación predictiva por bloques de 16x16 y códigos de prefijo
+// (Huffman) por canal, sin referencias hacia atrás ni caché de colores. El
+// resultado es mayor que el de libwebp pero cualquier decodificador WebP
+// estándar lo lee.
+package webp
+
+import (
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	"io"
+)
+
+const (
+	maxDimension = 16384
+
+	// predictorBits define bloques de 1<<predictorBits píxeles de lado
+	predictorBits = 4
+
+	transformPredictor     = 0
+	transformSubtractGreen = 2
+
+	numLiteralCodes  = 256
+	numLengthCodes   = 24
+	numDistanceCodes = 40
+)
+
+// ErrTooLarge indica que la imagen supera las dimensiones que admite WebP
+var ErrTooLarge = errors.New("webp: la imagen supera 16384x16384 píxeles")
+
+// Encode escribe img en formato WebP sin pérdida
+func Encode(w io.Writer, img image.Image) error {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width < 1 || height < 1 {
+		return errors.New("webp: imagen vacía")
+	}
+	if width > maxDimension || height > maxDimension {
+		return ErrTooLarge
+	}
+
+	argb, hasAlpha := toARGB(img)
+
+	bw := &bitWriter{}
+	bw.write(0x2f, 8)
+	bw.write(uint32(width-1), 14)
+	bw.write(uint32(height-1), 14)
+	if hasAlpha {
+		bw.write(1, 1)
+	} else {
+		bw.write(0, 1)
+	}
+	bw.write(0, 3) // versión
+
+	// Las transformaciones se escriben en el orden en que se aplican; el
+	// decodificador las deshace en orden inverso
+	subtractGreen(argb)
+	bw.write(1, 1)
+	bw.write(transformSubtractGreen, 2)
+
+	modes, tilesX := choosePredictors(argb, width, height)
+	bw.write(1, 1)
+	bw.write(transformPredictor, 2)
+	bw.write(predictorBits-2, 3)
+	writeEntropyImage(bw, modes, false)
+
+	residuals := predictResiduals(argb, width, height, modes, tilesX)
+
+	bw.write(0, 1) // no hay más transformaciones
+	writeEntropyImage(bw, residuals, true)
+
+	return writeContainer(w, bw.bytes())
+}
+
+// writeContainer envuelve el flujo VP8L en el contenedor RIFF
+func writeContainer(w io.Writer, payload []byte) error {
+	padding := len(payload) & 1
+
+	header := make([]byte, 20)
+	copy(header[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:8], uint32(4+8+len(payload)+padding))
+	copy(header[8:12], "WEBP")
+	copy(header[12:16], "VP8L")
+	binary.LittleEndian.PutUint32(header[16:20], uint32(len(payload)))
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if _, err := w.Write(payload); err != nil {
+		return err
+	}
+	if padding == 1 {
+		_, err := w.Write([]byte{0})
+		return err
+	}
+	return nil
+}
+
+// toARGB convierte la imagen en píxeles ARGB sin premultiplicar
+func toARGB(img image.Image) ([]uint32, bool) {
+	bounds := img.Bounds()
+	nrgba, ok := img.(*image.NRGBA)
+	if !ok {
+		nrgba = image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+		draw.Draw(nrgba, nrgba.Bounds(), img, bounds.Min, draw.Src)
+		bounds = nrgba.Bounds()
+	}
+
+	width, height := bounds.Dx(), bounds.Dy()
+	argb := make([]uint32, width*height)
+	hasAlpha := false
+
+	for y := 0; y < height; y++ {
+		row := nrgba.Pix[nrgba.PixOffset(bounds.Min.X, bounds.Min.Y+y):]
+		for x := 0; x < width; x++ {
+			r, g, b, a := row[4*x], row[4*x+1], row[4*x+2], row[4*x+3]
+			if a != 0xff {
+				hasAlpha = true
+			}
+			argb[y*width+x] = uint32(a)<<24 | uint32(r)<<16 | uint32(g)<<8 | uint32(b)
+		}
+	}
+
+	return argb, hasAlpha
+}
+
+// subtractGreen resta el verde de los canales rojo y azul
+func subtractGreen(argb []uint32) {
+	for i, p := range argb {
+		g := (p >> 8) & 0xff
+		r := ((p >> 16) - g) & 0xff
+		b := (p - g) & 0xff
+		argb[i] = p&0xff00ff00 | r<<16 | b
+	}
+}
+
+// writeEntropyImage escribe una imagen con un único grupo de códigos de
+// prefijo. Solo la imagen principal lleva el bit de meta códigos.
+func writeEntropyImage(bw *bitWriter, pixels []uint32, main bool) {
+	bw.write(0, 1) // sin caché de colores
+	if main {
+		bw.write(0, 1) // sin meta códigos de prefijo
+	}
+
+	green := make([]uint32, numLiteralCodes+numLengthCodes)
+	red := make([]uint32, numLiteralCodes)
+	blue := make([]uint32, numLiteralCodes)
+	alpha := make([]uint32, numLiteralCodes)
+	distance := make([]uint32, numDistanceCodes)
+
+	for _, p := range pixels {
+		alpha[p>>24]++
+		red[(p>>16)&0xff]++
+		green[(p>>8)&0xff]++
+		blue[p&0xff]++
+	}
+
+	greenCode := writePrefixCode(bw, green)
+	redCode := writePrefixCode(bw, red)
+	blueCode := writePrefixCode(bw, blue)
+	alphaCode := writePrefixCode(bw, alpha)
+	writePrefixCode(bw, distance)
+
+	for _, p := range pixels {
+		greenCode.write(bw, int((p>>8)&0xff))
+		redCode.write(bw, int((p>>16)&0xff))
+		blueCode.write(bw, int(p&0xff))
+		alphaCode.write(bw, int(p>>24))
+	}
+}
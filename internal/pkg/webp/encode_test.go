@@ -0,0 +1,95 @@
+package webp_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+
+	"github.com/RodrigoGonzalez78/internal/pkg/webp"
+	xwebp "golang.org/x/image/webp"
+)
+
+func gradient(w, h int, alpha bool) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			a := uint8(255)
+			if alpha {
+				a = uint8((x + y) % 256)
+			}
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(x * 3), G: uint8(y * 5), B: uint8(x ^ y), A: a})
+		}
+	}
+	return img
+}
+
+func noise(w, h int) *image.NRGBA {
+	rng := rand.New(rand.NewSource(1))
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	rng.Read(img.Pix)
+	return img
+}
+
+func solid(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = 200
+	}
+	return img
+}
+
+func TestEncode_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		img  image.Image
+	}{
+		{name: "un píxel", img: solid(1, 1)},
+		{name: "color sólido", img: solid(33, 17)},
+		{name: "degradado opaco", img: gradient(100, 70, false)},
+		{name: "degradado con alfa", img: gradient(64, 64, true)},
+		{name: "ruido", img: noise(50, 41)},
+		{name: "una fila", img: gradient(300, 1, false)},
+		{name: "una columna", img: gradient(1, 90, false)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := webp.Encode(&buf, tt.img); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+
+			decoded, err := xwebp.Decode(bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+
+			bounds := tt.img.Bounds()
+			if decoded.Bounds().Size() != bounds.Size() {
+				t.Fatalf("dimensiones = %v, want %v", decoded.Bounds().Size(), bounds.Size())
+			}
+
+			for y := 0; y < bounds.Dy(); y++ {
+				for x := 0; x < bounds.Dx(); x++ {
+					want := color.NRGBAModel.Convert(tt.img.At(bounds.Min.X+x, bounds.Min.Y+y))
+					got := color.NRGBAModel.Convert(decoded.At(x, y))
+					if want != got {
+						t.Fatalf("píxel (%d,%d) = %v, want %v", x, y, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestEncode_InvalidSize(t *testing.T) {
+	var buf bytes.Buffer
+	if err := webp.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 0, 0))); err == nil {
+		t.Error("Encode() de una imagen vacía se esperaba error")
+	}
+	if err := webp.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 16385, 1))); err != webp.ErrTooLarge {
+		t.Errorf("Encode() error = %v, want ErrTooLarge", err)
+	}
+}
@@ -0,0 +1,188 @@
+package webp
+
+// candidateModes son los modos de predicción que se prueban en cada bloque.
+// Cubren bordes horizontales, verticales, degradados y texturas sin probar
+// los 14 modos del formato.
+var candidateModes = []int{1, 2, 7, 11, 12, 13}
+
+// choosePredictors elige para cada bloque el modo que minimiza la suma de los
+// residuos. Devuelve la subimagen de modos (modo en el canal verde).
+func choosePredictors(argb []uint32, width, height int) ([]uint32, int) {
+	blockSize := 1 << predictorBits
+	tilesX := (width + blockSize - 1) / blockSize
+	tilesY := (height + blockSize - 1) / blockSize
+	modes := make([]uint32, tilesX*tilesY)
+
+	for ty := 0; ty < tilesY; ty++ {
+		for tx := 0; tx < tilesX; tx++ {
+			bestMode, bestCost := candidateModes[0], -1
+
+			for _, mode := range candidateModes {
+				cost := 0
+				for y := ty * blockSize; y < min((ty+1)*blockSize, height); y++ {
+					for x := tx * blockSize; x < min((tx+1)*blockSize, width); x++ {
+						if x == 0 || y == 0 {
+							continue
+						}
+						i := y*width + x
+						cost += residualCost(argb[i], predict(mode, argb, i, width))
+					}
+					if bestCost >= 0 && cost >= bestCost {
+						break
+					}
+				}
+				if bestCost < 0 || cost < bestCost {
+					bestMode, bestCost = mode, cost
+				}
+			}
+
+			modes[ty*tilesX+tx] = 0xff000000 | uint32(bestMode)<<8
+		}
+	}
+
+	return modes, tilesX
+}
+
+// predictResiduals reemplaza cada píxel por su diferencia con la predicción
+func predictResiduals(argb []uint32, width, height int, modes []uint32, tilesX int) []uint32 {
+	residuals := make([]uint32, len(argb))
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := y*width + x
+
+			var predicted uint32
+			switch {
+			case x == 0 && y == 0:
+				predicted = 0xff000000
+			case y == 0:
+				predicted = argb[i-1]
+			case x == 0:
+				predicted = argb[i-width]
+			default:
+				mode := int(modes[(y>>predictorBits)*tilesX+(x>>predictorBits)]>>8) & 0xff
+				predicted = predict(mode, argb, i, width)
+			}
+
+			residuals[i] = subPixels(argb[i], predicted)
+		}
+	}
+
+	return residuals
+}
+
+// predict calcula la predicción de un píxel que no está en la primera fila ni
+// en la primera columna. Para la última columna, TR es el primer píxel de la
+// fila actual, tal como define el formato.
+func predict(mode int, argb []uint32, i, width int) uint32 {
+	l := argb[i-1]
+	t := argb[i-width]
+	tl := argb[i-width-1]
+	tr := argb[i-width+1]
+
+	switch mode {
+	case 0:
+		return 0xff000000
+	case 1:
+		return l
+	case 2:
+		return t
+	case 3:
+		return tr
+	case 4:
+		return tl
+	case 5:
+		return average2(average2(l, tr), t)
+	case 6:
+		return average2(l, tl)
+	case 7:
+		return average2(l, t)
+	case 8:
+		return average2(tl, t)
+	case 9:
+		return average2(t, tr)
+	case 10:
+		return average2(average2(l, tl), average2(t, tr))
+	case 11:
+		return selectPredictor(l, t, tl)
+	case 12:
+		return clampAddSubtractFull(l, t, tl)
+	default:
+		return clampAddSubtractHalf(average2(l, t), tl)
+	}
+}
+
+func channel(p uint32, shift uint) int {
+	return int((p >> shift) & 0xff)
+}
+
+func average2(a, b uint32) uint32 {
+	return (((a ^ b) & 0xfefefefe) >> 1) + (a & b)
+}
+
+func selectPredictor(l, t, tl uint32) uint32 {
+	pL, pT := 0, 0
+	for shift := uint(0); shift < 32; shift += 8 {
+		estimate := channel(l, shift) + channel(t, shift) - channel(tl, shift)
+		pL += abs(estimate - channel(l, shift))
+		pT += abs(estimate - channel(t, shift))
+	}
+	if pL < pT {
+		return l
+	}
+	return t
+}
+
+func clampAddSubtractFull(a, b, c uint32) uint32 {
+	var result uint32
+	for shift := uint(0); shift < 32; shift += 8 {
+		v := clamp(channel(a, shift) + channel(b, shift) - channel(c, shift))
+		result |= uint32(v) << shift
+	}
+	return result
+}
+
+func clampAddSubtractHalf(a, b uint32) uint32 {
+	var result uint32
+	for shift := uint(0); shift < 32; shift += 8 {
+		ca := channel(a, shift)
+		v := clamp(ca + (ca-channel(b, shift))/2)
+		result |= uint32(v) << shift
+	}
+	return result
+}
+
+// subPixels resta canal por canal módulo 256
+func subPixels(a, b uint32) uint32 {
+	var result uint32
+	for shift := uint(0); shift < 32; shift += 8 {
+		result |= uint32((channel(a, shift)-channel(b, shift))&0xff) << shift
+	}
+	return result
+}
+
+// residualCost estima el costo de codificar la diferencia entre dos píxeles
+func residualCost(a, b uint32) int {
+	cost := 0
+	for shift := uint(0); shift < 32; shift += 8 {
+		cost += abs(int(int8(channel(a, shift) - channel(b, shift))))
+	}
+	return cost
+}
+
+func clamp(v int) int {
+	if v < 0 {
+		return 0
+	}
+	if v > 255 {
+		return 255
+	}
+	return v
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
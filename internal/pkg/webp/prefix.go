@@ -0,0 +1,317 @@
+package webp
+
+import (
+	"container/heap"
+	"sort"
+)
+
+const (
+	maxCodeLength           = 15
+	maxCodeLengthCodeLength = 7
+	defaultCodeLength       = 8
+)
+
+// codeLengthCodeOrder es el orden en que se escriben las longitudes del
+// código de longitudes
+var codeLengthCodeOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// bitWriter escribe bits empezando por el menos significativo, como exige VP8L
+type bitWriter struct {
+	buf   []byte
+	acc   uint64
+	nBits uint
+}
+
+func (w *bitWriter) write(value uint32, bits uint) {
+	w.acc |= uint64(value) << w.nBits
+	w.nBits += bits
+	for w.nBits >= 8 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc >>= 8
+		w.nBits -= 8
+	}
+}
+
+func (w *bitWriter) bytes() []byte {
+	if w.nBits > 0 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc, w.nBits = 0, 0
+	}
+	return w.buf
+}
+
+// prefixCode guarda los códigos ya invertidos para escribirse LSB primero
+type prefixCode struct {
+	lengths []uint8
+	codes   []uint32
+}
+
+func (c *prefixCode) write(w *bitWriter, symbol int) {
+	if length := c.lengths[symbol]; length > 0 {
+		w.write(c.codes[symbol], uint(length))
+	}
+}
+
+// writePrefixCode escribe el código para el histograma y lo devuelve. Con uno
+// o dos símbolos usa el código simple; con un único símbolo cada aparición
+// ocupa cero bits.
+func writePrefixCode(w *bitWriter, counts []uint32) *prefixCode {
+	var used []int
+	for symbol, count := range counts {
+		if count > 0 {
+			used = append(used, symbol)
+		}
+	}
+
+	if len(used) <= 2 && (len(used) == 0 || used[len(used)-1] < 256) {
+		return writeSimpleCode(w, used, len(counts))
+	}
+
+	lengths := buildLengths(counts, maxCodeLength)
+	w.write(0, 1)
+	writeCodeLengths(w, lengths)
+	return &prefixCode{lengths: lengths, codes: canonicalCodes(lengths)}
+}
+
+func writeSimpleCode(w *bitWriter, used []int, alphabetSize int) *prefixCode {
+	if len(used) == 0 {
+		used = []int{0}
+	}
+
+	w.write(1, 1)
+	w.write(uint32(len(used)-1), 1)
+	if used[0] < 2 {
+		w.write(0, 1)
+		w.write(uint32(used[0]), 1)
+	} else {
+		w.write(1, 1)
+		w.write(uint32(used[0]), 8)
+	}
+
+	code := &prefixCode{
+		lengths: make([]uint8, alphabetSize),
+		codes:   make([]uint32, alphabetSize),
+	}
+
+	// Con dos símbolos el primero (el menor) recibe el código 0
+	if len(used) == 2 {
+		w.write(uint32(used[1]), 8)
+		code.lengths[used[0]], code.codes[used[0]] = 1, 0
+		code.lengths[used[1]], code.codes[used[1]] = 1, 1
+	}
+
+	return code
+}
+
+type codeLengthToken struct {
+	code      int
+	extraBits uint
+	extra     uint32
+}
+
+// writeCodeLengths escribe las longitudes con el código de longitudes,
+// comprimiendo repeticiones con los símbolos 16, 17 y 18
+func writeCodeLengths(w *bitWriter, lengths []uint8) {
+	var tokens []codeLengthToken
+	prev := uint8(defaultCodeLength)
+
+	for i := 0; i < len(lengths); {
+		value := lengths[i]
+		run := 1
+		for i+run < len(lengths) && lengths[i+run] == value {
+			run++
+		}
+
+		switch {
+		case value == 0 && run >= 11:
+			n := min(run, 138)
+			tokens = append(tokens, codeLengthToken{code: 18, extraBits: 7, extra: uint32(n - 11)})
+			i += n
+		case value == 0 && run >= 3:
+			n := min(run, 10)
+			tokens = append(tokens, codeLengthToken{code: 17, extraBits: 3, extra: uint32(n - 3)})
+			i += n
+		case value != 0 && value == prev && run >= 3:
+			n := min(run, 6)
+			tokens = append(tokens, codeLengthToken{code: 16, extraBits: 2, extra: uint32(n - 3)})
+			i += n
+		default:
+			tokens = append(tokens, codeLengthToken{code: int(value)})
+			if value != 0 {
+				prev = value
+			}
+			i++
+		}
+	}
+
+	counts := make([]uint32, len(codeLengthCodeOrder))
+	for _, token := range tokens {
+		counts[token.code]++
+	}
+	clLengths := buildLengths(counts, maxCodeLengthCodeLength)
+	clCodes := canonicalCodes(clLengths)
+
+	numCodes := len(codeLengthCodeOrder)
+	for numCodes > 4 && clLengths[codeLengthCodeOrder[numCodes-1]] == 0 {
+		numCodes--
+	}
+
+	w.write(uint32(numCodes-4), 4)
+	for i := 0; i < numCodes; i++ {
+		w.write(uint32(clLengths[codeLengthCodeOrder[i]]), 3)
+	}
+	w.write(0, 1) // se escriben las longitudes de todo el alfabeto
+
+	code := &prefixCode{lengths: clLengths, codes: clCodes}
+	for _, token := range tokens {
+		code.write(w, token.code)
+		if token.extraBits > 0 {
+			w.write(token.extra, token.extraBits)
+		}
+	}
+}
+
+// buildLengths calcula longitudes de Huffman acotadas a maxLength. Siempre
+// devuelve al menos dos símbolos con longitud distinta de cero para que el
+// código quede completo.
+func buildLengths(counts []uint32, maxLength int) []uint8 {
+	weights := make([]uint64, len(counts))
+	used := 0
+	for i, count := range counts {
+		weights[i] = uint64(count)
+		if count > 0 {
+			used++
+		}
+	}
+
+	// Un código de un solo símbolo no ocupa bits; se agrega uno de relleno
+	for i := 0; used < 2 && i < len(weights); i++ {
+		if weights[i] == 0 {
+			weights[i] = 1
+			used++
+		}
+	}
+
+	for {
+		lengths, depth := huffmanLengths(weights)
+		if depth <= maxLength {
+			return lengths
+		}
+		// Aplanar la distribución hasta que el árbol entre en maxLength
+		for i, weight := range weights {
+			if weight > 0 {
+				weights[i] = (weight + 1) / 2
+			}
+		}
+	}
+}
+
+type huffmanNode struct {
+	weight      uint64
+	symbol      int
+	left, right int
+}
+
+type nodeHeap struct {
+	nodes []huffmanNode
+	items []int
+}
+
+func (h *nodeHeap) Len() int { return len(h.items) }
+func (h *nodeHeap) Less(i, j int) bool {
+	a, b := h.nodes[h.items[i]], h.nodes[h.items[j]]
+	if a.weight != b.weight {
+		return a.weight < b.weight
+	}
+	return h.items[i] < h.items[j]
+}
+func (h *nodeHeap) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *nodeHeap) Push(x any)    { h.items = append(h.items, x.(int)) }
+func (h *nodeHeap) Pop() any {
+	last := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return last
+}
+
+func huffmanLengths(weights []uint64) ([]uint8, int) {
+	h := &nodeHeap{}
+	for symbol, weight := range weights {
+		if weight > 0 {
+			h.nodes = append(h.nodes, huffmanNode{weight: weight, symbol: symbol, left: -1, right: -1})
+			h.items = append(h.items, len(h.nodes)-1)
+		}
+	}
+	heap.Init(h)
+
+	for h.Len() > 1 {
+		a := heap.Pop(h).(int)
+		b := heap.Pop(h).(int)
+		h.nodes = append(h.nodes, huffmanNode{
+			weight: h.nodes[a].weight + h.nodes[b].weight,
+			symbol: -1,
+			left:   a,
+			right:  b,
+		})
+		heap.Push(h, len(h.nodes)-1)
+	}
+
+	lengths := make([]uint8, len(weights))
+	maxDepth := 0
+
+	var walk func(node, depth int)
+	walk = func(node, depth int) {
+		n := h.nodes[node]
+		if n.symbol >= 0 {
+			lengths[n.symbol] = uint8(depth)
+			maxDepth = max(maxDepth, depth)
+			return
+		}
+		walk(n.left, depth+1)
+		walk(n.right, depth+1)
+	}
+	walk(h.items[0], 0)
+
+	return lengths, maxDepth
+}
+
+// canonicalCodes asigna los códigos canónicos y los invierte para que el
+// primer bit leído sea el más significativo del código
+func canonicalCodes(lengths []uint8) []uint32 {
+	var blCount [maxCodeLength + 1]uint32
+	for _, length := range lengths {
+		if length > 0 {
+			blCount[length]++
+		}
+	}
+
+	var nextCode [maxCodeLength + 1]uint32
+	code := uint32(0)
+	for bits := 1; bits <= maxCodeLength; bits++ {
+		code = (code + blCount[bits-1]) << 1
+		nextCode[bits] = code
+	}
+
+	symbols := make([]int, 0, len(lengths))
+	for symbol, length := range lengths {
+		if length > 0 {
+			symbols = append(symbols, symbol)
+		}
+	}
+	sort.Ints(symbols)
+
+	codes := make([]uint32, len(lengths))
+	for _, symbol := range symbols {
+		length := lengths[symbol]
+		codes[symbol] = reverseBits(nextCode[length], uint(length))
+		nextCode[length]++
+	}
+	return codes
+}
+
+func reverseBits(code uint32, length uint) uint32 {
+	var reversed uint32
+	for i := uint(0); i < length; i++ {
+		reversed = reversed<<1 | (code>>i)&1
+	}
+	return reversed
+}